@@ -43,6 +43,7 @@ type Config struct {
 	MinVersion             string              `mapstructure:"min_version" yaml:"min_version"`
 	DonetickCloudConfig    DonetickCloudConfig `mapstructure:"donetick_cloud" yaml:"donetick_cloud"`
 	FCM                    FCMConfig           `mapstructure:"fcm" yaml:"fcm"`
+	WebPush                WebPushConfig       `mapstructure:"webpush" yaml:"webpush"`
 	FeatureLimits          FeatureLimitsConfig `mapstructure:"feature_limits" yaml:"feature_limits"`
 	Storage                StorageConfig       `mapstructure:"storage" yaml:"storage"`
 	Info                   Info
@@ -54,6 +55,8 @@ type Info struct {
 	BuildDate string
 }
 type StorageConfig struct {
+	// StorageType is either "s3" (default) or "local". Local storage keeps files under
+	// BasePath and serves them through signed /api/v1/assets URLs.
 	StorageType string `mapstructure:"storage_type" yaml:"storage_type"`
 	// CloudStorage:
 	BucketName     string `mapstructure:"bucket_name" yaml:"bucket_name"`
@@ -62,8 +65,8 @@ type StorageConfig struct {
 	AccessKey      string `mapstructure:"access_key" yaml:"access_key"`
 	SecretKey      string `mapstructure:"secret_key" yaml:"secret_key"`
 	Endpoint       string `mapstructure:"endpoint" yaml:"endpoint"`
-	MaxUserStorage int    `mapstructure:"max_user_storage" yaml:"max_user_storage"`
-	MaxFileSize    int64  `mapstructure:"max_file_size" yaml:"max_file_size"`
+	MaxUserStorage int    `mapstructure:"max_user_storage" yaml:"max_user_storage" default:"1073741824"`
+	MaxFileSize    int64  `mapstructure:"max_file_size" yaml:"max_file_size" default:"10485760"`
 	PublicHost     string `mapstructure:"public_host" yaml:"public_host"`
 }
 type DonetickCloudConfig struct {
@@ -82,6 +85,10 @@ type FeatureLimitsConfig struct {
 
 type TelegramConfig struct {
 	Token string `mapstructure:"token" yaml:"token"`
+	// EnableBot long polls the bot for reminder buttons and commands. Only one instance may poll a token.
+	EnableBot bool `mapstructure:"enable_bot" yaml:"enable_bot"`
+	// BotUsername builds the t.me links that open the bot with a link code, e.g. "donetick_bot"
+	BotUsername string `mapstructure:"bot_username" yaml:"bot_username"`
 }
 
 type PushoverConfig struct {
@@ -122,6 +129,8 @@ type SchedulerConfig struct {
 	DueJob     time.Duration `mapstructure:"due_job" yaml:"due_job"`
 	OverdueJob time.Duration `mapstructure:"overdue_job" yaml:"overdue_job"`
 	PreDueJob  time.Duration `mapstructure:"pre_due_job" yaml:"pre_due_job"`
+	// DeadlineJob is how often chores past their deadline are marked as missed
+	DeadlineJob time.Duration `mapstructure:"deadline_job" yaml:"deadline_job" default:"5m"`
 }
 
 type StripeConfig struct {
@@ -167,6 +176,17 @@ type FCMConfig struct {
 	CredentialsPath string `json:"credentials_path" mapstructure:"credentials_path"`
 	ProjectID       string `json:"project_id" mapstructure:"project_id"`
 }
+
+// WebPushConfig holds the VAPID key pair browser push subscriptions are made with. Generate the
+// keys once, e.g. with `npx web-push generate-vapid-keys`; changing them invalidates every
+// subscription.
+type WebPushConfig struct {
+	PublicKey  string `mapstructure:"public_key" yaml:"public_key"`
+	PrivateKey string `mapstructure:"private_key" yaml:"private_key"`
+	// Subject is a mailto: or https: contact for push services, e.g. mailto:admin@example.com
+	Subject string `mapstructure:"subject" yaml:"subject"`
+}
+
 type EmailConfig struct {
 	Email   string `mapstructure:"email"`
 	User    string `mapstructure:"user"`
@@ -190,6 +210,13 @@ type OAuth2Config struct {
 type WebhookConfig struct {
 	Timeout   time.Duration `mapstructure:"timeout" yaml:"timeout" default:"5s"`
 	QueueSize int           `mapstructure:"queue_size" yaml:"queue_size" default:"100"`
+	// MaxAttempts is how many times a delivery is tried before it is marked as failed
+	MaxAttempts int `mapstructure:"max_attempts" yaml:"max_attempts" default:"8"`
+	// RetryBackoff is the delay before the first retry, doubled on every following attempt
+	RetryBackoff    time.Duration `mapstructure:"retry_backoff" yaml:"retry_backoff" default:"30s"`
+	MaxRetryBackoff time.Duration `mapstructure:"max_retry_backoff" yaml:"max_retry_backoff" default:"1h"`
+	// Retention is how long finished deliveries are kept for the delivery log
+	Retention time.Duration `mapstructure:"retention" yaml:"retention" default:"720h"`
 }
 
 type RealTimeConfig struct {
@@ -207,6 +234,16 @@ type RealTimeConfig struct {
 	EnableCompression     bool          `mapstructure:"enable_compression" yaml:"enable_compression" default:"true"`
 	EnableStats           bool          `mapstructure:"enable_stats" yaml:"enable_stats" default:"true"`
 	AllowedOrigins        []string      `mapstructure:"allowed_origins" yaml:"allowed_origins"`
+	// Broker is either "memory" (default, single instance) or "postgres", which fans events out
+	// to every instance through LISTEN/NOTIFY so replicas can run behind a load balancer. The
+	// postgres broker requires ReplayPersist, so every instance numbers events from the same table.
+	Broker string `mapstructure:"broker" yaml:"broker" default:"memory"`
+	// ReplayBufferSize is the number of recent events kept per circle so reconnecting clients can
+	// catch up with Last-Event-ID. ReplayPersist also stores events in the database, which lets
+	// replay survive restarts and keeps event IDs consistent across instances.
+	ReplayBufferSize int           `mapstructure:"replay_buffer_size" yaml:"replay_buffer_size" default:"256"`
+	ReplayPersist    bool          `mapstructure:"replay_persist" yaml:"replay_persist" default:"false"`
+	ReplayRetention  time.Duration `mapstructure:"replay_retention" yaml:"replay_retention" default:"24h"`
 }
 
 type MFAConfig struct {
@@ -259,6 +296,9 @@ func NewConfig() *Config {
 			EnableCompression:     true,
 			EnableStats:           true,
 			AllowedOrigins:        []string{"*"},
+			Broker:                "memory",
+			ReplayBufferSize:      256,
+			ReplayRetention:       24 * time.Hour,
 		},
 		Logging: LogConfig{
 			Level:       "info",
@@ -277,9 +317,21 @@ func configEnvironmentOverrides(config *Config) {
 	if os.Getenv("DONETICK_TELEGRAM_TOKEN") != "" {
 		config.Telegram.Token = os.Getenv("DONETICK_TELEGRAM_TOKEN")
 	}
+	if os.Getenv("DONETICK_TELEGRAM_ENABLE_BOT") == "true" {
+		config.Telegram.EnableBot = true
+	}
+	if os.Getenv("DONETICK_TELEGRAM_BOT_USERNAME") != "" {
+		config.Telegram.BotUsername = os.Getenv("DONETICK_TELEGRAM_BOT_USERNAME")
+	}
 	if os.Getenv("DONETICK_PUSHOVER_TOKEN") != "" {
 		config.Pushover.Token = os.Getenv("DONETICK_PUSHOVER_TOKEN")
 	}
+	if os.Getenv("DONETICK_WEBPUSH_PUBLIC_KEY") != "" {
+		config.WebPush.PublicKey = os.Getenv("DONETICK_WEBPUSH_PUBLIC_KEY")
+	}
+	if os.Getenv("DONETICK_WEBPUSH_PRIVATE_KEY") != "" {
+		config.WebPush.PrivateKey = os.Getenv("DONETICK_WEBPUSH_PRIVATE_KEY")
+	}
 	if os.Getenv("DONETICK_DISABLE_SIGNUP") == "true" {
 		config.IsUserCreationDisabled = true
 	}
@@ -6,6 +6,7 @@ toolchain go1.24.3
 
 require (
 	firebase.google.com/go/v4 v4.18.0
+	github.com/SherClockHolmes/webpush-go v1.4.0
 	github.com/appleboy/gin-jwt/v2 v2.9.2
 	github.com/aws/aws-sdk-go v1.55.7
 	github.com/gin-contrib/cors v1.7.2
@@ -17,6 +18,7 @@ require (
 	github.com/google/uuid v1.6.0
 	github.com/gorilla/websocket v1.5.3
 	github.com/gregdel/pushover v1.3.1
+	github.com/jackc/pgx/v5 v5.5.5
 	github.com/mcuadros/go-defaults v1.2.0
 	github.com/pquerna/otp v1.5.0
 	github.com/quasilyte/go-ruleguard/dsl v0.3.23
@@ -27,6 +29,7 @@ require (
 	github.com/swaggo/files v1.0.1
 	github.com/swaggo/gin-swagger v1.6.1
 	github.com/swaggo/swag v1.16.6
+	github.com/teambition/rrule-go v1.8.2
 	github.com/ulule/limiter/v3 v3.11.2
 	go.uber.org/fx v1.22.0
 	go.uber.org/zap v1.27.0
@@ -104,7 +107,6 @@ require (
 	github.com/hashicorp/go-version v1.6.0 // indirect
 	github.com/jackc/pgpassfile v1.0.0 // indirect
 	github.com/jackc/pgservicefile v0.0.0-20221227161230-091c0ba34f0a // indirect
-	github.com/jackc/pgx/v5 v5.5.5 // indirect
 	github.com/jackc/puddle/v2 v2.2.1 // indirect
 	github.com/jinzhu/inflection v1.0.0 // indirect
 	github.com/jinzhu/now v1.1.5 // indirect
@@ -0,0 +1,256 @@
+package calendar
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"donetick.com/core/internal/auth"
+	calModel "donetick.com/core/internal/calendar/model"
+	calRepo "donetick.com/core/internal/calendar/repo"
+	chModel "donetick.com/core/internal/chore/model"
+	chRepo "donetick.com/core/internal/chore/repo"
+	cRepo "donetick.com/core/internal/circle/repo"
+	"donetick.com/core/internal/utils"
+	"donetick.com/core/logging"
+	"github.com/gin-gonic/gin"
+	limiter "github.com/ulule/limiter/v3"
+	"gorm.io/gorm"
+)
+
+const feedRoutePrefix = "/api/v1/calendar/ics/"
+
+type Handler struct {
+	calendarRepo *calRepo.CalendarRepository
+	choreRepo    *chRepo.ChoreRepository
+	circleRepo   *cRepo.CircleRepository
+}
+
+func NewHandler(calr *calRepo.CalendarRepository, cr *chRepo.ChoreRepository, circleRepo *cRepo.CircleRepository) *Handler {
+	return &Handler{
+		calendarRepo: calr,
+		choreRepo:    cr,
+		circleRepo:   circleRepo,
+	}
+}
+
+type feedResponse struct {
+	*calModel.CalendarFeed
+	URL string `json:"url"`
+}
+
+func newFeedResponse(feed *calModel.CalendarFeed) feedResponse {
+	return feedResponse{CalendarFeed: feed, URL: feedRoutePrefix + feed.Token + ".ics"}
+}
+
+func generateFeedToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// getFeeds godoc
+//
+//	@Summary		List calendar feeds
+//	@Description	Lists the calendar subscription feeds of the current user in the current circle
+//	@Tags			calendar
+//	@Produce		json
+//	@Security		JWTKeyAuth
+//	@Security		APIKeyAuth
+//	@Success		200	{object}	map[string][]feedResponse	"res: array of feeds"
+//	@Failure		500	{object}	map[string]string			"error: Error getting calendar feeds"
+//	@Router			/calendar/feeds [get]
+func (h *Handler) getFeeds(c *gin.Context) {
+	currentUser, ok := auth.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error getting current user"})
+		return
+	}
+
+	feeds, err := h.calendarRepo.GetUserFeeds(c, currentUser.ID, currentUser.CircleID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error getting calendar feeds"})
+		return
+	}
+	res := make([]feedResponse, 0, len(feeds))
+	for _, feed := range feeds {
+		res = append(res, newFeedResponse(feed))
+	}
+	c.JSON(http.StatusOK, gin.H{"res": res})
+}
+
+// createFeed godoc
+//
+//	@Summary		Create calendar feed
+//	@Description	Creates a tokenized, read-only iCalendar URL with the chores of the current circle
+//	@Tags			calendar
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWTKeyAuth
+//	@Security		APIKeyAuth
+//	@Param			request	body		object{assignedOnly=bool}	false	"Only include chores assigned to the current user"
+//	@Success		201		{object}	map[string]feedResponse		"res: the created feed"
+//	@Failure		500		{object}	map[string]string			"error: Error creating calendar feed"
+//	@Router			/calendar/feeds [post]
+func (h *Handler) createFeed(c *gin.Context) {
+	log := logging.FromContext(c)
+	currentUser, ok := auth.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error getting current user"})
+		return
+	}
+
+	type Request struct {
+		AssignedOnly bool `json:"assignedOnly"`
+	}
+	var req Request
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+			return
+		}
+	}
+
+	token, err := generateFeedToken()
+	if err != nil {
+		log.Errorw("Failed to generate calendar feed token", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creating calendar feed"})
+		return
+	}
+	feed := &calModel.CalendarFeed{
+		UserID:       currentUser.ID,
+		CircleID:     currentUser.CircleID,
+		Token:        token,
+		AssignedOnly: req.AssignedOnly,
+	}
+	if err := h.calendarRepo.CreateFeed(c, feed); err != nil {
+		log.Errorw("Failed to create calendar feed", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creating calendar feed"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"res": newFeedResponse(feed)})
+}
+
+// deleteFeed godoc
+//
+//	@Summary		Revoke calendar feed
+//	@Description	Deletes a calendar feed, after which its URL stops working
+//	@Tags			calendar
+//	@Produce		json
+//	@Security		JWTKeyAuth
+//	@Security		APIKeyAuth
+//	@Param			id	path		int					true	"Feed ID"
+//	@Success		200	{object}	map[string]string	"message: Calendar feed deleted"
+//	@Failure		404	{object}	map[string]string	"error: Calendar feed not found"
+//	@Router			/calendar/feeds/{id} [delete]
+func (h *Handler) deleteFeed(c *gin.Context) {
+	currentUser, ok := auth.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error getting current user"})
+		return
+	}
+
+	feedID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid feed ID"})
+		return
+	}
+	if err := h.calendarRepo.DeleteFeed(c, currentUser.ID, feedID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Calendar feed not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error deleting calendar feed"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Calendar feed deleted"})
+}
+
+// getFeedICS godoc
+//
+//	@Summary		Calendar feed
+//	@Description	Renders the chores visible to the feed owner as an iCalendar document. The token is the only credential.
+//	@Tags			calendar
+//	@Produce		text/calendar
+//	@Param			token	path		string	true	"Feed token, optionally suffixed with .ics"
+//	@Success		200		{string}	string	"iCalendar document"
+//	@Failure		404		{object}	map[string]string	"error: Calendar not found"
+//	@Router			/calendar/ics/{token} [get]
+func (h *Handler) getFeedICS(c *gin.Context) {
+	log := logging.FromContext(c)
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+
+	feed, err := h.calendarRepo.GetFeedByToken(c, token)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Calendar not found"})
+		return
+	}
+
+	// the owner may have left the circle since the feed was created
+	circleUsers, err := h.circleRepo.GetCircleUsers(c, feed.CircleID)
+	if err != nil {
+		log.Errorw("Failed to get circle users for calendar feed", "error", err, "feedID", feed.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error getting calendar"})
+		return
+	}
+	isMember := false
+	for _, cu := range circleUsers {
+		if cu.UserID == feed.UserID && cu.IsActive {
+			isMember = true
+			break
+		}
+	}
+	if !isMember {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Calendar not found"})
+		return
+	}
+
+	chores, err := h.choreRepo.GetChores(c, feed.CircleID, feed.UserID, false)
+	if err != nil {
+		log.Errorw("Failed to get chores for calendar feed", "error", err, "feedID", feed.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error getting calendar"})
+		return
+	}
+
+	visible := make([]*chModel.Chore, 0, len(chores))
+	for _, chore := range chores {
+		if !chore.CanView(feed.UserID, circleUsers) {
+			continue
+		}
+		if feed.AssignedOnly && (chore.AssignedTo == nil || *chore.AssignedTo != feed.UserID) {
+			continue
+		}
+		visible = append(visible, chore)
+	}
+
+	now := time.Now().UTC()
+	if err := h.calendarRepo.TouchFeed(c, feed.ID, now); err != nil {
+		log.Warnw("Failed to update calendar feed usage", "error", err, "feedID", feed.ID)
+	}
+
+	c.Header("Cache-Control", "private, max-age=300")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", []byte(RenderCalendar("Donetick", visible, now)))
+}
+
+func Routes(r *gin.Engine, h *Handler, multiAuthMiddleware *auth.MultiAuthMiddleware, limiter *limiter.Limiter) {
+	feedRoutes := r.Group("api/v1/calendar/feeds")
+	feedRoutes.Use(multiAuthMiddleware.MiddlewareFunc())
+	{
+		feedRoutes.GET("", h.getFeeds)
+		feedRoutes.POST("", h.createFeed)
+		feedRoutes.DELETE("/:id", h.deleteFeed)
+	}
+
+	// calendar clients can't authenticate, the token in the url is the credential
+	icsRoutes := r.Group("api/v1/calendar/ics")
+	icsRoutes.Use(utils.RateLimitMiddleware(limiter))
+	{
+		icsRoutes.GET("/:token", h.getFeedICS)
+	}
+}
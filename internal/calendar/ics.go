@@ -0,0 +1,121 @@
+package calendar
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"donetick.com/core/internal/chore"
+	chModel "donetick.com/core/internal/chore/model"
+)
+
+const (
+	icsDateTimeFormat = "20060102T150405"
+	icsLineLimit      = 75
+	eventDuration     = "PT30M"
+)
+
+// RenderCalendar renders the chores as an iCalendar document. Chores without a due date are left out.
+func RenderCalendar(name string, chores []*chModel.Chore, now time.Time) string {
+	var b strings.Builder
+	writeLine(&b, "BEGIN:VCALENDAR")
+	writeLine(&b, "VERSION:2.0")
+	writeLine(&b, "PRODID:-//Donetick//Chores//EN")
+	writeLine(&b, "CALSCALE:GREGORIAN")
+	writeLine(&b, "METHOD:PUBLISH")
+	writeLine(&b, "X-WR-CALNAME:"+escapeText(name))
+	for _, chore := range chores {
+		if chore.NextDueDate == nil {
+			continue
+		}
+		writeEvent(&b, chore, now)
+	}
+	writeLine(&b, "END:VCALENDAR")
+	return b.String()
+}
+
+func writeEvent(b *strings.Builder, chore *chModel.Chore, now time.Time) {
+	start, recurrence := ChoreRRule(chore)
+
+	writeLine(b, "BEGIN:VEVENT")
+	writeLine(b, fmt.Sprintf("UID:chore-%d@donetick", chore.ID))
+	writeLine(b, "DTSTAMP:"+now.UTC().Format(icsDateTimeFormat)+"Z")
+	writeLine(b, formatDateTime("DTSTART", start))
+	writeLine(b, "DURATION:"+eventDuration)
+	writeLine(b, "SUMMARY:"+escapeText(chore.Name))
+	if chore.Description != nil && *chore.Description != "" {
+		writeLine(b, "DESCRIPTION:"+escapeText(*chore.Description))
+	}
+	for _, line := range recurrence {
+		writeLine(b, line)
+	}
+	if !chore.UpdatedAt.IsZero() {
+		writeLine(b, "LAST-MODIFIED:"+chore.UpdatedAt.UTC().Format(icsDateTimeFormat)+"Z")
+	}
+	writeLine(b, "END:VEVENT")
+}
+
+// ChoreRRule returns the start of the chore's event and its RRULE, RDATE and EXDATE lines, using
+// the same conversion as the chore's RRULE endpoint. Chores without an equivalent recurrence are
+// rendered as a single event at their next due date. That is the case for one-off, trigger and
+// adaptive chores, and for rolling chores whose next occurrence depends on when they are completed.
+func ChoreRRule(ch *chModel.Chore) (time.Time, []string) {
+	start := ch.NextDueDate.In(choreLocation(ch))
+	if ch.IsRolling {
+		return start, nil
+	}
+	seriesStart, recurrence, err := chore.ChoreRecurrence(ch)
+	if err != nil {
+		return start, nil
+	}
+	return seriesStart, recurrence
+}
+
+func choreLocation(chore *chModel.Chore) *time.Location {
+	if chore.FrequencyMetadataV2 == nil || chore.FrequencyMetadataV2.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(chore.FrequencyMetadataV2.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+func formatDateTime(property string, t time.Time) string {
+	if t.Location() == time.UTC {
+		return property + ":" + t.Format(icsDateTimeFormat) + "Z"
+	}
+	return fmt.Sprintf("%s;TZID=%s:%s", property, t.Location().String(), t.Format(icsDateTimeFormat))
+}
+
+func escapeText(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\\\")
+	s = strings.ReplaceAll(s, ";", "\\;")
+	s = strings.ReplaceAll(s, ",", "\\,")
+	s = strings.ReplaceAll(s, "\r\n", "\\n")
+	s = strings.ReplaceAll(s, "\n", "\\n")
+	return s
+}
+
+// writeLine writes a content line, folding it at 75 octets without splitting UTF-8 characters.
+func writeLine(b *strings.Builder, line string) {
+	limit := icsLineLimit
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// continuation lines start with a space that counts towards the limit
+		limit = icsLineLimit - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+func isRuneStart(c byte) bool {
+	return c&0xC0 != 0x80
+}
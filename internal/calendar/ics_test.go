@@ -0,0 +1,201 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	chModel "donetick.com/core/internal/chore/model"
+)
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func TestChoreRRule(t *testing.T) {
+	weekOfMonth := chModel.WeekPatternWeekOfMonth
+	weekOfQuarter := chModel.WeekPatternWeekOfQuarter
+
+	tests := []struct {
+		name  string
+		chore *chModel.Chore
+		want  string
+	}{
+		{
+			name:  "daily",
+			chore: &chModel.Chore{FrequencyType: chModel.FrequencyTypeDaily},
+			want:  "FREQ=DAILY",
+		},
+		{
+			name:  "monthly past the end of short months",
+			chore: &chModel.Chore{FrequencyType: chModel.FrequencyTypeMonthly},
+			want:  "FREQ=MONTHLY;BYMONTHDAY=28,29,30,31;BYSETPOS=-1",
+		},
+		{
+			name:  "rolling renders as single event",
+			chore: &chModel.Chore{FrequencyType: chModel.FrequencyTypeDaily, IsRolling: true},
+			want:  "",
+		},
+		{
+			name:  "adaptive renders as single event",
+			chore: &chModel.Chore{FrequencyType: chModel.FrequencyTypeAdaptive},
+			want:  "",
+		},
+		{
+			name:  "once renders as single event",
+			chore: &chModel.Chore{FrequencyType: chModel.FrequencyTypeOnce},
+			want:  "",
+		},
+		{
+			name: "interval in weeks",
+			chore: &chModel.Chore{
+				FrequencyType:       chModel.FrequencyTypeInterval,
+				Frequency:           2,
+				FrequencyMetadataV2: &chModel.FrequencyMetadata{Unit: strPtr("weeks")},
+			},
+			want: "FREQ=WEEKLY;INTERVAL=2",
+		},
+		{
+			name: "interval in hours",
+			chore: &chModel.Chore{
+				FrequencyType:       chModel.FrequencyTypeInterval,
+				Frequency:           6,
+				FrequencyMetadataV2: &chModel.FrequencyMetadata{Unit: strPtr("hours")},
+			},
+			want: "FREQ=HOURLY;INTERVAL=6",
+		},
+		{
+			name: "days of the week",
+			chore: &chModel.Chore{
+				FrequencyType: chModel.FrequencyTypeDayOfTheWeek,
+				FrequencyMetadataV2: &chModel.FrequencyMetadata{
+					Days: []*string{strPtr("monday"), strPtr("Thursday")},
+				},
+			},
+			want: "FREQ=WEEKLY;BYDAY=MO,TH",
+		},
+		{
+			name: "week of month occurrences",
+			chore: &chModel.Chore{
+				FrequencyType: chModel.FrequencyTypeDayOfTheWeek,
+				FrequencyMetadataV2: &chModel.FrequencyMetadata{
+					Days:        []*string{strPtr("saturday")},
+					WeekPattern: &weekOfMonth,
+					Occurrences: []*int{intPtr(1), intPtr(-1)},
+				},
+			},
+			want: "FREQ=MONTHLY;BYDAY=1SA,-1SA",
+		},
+		{
+			name: "week of quarter renders as single event",
+			chore: &chModel.Chore{
+				FrequencyType: chModel.FrequencyTypeDayOfTheWeek,
+				FrequencyMetadataV2: &chModel.FrequencyMetadata{
+					Days:        []*string{strPtr("saturday")},
+					WeekPattern: &weekOfQuarter,
+					Occurrences: []*int{intPtr(1)},
+				},
+			},
+			want: "",
+		},
+		{
+			name: "day of the month",
+			chore: &chModel.Chore{
+				FrequencyType:       chModel.FrequencyTypeDayOfTheMonth,
+				Frequency:           15,
+				FrequencyMetadataV2: &chModel.FrequencyMetadata{Months: []*string{strPtr("january"), strPtr("july")}},
+			},
+			want: "FREQ=MONTHLY;BYMONTH=1,7;BYMONTHDAY=15",
+		},
+		{
+			name: "day of the month past the end of short months",
+			chore: &chModel.Chore{
+				FrequencyType:       chModel.FrequencyTypeDayOfTheMonth,
+				Frequency:           30,
+				FrequencyMetadataV2: &chModel.FrequencyMetadata{Months: []*string{strPtr("february")}},
+			},
+			want: "FREQ=MONTHLY;BYMONTH=2;BYMONTHDAY=28,29,30;BYSETPOS=-1",
+		},
+	}
+
+	dueDate := time.Date(2026, 1, 31, 8, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.chore.NextDueDate = &dueDate
+			start, recurrence := ChoreRRule(tt.chore)
+			if !start.Equal(dueDate) {
+				t.Errorf("expected the event to start at %v, got %v", dueDate, start)
+			}
+			if got := strings.TrimPrefix(strings.Join(recurrence, "\n"), "RRULE:"); got != tt.want {
+				t.Errorf("ChoreRRule() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChoreRRuleKeepsStoredRecurrence(t *testing.T) {
+	dueDate := time.Date(2026, 3, 16, 8, 0, 0, 0, time.UTC)
+	ch := &chModel.Chore{
+		FrequencyType: chModel.FrequencyTypeRRule,
+		NextDueDate:   &dueDate,
+		FrequencyMetadataV2: &chModel.FrequencyMetadata{
+			Timezone: "Europe/Berlin",
+			RRule:    "DTSTART;TZID=Europe/Berlin:20260302T090000\nRRULE:FREQ=WEEKLY;BYDAY=MO;COUNT=10\nEXDATE;TZID=Europe/Berlin:20260309T090000",
+		},
+	}
+
+	start, recurrence := ChoreRRule(ch)
+	if want := time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC); !start.Equal(want) {
+		t.Errorf("expected the series to start at its DTSTART %v, got %v", want, start)
+	}
+	want := []string{"RRULE:FREQ=WEEKLY;COUNT=10;BYDAY=MO", "EXDATE;TZID=Europe/Berlin:20260309T090000"}
+	if strings.Join(recurrence, "\n") != strings.Join(want, "\n") {
+		t.Errorf("expected recurrence %q, got %q", want, recurrence)
+	}
+}
+
+func TestRenderCalendar(t *testing.T) {
+	dueDate := time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	chores := []*chModel.Chore{
+		{
+			ID:            1,
+			Name:          "Water plants, then feed; the cat",
+			FrequencyType: chModel.FrequencyTypeDayOfTheWeek,
+			NextDueDate:   &dueDate,
+			FrequencyMetadataV2: &chModel.FrequencyMetadata{
+				Days:     []*string{strPtr("monday")},
+				Timezone: "Europe/Berlin",
+			},
+			Description: strPtr(strings.Repeat("long description ", 10)),
+		},
+		{ID: 2, Name: "No due date", FrequencyType: chModel.FrequencyTypeOnce},
+	}
+
+	ics := RenderCalendar("Donetick", chores, now)
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"UID:chore-1@donetick\r\n",
+		"DTSTART;TZID=Europe/Berlin:20260302T090000\r\n",
+		"SUMMARY:Water plants\\, then feed\\; the cat\r\n",
+		"RRULE:FREQ=WEEKLY;BYDAY=MO\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("expected calendar to contain %q, got:\n%s", want, ics)
+		}
+	}
+	if strings.Contains(ics, "chore-2@donetick") {
+		t.Errorf("expected chore without due date to be left out")
+	}
+	for _, line := range strings.Split(ics, "\r\n") {
+		if len(line) > icsLineLimit {
+			t.Errorf("line exceeds %d octets: %q", icsLineLimit, line)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// CalendarFeed is a read-only iCalendar subscription for a user's view of a circle.
+// Anyone holding the token can read the feed, so it never grants write access.
+type CalendarFeed struct {
+	ID           int        `json:"id" gorm:"primary_key"`
+	UserID       int        `json:"userId" gorm:"column:user_id;index"`
+	CircleID     int        `json:"circleId" gorm:"column:circle_id;index"`
+	Token        string     `json:"token" gorm:"column:token;uniqueIndex"`
+	AssignedOnly bool       `json:"assignedOnly" gorm:"column:assigned_only;default:false"` // Only include chores assigned to the user
+	LastUsedAt   *time.Time `json:"lastUsedAt" gorm:"column:last_used_at"`
+	CreatedAt    time.Time  `json:"createdAt" gorm:"column:created_at"`
+}
@@ -0,0 +1,52 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	calModel "donetick.com/core/internal/calendar/model"
+	"gorm.io/gorm"
+)
+
+type CalendarRepository struct {
+	db *gorm.DB
+}
+
+func NewCalendarRepository(db *gorm.DB) *CalendarRepository {
+	return &CalendarRepository{db}
+}
+
+func (r *CalendarRepository) CreateFeed(c context.Context, feed *calModel.CalendarFeed) error {
+	return r.db.WithContext(c).Create(feed).Error
+}
+
+func (r *CalendarRepository) GetUserFeeds(c context.Context, userID int, circleID int) ([]*calModel.CalendarFeed, error) {
+	var feeds []*calModel.CalendarFeed
+	if err := r.db.WithContext(c).Where("user_id = ? AND circle_id = ?", userID, circleID).Order("created_at desc").Find(&feeds).Error; err != nil {
+		return nil, err
+	}
+	return feeds, nil
+}
+
+func (r *CalendarRepository) GetFeedByToken(c context.Context, token string) (*calModel.CalendarFeed, error) {
+	var feed calModel.CalendarFeed
+	if err := r.db.WithContext(c).Where("token = ?", token).First(&feed).Error; err != nil {
+		return nil, err
+	}
+	return &feed, nil
+}
+
+func (r *CalendarRepository) DeleteFeed(c context.Context, userID int, feedID int) error {
+	res := r.db.WithContext(c).Where("id = ? AND user_id = ?", feedID, userID).Delete(&calModel.CalendarFeed{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *CalendarRepository) TouchFeed(c context.Context, feedID int, usedAt time.Time) error {
+	return r.db.WithContext(c).Model(&calModel.CalendarFeed{}).Where("id = ?", feedID).Update("last_used_at", usedAt).Error
+}
@@ -0,0 +1,227 @@
+package chore
+
+import (
+	"context"
+	"errors"
+	"html/template"
+	"net/http"
+	"time"
+
+	chModel "donetick.com/core/internal/chore/model"
+	emailNotifier "donetick.com/core/internal/notifier/service/email"
+	"donetick.com/core/internal/realtime"
+	uModel "donetick.com/core/internal/user/model"
+	"donetick.com/core/logging"
+	"github.com/gin-gonic/gin"
+)
+
+// actionPage is shown for the links in notification emails. Opening a link only renders the page,
+// which submits itself, so mail scanners that prefetch links don't complete chores.
+var actionPage = template.Must(template.New("action").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="utf-8"><meta name="viewport" content="width=device-width"><meta name="robots" content="noindex"><title>Donetick</title></head>
+<body style="font-family:BlinkMacSystemFont,Segoe UI,Helvetica Neue,Arial,sans-serif;text-align:center;padding:48px 16px;color:#18181b;">
+<p style="font-size:18px;">{{.Message}}</p>
+{{if .Confirm}}<form id="action" method="post"><button type="submit" style="padding:10px 18px;font-size:16px;">{{.Confirm}}</button></form>
+<script>document.getElementById("action").submit();</script>{{end}}
+</body>
+</html>
+`))
+
+type actionPageData struct {
+	Message string
+	Confirm string
+}
+
+func renderActionPage(c *gin.Context, status int, message, confirm string) {
+	c.Status(status)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Header("Cache-Control", "no-store")
+	if err := actionPage.Execute(c.Writer, actionPageData{Message: message, Confirm: confirm}); err != nil {
+		logging.FromContext(c).Errorw("Failed to render action page", "error", err)
+	}
+}
+
+func actionTokenError(c *gin.Context, err error) {
+	if errors.Is(err, emailNotifier.ErrExpiredActionToken) {
+		renderActionPage(c, http.StatusGone, "This link has expired. Open Donetick to update the chore.", "")
+		return
+	}
+	renderActionPage(c, http.StatusBadRequest, "This link is not valid.", "")
+}
+
+// showEmailAction godoc
+//
+//	@Summary		Confirm an email action
+//	@Description	Renders the page behind a signed "mark done" or "snooze" link from a notification email. The page submits the action itself
+//	@Tags			chores
+//	@Produce		html
+//	@Param			token	path	string	true	"Signed action token"
+//	@Success		200
+//	@Failure		400
+//	@Failure		410
+//	@Router			/actions/{token} [get]
+func (h *Handler) showEmailAction(c *gin.Context) {
+	claims, err := h.actionSigner.Verify(c.Param("token"))
+	if err != nil {
+		actionTokenError(c, err)
+		return
+	}
+	if claims.Action == emailNotifier.ActionSnooze {
+		renderActionPage(c, http.StatusOK, "Snoozing the chore…", "Snooze for an hour")
+		return
+	}
+	renderActionPage(c, http.StatusOK, "Marking the chore as done…", "Mark as done")
+}
+
+// performEmailAction godoc
+//
+//	@Summary		Perform an email action
+//	@Description	Completes or snoozes the chore of a signed link from a notification email. The token authenticates the recipient
+//	@Tags			chores
+//	@Produce		html
+//	@Param			token	path	string	true	"Signed action token"
+//	@Success		200
+//	@Failure		400
+//	@Failure		403
+//	@Failure		409
+//	@Failure		410
+//	@Router			/actions/{token} [post]
+func (h *Handler) performEmailAction(c *gin.Context) {
+	logger := logging.FromContext(c)
+	claims, err := h.actionSigner.Verify(c.Param("token"))
+	if err != nil {
+		actionTokenError(c, err)
+		return
+	}
+
+	user, err := h.uRepo.GetUserByID(c, claims.UserID)
+	if err != nil || user.Disabled {
+		renderActionPage(c, http.StatusForbidden, "This link is not valid.", "")
+		return
+	}
+	userDetails, err := h.uRepo.GetUserByUsername(c, user.Username)
+	if err != nil {
+		logger.Errorw("Failed to get user for email action", "error", err, "userID", user.ID)
+		renderActionPage(c, http.StatusInternalServerError, "Something went wrong, please try again.", "")
+		return
+	}
+	chore, status, refusal := h.choreForAction(c, claims.ChoreID, claims.DueDate, user)
+	if chore == nil {
+		renderActionPage(c, status, refusal, "")
+		return
+	}
+
+	switch claims.Action {
+	case emailNotifier.ActionSnooze:
+		snoozedUntil, err := h.snoozeChore(c, chore, user)
+		if err != nil {
+			logger.Errorw("Failed to snooze chore from email", "error", err, "choreID", chore.ID)
+			renderActionPage(c, http.StatusInternalServerError, "Something went wrong, please try again.", "")
+			return
+		}
+		renderActionPage(c, http.StatusOK, "Snoozed "+chore.Name+" until "+snoozedUntil.In(userLocation(user)).Format("15:04")+".", "")
+	default:
+		message, err := h.completeChoreAs(c, chore, userDetails)
+		if err != nil {
+			logger.Errorw("Failed to complete chore from email", "error", err, "choreID", chore.ID)
+			renderActionPage(c, http.StatusInternalServerError, "Something went wrong, please try again.", "")
+			return
+		}
+		renderActionPage(c, http.StatusOK, message, "")
+	}
+}
+
+// choreForAction loads the chore an email link or a Telegram button acts on and checks that the
+// user can still complete it. When they can't, it returns the status and message to show instead.
+func (h *Handler) choreForAction(c context.Context, choreID int, dueDate int64, user *uModel.User) (*chModel.Chore, int, string) {
+	chore, err := h.choreRepo.GetChore(c, choreID, user.ID, user.CircleID)
+	if err != nil {
+		return nil, http.StatusNotFound, "This chore no longer exists."
+	}
+	// the link was sent for one occurrence of the chore, it must not act on the next one
+	if !chore.IsActive || chore.NextDueDate == nil || (dueDate != 0 && chore.NextDueDate.Unix() != dueDate) {
+		return nil, http.StatusConflict, "This chore was already taken care of."
+	}
+	circleUsers, err := h.circleRepo.GetCircleUsers(c, user.CircleID)
+	if err != nil {
+		logging.FromContext(c).Errorw("Failed to retrieve circle users", "error", err)
+		return nil, http.StatusInternalServerError, "Something went wrong, please try again."
+	}
+	if chore.IsBlocked {
+		return nil, http.StatusConflict, "This chore is waiting for its prerequisites."
+	}
+	if !chore.CanComplete(user.ID, circleUsers) {
+		return nil, http.StatusForbidden, "You are not assigned to this chore."
+	}
+	return chore, http.StatusOK, ""
+}
+
+func userLocation(user *uModel.User) *time.Location {
+	if loc, err := time.LoadLocation(user.Timezone); err == nil && user.Timezone != "" {
+		return loc
+	}
+	return time.UTC
+}
+
+// completeChoreAs completes the chore for the user the same way the complete endpoint does, for
+// email links and the Telegram bot. It returns the message to show the user.
+func (h *Handler) completeChoreAs(c context.Context, chore *chModel.Chore, user *uModel.UserDetails) (string, error) {
+	completedDate := time.Now().UTC()
+	if outOfCompletionWindow(chore, completedDate) {
+		return "This chore can't be completed yet.", nil
+	}
+	_, pendingApproval, err := h.completeChoreFor(c, chore, user, user.ID, user.ID, nil, completedDate)
+	if err != nil {
+		return "", err
+	}
+	if pendingApproval {
+		return chore.Name + " was submitted for approval.", nil
+	}
+	return "Nice work! " + chore.Name + " is done.", nil
+}
+
+// snoozeChore pushes the chore's due date back by the snooze duration, counting from now when it
+// is already overdue
+func (h *Handler) snoozeChore(c context.Context, chore *chModel.Chore, user *uModel.User) (time.Time, error) {
+	now := time.Now().UTC()
+	snoozedUntil := chore.NextDueDate.UTC()
+	if snoozedUntil.Before(now) {
+		snoozedUntil = now
+	}
+	snoozedUntil = snoozedUntil.Add(emailNotifier.SnoozeDuration)
+
+	if err := h.choreRepo.UpdateChoreFields(c, chore.ID, map[string]interface{}{
+		"next_due_date": snoozedUntil,
+		"updated_by":    user.ID,
+		"updated_at":    now,
+	}); err != nil {
+		return time.Time{}, err
+	}
+	historyEntry := &chModel.ChoreHistory{
+		ChoreID:     chore.ID,
+		PerformedAt: &now,
+		CompletedBy: user.ID,
+		AssignedTo:  chore.AssignedTo,
+		DueDate:     chore.NextDueDate,
+		Status:      chModel.ChoreHistoryStatusRescheduled,
+	}
+	if err := h.choreRepo.CreateChoreHistory(c, historyEntry); err != nil {
+		logging.FromContext(c).Error("Failed to create reschedule history", "error", err)
+	}
+
+	updatedChore, err := h.choreRepo.GetChore(c, chore.ID, user.ID, user.CircleID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	h.nPlanner.GenerateNotifications(c, updatedChore)
+	if h.realTimeService != nil {
+		changes := map[string]interface{}{
+			"nextDueDate": snoozedUntil,
+			"updatedBy":   user.ID,
+			"updatedAt":   now,
+		}
+		h.realTimeService.GetEventBroadcaster().BroadcastChoreChanged(realtime.EventTypeChoreDueDateChanged, updatedChore, user, changes, nil, nil)
+	}
+	return snoozedUntil, nil
+}
@@ -10,6 +10,7 @@ import (
 	chRepo "donetick.com/core/internal/chore/repo"
 	"donetick.com/core/internal/events"
 	nps "donetick.com/core/internal/notifier/service"
+	"donetick.com/core/internal/realtime"
 	"donetick.com/core/internal/utils"
 	"donetick.com/core/logging"
 	jwt "github.com/appleboy/gin-jwt/v2"
@@ -24,22 +25,24 @@ import (
 )
 
 type API struct {
-	choreRepo     *chRepo.ChoreRepository
-	userRepo      *uRepo.UserRepository
-	circleRepo    *cRepo.CircleRepository
-	nPlanner      *nps.NotificationPlanner
-	eventProducer *events.EventsProducer
-	stRepo        *stRepo.SubTasksRepository
+	choreRepo       *chRepo.ChoreRepository
+	userRepo        *uRepo.UserRepository
+	circleRepo      *cRepo.CircleRepository
+	nPlanner        *nps.NotificationPlanner
+	eventProducer   *events.EventsProducer
+	stRepo          *stRepo.SubTasksRepository
+	realTimeService *realtime.RealTimeService
 }
 
-func NewAPI(cr *chRepo.ChoreRepository, userRepo *uRepo.UserRepository, circleRepo *cRepo.CircleRepository, nPlanner *nps.NotificationPlanner, eventProducer *events.EventsProducer, stRepo *stRepo.SubTasksRepository) *API {
+func NewAPI(cr *chRepo.ChoreRepository, userRepo *uRepo.UserRepository, circleRepo *cRepo.CircleRepository, nPlanner *nps.NotificationPlanner, eventProducer *events.EventsProducer, stRepo *stRepo.SubTasksRepository, rts *realtime.RealTimeService) *API {
 	return &API{
-		choreRepo:     cr,
-		userRepo:      userRepo,
-		circleRepo:    circleRepo,
-		nPlanner:      nPlanner,
-		eventProducer: eventProducer,
-		stRepo:        stRepo,
+		choreRepo:       cr,
+		userRepo:        userRepo,
+		circleRepo:      circleRepo,
+		nPlanner:        nPlanner,
+		eventProducer:   eventProducer,
+		stRepo:          stRepo,
+		realTimeService: rts,
 	}
 }
 
@@ -147,6 +150,9 @@ func (h *API) CreateChore(c *gin.Context) {
 	}
 
 	h.eventProducer.ChoreCreated(c, user.WebhookURL, createdChore, &user.User)
+	if h.realTimeService != nil {
+		h.realTimeService.GetEventBroadcaster().BroadcastChoreCreated(createdChore, &user.User)
+	}
 
 	c.JSON(201, createdChore)
 }
@@ -244,6 +250,9 @@ func (h *API) UpdateChore(c *gin.Context) {
 		c.JSON(500, gin.H{"error": "Error fetching updated chore"})
 		return
 	}
+	if h.realTimeService != nil {
+		h.realTimeService.GetEventBroadcaster().BroadcastChoreUpdated(updatedChore, &user.User, updates, nil)
+	}
 
 	c.JSON(200, updatedChore)
 }
@@ -287,6 +296,12 @@ func (h *API) CompleteChore(c *gin.Context) {
 		})
 		return
 	}
+	if chore.IsBlocked {
+		c.JSON(400, gin.H{
+			"error": "Chore is blocked until its prerequisites are completed",
+		})
+		return
+	}
 	if !chore.CanComplete(performer, circleUsers) {
 		log.Debugw("chore.api.CompleteChore user is not assigned to chore", "userID", performer, "choreID", choreID)
 		c.JSON(400, gin.H{
@@ -308,7 +323,7 @@ func (h *API) CompleteChore(c *gin.Context) {
 
 	var nextDueDate *time.Time
 	if chore.FrequencyType == "adaptive" {
-		history, err := h.choreRepo.GetChoreHistoryWithLimit(c, chore.ID, 5)
+		history, err := h.choreRepo.GetChoreHistoryWithLimit(c, chore.ID, AdaptiveHistoryLimit)
 		if err != nil {
 			c.JSON(500, gin.H{
 				"error": "Error getting chore history",
@@ -370,7 +385,12 @@ func (h *API) CompleteChore(c *gin.Context) {
 		return
 	}
 	h.nPlanner.GenerateNotifications(c, updatedChore)
+	notifyUnblockedDependents(c, h.choreRepo, h.nPlanner, updatedChore.ID)
+	h.nPlanner.GenerateCompletionNotifications(c, updatedChore, performer)
 	h.eventProducer.ChoreCompleted(c, currentUser.WebhookURL, chore, &currentUser.User)
+	if h.realTimeService != nil {
+		h.realTimeService.GetEventBroadcaster().BroadcastChoreCompleted(updatedChore, &currentUser.User, nil, nil)
+	}
 	c.JSON(200,
 		updatedChore,
 	)
@@ -410,6 +430,9 @@ func (h *API) DeleteChore(c *gin.Context) {
 		c.JSON(500, gin.H{"error": "Failed to delete chore"})
 		return
 	}
+	if h.realTimeService != nil {
+		h.realTimeService.GetEventBroadcaster().BroadcastChoreDeleted(chore, &currentUser.User)
+	}
 	c.JSON(200, gin.H{"message": "Chore deleted successfully"})
 }
 
@@ -0,0 +1,118 @@
+package chore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	chModel "donetick.com/core/internal/chore/model"
+	uModel "donetick.com/core/internal/user/model"
+)
+
+// outOfCompletionWindow reports whether the chore's completion window hasn't opened yet
+func outOfCompletionWindow(chore *chModel.Chore, completedDate time.Time) bool {
+	return chore.CompletionWindow != nil && chore.NextDueDate != nil &&
+		completedDate.UTC().Before(chore.NextDueDate.UTC().Add(-time.Hour*time.Duration(*chore.CompletionWindow)))
+}
+
+// completeChoreFor completes the chore as completedBy, or submits it for approval when the chore
+// requires it, and runs everything a completion triggers: scheduling, rotation, subtask reset,
+// reminders, dependents, webhooks and real-time events. user is who acts on the chore and updatedBy
+// the user recorded for audit, they differ under impersonation. Callers check that the user may
+// complete the chore and that its completion window is open.
+func (h *Handler) completeChoreFor(c context.Context, chore *chModel.Chore, user *uModel.UserDetails, updatedBy int, completedBy int, note *string, completedDate time.Time) (*chModel.Chore, bool, error) {
+	if chore.RequireApproval {
+		if err := h.choreRepo.SetChorePendingApproval(c, chore, note, completedBy, &completedDate); err != nil {
+			return nil, false, fmt.Errorf("failed to set chore pending approval: %w", err)
+		}
+		updatedChore, err := h.choreRepo.GetChore(c, chore.ID, user.ID, user.CircleID)
+		if err != nil {
+			return nil, false, err
+		}
+		h.nPlanner.GenerateApprovalNotifications(c, updatedChore, completedBy)
+		if h.realTimeService != nil {
+			changes := map[string]interface{}{
+				"status":    chModel.ChoreStatusPendingApproval,
+				"updatedBy": updatedBy,
+				"updatedAt": time.Now().UTC(),
+			}
+			h.realTimeService.GetEventBroadcaster().BroadcastChoreUpdated(updatedChore, &user.User, changes, note)
+		}
+		return updatedChore, true, nil
+	}
+
+	var nextDueDate *time.Time
+	if chore.FrequencyType == chModel.FrequencyTypeAdaptive {
+		history, err := h.choreRepo.GetChoreHistoryWithLimit(c, chore.ID, AdaptiveHistoryLimit)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to fetch chore history for adaptive scheduling: %w", err)
+		}
+		if nextDueDate, err = scheduleAdaptiveNextDueDate(chore, completedDate, history); err != nil {
+			return nil, false, fmt.Errorf("failed to schedule next due date: %w", err)
+		}
+	} else {
+		var err error
+		if nextDueDate, err = scheduleNextDueDate(c, chore, completedDate.UTC()); err != nil {
+			return nil, false, fmt.Errorf("failed to schedule next due date: %w", err)
+		}
+	}
+	choreHistory, err := h.choreRepo.GetChoreHistory(c, chore.ID)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to fetch chore history for assignee calculation: %w", err)
+	}
+	nextAssignedTo, err := checkNextAssignee(chore, choreHistory, completedBy)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to check next assignee: %w", err)
+	}
+	if err := h.choreRepo.CompleteChore(c, chore, note, completedBy, nextDueDate, &completedDate, nextAssignedTo, true); err != nil {
+		return nil, false, err
+	}
+
+	updatedChore, err := h.choreRepo.GetChore(c, chore.ID, user.ID, user.CircleID)
+	if err != nil {
+		return nil, false, err
+	}
+	if updatedChore.SubTasks != nil && updatedChore.FrequencyType != chModel.FrequencyTypeOnce {
+		h.stRepo.ResetSubtasksCompletion(c, updatedChore.ID)
+	}
+	h.nPlanner.GenerateNotifications(c, updatedChore)
+	notifyUnblockedDependents(c, h.choreRepo, h.nPlanner, updatedChore.ID)
+	h.nPlanner.GenerateCompletionNotifications(c, updatedChore, completedBy)
+	h.eventProducer.ChoreCompleted(c, user.WebhookURL, chore, &user.User)
+	if h.realTimeService != nil {
+		history, _ := h.choreRepo.GetChoreHistoryWithLimit(c, chore.ID, 1)
+		var completion *chModel.ChoreHistory
+		if len(history) > 0 {
+			completion = history[0]
+		}
+		h.realTimeService.GetEventBroadcaster().BroadcastChoreCompleted(updatedChore, &user.User, completion, note)
+	}
+	return updatedChore, false, nil
+}
+
+// skipChoreFor skips the chore's current occurrence for the user and moves it to the next one
+func (h *Handler) skipChoreFor(c context.Context, chore *chModel.Chore, user *uModel.UserDetails) (*chModel.Chore, error) {
+	nextDueDate, err := scheduleNextDueDate(c, chore, chore.NextDueDate.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to schedule next due date: %w", err)
+	}
+	if err := h.choreRepo.SkipChore(c, chore, user.ID, nextDueDate, chore.AssignedTo); err != nil {
+		return nil, err
+	}
+	updatedChore, err := h.choreRepo.GetChore(c, chore.ID, user.ID, user.CircleID)
+	if err != nil {
+		return nil, err
+	}
+	// drop reminders for the skipped occurrence, including any nagging
+	h.nPlanner.GenerateNotifications(c, updatedChore)
+	h.eventProducer.ChoreSkipped(c, user.WebhookURL, updatedChore, &user.User)
+	if h.realTimeService != nil {
+		history, _ := h.choreRepo.GetChoreHistoryWithLimit(c, chore.ID, 1)
+		var choreHistory *chModel.ChoreHistory
+		if len(history) > 0 {
+			choreHistory = history[0]
+		}
+		h.realTimeService.GetEventBroadcaster().BroadcastChoreSkipped(updatedChore, &user.User, choreHistory, nil)
+	}
+	return updatedChore, nil
+}
@@ -0,0 +1,161 @@
+package chore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"donetick.com/core/config"
+	chModel "donetick.com/core/internal/chore/model"
+	chRepo "donetick.com/core/internal/chore/repo"
+	nps "donetick.com/core/internal/notifier/service"
+	"donetick.com/core/internal/realtime"
+	"donetick.com/core/logging"
+)
+
+// errDueDateNotAdvanced is returned when a chore's recurrence does not move it past its current due date
+var errDueDateNotAdvanced = errors.New("next due date is not after the current due date")
+
+// DeadlineScheduler periodically looks for chores whose deadline (NextDueDate + DeadlineOffset)
+// has passed, records them as missed and rolls them over to their next occurrence.
+type DeadlineScheduler struct {
+	choreRepo       *chRepo.ChoreRepository
+	nPlanner        *nps.NotificationPlanner
+	realTimeService *realtime.RealTimeService
+	interval        time.Duration
+	done            chan bool
+	// stuck holds the due date of each chore whose recurrence did not advance, so it is reported once
+	stuck map[int]time.Time
+}
+
+func NewDeadlineScheduler(cfg *config.Config, cr *chRepo.ChoreRepository, np *nps.NotificationPlanner, rts *realtime.RealTimeService) *DeadlineScheduler {
+	interval := cfg.SchedulerJobs.DeadlineJob
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+	return &DeadlineScheduler{
+		choreRepo:       cr,
+		nPlanner:        np,
+		realTimeService: rts,
+		interval:        interval,
+		done:            make(chan bool),
+		stuck:           map[int]time.Time{},
+	}
+}
+
+func (s *DeadlineScheduler) Start(ctx context.Context) {
+	logger := logging.FromContext(ctx)
+	logger.Info("Deadline scheduler started")
+
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.done:
+				logger.Info("Deadline scheduler stopped")
+				return
+			case <-ticker.C:
+				if err := s.processMissedChores(ctx); err != nil {
+					logger.Errorw("Failed to process missed chores", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// Stop stops the deadline scheduler
+func (s *DeadlineScheduler) Stop() {
+	s.done <- true
+}
+
+func (s *DeadlineScheduler) processMissedChores(ctx context.Context) error {
+	logger := logging.FromContext(ctx)
+	now := time.Now().UTC()
+
+	chores, err := s.choreRepo.GetChoresPastDeadline(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	var missedCount int
+	stuck := map[int]time.Time{}
+	for _, chore := range chores {
+		deadline := chore.GetDeadline()
+		if deadline == nil || !deadline.Before(now) {
+			continue
+		}
+		if err := s.missChore(ctx, chore, *deadline); err != nil {
+			if errors.Is(err, chRepo.ErrChoreChanged) {
+				logger.Debugw("Chore changed while marking as missed, skipping", "choreID", chore.ID)
+				continue
+			}
+			if errors.Is(err, errDueDateNotAdvanced) {
+				// the chore stays as it is until someone completes or edits it, no need to say so every run
+				stuck[chore.ID] = *chore.NextDueDate
+				if warned, ok := s.stuck[chore.ID]; !ok || !warned.Equal(*chore.NextDueDate) {
+					logger.Warnw("Chore recurrence does not advance past its due date, not marking as missed", "choreID", chore.ID, "nextDueDate", chore.NextDueDate)
+				}
+				continue
+			}
+			logger.Errorw("Failed to mark chore as missed", "error", err, "choreID", chore.ID)
+			continue
+		}
+		missedCount++
+	}
+	s.stuck = stuck
+
+	if missedCount > 0 {
+		logger.Infow("Marked chores as missed", "count", missedCount)
+	}
+	return nil
+}
+
+// missChore records a missed history entry for the chore and moves it to the next occurrence.
+// The deadline is treated as the point the cycle closed, so rolling and adaptive chores
+// schedule their next occurrence from it.
+func (s *DeadlineScheduler) missChore(ctx context.Context, chore *chModel.Chore, deadline time.Time) error {
+	nextDueDate, err := scheduleNextDueDate(ctx, chore, deadline)
+	if err != nil {
+		return err
+	}
+	if nextDueDate != nil && !nextDueDate.After(*chore.NextDueDate) {
+		// never move a chore backward, otherwise it would be marked as missed again on the next run
+		return errDueDateNotAdvanced
+	}
+
+	history, err := s.choreRepo.GetChoreHistory(ctx, chore.ID)
+	if err != nil {
+		return err
+	}
+
+	performerID := 0
+	if chore.AssignedTo != nil {
+		performerID = *chore.AssignedTo
+	}
+	nextAssignedTo, err := checkNextAssignee(chore, history, performerID)
+	if err != nil {
+		logging.FromContext(ctx).Warnw("Failed to check next assignee, keeping current assignee", "error", err, "choreID", chore.ID)
+		nextAssignedTo = chore.AssignedTo
+	}
+
+	missedHistory, err := s.choreRepo.MissChore(ctx, chore, deadline, nextDueDate, nextAssignedTo)
+	if err != nil {
+		return err
+	}
+
+	chore.NextDueDate = nextDueDate
+	if nextDueDate != nil {
+		chore.AssignedTo = nextAssignedTo
+	} else {
+		chore.IsActive = false
+	}
+	chore.Status = chModel.ChoreStatusNoStatus
+
+	s.nPlanner.GenerateNotifications(ctx, chore)
+
+	if s.realTimeService != nil {
+		s.realTimeService.GetEventBroadcaster().BroadcastChoreMissed(chore, missedHistory)
+	}
+	return nil
+}
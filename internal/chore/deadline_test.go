@@ -0,0 +1,177 @@
+package chore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"donetick.com/core/config"
+	chModel "donetick.com/core/internal/chore/model"
+	chRepo "donetick.com/core/internal/chore/repo"
+	cRepo "donetick.com/core/internal/circle/repo"
+	"donetick.com/core/internal/database"
+	nRepo "donetick.com/core/internal/notifier/repo"
+	nps "donetick.com/core/internal/notifier/service"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupDeadlineScheduler(t *testing.T) (*DeadlineScheduler, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect database: %v", err)
+	}
+	if err := database.Migration(db); err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
+	}
+
+	cfg := &config.Config{Database: config.DatabaseConfig{Type: "sqlite"}}
+	planner := nps.NewNotificationPlanner(nRepo.NewNotificationRepository(db), cRepo.NewCircleRepository(db))
+	return NewDeadlineScheduler(cfg, chRepo.NewChoreRepository(db, cfg), planner, nil), db
+}
+
+func TestProcessMissedChoresRecordsMissAndRollsOver(t *testing.T) {
+	s, db := setupDeadlineScheduler(t)
+	ctx := context.Background()
+
+	dueDate := time.Now().UTC().Add(-2 * time.Hour).Truncate(time.Second)
+	deadlineOffset := 3600
+	chore := &chModel.Chore{
+		Name:           "Take out trash",
+		FrequencyType:  chModel.FrequencyTypeDaily,
+		NextDueDate:    &dueDate,
+		DeadlineOffset: &deadlineOffset,
+		IsActive:       true,
+		CircleID:       1,
+		CreatedBy:      1,
+		AssignedTo:     intPtr(1),
+		AssignStrategy: chModel.AssignmentStrategyRoundRobin,
+		Assignees:      []chModel.ChoreAssignees{{UserID: 1}, {UserID: 2}},
+	}
+	if err := db.Create(chore).Error; err != nil {
+		t.Fatalf("failed to create chore: %v", err)
+	}
+
+	if err := s.processMissedChores(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var history []chModel.ChoreHistory
+	db.Where("chore_id = ?", chore.ID).Find(&history)
+	if len(history) != 1 {
+		t.Fatalf("expected 1 history entry, got %d", len(history))
+	}
+	if history[0].Status != chModel.ChoreHistoryStatusMissed {
+		t.Errorf("expected status missed, got %d", history[0].Status)
+	}
+	if history[0].AssignedTo == nil || *history[0].AssignedTo != 1 {
+		t.Errorf("expected missed entry to be assigned to 1, got %v", history[0].AssignedTo)
+	}
+
+	var updated chModel.Chore
+	db.First(&updated, chore.ID)
+	want := dueDate.AddDate(0, 0, 1)
+	if updated.NextDueDate == nil || !updated.NextDueDate.Equal(want) {
+		t.Errorf("expected next due date %v, got %v", want, updated.NextDueDate)
+	}
+	if updated.AssignedTo == nil || *updated.AssignedTo != 2 {
+		t.Errorf("expected chore to rotate to assignee 2, got %v", updated.AssignedTo)
+	}
+
+	// running again must not record a second miss for the same occurrence
+	if err := s.processMissedChores(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var count int64
+	db.Model(&chModel.ChoreHistory{}).Where("chore_id = ?", chore.ID).Count(&count)
+	if count != 1 {
+		t.Errorf("expected 1 history entry after second run, got %d", count)
+	}
+}
+
+func TestProcessMissedChoresIgnoresChoresBeforeDeadline(t *testing.T) {
+	s, db := setupDeadlineScheduler(t)
+
+	dueDate := time.Now().UTC().Add(-30 * time.Minute)
+	deadlineOffset := 3600
+	chore := &chModel.Chore{
+		Name:           "Water plants",
+		FrequencyType:  chModel.FrequencyTypeDaily,
+		NextDueDate:    &dueDate,
+		DeadlineOffset: &deadlineOffset,
+		IsActive:       true,
+		CircleID:       1,
+		CreatedBy:      1,
+		AssignStrategy: chModel.AssignmentStrategyRandom,
+	}
+	if err := db.Create(chore).Error; err != nil {
+		t.Fatalf("failed to create chore: %v", err)
+	}
+
+	if err := s.processMissedChores(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var count int64
+	db.Model(&chModel.ChoreHistory{}).Where("chore_id = ?", chore.ID).Count(&count)
+	if count != 0 {
+		t.Errorf("expected no history entries, got %d", count)
+	}
+}
+
+func TestProcessMissedChoresSkipsChoresThatDoNotAdvance(t *testing.T) {
+	s, db := setupDeadlineScheduler(t)
+	ctx := context.Background()
+
+	// hourly from midnight lands the next occurrence at 1am, before the noon due date
+	day := time.Now().UTC().AddDate(0, 0, -2)
+	dueDate := time.Date(day.Year(), day.Month(), day.Day(), 12, 0, 0, 0, time.UTC)
+	deadlineOffset := 3600
+	chore := &chModel.Chore{
+		Name:          "Feed the cat",
+		FrequencyType: "interval",
+		Frequency:     1,
+		FrequencyMetadataV2: &chModel.FrequencyMetadata{
+			Unit: jsonPtr("hours"),
+			Time: dueDate.Truncate(24 * time.Hour).Format(time.RFC3339),
+		},
+		NextDueDate:    &dueDate,
+		DeadlineOffset: &deadlineOffset,
+		IsActive:       true,
+		CircleID:       1,
+		CreatedBy:      1,
+		AssignStrategy: chModel.AssignmentStrategyRandom,
+	}
+	if err := db.Create(chore).Error; err != nil {
+		t.Fatalf("failed to create chore: %v", err)
+	}
+
+	for run := 0; run < 2; run++ {
+		if err := s.processMissedChores(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if warned, ok := s.stuck[chore.ID]; !ok || !warned.Equal(dueDate) {
+			t.Errorf("run %d: expected chore to be remembered as stuck at %v, got %v", run, dueDate, s.stuck)
+		}
+	}
+
+	var count int64
+	db.Model(&chModel.ChoreHistory{}).Where("chore_id = ?", chore.ID).Count(&count)
+	if count != 0 {
+		t.Errorf("expected no history entries, got %d", count)
+	}
+	var updated chModel.Chore
+	db.First(&updated, chore.ID)
+	if !updated.IsActive || updated.NextDueDate == nil || !updated.NextDueDate.Equal(dueDate) {
+		t.Errorf("expected chore to stay active and due at %v, got active %v due %v", dueDate, updated.IsActive, updated.NextDueDate)
+	}
+
+	// once the chore is no longer past its deadline it is forgotten
+	db.Model(&chModel.Chore{}).Where("id = ?", chore.ID).Update("next_due_date", time.Now().UTC().Add(time.Hour))
+	if err := s.processMissedChores(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := s.stuck[chore.ID]; ok {
+		t.Errorf("expected chore to be forgotten once it is no longer past its deadline")
+	}
+}
@@ -0,0 +1,92 @@
+package chore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	chModel "donetick.com/core/internal/chore/model"
+	chRepo "donetick.com/core/internal/chore/repo"
+	nps "donetick.com/core/internal/notifier/service"
+	"donetick.com/core/logging"
+)
+
+// validateDependencies checks the prerequisites requested for a chore: they belong to the circle,
+// aren't repeated and don't make the chore depend on itself. choreID is 0 for a new chore.
+func (h *Handler) validateDependencies(c context.Context, circleID int, choreID int, dependencies []chModel.ChoreDependency) error {
+	if len(dependencies) == 0 {
+		return nil
+	}
+	if len(dependencies) > chModel.MAX_DEPENDENCIES {
+		return fmt.Errorf("dependencies cannot exceed %d items (got %d)", chModel.MAX_DEPENDENCIES, len(dependencies))
+	}
+	prerequisites := make([]int, 0, len(dependencies))
+	seen := make(map[int]bool)
+	for _, dependency := range dependencies {
+		if dependency.DependsOnID == 0 || dependency.DependsOnID == choreID {
+			return errors.New("a chore cannot depend on itself")
+		}
+		if seen[dependency.DependsOnID] {
+			return errors.New("duplicate dependency")
+		}
+		if dependency.DueOffset != nil && *dependency.DueOffset < 0 {
+			return errors.New("dependency due offset cannot be negative")
+		}
+		seen[dependency.DependsOnID] = true
+		prerequisites = append(prerequisites, dependency.DependsOnID)
+	}
+
+	count, err := h.choreRepo.CountCircleChores(c, circleID, prerequisites)
+	if err != nil {
+		return err
+	}
+	if int(count) != len(prerequisites) {
+		return errors.New("dependency not found in circle")
+	}
+	if choreID == 0 {
+		// nothing depends on a new chore yet
+		return nil
+	}
+	graph, err := h.choreRepo.GetCircleDependencyGraph(c, circleID)
+	if err != nil {
+		return err
+	}
+	if hasDependencyCycle(graph, choreID, prerequisites) {
+		return errors.New("dependencies would create a cycle")
+	}
+	return nil
+}
+
+// hasDependencyCycle reports whether giving the chore these prerequisites lets it reach itself
+// through the circle's dependency graph
+func hasDependencyCycle(graph map[int][]int, choreID int, prerequisites []int) bool {
+	visited := make(map[int]bool)
+	stack := append([]int(nil), prerequisites...)
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if current == choreID {
+			return true
+		}
+		if visited[current] {
+			continue
+		}
+		visited[current] = true
+		stack = append(stack, graph[current]...)
+	}
+	return false
+}
+
+// notifyUnblockedDependents plans the reminders of the chores a completion unblocked
+func notifyUnblockedDependents(c context.Context, choreRepo *chRepo.ChoreRepository, nPlanner *nps.NotificationPlanner, choreID int) {
+	dependents, err := choreRepo.GetDependentChores(c, choreID)
+	if err != nil {
+		logging.FromContext(c).Errorw("Failed to get dependent chores", "error", err, "choreID", choreID)
+		return
+	}
+	for _, dependent := range dependents {
+		if !dependent.IsBlocked {
+			nPlanner.GenerateNotifications(c, dependent)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package chore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"donetick.com/core/config"
+	chModel "donetick.com/core/internal/chore/model"
+	chRepo "donetick.com/core/internal/chore/repo"
+	"donetick.com/core/internal/database"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestHasDependencyCycle(t *testing.T) {
+	tests := []struct {
+		name          string
+		graph         map[int][]int
+		choreID       int
+		prerequisites []int
+		expected      bool
+	}{
+		{
+			name:          "No existing dependencies",
+			graph:         map[int][]int{},
+			choreID:       2,
+			prerequisites: []int{1},
+			expected:      false,
+		},
+		{
+			name:          "Direct cycle",
+			graph:         map[int][]int{1: {2}},
+			choreID:       2,
+			prerequisites: []int{1},
+			expected:      true,
+		},
+		{
+			name:          "Indirect cycle",
+			graph:         map[int][]int{1: {3}, 3: {2}},
+			choreID:       2,
+			prerequisites: []int{1},
+			expected:      true,
+		},
+		{
+			name:          "Shared prerequisite is not a cycle",
+			graph:         map[int][]int{3: {1}, 4: {1, 3}},
+			choreID:       2,
+			prerequisites: []int{3, 4},
+			expected:      false,
+		},
+		{
+			name:          "Replaced prerequisites are ignored",
+			graph:         map[int][]int{2: {1}, 3: {2}},
+			choreID:       2,
+			prerequisites: []int{4},
+			expected:      false,
+		},
+		{
+			name:          "Existing cycle elsewhere terminates",
+			graph:         map[int][]int{3: {4}, 4: {3}},
+			choreID:       2,
+			prerequisites: []int{3},
+			expected:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hasDependencyCycle(tt.graph, tt.choreID, tt.prerequisites); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCompletingPrerequisiteUnblocksDependent(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect database: %v", err)
+	}
+	if err := database.Migration(db); err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
+	}
+	repo := chRepo.NewChoreRepository(db, &config.Config{Database: config.DatabaseConfig{Type: "sqlite"}})
+	ctx := context.Background()
+
+	dueDate := time.Now().UTC().Add(time.Hour).Truncate(time.Second)
+	washer := &chModel.Chore{Name: "Run washer", FrequencyType: chModel.FrequencyTypeDaily, NextDueDate: &dueDate, IsActive: true, CircleID: 1, CreatedBy: 1}
+	laundry := &chModel.Chore{Name: "Hang laundry", FrequencyType: chModel.FrequencyTypeDaily, NextDueDate: &dueDate, IsActive: true, CircleID: 1, CreatedBy: 1}
+	for _, chore := range []*chModel.Chore{washer, laundry} {
+		if err := db.Create(chore).Error; err != nil {
+			t.Fatalf("failed to create chore: %v", err)
+		}
+	}
+
+	offset := 7200
+	blocked, err := repo.SaveChoreDependencies(ctx, laundry.ID, []chModel.ChoreDependency{{DependsOnID: washer.ID, DueOffset: &offset}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !blocked {
+		t.Fatalf("expected chore to be blocked by its prerequisite")
+	}
+
+	completedDate := time.Now().UTC().Truncate(time.Second)
+	nextDueDate := dueDate.AddDate(0, 0, 1)
+	if err := repo.CompleteChore(ctx, washer, nil, 1, &nextDueDate, &completedDate, nil, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var updated chModel.Chore
+	db.First(&updated, laundry.ID)
+	if updated.IsBlocked {
+		t.Errorf("expected chore to be unblocked after its prerequisite was completed")
+	}
+	expectedDueDate := completedDate.Add(2 * time.Hour)
+	if updated.NextDueDate == nil || !updated.NextDueDate.Equal(expectedDueDate) {
+		t.Errorf("expected due date %v, got %v", expectedDueDate, updated.NextDueDate)
+	}
+
+	// completing the dependent starts its next cycle, waiting for the washer again
+	laundryCompletedDate := completedDate.Add(3 * time.Hour)
+	if err := repo.CompleteChore(ctx, &updated, nil, 1, &nextDueDate, &laundryCompletedDate, nil, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	db.First(&updated, laundry.ID)
+	if !updated.IsBlocked {
+		t.Errorf("expected chore to be blocked again for its next cycle")
+	}
+}
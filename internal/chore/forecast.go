@@ -0,0 +1,105 @@
+package chore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	chModel "donetick.com/core/internal/chore/model"
+)
+
+const (
+	DefaultForecastCount = 10
+	MaxForecastCount     = 100
+)
+
+// ForecastEntry is one projected occurrence of a chore
+type ForecastEntry struct {
+	DueDate    time.Time `json:"dueDate"`
+	AssignedTo *int      `json:"assignedTo"`
+}
+
+// forecastChore projects the next count due dates and assignees of the chore by completing it on
+// time over and over, the way completeChore would. Neither the chore nor the history is modified.
+// Random assignment strategies give one possible rotation.
+func forecastChore(ctx context.Context, chore *chModel.Chore, history []*chModel.ChoreHistory, count int) ([]ForecastEntry, error) {
+	simulated := *chore
+	simulated.Assignees = append([]chModel.ChoreAssignees(nil), chore.Assignees...)
+	simulated.AssignedTo = copyIntPtr(chore.AssignedTo)
+	simulated.NextDueDate = copyTimePtr(chore.NextDueDate)
+	simulatedHistory := append([]*chModel.ChoreHistory(nil), history...)
+	if simulated.FrequencyMetadataV2 == nil {
+		simulated.FrequencyMetadataV2 = &chModel.FrequencyMetadata{}
+	}
+	if simulated.FrequencyType == chModel.FrequencyTypeInterval && simulated.FrequencyMetadataV2.Unit == nil {
+		return nil, errors.New("interval frequency requires a unit")
+	}
+
+	// a chore without a due date starts its schedule from now
+	if simulated.NextDueDate == nil && simulated.FrequencyType != chModel.FrequencyTypeAdaptive {
+		firstDueDate, err := scheduleNextDueDate(ctx, &simulated, time.Now().UTC())
+		if err != nil {
+			return nil, err
+		}
+		simulated.NextDueDate = firstDueDate
+	}
+
+	forecast := make([]ForecastEntry, 0, count)
+	for len(forecast) < count && simulated.NextDueDate != nil {
+		dueDate := simulated.NextDueDate.UTC()
+		forecast = append(forecast, ForecastEntry{DueDate: dueDate, AssignedTo: copyIntPtr(simulated.AssignedTo)})
+
+		performer := simulated.CreatedBy
+		if simulated.AssignedTo != nil {
+			performer = *simulated.AssignedTo
+		}
+		var nextDueDate *time.Time
+		var err error
+		if simulated.FrequencyType == chModel.FrequencyTypeAdaptive {
+			nextDueDate, err = scheduleAdaptiveNextDueDate(&simulated, dueDate, simulatedHistory[:min(len(simulatedHistory), AdaptiveHistoryLimit)])
+		} else {
+			nextDueDate, err = scheduleNextDueDate(ctx, &simulated, dueDate)
+		}
+		if err != nil {
+			return nil, err
+		}
+		nextAssignee, err := checkNextAssignee(&simulated, simulatedHistory, performer)
+		if err != nil {
+			return nil, err
+		}
+
+		performedAt := dueDate
+		simulatedHistory = append([]*chModel.ChoreHistory{{
+			ChoreID:     simulated.ID,
+			AssignedTo:  copyIntPtr(simulated.AssignedTo),
+			CompletedBy: performer,
+			PerformedAt: &performedAt,
+			DueDate:     &performedAt,
+			Status:      chModel.ChoreHistoryStatusCompleted,
+		}}, simulatedHistory...)
+
+		// a schedule that doesn't move forward would repeat the same date forever
+		if nextDueDate != nil && !nextDueDate.After(dueDate) {
+			break
+		}
+		simulated.NextDueDate = nextDueDate
+		simulated.AssignedTo = nextAssignee
+	}
+	return forecast, nil
+}
+
+func copyIntPtr(i *int) *int {
+	if i == nil {
+		return nil
+	}
+	v := *i
+	return &v
+}
+
+func copyTimePtr(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	v := *t
+	return &v
+}
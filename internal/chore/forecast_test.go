@@ -0,0 +1,108 @@
+package chore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	chModel "donetick.com/core/internal/chore/model"
+)
+
+func TestForecastChore(t *testing.T) {
+	start := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+	quarterly := chModel.WeekPatternWeekOfQuarter
+	tests := []struct {
+		name         string
+		chore        chModel.Chore
+		count        int
+		wantDates    []time.Time
+		wantAssignee []int
+	}{
+		{
+			name: "weekly round robin",
+			chore: chModel.Chore{
+				FrequencyType:  chModel.FrequencyTypeWeekly,
+				NextDueDate:    timePtr(start),
+				AssignStrategy: chModel.AssignmentStrategyRoundRobin,
+				Assignees:      []chModel.ChoreAssignees{{UserID: 1}, {UserID: 2}, {UserID: 3}},
+				AssignedTo:     intPtr(2),
+			},
+			count:        4,
+			wantDates:    []time.Time{start, start.AddDate(0, 0, 7), start.AddDate(0, 0, 14), start.AddDate(0, 0, 21)},
+			wantAssignee: []int{2, 3, 1, 2},
+		},
+		{
+			name: "first Monday of the quarter",
+			chore: chModel.Chore{
+				FrequencyType: chModel.FrequencyTypeDayOfTheWeek,
+				NextDueDate:   timePtr(start),
+				FrequencyMetadataV2: &chModel.FrequencyMetadata{
+					Days:        []*string{jsonPtr("monday")},
+					Time:        "2025-01-06T09:00:00Z",
+					WeekPattern: &quarterly,
+					Occurrences: []*int{intPtr(1)},
+				},
+				AssignStrategy: chModel.AssignmentStrategyRoundRobin,
+				Assignees:      []chModel.ChoreAssignees{{UserID: 1}, {UserID: 2}},
+				AssignedTo:     intPtr(1),
+			},
+			count: 3,
+			wantDates: []time.Time{
+				start,
+				time.Date(2025, 4, 7, 9, 0, 0, 0, time.UTC),
+				time.Date(2025, 7, 7, 9, 0, 0, 0, time.UTC),
+			},
+			wantAssignee: []int{1, 2, 1},
+		},
+		{
+			name: "recurrence ending with COUNT",
+			chore: chModel.Chore{
+				FrequencyType: chModel.FrequencyTypeRRule,
+				NextDueDate:   timePtr(start),
+				FrequencyMetadataV2: &chModel.FrequencyMetadata{
+					RRule: "DTSTART:20250106T090000Z\nRRULE:FREQ=DAILY;COUNT=2",
+				},
+				AssignStrategy: chModel.AssignmentStrategyKeepLastAssigned,
+				AssignedTo:     intPtr(1),
+			},
+			count:        5,
+			wantDates:    []time.Time{start, start.AddDate(0, 0, 1)},
+			wantAssignee: []int{1, 1},
+		},
+		{
+			name: "one-off chore",
+			chore: chModel.Chore{
+				FrequencyType:  chModel.FrequencyTypeOnce,
+				NextDueDate:    timePtr(start),
+				AssignStrategy: chModel.AssignmentStrategyKeepLastAssigned,
+				AssignedTo:     intPtr(1),
+			},
+			count:        5,
+			wantDates:    []time.Time{start},
+			wantAssignee: []int{1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nextDueDate, assignedTo := *tt.chore.NextDueDate, *tt.chore.AssignedTo
+			forecast, err := forecastChore(context.Background(), &tt.chore, nil, tt.count)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(forecast) != len(tt.wantDates) {
+				t.Fatalf("got %d occurrences, want %d: %+v", len(forecast), len(tt.wantDates), forecast)
+			}
+			for i, entry := range forecast {
+				if !entry.DueDate.Equal(tt.wantDates[i]) {
+					t.Errorf("occurrence %d: due %v, want %v", i, entry.DueDate, tt.wantDates[i])
+				}
+				if entry.AssignedTo == nil || *entry.AssignedTo != tt.wantAssignee[i] {
+					t.Errorf("occurrence %d: assigned to %v, want %d", i, entry.AssignedTo, tt.wantAssignee[i])
+				}
+			}
+			if !tt.chore.NextDueDate.Equal(nextDueDate) || *tt.chore.AssignedTo != assignedTo {
+				t.Errorf("forecast modified the chore")
+			}
+		})
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"donetick.com/core/internal/notifier"
 	nRepo "donetick.com/core/internal/notifier/repo"
 	nps "donetick.com/core/internal/notifier/service"
+	emailNotifier "donetick.com/core/internal/notifier/service/email"
 	fcmService "donetick.com/core/internal/notifier/service/fcm"
 	"donetick.com/core/internal/realtime"
 	storage "donetick.com/core/internal/storage"
@@ -51,18 +52,20 @@ type Handler struct {
 	eventProducer   *events.EventsProducer
 	stRepo          *stRepo.SubTasksRepository
 	storageRepo     *storageRepo.StorageRepository
-	storage         *storage.S3Storage
+	storage         storage.Storage
 	realTimeService *realtime.RealTimeService
+	actionSigner    *emailNotifier.ActionSigner
 }
 
 func NewHandler(cr *chRepo.ChoreRepository, circleRepo *cRepo.CircleRepository, nt *notifier.Notifier,
 	np *nps.NotificationPlanner, nRepo *nRepo.NotificationRepository, tRepo *tRepo.ThingRepository, lRepo *lRepo.LabelRepository,
 	ep *events.EventsProducer, stRepo *stRepo.SubTasksRepository,
-	storage *storage.S3Storage,
+	storage storage.Storage,
 	ur *uRepo.UserRepository,
 	dr *dRepo.DeviceRepository,
 	stoRepo *storageRepo.StorageRepository,
-	rts *realtime.RealTimeService) *Handler {
+	rts *realtime.RealTimeService,
+	actionSigner *emailNotifier.ActionSigner) *Handler {
 	return &Handler{
 		choreRepo:       cr,
 		uRepo:           ur,
@@ -78,6 +81,7 @@ func NewHandler(cr *chRepo.ChoreRepository, circleRepo *cRepo.CircleRepository,
 		storageRepo:     stoRepo,
 		storage:         storage,
 		realTimeService: rts,
+		actionSigner:    actionSigner,
 	}
 }
 
@@ -226,6 +230,310 @@ func (h *Handler) getChore(c *gin.Context) {
 	})
 }
 
+// GetChoreRRule godoc
+//
+//	@Summary		Convert a chore's frequency to RRULE
+//	@Description	Returns the RFC 5545 recurrence equivalent to the chore's frequency, starting at its next due date
+//	@Tags			chores
+//	@Produce		json
+//	@Security		JWTKeyAuth
+//	@Security		APIKeyAuth
+//	@Param			id	path		int					true	"Chore ID"
+//	@Success		200	{object}	map[string]string	"res: recurrence with DTSTART and RRULE lines"
+//	@Failure		400	{object}	map[string]string	"error: Invalid chore ID | frequency has no RRULE equivalent"
+//	@Failure		401	{object}	map[string]string	"error: Authentication failed"
+//	@Failure		403	{object}	map[string]string	"error: You are not allowed to view this chore"
+//	@Failure		500	{object}	map[string]string	"error: Failed to retrieve chore"
+//	@Router			/chores/{id}/rrule [get]
+func (h *Handler) getChoreRRule(c *gin.Context) {
+	logger := logging.FromContext(c)
+	currentUser, ok := auth.CurrentUser(c)
+	if !ok {
+		logger.Error("Failed to get current user from authentication context")
+		c.JSON(401, gin.H{
+			"error": "Authentication failed",
+		})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{
+			"error": "Invalid chore ID",
+		})
+		return
+	}
+
+	chore, err := h.choreRepo.GetChore(c, id, currentUser.ID, currentUser.CircleID)
+	if err != nil {
+		logger.Error("Failed to retrieve chore", "error", err, "choreID", id, "userID", currentUser.ID)
+		c.JSON(500, gin.H{
+			"error": "Failed to retrieve chore",
+		})
+		return
+	}
+	circleUsers, err := h.circleRepo.GetCircleUsers(c, currentUser.CircleID)
+	if err != nil {
+		logger.Error("Failed to retrieve circle users", "error", err, "circleID", currentUser.CircleID, "userID", currentUser.ID)
+		c.JSON(500, gin.H{"error": "Failed to retrieve circle users"})
+		return
+	}
+	if !chore.CanView(currentUser.ID, circleUsers) {
+		c.JSON(403, gin.H{
+			"error": "You are not allowed to view this chore",
+		})
+		return
+	}
+
+	recurrence, err := FrequencyToRRule(chore)
+	if err != nil {
+		c.JSON(400, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	c.JSON(200, gin.H{
+		"res": recurrence,
+	})
+}
+
+func forecastCount(c *gin.Context) (int, bool) {
+	count, err := strconv.Atoi(c.DefaultQuery("count", strconv.Itoa(DefaultForecastCount)))
+	if err != nil || count < 1 || count > MaxForecastCount {
+		c.JSON(400, gin.H{
+			"error": fmt.Sprintf("count must be between 1 and %d", MaxForecastCount),
+		})
+		return 0, false
+	}
+	return count, true
+}
+
+// GetChoreForecast godoc
+//
+//	@Summary		Forecast a chore's next due dates
+//	@Description	Projects the next due dates and assignee rotation of a chore, assuming each occurrence is completed on time. Nothing is saved.
+//	@Tags			chores
+//	@Produce		json
+//	@Security		JWTKeyAuth
+//	@Security		APIKeyAuth
+//	@Param			id		path		int								true	"Chore ID"
+//	@Param			count	query		int								false	"Number of occurrences (default 10, max 100)"
+//	@Success		200		{object}	map[string][]ForecastEntry		"res: projected occurrences"
+//	@Failure		400		{object}	map[string]string				"error: Invalid chore ID | count must be between 1 and 100"
+//	@Failure		401		{object}	map[string]string				"error: Authentication failed"
+//	@Failure		403		{object}	map[string]string				"error: You are not allowed to view this chore"
+//	@Failure		500		{object}	map[string]string				"error: Failed to retrieve chore | Failed to forecast chore"
+//	@Router			/chores/{id}/forecast [get]
+func (h *Handler) getChoreForecast(c *gin.Context) {
+	logger := logging.FromContext(c)
+	currentUser, ok := auth.CurrentUser(c)
+	if !ok {
+		logger.Error("Failed to get current user from authentication context")
+		c.JSON(401, gin.H{
+			"error": "Authentication failed",
+		})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{
+			"error": "Invalid chore ID",
+		})
+		return
+	}
+	count, ok := forecastCount(c)
+	if !ok {
+		return
+	}
+
+	chore, err := h.choreRepo.GetChore(c, id, currentUser.ID, currentUser.CircleID)
+	if err != nil {
+		logger.Error("Failed to retrieve chore", "error", err, "choreID", id, "userID", currentUser.ID)
+		c.JSON(500, gin.H{
+			"error": "Failed to retrieve chore",
+		})
+		return
+	}
+	circleUsers, err := h.circleRepo.GetCircleUsers(c, currentUser.CircleID)
+	if err != nil {
+		logger.Error("Failed to retrieve circle users", "error", err, "circleID", currentUser.CircleID, "userID", currentUser.ID)
+		c.JSON(500, gin.H{"error": "Failed to retrieve circle users"})
+		return
+	}
+	if !chore.CanView(currentUser.ID, circleUsers) {
+		c.JSON(403, gin.H{
+			"error": "You are not allowed to view this chore",
+		})
+		return
+	}
+
+	history, err := h.choreRepo.GetChoreHistory(c, chore.ID)
+	if err != nil {
+		logger.Error("Failed to retrieve chore history", "error", err, "choreID", chore.ID)
+		c.JSON(500, gin.H{
+			"error": "Failed to retrieve chore history",
+		})
+		return
+	}
+	forecast, err := forecastChore(c, chore, history, count)
+	if err != nil {
+		logger.Error("Failed to forecast chore", "error", err, "choreID", chore.ID)
+		c.JSON(500, gin.H{
+			"error": "Failed to forecast chore",
+		})
+		return
+	}
+	c.JSON(200, gin.H{
+		"res": forecast,
+	})
+}
+
+// PreviewChoreForecast godoc
+//
+//	@Summary		Forecast an unsaved chore
+//	@Description	Projects the next due dates and assignee rotation of a chore as it would be created or edited with the request. Without a due date the schedule starts from now. Nothing is saved.
+//	@Tags			chores
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWTKeyAuth
+//	@Security		APIKeyAuth
+//	@Param			chore	body		chModel.ChoreReq				true	"Chore creation or edit request"
+//	@Param			count	query		int								false	"Number of occurrences (default 10, max 100)"
+//	@Success		200		{object}	map[string][]ForecastEntry		"res: projected occurrences"
+//	@Failure		400		{object}	map[string]string				"error: Invalid request format | Assignee not found in circle | Invalid date | invalid schedule"
+//	@Failure		401		{object}	map[string]string				"error: Authentication failed"
+//	@Failure		403		{object}	map[string]string				"error: You are not allowed to view this chore"
+//	@Failure		404		{object}	map[string]string				"error: Chore not found"
+//	@Failure		500		{object}	map[string]string				"error: Failed to retrieve circle users"
+//	@Router			/chores/forecast [post]
+func (h *Handler) previewChoreForecast(c *gin.Context) {
+	logger := logging.FromContext(c)
+	currentUser, ok := auth.CurrentUser(c)
+	if !ok {
+		logger.Error("Failed to get current user from authentication context")
+		c.JSON(401, gin.H{
+			"error": "Authentication failed",
+		})
+		return
+	}
+	count, ok := forecastCount(c)
+	if !ok {
+		return
+	}
+	var choreReq chModel.ChoreReq
+	if err := c.ShouldBindJSON(&choreReq); err != nil {
+		c.JSON(400, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	circleUsers, err := h.circleRepo.GetCircleUsers(c, currentUser.CircleID)
+	if err != nil {
+		logger.Error("Failed to retrieve circle users", "error", err, "circleID", currentUser.CircleID, "userID", currentUser.ID)
+		c.JSON(500, gin.H{"error": "Failed to retrieve circle users"})
+		return
+	}
+	for _, assignee := range choreReq.Assignees {
+		userFound := false
+		for _, circleUser := range circleUsers {
+			if assignee.UserID == circleUser.UserID {
+				userFound = true
+				break
+			}
+		}
+		if !userFound {
+			c.JSON(400, gin.H{
+				"error": "Assignee not found in circle",
+			})
+			return
+		}
+	}
+
+	var dueDate *time.Time
+	if choreReq.DueDate != "" {
+		rawDueDate, err := time.Parse(time.RFC3339, choreReq.DueDate)
+		if err != nil {
+			c.JSON(400, gin.H{
+				"error": "Invalid date",
+			})
+			return
+		}
+		rawDueDate = rawDueDate.UTC()
+		dueDate = &rawDueDate
+	}
+	if err := choreReq.FrequencyMetadata.Validate(); err != nil {
+		c.JSON(400, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err := applyScheduleTimezone(choreReq.FrequencyMetadata, choreReq.AssignedTo, currentUser.Timezone, circleUsers); err != nil {
+		c.JSON(400, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err := normalizeRRuleFrequency(choreReq.FrequencyType, choreReq.FrequencyMetadata, dueDate); err != nil {
+		c.JSON(400, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	chore := &chModel.Chore{
+		ID:                  choreReq.ID,
+		CircleID:            currentUser.CircleID,
+		CreatedBy:           currentUser.ID,
+		FrequencyType:       choreReq.FrequencyType,
+		Frequency:           choreReq.Frequency,
+		FrequencyMetadataV2: choreReq.FrequencyMetadata,
+		NextDueDate:         dueDate,
+		IsRolling:           choreReq.IsRolling,
+		Assignees:           choreReq.Assignees,
+		AssignStrategy:      choreReq.AssignStrategy,
+		AssignedTo:          choreReq.AssignedTo,
+	}
+
+	// an edited chore keeps rotating on top of its history
+	var history []*chModel.ChoreHistory
+	if choreReq.ID != 0 {
+		existing, err := h.choreRepo.GetChore(c, choreReq.ID, currentUser.ID, currentUser.CircleID)
+		if err != nil {
+			c.JSON(404, gin.H{
+				"error": "Chore not found",
+			})
+			return
+		}
+		if !existing.CanView(currentUser.ID, circleUsers) {
+			c.JSON(403, gin.H{
+				"error": "You are not allowed to view this chore",
+			})
+			return
+		}
+		chore.CreatedBy = existing.CreatedBy
+		if history, err = h.choreRepo.GetChoreHistory(c, existing.ID); err != nil {
+			logger.Error("Failed to retrieve chore history", "error", err, "choreID", existing.ID)
+			c.JSON(500, gin.H{
+				"error": "Failed to retrieve chore history",
+			})
+			return
+		}
+	}
+
+	forecast, err := forecastChore(c, chore, history, count)
+	if err != nil {
+		c.JSON(400, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	c.JSON(200, gin.H{
+		"res": forecast,
+	})
+}
+
 // CreateChore godoc
 //
 //	@Summary		Create a new chore
@@ -262,6 +570,12 @@ func (h *Handler) createChore(c *gin.Context) {
 		})
 		return
 	}
+	if err := choreReq.NotificationMetadata.Validate(); err != nil {
+		c.JSON(400, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
 
 	circleUsers, err := h.circleRepo.GetCircleUsers(c, currentUser.CircleID)
 	if err != nil {
@@ -301,6 +615,32 @@ func (h *Handler) createChore(c *gin.Context) {
 		}
 
 	}
+	if err := choreReq.FrequencyMetadata.Validate(); err != nil {
+		c.JSON(400, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err := applyScheduleTimezone(choreReq.FrequencyMetadata, choreReq.AssignedTo, currentUser.Timezone, circleUsers); err != nil {
+		c.JSON(400, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err := normalizeRRuleFrequency(choreReq.FrequencyType, choreReq.FrequencyMetadata, dueDate); err != nil {
+		c.JSON(400, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if choreReq.Dependencies != nil {
+		if err := h.validateDependencies(c, currentUser.CircleID, 0, *choreReq.Dependencies); err != nil {
+			c.JSON(400, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+	}
 
 	createdChore := &chModel.Chore{
 
@@ -385,6 +725,16 @@ func (h *Handler) createChore(c *gin.Context) {
 			return
 		}
 	}
+	if choreReq.Dependencies != nil {
+		isBlocked, err := h.choreRepo.SaveChoreDependencies(c, createdChore.ID, *choreReq.Dependencies)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"error": "Error adding chore dependencies",
+			})
+			return
+		}
+		createdChore.IsBlocked = isBlocked
+	}
 	go func() {
 		h.nPlanner.GenerateNotifications(c, createdChore)
 	}()
@@ -443,6 +793,12 @@ func (h *Handler) editChore(c *gin.Context) {
 		})
 		return
 	}
+	if err := choreReq.NotificationMetadata.Validate(); err != nil {
+		c.JSON(400, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
 
 	circleUsers, err := h.circleRepo.GetCircleUsers(c, currentUser.CircleID)
 	if err != nil {
@@ -522,6 +878,24 @@ func (h *Handler) editChore(c *gin.Context) {
 		}
 
 	}
+	if err := choreReq.FrequencyMetadata.Validate(); err != nil {
+		c.JSON(400, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err := applyScheduleTimezone(choreReq.FrequencyMetadata, choreReq.AssignedTo, currentUser.Timezone, circleUsers); err != nil {
+		c.JSON(400, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err := normalizeRRuleFrequency(choreReq.FrequencyType, choreReq.FrequencyMetadata, dueDate); err != nil {
+		c.JSON(400, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
 
 	//  validate assignedTo part of the assignees:
 	if choreReq.AssignedTo != nil {
@@ -556,6 +930,14 @@ func (h *Handler) editChore(c *gin.Context) {
 		})
 		return
 	}
+	if choreReq.Dependencies != nil {
+		if err := h.validateDependencies(c, currentUser.CircleID, oldChore.ID, *choreReq.Dependencies); err != nil {
+			c.JSON(400, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+	}
 
 	// Create a map to store the existing labels for quick lookup
 	oldLabelsMap := make(map[int]struct{})
@@ -628,6 +1010,7 @@ func (h *Handler) editChore(c *gin.Context) {
 		IsPrivate:              choreReq.IsPrivate,
 		ProjectID:              choreReq.ProjectID,
 		Status:                 oldChore.Status,
+		IsBlocked:              oldChore.IsBlocked,
 	}
 	if err := h.choreRepo.UpsertChore(c, updatedChore); err != nil {
 		c.JSON(500, gin.H{
@@ -702,6 +1085,16 @@ func (h *Handler) editChore(c *gin.Context) {
 			return
 		}
 	}
+	if choreReq.Dependencies != nil {
+		isBlocked, err := h.choreRepo.SaveChoreDependencies(c, oldChore.ID, *choreReq.Dependencies)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"error": "Error updating chore dependencies",
+			})
+			return
+		}
+		updatedChore.IsBlocked = isBlocked
+	}
 	if oldChore.NextDueDate != updatedChore.NextDueDate {
 		historyEntry := &chModel.ChoreHistory{
 			ChoreID:     oldChore.ID,
@@ -867,7 +1260,7 @@ func (h *Handler) deleteChore(c *gin.Context) {
 	// Broadcast real-time chore deletion event
 	if h.realTimeService != nil {
 		broadcaster := h.realTimeService.GetEventBroadcaster()
-		broadcaster.BroadcastChoreDeleted(chore.ID, chore.Name, chore.CircleID, &currentUser.User)
+		broadcaster.BroadcastChoreDeleted(chore, &currentUser.User)
 	}
 
 	c.JSON(200, gin.H{
@@ -1008,7 +1401,7 @@ func (h *Handler) updateAssignee(c *gin.Context) {
 				"updatedBy":  currentUser.ID,
 				"updatedAt":  assigneeReq.UpdatedAt,
 			}
-			broadcaster.BroadcastChoreUpdated(updatedChore, &currentUser.User, changes, nil)
+			broadcaster.BroadcastChoreChanged(realtime.EventTypeChoreAssigneeChanged, updatedChore, &currentUser.User, changes, nil, nil)
 		}
 	}
 
@@ -1402,7 +1795,7 @@ func (h *Handler) ResetChoreTimer(c *gin.Context) {
 //	@Success		200	{object}	map[string]chModel.Chore	"res: updated chore"
 //	@Failure		400	{object}	map[string]string			"error: Invalid ID"
 //	@Failure		401	{object}	map[string]string			"error: Authentication failed"
-//	@Failure		500	{object}	map[string]string			"error: Failed to retrieve chore | Error scheduling next due date | Error completing chore"
+//	@Failure		500	{object}	map[string]string			"error: Failed to retrieve chore | Error skipping chore"
 //	@Router			/chores/{id}/skip [post]
 func (h *Handler) skipChore(c *gin.Context) {
 	rawID := c.Param("id")
@@ -1446,44 +1839,15 @@ func (h *Handler) skipChore(c *gin.Context) {
 		})
 		return
 	}
-	nextDueDate, err := scheduleNextDueDate(c, chore, chore.NextDueDate.UTC())
+	updatedChore, err := h.skipChoreFor(c, chore, effectiveUser)
 	if err != nil {
+		logger.Errorw("Failed to skip chore", "error", err, "choreID", chore.ID)
 		c.JSON(500, gin.H{
-			"error": "Error scheduling next due date",
-		})
-		return
-	}
-
-	nextAssignedTo := chore.AssignedTo
-	if err := h.choreRepo.SkipChore(c, chore, effectiveUser.ID, nextDueDate, nextAssignedTo); err != nil {
-		c.JSON(500, gin.H{
-			"error": "Error completing chore",
+			"error": "Error skipping chore",
 		})
 		return
 	}
 
-	updatedChore, err := h.choreRepo.GetChore(c, id, effectiveUser.ID, actualUser.CircleID)
-	if err != nil {
-		logger.Error("Failed to retrieve chore", "error", err)
-		c.JSON(500, gin.H{
-			"error": "Failed to retrieve chore",
-		})
-		return
-	}
-	h.eventProducer.ChoreSkipped(c, effectiveUser.WebhookURL, updatedChore, &effectiveUser.User)
-
-	// Broadcast real-time chore skip event
-	if h.realTimeService != nil {
-		broadcaster := h.realTimeService.GetEventBroadcaster()
-		// Get the skip history entry
-		history, _ := h.choreRepo.GetChoreHistoryWithLimit(c, chore.ID, 1)
-		var choreHistory *chModel.ChoreHistory
-		if len(history) > 0 {
-			choreHistory = history[0]
-		}
-		broadcaster.BroadcastChoreSkipped(updatedChore, &effectiveUser.User, choreHistory, nil)
-	}
-
 	c.JSON(200, gin.H{
 		"res": updatedChore,
 	})
@@ -1609,7 +1973,7 @@ func (h *Handler) updateDueDate(c *gin.Context) {
 				"updatedBy":   currentUser.ID,
 				"updatedAt":   time.Now().UTC(),
 			}
-			broadcaster.BroadcastChoreUpdated(updatedChore, &currentUser.User, changes, nil)
+			broadcaster.BroadcastChoreChanged(realtime.EventTypeChoreDueDateChanged, updatedChore, &currentUser.User, changes, nil, nil)
 		}
 	}
 
@@ -1672,7 +2036,7 @@ func (h *Handler) archiveChore(c *gin.Context) {
 				"updatedBy": currentUser.ID,
 				"updatedAt": time.Now().UTC(),
 			}
-			broadcaster.BroadcastChoreUpdated(updatedChore, &currentUser.User, changes, nil)
+			broadcaster.BroadcastChoreChanged(realtime.EventTypeChoreArchived, updatedChore, &currentUser.User, changes, nil, nil)
 		}
 	}
 
@@ -1734,7 +2098,7 @@ func (h *Handler) UnarchiveChore(c *gin.Context) {
 				"updatedBy": currentUser.ID,
 				"updatedAt": time.Now().UTC(),
 			}
-			broadcaster.BroadcastChoreUpdated(updatedChore, &currentUser.User, changes, nil)
+			broadcaster.BroadcastChoreChanged(realtime.EventTypeChoreUnarchived, updatedChore, &currentUser.User, changes, nil, nil)
 		}
 	}
 
@@ -1840,6 +2204,12 @@ func (h *Handler) completeChore(c *gin.Context) {
 		})
 		return
 	}
+	if chore.IsBlocked {
+		c.JSON(400, gin.H{
+			"error": "Chore is blocked until its prerequisites are completed",
+		})
+		return
+	}
 	if !chore.CanComplete(effectiveUser.ID, circleUsers) {
 		c.JSON(400, gin.H{
 			"error": "User is not assigned to chore",
@@ -1848,13 +2218,11 @@ func (h *Handler) completeChore(c *gin.Context) {
 	}
 
 	// confirm that the chore in completion window:
-	if chore.CompletionWindow != nil {
-		if completedDate.UTC().Before(chore.NextDueDate.UTC().Add(-time.Hour * time.Duration(*chore.CompletionWindow))) {
-			c.JSON(400, gin.H{
-				"error": "Chore is out of completion window",
-			})
-			return
-		}
+	if outOfCompletionWindow(chore, completedDate) {
+		c.JSON(400, gin.H{
+			"error": "Chore is out of completion window",
+		})
+		return
 	}
 
 	if req.CompletedBy != nil {
@@ -1866,73 +2234,17 @@ func (h *Handler) completeChore(c *gin.Context) {
 		}
 		completedBy = *req.CompletedBy
 	}
-	var nextDueDate *time.Time
-	if chore.FrequencyType == "adaptive" {
-		history, err := h.choreRepo.GetChoreHistoryWithLimit(c, chore.ID, 5)
-		if err != nil {
-			logging.FromContext(c).Errorw("Failed to fetch chore history for adaptive scheduling", "error", err, "choreID", chore.ID)
-			c.JSON(500, gin.H{
-				"error": "Failed to fetch chore history for adaptive scheduling",
-			})
-			return
-		}
-		nextDueDate, err = scheduleAdaptiveNextDueDate(chore, completedDate, history)
-		if err != nil {
-			logging.FromContext(c).Error("Failed to schedule next due date", "error", err)
-			c.JSON(500, gin.H{
-				"error": "Error scheduling next due date",
-			})
-			return
-		}
 
-	} else {
-		nextDueDate, err = scheduleNextDueDate(c, chore, completedDate.UTC())
-		if err != nil {
-			logging.FromContext(c).Error("Failed to schedule next due date", "error", err)
-			c.JSON(500, gin.H{
-				"error": "Error scheduling next due date",
-			})
-			return
-		}
-	}
-	choreHistory, err := h.choreRepo.GetChoreHistory(c, chore.ID)
+	// the actual user is recorded for the audit trail
+	updatedChore, pendingApproval, err := h.completeChoreFor(c, chore, effectiveUser, actualUser.ID, completedBy, additionalNotes, completedDate)
 	if err != nil {
-		logging.FromContext(c).Errorw("Failed to fetch chore history for assignee calculation", "error", err, "choreID", chore.ID)
+		logger.Errorw("Failed to complete chore", "error", err, "choreID", chore.ID)
 		c.JSON(500, gin.H{
-			"error": "Failed to fetch chore history for assignee calculation",
+			"error": "Error completing chore",
 		})
 		return
 	}
-
-	// Check if chore requires approval
-	if chore.RequireApproval {
-		// Set chore status to pending approval instead of completing
-		if err := h.choreRepo.SetChorePendingApproval(c, chore, additionalNotes, completedBy, &completedDate); err != nil {
-			c.JSON(500, gin.H{
-				"error": "Error setting chore pending approval",
-			})
-			return
-		}
-
-		updatedChore, err := h.choreRepo.GetChore(c, id, effectiveUser.ID, actualUser.CircleID)
-		if err != nil {
-			c.JSON(500, gin.H{
-				"error": "Error getting chore",
-			})
-			return
-		}
-
-		// Broadcast pending approval event
-		if h.realTimeService != nil {
-			broadcaster := h.realTimeService.GetEventBroadcaster()
-			changes := map[string]interface{}{
-				"status":    chModel.ChoreStatusPendingApproval,
-				"updatedBy": actualUser.ID, // Use actual user for audit trail
-				"updatedAt": time.Now().UTC(),
-			}
-			broadcaster.BroadcastChoreUpdated(updatedChore, &effectiveUser.User, changes, additionalNotes)
-		}
-
+	if pendingApproval {
 		c.JSON(200, gin.H{
 			"res":     updatedChore,
 			"message": "Chore completion submitted for approval",
@@ -1940,51 +2252,6 @@ func (h *Handler) completeChore(c *gin.Context) {
 		return
 	}
 
-	nextAssignedTo, err := checkNextAssignee(chore, choreHistory, completedBy)
-	if err != nil {
-		logging.FromContext(c).Error("Failed to check next assignee", "error", err)
-		c.JSON(500, gin.H{
-			"error": "Error checking next assignee",
-		})
-		return
-	}
-
-	if err := h.choreRepo.CompleteChore(c, chore, additionalNotes, completedBy, nextDueDate, &completedDate, nextAssignedTo, true); err != nil {
-		c.JSON(500, gin.H{
-			"error": "Error completing chore",
-		})
-		return
-	}
-	updatedChore, err := h.choreRepo.GetChore(c, id, effectiveUser.ID, actualUser.CircleID)
-	if err != nil {
-		logger.Error("Failed to retrieve chore", "error", err)
-		c.JSON(500, gin.H{
-			"error": "Failed to retrieve chore",
-		})
-		return
-	}
-	if updatedChore.SubTasks != nil && updatedChore.FrequencyType != chModel.FrequencyTypeOnce {
-		h.stRepo.ResetSubtasksCompletion(c, updatedChore.ID)
-	}
-
-	// go func() {
-
-	// 	h.notifier.SendChoreCompletion(c, chore, effectiveUser)
-	// }()
-	h.nPlanner.GenerateNotifications(c, updatedChore)
-	h.eventProducer.ChoreCompleted(c, effectiveUser.WebhookURL, chore, &effectiveUser.User)
-	if h.realTimeService != nil {
-		broadcaster := h.realTimeService.GetEventBroadcaster()
-		// Get the completion history entry
-		history, _ := h.choreRepo.GetChoreHistoryWithLimit(c, chore.ID, 1)
-
-		var choreHistory *chModel.ChoreHistory
-		if len(history) > 0 {
-			choreHistory = history[0]
-		}
-		broadcaster.BroadcastChoreCompleted(updatedChore, &effectiveUser.User, choreHistory, additionalNotes)
-	}
-
 	c.JSON(200, gin.H{
 		"res": updatedChore,
 	})
@@ -2136,12 +2403,41 @@ func (h *Handler) GetChoreDetail(c *gin.Context) {
 		})
 		return
 	}
+	if detailed.FrequencyType == string(chModel.FrequencyTypeAdaptive) {
+		detailed.Adaptive, err = h.explainAdaptiveSchedule(c, id, currentUser.ID, currentUser.CircleID)
+		if err != nil {
+			logger.Errorw("Failed to explain adaptive schedule", "error", err, "choreID", id)
+		}
+	}
 
 	c.JSON(200, gin.H{
 		"res": detailed,
 	})
 }
 
+// explainAdaptiveSchedule replays how the chore's current due date was computed from its latest
+// completion
+func (h *Handler) explainAdaptiveSchedule(c *gin.Context, choreID int, userID int, circleID int) (*chModel.AdaptiveExplanation, error) {
+	chore, err := h.choreRepo.GetChore(c, choreID, userID, circleID)
+	if err != nil {
+		return nil, err
+	}
+	history, err := h.choreRepo.GetChoreHistoryWithLimit(c, choreID, AdaptiveHistoryLimit+1)
+	if err != nil {
+		return nil, err
+	}
+	for i, entry := range history {
+		if entry.PerformedAt == nil || entry.Status != chModel.ChoreHistoryStatusCompleted {
+			continue
+		}
+		// the fallback compares the completion with the due date it had then
+		chore.NextDueDate = entry.DueDate
+		_, explanation, err := explainAdaptiveNextDueDate(chore, *entry.PerformedAt, history[i+1:])
+		return explanation, err
+	}
+	return nil, nil
+}
+
 // ModifyHistory godoc
 //
 //	@Summary		Modify chore history entry
@@ -2204,7 +2500,8 @@ func (h *Handler) ModifyHistory(c *gin.Context) {
 	}
 
 	// Verify the chore belongs to the user's circle before accessing history
-	if _, err := h.choreRepo.GetChore(c, choreID, currentUser.ID, currentUser.CircleID); err != nil {
+	chore, err := h.choreRepo.GetChore(c, choreID, currentUser.ID, currentUser.CircleID)
+	if err != nil {
 		logger.Error("Failed to retrieve chore", "error", err, "choreID", choreID, "userID", currentUser.ID)
 		c.JSON(500, gin.H{
 			"error": "Failed to retrieve chore",
@@ -2253,6 +2550,11 @@ func (h *Handler) ModifyHistory(c *gin.Context) {
 		return
 	}
 
+	if h.realTimeService != nil {
+		broadcaster := h.realTimeService.GetEventBroadcaster()
+		broadcaster.BroadcastChoreHistoryChanged(realtime.EventTypeChoreHistoryUpdated, chore, historyID, history, &currentUser.User)
+	}
+
 	c.JSON(200, gin.H{
 		"res": history,
 	})
@@ -2341,6 +2643,17 @@ func (h *Handler) updatePriority(c *gin.Context) {
 		return
 	}
 
+	if h.realTimeService != nil {
+		broadcaster := h.realTimeService.GetEventBroadcaster()
+		chore.Priority = *priorityReq.Priority
+		changes := map[string]interface{}{
+			"priority":  *priorityReq.Priority,
+			"updatedBy": currentUser.ID,
+			"updatedAt": time.Now().UTC(),
+		}
+		broadcaster.BroadcastChoreChanged(realtime.EventTypeChorePriority, chore, &currentUser.User, changes, nil, nil)
+	}
+
 	c.JSON(200, gin.H{
 		"message": "Priority updated successfully",
 	})
@@ -2490,6 +2803,11 @@ func (h *Handler) DeleteHistory(c *gin.Context) {
 		return
 	}
 
+	if h.realTimeService != nil {
+		broadcaster := h.realTimeService.GetEventBroadcaster()
+		broadcaster.BroadcastChoreHistoryChanged(realtime.EventTypeChoreHistoryDeleted, chore, historyID, nil, &currentUser.User)
+	}
+
 	c.JSON(200, gin.H{
 		"message": "History deleted successfully",
 	})
@@ -2598,16 +2916,15 @@ func (h *Handler) UpdateSubtaskCompletedAt(c *gin.Context) {
 		broadcaster := h.realTimeService.GetEventBroadcaster()
 
 		broadcaster.BroadcastSubtaskUpdated(
-			choreID,
+			chore,
 			req.ID,
 			completedAt,
 			&effectiveUser.User,
-			chore.CircleID,
 		)
 
 	}
 
-	h.eventProducer.SubtaskUpdated(c, effectiveUser.WebhookURL,
+	h.eventProducer.SubtaskUpdated(c, chore.CircleID, effectiveUser.WebhookURL,
 		&stModel.SubTask{
 			ID:          req.ID,
 			ChoreID:     req.ChoreID,
@@ -2841,6 +3158,11 @@ func (h *Handler) UpdateTimeSession(c *gin.Context) {
 		return
 	}
 
+	if h.realTimeService != nil {
+		broadcaster := h.realTimeService.GetEventBroadcaster()
+		broadcaster.BroadcastTimeSessionChanged(realtime.EventTypeTimeSessionUpdated, chore, sessionID, session, &currentUser.User)
+	}
+
 	c.JSON(200, gin.H{
 		"res": session,
 	})
@@ -2945,12 +3267,16 @@ func (h *Handler) DeleteTimeSession(c *gin.Context) {
 	}
 	if chore.Status == chModel.ChoreStatusInProgress || chore.Status == chModel.ChoreStatusPaused {
 		h.choreRepo.UpdateChoreStatus(c, choreID, chModel.ChoreStatusNoStatus)
-		c.JSON(200, gin.H{
-			"message": "Time session deleted successfully",
-		})
+	}
 
-		return
+	if h.realTimeService != nil {
+		broadcaster := h.realTimeService.GetEventBroadcaster()
+		broadcaster.BroadcastTimeSessionChanged(realtime.EventTypeTimeSessionDeleted, chore, sessionID, nil, &currentUser.User)
 	}
+
+	c.JSON(200, gin.H{
+		"message": "Time session deleted successfully",
+	})
 }
 
 // ApproveChore godoc
@@ -3064,7 +3390,7 @@ func (h *Handler) approveChore(c *gin.Context) {
 	// Calculate next due date and assignee like in normal completion
 	var nextDueDate *time.Time
 	if chore.FrequencyType == "adaptive" {
-		allHistory, err := h.choreRepo.GetChoreHistoryWithLimit(c, chore.ID, 5)
+		allHistory, err := h.choreRepo.GetChoreHistoryWithLimit(c, chore.ID, AdaptiveHistoryLimit)
 		if err != nil {
 			logging.FromContext(c).Errorw("Failed to fetch chore history for adaptive scheduling during approval", "error", err, "choreID", chore.ID)
 			c.JSON(500, gin.H{
@@ -3119,12 +3445,14 @@ func (h *Handler) approveChore(c *gin.Context) {
 	}
 
 	h.nPlanner.GenerateNotifications(c, updatedChore)
+	notifyUnblockedDependents(c, h.choreRepo, h.nPlanner, updatedChore.ID)
+	h.nPlanner.GenerateCompletionNotifications(c, updatedChore, completedBy)
 	h.eventProducer.ChoreCompleted(c, currentUser.WebhookURL, chore, &currentUser.User)
 
 	// Broadcast real-time chore approved event
 	if h.realTimeService != nil {
 		broadcaster := h.realTimeService.GetEventBroadcaster()
-		broadcaster.BroadcastChoreCompleted(updatedChore, &currentUser.User, pendingHistory, nil)
+		broadcaster.BroadcastChoreChanged(realtime.EventTypeChoreApproved, updatedChore, &currentUser.User, nil, pendingHistory, nil)
 	}
 
 	c.JSON(200, gin.H{
@@ -3249,7 +3577,7 @@ func (h *Handler) rejectChore(c *gin.Context) {
 			"updatedBy": currentUser.ID,
 			"updatedAt": time.Now().UTC(),
 		}
-		broadcaster.BroadcastChoreUpdated(updatedChore, &currentUser.User, changes, rejectionNote)
+		broadcaster.BroadcastChoreChanged(realtime.EventTypeChoreRejected, updatedChore, &currentUser.User, changes, nil, rejectionNote)
 	}
 
 	c.JSON(200, gin.H{
@@ -3714,6 +4042,13 @@ func (h *Handler) sendNudgeNotification(c *gin.Context) {
 		}
 	}
 
+	// forward the nudge to the users' other targets that have a routing rule for nudges
+	nudgeMessage := req.Message
+	if nudgeMessage == "" {
+		nudgeMessage = fmt.Sprintf("👋 %s nudged you about '%s'", currentUser.DisplayName, chore.Name)
+	}
+	h.nPlanner.GenerateNudgeNotifications(c, chore, filteredTargets, nudgeMessage)
+
 	log.Infow("Nudge notification process completed",
 		"fromUserID", currentUser.ID,
 		"choreID", choreID,
@@ -3721,6 +4056,11 @@ func (h *Handler) sendNudgeNotification(c *gin.Context) {
 		"totalDevicesSent", totalDevicesSent,
 		"errors", len(errors))
 
+	if h.realTimeService != nil {
+		broadcaster := h.realTimeService.GetEventBroadcaster()
+		broadcaster.BroadcastChoreNudged(chore, filteredTargets, &currentUser.User)
+	}
+
 	response := gin.H{
 		"message": fmt.Sprintf("Nudge sent to %d user(s) across %d device(s)", len(filteredTargets)-len(errors), totalDevicesSent),
 	}
@@ -3941,7 +4281,7 @@ func (h *Handler) undoChore(c *gin.Context) {
 			"nextDueDate": previousDueDate,
 			"status":      updatedChore.Status,
 		}
-		broadcaster.BroadcastChoreUpdated(updatedChore, &currentUser.User, changes, nil)
+		broadcaster.BroadcastChoreChanged(realtime.EventTypeChoreUndone, updatedChore, &currentUser.User, changes, nil, nil)
 	}
 
 	c.JSON(200, gin.H{
@@ -3977,9 +4317,12 @@ func Routes(router *gin.Engine, h *Handler, multiAuthMiddleware *auth.MultiAuthM
 		choresRoutes.PUT("/", h.editChore)
 		choresRoutes.PUT("/:id/priority", h.updatePriority)
 		choresRoutes.POST("/", h.createChore)
+		choresRoutes.POST("/forecast", h.previewChoreForecast)
 		choresRoutes.GET("/:id", h.getChore)
 		choresRoutes.PUT("/:id/subtask", h.UpdateSubtaskCompletedAt)
 		choresRoutes.GET("/:id/details", h.GetChoreDetail)
+		choresRoutes.GET("/:id/rrule", h.getChoreRRule)
+		choresRoutes.GET("/:id/forecast", h.getChoreForecast)
 		choresRoutes.GET("/:id/history", h.GetChoreHistory)
 		choresRoutes.PUT("/:id/history/:history_id", h.ModifyHistory)
 		choresRoutes.DELETE("/:id/history/:history_id", h.DeleteHistory)
@@ -4001,4 +4344,11 @@ func Routes(router *gin.Engine, h *Handler, multiAuthMiddleware *auth.MultiAuthM
 		choresRoutes.POST("/:id/nudge", h.sendNudgeNotification)
 		choresRoutes.POST("/:id/undo", h.undoChore)
 	}
+
+	// links in notification emails, the signed token authenticates the request
+	actionRoutes := router.Group("api/v1/actions")
+	{
+		actionRoutes.GET("/:token", h.showEmailAction)
+		actionRoutes.POST("/:token", h.performEmailAction)
+	}
 }
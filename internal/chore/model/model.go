@@ -15,6 +15,8 @@ import (
 )
 
 const MAX_TEMPLATES = 5
+const MAX_DEPENDENCIES = 20
+const MAX_ESCALATIONS = 5
 
 type FrequencyType string
 
@@ -30,6 +32,7 @@ const (
 	FrequencyTypeDayOfTheMonth FrequencyType = "day_of_the_month"
 	FrequencyTypeTrigger       FrequencyType = "trigger"
 	FrequencyTypeNoRepeat      FrequencyType = "no_repeat"
+	FrequencyTypeRRule         FrequencyType = "rrule"
 )
 
 type AssignmentStrategy string
@@ -79,6 +82,8 @@ type Chore struct {
 	DeadlineOffset         *int                  `json:"deadlineOffset,omitempty" gorm:"column:deadline_offset"`      // Seconds after NextDueDate when chore deadline is reached
 	ProjectID              *int                  `json:"projectId,omitempty" gorm:"column:project_id;index"`          // The project this chore belongs to
 	Project                *pModel.Project       `json:"project,omitempty" gorm:"foreignkey:ProjectID;references:ID"` // Project relationship
+	Dependencies           []ChoreDependency     `json:"dependencies" gorm:"foreignkey:ChoreID;references:ID"`        // Prerequisites of the chore
+	IsBlocked              bool                  `json:"isBlocked" gorm:"column:is_blocked;default:false"`            // Whether a prerequisite isn't completed for the current cycle
 }
 
 type Status int8
@@ -95,6 +100,15 @@ type ChoreAssignees struct {
 	ChoreID int `json:"-" gorm:"column:chore_id;uniqueIndex:idx_chore_user"`     // The chore this assignee is for
 	UserID  int `json:"userId" gorm:"column:user_id;uniqueIndex:idx_chore_user"` // The user this assignee is for
 }
+
+// ChoreDependency makes a chore wait for a prerequisite chore to be completed in each of its cycles
+type ChoreDependency struct {
+	ID          int  `json:"-" gorm:"primary_key"`
+	ChoreID     int  `json:"-" gorm:"column:chore_id;uniqueIndex:idx_chore_dependency"`                      // The dependent chore
+	DependsOnID int  `json:"dependsOnId" gorm:"column:depends_on_id;uniqueIndex:idx_chore_dependency;index"` // The prerequisite chore
+	DueOffset   *int `json:"dueOffset,omitempty" gorm:"column:due_offset"`                                   // Seconds after the prerequisite's completion the unblocked chore is due
+}
+
 type ChoreHistory struct {
 	ID          int                `json:"id" gorm:"primary_key"`                             // Unique identifier
 	ChoreID     int                `json:"choreId" gorm:"column:chore_id"`                    // The chore this history is for
@@ -123,14 +137,61 @@ const (
 )
 
 type FrequencyMetadata struct {
-	Days        []*string    `json:"days,omitempty"`
-	Months      []*string    `json:"months,omitempty"`
-	Unit        *string      `json:"unit,omitempty"`
-	Time        string       `json:"time,omitempty"`
-	Timezone    string       `json:"timezone,omitempty"`
-	WeekPattern *Weekpattern `json:"weekPattern,omitempty"`
-	WeekNumbers []int        `json:"weekNumbers,omitempty"` // DEPRECATED: use Occurrences instead
-	Occurrences []*int       `json:"occurrences,omitempty"` // e.g. ["1","3","last"] for 1st, 3rd, and last occurrence of the day
+	Days        []*string         `json:"days,omitempty"`
+	Months      []*string         `json:"months,omitempty"`
+	Unit        *string           `json:"unit,omitempty"`
+	Time        string            `json:"time,omitempty"`
+	Timezone    string            `json:"timezone,omitempty"`
+	WeekPattern *Weekpattern      `json:"weekPattern,omitempty"`
+	WeekNumbers []int             `json:"weekNumbers,omitempty"` // DEPRECATED: use Occurrences instead
+	Occurrences []*int            `json:"occurrences,omitempty"` // e.g. ["1","3","last"] for 1st, 3rd, and last occurrence of the day
+	RRule       string            `json:"rrule,omitempty"`       // RFC 5545 DTSTART, RRULE and EXDATE lines of an rrule chore
+	Adaptive    *AdaptiveSettings `json:"adaptive,omitempty"`
+}
+
+// AdaptiveSettings tune how an adaptive chore learns its interval from past completions
+type AdaptiveSettings struct {
+	MinInterval *int     `json:"minInterval,omitempty"` // shortest interval in seconds
+	MaxInterval *int     `json:"maxInterval,omitempty"` // longest interval in seconds
+	DecayFactor *float64 `json:"decayFactor,omitempty"` // weight of each older interval relative to the next newer one, 0.5 by default
+}
+
+// AdaptiveExplanation is the breakdown of how an adaptive chore's interval was computed
+type AdaptiveExplanation struct {
+	Samples         int     `json:"samples"`  // intervals used in the average
+	Ignored         int     `json:"ignored"`  // skipped, rescheduled and missed entries left out
+	Outliers        int     `json:"outliers"` // intervals rejected as outliers
+	DecayFactor     float64 `json:"decayFactor"`
+	WeightedAverage float64 `json:"weightedAverage"` // seconds
+	Interval        float64 `json:"interval"`        // seconds, after clamping
+	Clamp           string  `json:"clamp,omitempty"` // "min" or "max" when a bound was applied
+	Fallback        bool    `json:"fallback"`        // too little history, the interval follows the last delay
+}
+
+func (m *FrequencyMetadata) Validate() error {
+	if m == nil {
+		return nil
+	}
+	return m.Adaptive.Validate()
+}
+
+func (a *AdaptiveSettings) Validate() error {
+	if a == nil {
+		return nil
+	}
+	if a.DecayFactor != nil && (*a.DecayFactor <= 0 || *a.DecayFactor > 1) {
+		return errors.New("adaptive decay factor must be greater than 0 and at most 1")
+	}
+	if a.MinInterval != nil && *a.MinInterval <= 0 {
+		return errors.New("adaptive minimum interval must be positive")
+	}
+	if a.MaxInterval != nil && *a.MaxInterval <= 0 {
+		return errors.New("adaptive maximum interval must be positive")
+	}
+	if a.MinInterval != nil && a.MaxInterval != nil && *a.MinInterval > *a.MaxInterval {
+		return errors.New("adaptive minimum interval cannot exceed the maximum")
+	}
+	return nil
 }
 
 type Weekpattern string
@@ -149,8 +210,28 @@ type NotificationMetadata struct {
 	CircleGroup   bool                    `json:"circleGroup,omitempty"`
 	CircleGroupID *int64                  `json:"circleGroupID,omitempty"`
 	Templates     []*NotificationTemplate `json:"templates,omitempty" validate:"max=5"` // Template for notification
+	// NaggingInterval is the number of minutes between nagging reminders once the chore is overdue
+	NaggingInterval int `json:"naggingInterval,omitempty"`
+	// Escalations notify more people when the chore stays overdue, each level after its own delay
+	Escalations []*EscalationLevel `json:"escalations,omitempty" validate:"max=5"`
 }
 
+// EscalationLevel notifies Target once the chore has been overdue for Value Unit. Message
+// replaces the default text, {chore} and {assignee} are substituted.
+type EscalationLevel struct {
+	Value   int                      `json:"value"`
+	Unit    NotificationTemplateUnit `json:"unit"`
+	Target  EscalationTarget         `json:"target"`
+	Message string                   `json:"message,omitempty"`
+}
+
+type EscalationTarget string
+
+const (
+	EscalationTargetManagers    EscalationTarget = "managers"     // circle managers and admins
+	EscalationTargetCircleGroup EscalationTarget = "circle_group" // the circle group chat, see CircleGroupID
+)
+
 type NotificationTemplate struct {
 	Value int                      `json:"value"`
 	Unit  NotificationTemplateUnit `json:"unit"`
@@ -170,27 +251,28 @@ type Tag struct {
 }
 
 type ChoreDetail struct {
-	ID                  int                `json:"id" gorm:"column:id"`
-	Name                string             `json:"name" gorm:"column:name"`
-	Description         *string            `json:"description" gorm:"column:description"`
-	FrequencyType       string             `json:"frequencyType" gorm:"column:frequency_type"`
-	NextDueDate         *time.Time         `json:"nextDueDate" gorm:"column:next_due_date"`
-	AssignedTo          *int               `json:"assignedTo" gorm:"column:assigned_to"`
-	LastCompletedDate   *time.Time         `json:"lastCompletedDate" gorm:"column:last_completed_date"`
-	LastCompletedBy     *int               `json:"lastCompletedBy" gorm:"column:last_completed_by"`
-	TotalCompletedCount int                `json:"totalCompletedCount" gorm:"column:total_completed"`
-	Priority            int                `json:"priority" gorm:"column:priority"`
-	Notes               *string            `json:"notes" gorm:"column:notes"`
-	CreatedBy           int                `json:"createdBy" gorm:"column:created_by"`
-	CompletionWindow    *int               `json:"completionWindow,omitempty" gorm:"column:completion_window"`
-	Subtasks            *[]stModel.SubTask `json:"subTasks,omitempty" gorm:"foreignkey:ChoreID;references:ID"`
-	Status              Status             `json:"status" gorm:"column:status"`
-	Duration            int                `json:"duration" gorm:"column:duration"` // Total duration in seconds for the chore
-	StartTime           *time.Time         `json:"startTime" gorm:"column:start_time"`
-	TimerUpdatedAt      *time.Time         `json:"timerUpdatedAt" gorm:"column:timer_updated_at"` // When the chore was last started
-	DeadlineOffset      *int               `json:"deadlineOffset,omitempty" gorm:"column:deadline_offset"`
-	ProjectID           *int               `json:"projectId,omitempty" gorm:"column:project_id"`
-	IsActive            bool               `json:"isActive" gorm:"column:is_active"`
+	ID                  int                  `json:"id" gorm:"column:id"`
+	Name                string               `json:"name" gorm:"column:name"`
+	Description         *string              `json:"description" gorm:"column:description"`
+	FrequencyType       string               `json:"frequencyType" gorm:"column:frequency_type"`
+	NextDueDate         *time.Time           `json:"nextDueDate" gorm:"column:next_due_date"`
+	AssignedTo          *int                 `json:"assignedTo" gorm:"column:assigned_to"`
+	LastCompletedDate   *time.Time           `json:"lastCompletedDate" gorm:"column:last_completed_date"`
+	LastCompletedBy     *int                 `json:"lastCompletedBy" gorm:"column:last_completed_by"`
+	TotalCompletedCount int                  `json:"totalCompletedCount" gorm:"column:total_completed"`
+	Priority            int                  `json:"priority" gorm:"column:priority"`
+	Notes               *string              `json:"notes" gorm:"column:notes"`
+	CreatedBy           int                  `json:"createdBy" gorm:"column:created_by"`
+	CompletionWindow    *int                 `json:"completionWindow,omitempty" gorm:"column:completion_window"`
+	Subtasks            *[]stModel.SubTask   `json:"subTasks,omitempty" gorm:"foreignkey:ChoreID;references:ID"`
+	Status              Status               `json:"status" gorm:"column:status"`
+	Duration            int                  `json:"duration" gorm:"column:duration"` // Total duration in seconds for the chore
+	StartTime           *time.Time           `json:"startTime" gorm:"column:start_time"`
+	TimerUpdatedAt      *time.Time           `json:"timerUpdatedAt" gorm:"column:timer_updated_at"` // When the chore was last started
+	DeadlineOffset      *int                 `json:"deadlineOffset,omitempty" gorm:"column:deadline_offset"`
+	ProjectID           *int                 `json:"projectId,omitempty" gorm:"column:project_id"`
+	IsActive            bool                 `json:"isActive" gorm:"column:is_active"`
+	Adaptive            *AdaptiveExplanation `json:"adaptive,omitempty" gorm:"-"` // why an adaptive chore is due when it is
 }
 
 type ChoreLabels struct {
@@ -229,6 +311,7 @@ type ChoreReq struct {
 	Description          *string               `json:"description"`
 	Priority             int                   `json:"priority"`
 	SubTasks             *[]stModel.SubTask    `json:"subTasks"`
+	Dependencies         *[]ChoreDependency    `json:"dependencies"`
 	RequireApproval      bool                  `json:"requireApproval"`
 	IsPrivate            bool                  `json:"isPrivate"`
 	DeadlineOffset       *int                  `json:"deadlineOffset,omitempty"`
@@ -326,6 +409,10 @@ func (c *Chore) CanView(userID int, circleUsers []*cModel.UserCircleDetail) bool
 	return false
 }
 func (c *Chore) CanComplete(userID int, circleUsers []*cModel.UserCircleDetail) bool {
+	// a blocked chore waits for its prerequisites
+	if c.IsBlocked {
+		return false
+	}
 	// If using no assignee strategy, allow any circle member to complete
 	if c.AssignStrategy == AssignmentStrategyNoAssignee && (c.AssignedTo == nil || *c.AssignedTo == 0) {
 		if !c.IsPrivate {
@@ -414,6 +501,27 @@ func (n *NotificationMetadata) Validate() error {
 			MAX_TEMPLATES, len(n.Templates))
 	}
 
+	if len(n.Escalations) > MAX_ESCALATIONS {
+		return fmt.Errorf("escalations cannot exceed %d levels (got %d)",
+			MAX_ESCALATIONS, len(n.Escalations))
+	}
+
+	for _, level := range n.Escalations {
+		if level == nil || level.Value <= 0 {
+			return errors.New("escalation delay must be positive")
+		}
+		switch level.Unit {
+		case NotificationTemplateUnitMinute, NotificationTemplateUnitHour, NotificationTemplateUnitDay:
+		default:
+			return fmt.Errorf("unsupported escalation unit: %s", level.Unit)
+		}
+		switch level.Target {
+		case EscalationTargetManagers, EscalationTargetCircleGroup:
+		default:
+			return fmt.Errorf("unsupported escalation target: %s", level.Target)
+		}
+	}
+
 	return nil
 }
 
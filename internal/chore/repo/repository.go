@@ -9,12 +9,15 @@ import (
 	config "donetick.com/core/config"
 	chModel "donetick.com/core/internal/chore/model"
 	cModel "donetick.com/core/internal/circle/model"
+	pModel "donetick.com/core/internal/points"
 	storageModel "donetick.com/core/internal/storage/model"
 	stModel "donetick.com/core/internal/subtask/model"
 	"donetick.com/core/logging"
 	"gorm.io/gorm"
 )
 
+var ErrChoreChanged = errors.New("chore was modified concurrently")
+
 type ChoreRepository struct {
 	db     *gorm.DB
 	dbType string
@@ -55,6 +58,7 @@ func (r *ChoreRepository) GetChore(c context.Context, choreID int, userID int, c
 	query := r.db.WithContext(c).Model(&chModel.Chore{}).
 		Preload("SubTasks", "chore_id = ?", choreID).
 		Preload("Assignees").
+		Preload("Dependencies").
 		Preload("ThingChore").
 		Preload("LabelsV2").
 		Joins("LEFT JOIN chore_assignees ON chores.id = chore_assignees.chore_id AND chore_assignees.user_id = ?", userID).
@@ -91,6 +95,19 @@ func (r *ChoreRepository) DeleteChore(c context.Context, id int) error {
 		if err := tx.Where("chore_id = ?", id).Delete(&chModel.ChoreAssignees{}).Error; err != nil {
 			return err
 		}
+		var dependentIDs []int
+		if err := tx.Model(&chModel.ChoreDependency{}).Where("depends_on_id = ?", id).Pluck("chore_id", &dependentIDs).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("chore_id = ? OR depends_on_id = ?", id, id).Delete(&chModel.ChoreDependency{}).Error; err != nil {
+			return err
+		}
+		// chores waiting only for the deleted chore are no longer blocked
+		for _, dependentID := range dependentIDs {
+			if _, err := refreshBlockedState(tx, dependentID); err != nil {
+				return err
+			}
+		}
 		if err := tx.Where("chore_id = ?", id).Delete(&chModel.TimeSession{}).Error; err != nil {
 			return err
 		}
@@ -216,6 +233,16 @@ func (r *ChoreRepository) ApproveChore(c context.Context, chore *chModel.Chore,
 			if err := tx.Model(&cModel.UserCircle{}).Where("user_id = ? AND circle_id = ?", history.CompletedBy, chore.CircleID).Update("points", gorm.Expr("points + ?", chore.Points)).Error; err != nil {
 				return err
 			}
+			if err := tx.Create(&pModel.PointsHistory{
+				Action:    pModel.PointsHistoryActionAdd,
+				Points:    *chore.Points,
+				CreatedAt: time.Now().UTC(),
+				CreatedBy: adminUserID,
+				UserID:    history.CompletedBy,
+				CircleID:  chore.CircleID,
+			}).Error; err != nil {
+				return err
+			}
 		}
 
 		// Save the updated history
@@ -227,6 +254,9 @@ func (r *ChoreRepository) ApproveChore(c context.Context, chore *chModel.Chore,
 		if err := tx.Model(&chModel.Chore{}).Where("id = ?", chore.ID).Updates(choreUpdates).Error; err != nil {
 			return err
 		}
+		if err := applyCompletionToDependencies(tx, chore.ID, *history.PerformedAt, dueDate != nil); err != nil {
+			return err
+		}
 
 		return nil
 	})
@@ -315,6 +345,16 @@ func (r *ChoreRepository) CompleteChore(c context.Context, chore *chModel.Chore,
 			if err := tx.Model(&cModel.UserCircle{}).Where("user_id = ? AND circle_id = ?", userID, chore.CircleID).Update("points", gorm.Expr("points + ?", chore.Points)).Error; err != nil {
 				return err
 			}
+			if err := tx.Create(&pModel.PointsHistory{
+				Action:    pModel.PointsHistoryActionAdd,
+				Points:    *chore.Points,
+				CreatedAt: time.Now().UTC(),
+				CreatedBy: userID,
+				UserID:    userID,
+				CircleID:  chore.CircleID,
+			}).Error; err != nil {
+				return err
+			}
 		}
 		// Perform the update operation once, using the prepared updates map.
 		if err := tx.Model(&chModel.Chore{}).Where("id = ?", chore.ID).Updates(choreUpdates).Error; err != nil {
@@ -324,6 +364,9 @@ func (r *ChoreRepository) CompleteChore(c context.Context, chore *chModel.Chore,
 		if err := tx.Save(ch).Error; err != nil {
 			return err
 		}
+		if err := applyCompletionToDependencies(tx, chore.ID, *completedDate, dueDate != nil); err != nil {
+			return err
+		}
 		// if there is any time session associated with the chore, mark them as finished:
 		var timeSessions []*chModel.TimeSession
 		tx.Model(&chModel.TimeSession{}).Where("chore_id = ? AND status < ?", chore.ID, chModel.TimeSessionStatusCompleted).Find(&timeSessions)
@@ -411,6 +454,71 @@ func (r *ChoreRepository) SkipChore(c context.Context, chore *chModel.Chore, use
 	return err
 }
 
+// GetChoresPastDeadline returns active chores with a deadline offset whose due date is before now.
+// The deadline itself is computed by the caller with Chore.GetDeadline as the offset arithmetic is not portable across databases.
+func (r *ChoreRepository) GetChoresPastDeadline(c context.Context, now time.Time) ([]*chModel.Chore, error) {
+	var chores []*chModel.Chore
+	if err := r.db.WithContext(c).
+		Preload("Assignees").
+		Where("is_active = ? AND is_blocked = ? AND deadline_offset IS NOT NULL AND next_due_date IS NOT NULL AND next_due_date < ?", true, false, now).
+		Where("status <> ?", chModel.ChoreStatusPendingApproval).
+		Find(&chores).Error; err != nil {
+		return nil, err
+	}
+	return chores, nil
+}
+
+// GetAssignedChoresDueBefore returns the active chores assigned to the user that are due before the given time, overdue ones included
+func (r *ChoreRepository) GetAssignedChoresDueBefore(c context.Context, userID int, before time.Time) ([]*chModel.Chore, error) {
+	var chores []*chModel.Chore
+	if err := r.db.WithContext(c).
+		Where("is_active = ? AND is_blocked = ? AND assigned_to = ? AND next_due_date IS NOT NULL AND next_due_date < ?", true, false, userID, before).
+		Where("status <> ?", chModel.ChoreStatusPendingApproval).
+		Order("next_due_date asc").
+		Find(&chores).Error; err != nil {
+		return nil, err
+	}
+	return chores, nil
+}
+
+func (r *ChoreRepository) MissChore(c context.Context, chore *chModel.Chore, missedAt time.Time, dueDate *time.Time, nextAssignedTo *int) (*chModel.ChoreHistory, error) {
+	ch := &chModel.ChoreHistory{
+		ChoreID:     chore.ID,
+		PerformedAt: &missedAt,
+		AssignedTo:  chore.AssignedTo,
+		DueDate:     chore.NextDueDate,
+		Status:      chModel.ChoreHistoryStatusMissed,
+	}
+	err := r.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		choreUpdates := map[string]interface{}{}
+		choreUpdates["next_due_date"] = dueDate
+		choreUpdates["status"] = chModel.ChoreStatusNoStatus
+
+		if dueDate != nil {
+			choreUpdates["assigned_to"] = nextAssignedTo
+		} else {
+			// one time task
+			choreUpdates["is_active"] = false
+		}
+
+		// only update the chore if it still has the due date we evaluated, so a completion
+		// that happened in the meantime is not overwritten:
+		result := tx.Model(&chModel.Chore{}).Where("id = ? AND next_due_date = ?", chore.ID, chore.NextDueDate).Updates(choreUpdates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrChoreChanged
+		}
+
+		return tx.Create(ch).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ch, nil
+}
+
 func (r *ChoreRepository) GetChoreHistory(c context.Context, choreID int) ([]*chModel.ChoreHistory, error) {
 	var histories []*chModel.ChoreHistory
 	if err := r.db.WithContext(c).
@@ -886,6 +994,16 @@ func (r *ChoreRepository) UndoChoreAction(c context.Context, choreID int, histor
 				Update("points", gorm.Expr("points - ?", *historyToUndo.Points)).Error; err != nil {
 				return err
 			}
+			if err := tx.Create(&pModel.PointsHistory{
+				Action:    pModel.PointsHistoryActionRemove,
+				Points:    *historyToUndo.Points,
+				CreatedAt: time.Now().UTC(),
+				CreatedBy: historyToUndo.CompletedBy,
+				UserID:    historyToUndo.CompletedBy,
+				CircleID:  chore.CircleID,
+			}).Error; err != nil {
+				return err
+			}
 		}
 
 		// Delete the history entry being undone
@@ -901,3 +1019,129 @@ func (r *ChoreRepository) UndoChoreAction(c context.Context, choreID int, histor
 		return nil
 	})
 }
+
+// GetCircleDependencyGraph returns the prerequisites of every chore in the circle, keyed by chore ID
+func (r *ChoreRepository) GetCircleDependencyGraph(c context.Context, circleID int) (map[int][]int, error) {
+	var dependencies []*chModel.ChoreDependency
+	if err := r.db.WithContext(c).
+		Joins("JOIN chores ON chores.id = chore_dependencies.chore_id").
+		Where("chores.circle_id = ?", circleID).
+		Find(&dependencies).Error; err != nil {
+		return nil, err
+	}
+	graph := make(map[int][]int)
+	for _, dependency := range dependencies {
+		graph[dependency.ChoreID] = append(graph[dependency.ChoreID], dependency.DependsOnID)
+	}
+	return graph, nil
+}
+
+// CountCircleChores counts how many of the given chores belong to the circle
+func (r *ChoreRepository) CountCircleChores(c context.Context, circleID int, choreIDs []int) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(c).Model(&chModel.Chore{}).Where("id IN (?) AND circle_id = ?", choreIDs, circleID).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// SaveChoreDependencies replaces the prerequisites of the chore and returns whether it is blocked by them
+func (r *ChoreRepository) SaveChoreDependencies(c context.Context, choreID int, dependencies []chModel.ChoreDependency) (bool, error) {
+	var blocked bool
+	err := r.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("chore_id = ?", choreID).Delete(&chModel.ChoreDependency{}).Error; err != nil {
+			return err
+		}
+		for i := range dependencies {
+			dependencies[i].ID = 0
+			dependencies[i].ChoreID = choreID
+		}
+		if len(dependencies) > 0 {
+			if err := tx.Create(&dependencies).Error; err != nil {
+				return err
+			}
+		}
+		var err error
+		blocked, err = refreshBlockedState(tx, choreID)
+		return err
+	})
+	return blocked, err
+}
+
+// GetDependentChores returns the active chores that have the given chore as a prerequisite
+func (r *ChoreRepository) GetDependentChores(c context.Context, choreID int) ([]*chModel.Chore, error) {
+	var chores []*chModel.Chore
+	if err := r.db.WithContext(c).
+		Preload("Assignees").
+		Where("is_active = ? AND id IN (SELECT chore_id FROM chore_dependencies WHERE depends_on_id = ?)", true, choreID).
+		Find(&chores).Error; err != nil {
+		return nil, err
+	}
+	return chores, nil
+}
+
+// lastCompletion returns when the chore was last completed, the zero time if never
+func lastCompletion(tx *gorm.DB, choreID int) (time.Time, error) {
+	var history []*chModel.ChoreHistory
+	if err := tx.Where("chore_id = ? AND status = ? AND performed_at IS NOT NULL", choreID, chModel.ChoreHistoryStatusCompleted).
+		Order("performed_at desc").Limit(1).Find(&history).Error; err != nil {
+		return time.Time{}, err
+	}
+	if len(history) == 0 {
+		return time.Time{}, nil
+	}
+	return history[0].PerformedAt.UTC(), nil
+}
+
+// refreshBlockedState blocks the chore while any prerequisite wasn't completed since the chore's own
+// last completion, which starts its current cycle
+func refreshBlockedState(tx *gorm.DB, choreID int) (bool, error) {
+	cycleStart, err := lastCompletion(tx, choreID)
+	if err != nil {
+		return false, err
+	}
+	var pending int64
+	if err := tx.Model(&chModel.ChoreDependency{}).
+		Where("chore_id = ?", choreID).
+		Where("NOT EXISTS (SELECT 1 FROM chore_histories h WHERE h.chore_id = chore_dependencies.depends_on_id AND h.status = ? AND h.performed_at > ?)", chModel.ChoreHistoryStatusCompleted, cycleStart).
+		Count(&pending).Error; err != nil {
+		return false, err
+	}
+	blocked := pending > 0
+	if err := tx.Model(&chModel.Chore{}).Where("id = ?", choreID).Update("is_blocked", blocked).Error; err != nil {
+		return false, err
+	}
+	return blocked, nil
+}
+
+// applyCompletionToDependencies starts a new blocked cycle for the completed chore when it recurs, and
+// unblocks the dependents whose prerequisites are now all completed. A dependent's due date moves
+// to the completion plus the dependency's offset when it has one.
+func applyCompletionToDependencies(tx *gorm.DB, choreID int, completedDate time.Time, recurring bool) error {
+	if recurring {
+		if _, err := refreshBlockedState(tx, choreID); err != nil {
+			return err
+		}
+	}
+	var dependencies []*chModel.ChoreDependency
+	if err := tx.Where("depends_on_id = ?", choreID).Find(&dependencies).Error; err != nil {
+		return err
+	}
+	for _, dependency := range dependencies {
+		var dependent chModel.Chore
+		if err := tx.Select("id", "is_blocked").Where("id = ?", dependency.ChoreID).First(&dependent).Error; err != nil {
+			return err
+		}
+		blocked, err := refreshBlockedState(tx, dependency.ChoreID)
+		if err != nil {
+			return err
+		}
+		if dependent.IsBlocked && !blocked && dependency.DueOffset != nil {
+			dueDate := completedDate.UTC().Add(time.Duration(*dependency.DueOffset) * time.Second)
+			if err := tx.Model(&chModel.Chore{}).Where("id = ?", dependency.ChoreID).Update("next_due_date", dueDate).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,308 @@
+package chore
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	chModel "donetick.com/core/internal/chore/model"
+	"github.com/teambition/rrule-go"
+)
+
+// maxRecurrenceLength bounds the stored recurrence, EXDATE lists are the only part that grows
+const maxRecurrenceLength = 4096
+
+// recurrenceLocation returns the timezone the chore's recurrence is expanded in
+func recurrenceLocation(metadata *chModel.FrequencyMetadata) *time.Location {
+	if metadata == nil || metadata.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(metadata.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// parseRecurrence parses the RFC 5545 recurrence of an rrule chore: an optional DTSTART line, one
+// RRULE line and any EXDATE or RDATE lines. A bare "FREQ=..." rule is accepted as the RRULE line.
+// Times without a TZID are read in the chore's timezone.
+func parseRecurrence(metadata *chModel.FrequencyMetadata) (*rrule.Set, error) {
+	if metadata == nil || strings.TrimSpace(metadata.RRule) == "" {
+		return nil, errors.New("rrule frequency requires a recurrence rule")
+	}
+	if len(metadata.RRule) > maxRecurrenceLength {
+		return nil, fmt.Errorf("recurrence rule is longer than %d characters", maxRecurrenceLength)
+	}
+
+	lines := make([]string, 0)
+	rules := 0
+	// long lines may be folded, a continuation line starts with a space
+	unfolded := strings.NewReplacer("\r\n ", "", "\n ", "", "\r\n\t", "", "\n\t", "").Replace(metadata.RRule)
+	for _, line := range strings.Split(unfolded, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(strings.ToUpper(line), "FREQ=") {
+			line = "RRULE:" + line
+		}
+		name := strings.ToUpper(line)
+		if i := strings.IndexAny(name, ":;"); i >= 0 {
+			name = name[:i]
+		}
+		switch name {
+		case "RRULE":
+			rules++
+		case "DTSTART":
+			if len(lines) > 0 {
+				return nil, errors.New("DTSTART must be the first line of the recurrence")
+			}
+		case "EXDATE", "RDATE":
+		default:
+			return nil, fmt.Errorf("unsupported recurrence property %q", name)
+		}
+		lines = append(lines, line)
+	}
+	if rules != 1 {
+		return nil, errors.New("recurrence must have exactly one RRULE")
+	}
+
+	set, err := rrule.StrSliceToRRuleSetInLoc(lines, recurrenceLocation(metadata))
+	if err != nil {
+		return nil, fmt.Errorf("invalid recurrence rule: %w", err)
+	}
+	return set, nil
+}
+
+// nextRRuleOccurrence returns the first occurrence after the given time, or nil once COUNT or
+// UNTIL ends the recurrence
+func nextRRuleOccurrence(metadata *chModel.FrequencyMetadata, after time.Time) (*time.Time, error) {
+	set, err := parseRecurrence(metadata)
+	if err != nil {
+		return nil, err
+	}
+	next := set.After(after, false)
+	if next.IsZero() {
+		return nil, nil
+	}
+	next = next.UTC()
+	return &next, nil
+}
+
+// normalizeRRuleFrequency validates the recurrence of an rrule chore and pins its DTSTART, so COUNT
+// and ordinal rules keep counting from the same start when the chore is rescheduled. Without a
+// DTSTART the recurrence starts at the due date, or now.
+func normalizeRRuleFrequency(frequencyType chModel.FrequencyType, metadata *chModel.FrequencyMetadata, dueDate *time.Time) error {
+	if frequencyType != chModel.FrequencyTypeRRule {
+		return nil
+	}
+	set, err := parseRecurrence(metadata)
+	if err != nil {
+		return err
+	}
+	if set.GetDTStart().IsZero() {
+		start := time.Now().UTC()
+		if dueDate != nil {
+			start = *dueDate
+		}
+		set.DTStart(start.In(recurrenceLocation(metadata)).Truncate(time.Second))
+	}
+	if set.After(set.GetDTStart(), true).IsZero() {
+		return errors.New("recurrence rule has no occurrences")
+	}
+	metadata.RRule = set.String()
+	return nil
+}
+
+var rruleWeekdays = map[string]string{
+	"monday": "MO", "tuesday": "TU", "wednesday": "WE", "thursday": "TH",
+	"friday": "FR", "saturday": "SA", "sunday": "SU",
+}
+
+// FrequencyToRRule converts a chore's frequency to an equivalent RFC 5545 recurrence starting at
+// its next due date. Adaptive, trigger and one-off chores, and week_of_quarter patterns, have no
+// equivalent.
+func FrequencyToRRule(chore *chModel.Chore) (string, error) {
+	metadata := chore.FrequencyMetadataV2
+	if metadata == nil {
+		metadata = &chModel.FrequencyMetadata{}
+	}
+	if chore.FrequencyType == chModel.FrequencyTypeRRule {
+		return metadata.RRule, nil
+	}
+	start, lines, err := ChoreRecurrence(chore)
+	if err != nil {
+		return "", err
+	}
+	converted := &chModel.FrequencyMetadata{Timezone: metadata.Timezone, RRule: strings.Join(lines, "\n")}
+	if err := normalizeRRuleFrequency(chModel.FrequencyTypeRRule, converted, &start); err != nil {
+		return "", err
+	}
+	return converted.RRule, nil
+}
+
+// ChoreRecurrence returns the start of the series matching the chore's frequency and its RRULE,
+// RDATE and EXDATE lines, in the chore's timezone. Rrule chores keep their own DTSTART, other
+// chores start at their next due date. It is the one conversion used by the RRULE endpoint and
+// the calendar feeds.
+func ChoreRecurrence(chore *chModel.Chore) (time.Time, []string, error) {
+	metadata := chore.FrequencyMetadataV2
+	if metadata == nil {
+		metadata = &chModel.FrequencyMetadata{}
+	}
+	if chore.FrequencyType == chModel.FrequencyTypeRRule {
+		set, err := parseRecurrence(metadata)
+		if err != nil {
+			return time.Time{}, nil, err
+		}
+		start := set.GetDTStart()
+		if start.IsZero() {
+			if chore.NextDueDate == nil {
+				return time.Time{}, nil, errors.New("chore has no due date to start the recurrence from")
+			}
+			start = chore.NextDueDate.In(recurrenceLocation(metadata))
+		}
+		lines := make([]string, 0)
+		for _, line := range set.Recurrence() {
+			if !strings.HasPrefix(line, "DTSTART") {
+				lines = append(lines, line)
+			}
+		}
+		return start, lines, nil
+	}
+
+	if chore.NextDueDate == nil {
+		return time.Time{}, nil, errors.New("chore has no due date to start the recurrence from")
+	}
+	start := chore.NextDueDate.In(recurrenceLocation(metadata))
+	rule, err := frequencyRule(chore, metadata, start)
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+	return start, []string{"RRULE:" + rule}, nil
+}
+
+// frequencyRule returns the RRULE value of a built-in frequency for a series starting at start
+func frequencyRule(chore *chModel.Chore, metadata *chModel.FrequencyMetadata, start time.Time) (string, error) {
+	var rule string
+	switch chore.FrequencyType {
+	case chModel.FrequencyTypeDaily:
+		rule = "FREQ=DAILY"
+	case chModel.FrequencyTypeWeekly:
+		rule = "FREQ=WEEKLY"
+	case chModel.FrequencyTypeMonthly:
+		rule = "FREQ=MONTHLY" + clampedMonthDay(start.Day())
+	case chModel.FrequencyTypeYearly:
+		rule = fmt.Sprintf("FREQ=YEARLY;BYMONTH=%d", int(start.Month())) + clampedMonthDay(start.Day())
+	case chModel.FrequencyTypeInterval:
+		if metadata.Unit == nil || chore.Frequency <= 0 {
+			return "", errors.New("interval frequency needs a unit and a positive interval")
+		}
+		switch *metadata.Unit {
+		case "hours":
+			rule = "FREQ=HOURLY"
+		case "days":
+			rule = "FREQ=DAILY"
+		case "weeks":
+			rule = "FREQ=WEEKLY"
+		case "months":
+			rule = "FREQ=MONTHLY" + clampedMonthDay(start.Day())
+		case "years":
+			rule = fmt.Sprintf("FREQ=YEARLY;BYMONTH=%d", int(start.Month())) + clampedMonthDay(start.Day())
+		default:
+			return "", fmt.Errorf("invalid frequency unit: %s", *metadata.Unit)
+		}
+		if chore.Frequency > 1 {
+			rule += fmt.Sprintf(";INTERVAL=%d", chore.Frequency)
+		}
+	case chModel.FrequencyTypeDayOfTheWeek:
+		days, err := rruleDays(metadata.Days)
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case metadata.WeekPattern == nil || *metadata.WeekPattern == "" || *metadata.WeekPattern == chModel.WeekpatternEveryWeek:
+			rule = "FREQ=WEEKLY;BYDAY=" + strings.Join(days, ",")
+		case *metadata.WeekPattern == chModel.WeekPatternWeekOfMonth:
+			byDay := make([]string, 0)
+			for _, occurrence := range getOccurrences(metadata) {
+				if occurrence == "last" {
+					occurrence = "-1"
+				}
+				for _, day := range days {
+					byDay = append(byDay, occurrence+day)
+				}
+			}
+			if len(byDay) == 0 {
+				return "", errors.New("week_of_month requires at least one occurrence")
+			}
+			rule = "FREQ=MONTHLY;BYDAY=" + strings.Join(byDay, ",")
+		default:
+			return "", fmt.Errorf("week pattern %s has no RRULE equivalent", *metadata.WeekPattern)
+		}
+	case chModel.FrequencyTypeDayOfTheMonth:
+		if chore.Frequency <= 0 || chore.Frequency > 31 {
+			return "", fmt.Errorf("invalid day of the month: %d", chore.Frequency)
+		}
+		months := make([]string, 0, len(metadata.Months))
+		for _, month := range metadata.Months {
+			if month == nil {
+				continue
+			}
+			m := monthNumber(*month)
+			if m == 0 {
+				return "", fmt.Errorf("invalid month: %s", *month)
+			}
+			months = append(months, fmt.Sprint(m))
+		}
+		if len(months) == 0 {
+			return "", errors.New("day_of_the_month requires at least one month")
+		}
+		rule = "FREQ=MONTHLY;BYMONTH=" + strings.Join(months, ",") + clampedMonthDay(chore.Frequency)
+	default:
+		return "", fmt.Errorf("%s frequency has no RRULE equivalent", chore.FrequencyType)
+	}
+	return rule, nil
+}
+
+// clampedMonthDay selects the day of the month, or the month's last day when it is shorter, the
+// way the built-in frequencies treat e.g. the 31st
+func clampedMonthDay(day int) string {
+	if day <= 28 {
+		return fmt.Sprintf(";BYMONTHDAY=%d", day)
+	}
+	days := make([]string, 0, day-27)
+	for d := 28; d <= day; d++ {
+		days = append(days, fmt.Sprint(d))
+	}
+	return ";BYMONTHDAY=" + strings.Join(days, ",") + ";BYSETPOS=-1"
+}
+
+func rruleDays(days []*string) ([]string, error) {
+	converted := make([]string, 0, len(days))
+	for _, day := range days {
+		if day == nil {
+			continue
+		}
+		code, ok := rruleWeekdays[strings.ToLower(*day)]
+		if !ok {
+			return nil, fmt.Errorf("invalid day of the week: %s", *day)
+		}
+		converted = append(converted, code)
+	}
+	if len(converted) == 0 {
+		return nil, errors.New("days_of_the_week requires at least one day")
+	}
+	return converted, nil
+}
+
+func monthNumber(name string) int {
+	for m := time.January; m <= time.December; m++ {
+		if strings.EqualFold(m.String(), name) {
+			return int(m)
+		}
+	}
+	return 0
+}
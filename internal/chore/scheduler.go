@@ -5,10 +5,12 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
+	"sort"
 	"strings"
 	"time"
 
 	chModel "donetick.com/core/internal/chore/model"
+	cModel "donetick.com/core/internal/circle/model"
 	"donetick.com/core/logging"
 )
 
@@ -27,6 +29,16 @@ func scheduleNextDueDate(ctx context.Context, chore *chModel.Chore, completedDat
 		baseDate = completedDate.UTC()
 	}
 
+	if chore.FrequencyType == chModel.FrequencyTypeRRule {
+		// the recurrence carries its own times, expanded in the chore's timezone
+		return nextRRuleOccurrence(chore.FrequencyMetadataV2, baseDate)
+	}
+
+	// Calendar arithmetic happens on the wall clock of the chore's timezone, so a 7pm chore stays
+	// at 7pm across DST changes and days and months roll over at local midnight
+	loc := schedulingLocation(ctx, chore)
+	baseDate = baseDate.In(loc)
+
 	// Handle time-based frequencies, ensure time is in the future
 	if chore.FrequencyType == "day_of_the_month" || chore.FrequencyType == "days_of_the_week" || chore.FrequencyType == "interval" {
 		t, err := time.Parse(time.RFC3339, chore.FrequencyMetadataV2.Time)
@@ -43,8 +55,8 @@ func scheduleNextDueDate(ctx context.Context, chore *chModel.Chore, completedDat
 			}
 
 		}
-		t = t.UTC()
-		baseDate = time.Date(baseDate.Year(), baseDate.Month(), baseDate.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+		t = t.In(loc)
+		baseDate = time.Date(baseDate.Year(), baseDate.Month(), baseDate.Day(), t.Hour(), t.Minute(), t.Second(), 0, loc)
 	}
 
 	switch chore.FrequencyType {
@@ -57,9 +69,9 @@ func scheduleNextDueDate(ctx context.Context, chore *chModel.Chore, completedDat
 	case "yearly":
 		baseDate = baseDate.AddDate(1, 0, 0)
 	case "adaptive":
-		// TODO: Implement a more sophisticated adaptive logic
-		diff := completedDate.UTC().Sub(chore.NextDueDate.UTC())
-		baseDate = completedDate.UTC().Add(diff)
+		// callers with the chore's history use scheduleAdaptiveNextDueDate, without it only the
+		// delay of this completion and the chore's bounds apply
+		return scheduleAdaptiveNextDueDate(chore, completedDate, nil)
 	case "interval":
 		switch *chore.FrequencyMetadataV2.Unit {
 		case "hours":
@@ -85,26 +97,9 @@ func scheduleNextDueDate(ctx context.Context, chore *chModel.Chore, completedDat
 
 		// Default to every_week if no pattern specified
 		if weekPattern == nil || *weekPattern == "" || *weekPattern == "every_week" {
-			// Get timezone for calculations - prefer chore timezone, fallback to UTC
-			var loc *time.Location
-			var err error
-			if chore.FrequencyMetadataV2.Timezone != "" {
-				loc, err = time.LoadLocation(chore.FrequencyMetadataV2.Timezone)
-				if err != nil {
-					log := logging.FromContext(ctx)
-					log.Error("error loading timezone from frequency metadata", "error", err, "timezone", chore.FrequencyMetadataV2.Timezone, "chore_id", chore.ID)
-					loc = time.UTC // fallback to UTC
-				}
-			} else {
-				loc = time.UTC
-			}
-
-			// Convert baseDate to the target timezone for weekday calculations
-			baseDateInTimezone := baseDate.In(loc)
-
-			// Find the next valid day of the week in the target timezone
+			// Find the next valid day of the week in the chore's timezone
 			for i := 1; i <= 7; i++ {
-				nextDueDateInTimezone := baseDateInTimezone.AddDate(0, 0, i)
+				nextDueDateInTimezone := baseDate.AddDate(0, 0, i)
 				nextDay := strings.ToLower(nextDueDateInTimezone.Weekday().String())
 				for _, day := range chore.FrequencyMetadataV2.Days {
 					if strings.ToLower(*day) == nextDay {
@@ -142,7 +137,7 @@ func scheduleNextDueDate(ctx context.Context, chore *chModel.Chore, completedDat
 		// if task due every 15 of jan, and you completed it on the 13 of jan( before the due date ) if we schedule from due date
 		// we will go back to 15 of jan. so we need to pick the highest between the two dates specifically for day of the month
 		if chore.IsRolling && chore.NextDueDate != nil {
-			secondAfterDueDate := chore.NextDueDate.UTC().Add(time.Second)
+			secondAfterDueDate := chore.NextDueDate.In(loc).Add(time.Second)
 			if completedDate.Before(secondAfterDueDate) {
 				baseDate = secondAfterDueDate
 			}
@@ -159,7 +154,7 @@ func scheduleNextDueDate(ctx context.Context, chore *chModel.Chore, completedDat
 		currentMonth := int(baseDate.Month())
 
 		var startFrom int
-		if chore.NextDueDate != nil && baseDate.Month() == chore.NextDueDate.Month() {
+		if chore.NextDueDate != nil && baseDate.Month() == chore.NextDueDate.In(loc).Month() {
 			startFrom = 1
 		}
 
@@ -171,13 +166,13 @@ func scheduleNextDueDate(ctx context.Context, chore *chModel.Chore, completedDat
 			}
 
 			// Ensure the target day exists in the month (e.g., Feb 30th is invalid)
-			lastDayOfMonth := time.Date(nextDueDate.Year(), time.Month(nextMonth+1), 0, 0, 0, 0, 0, time.UTC).Day()
+			lastDayOfMonth := time.Date(nextDueDate.Year(), time.Month(nextMonth+1), 0, 0, 0, 0, 0, loc).Day()
 			targetDay := chore.Frequency
 			if targetDay > lastDayOfMonth {
 				targetDay = lastDayOfMonth
 			}
 
-			nextDueDate = time.Date(nextDueDate.Year(), time.Month(nextMonth), targetDay, nextDueDate.Hour(), nextDueDate.Minute(), 0, 0, time.UTC)
+			nextDueDate = time.Date(nextDueDate.Year(), time.Month(nextMonth), targetDay, nextDueDate.Hour(), nextDueDate.Minute(), 0, 0, loc).UTC()
 
 			for _, month := range chore.FrequencyMetadataV2.Months {
 				if strings.EqualFold(*month, time.Month(nextMonth).String()) {
@@ -190,9 +185,51 @@ func scheduleNextDueDate(ctx context.Context, chore *chModel.Chore, completedDat
 		return nil, fmt.Errorf("invalid frequency type: %s", chore.FrequencyType)
 	}
 
+	baseDate = baseDate.UTC()
 	return &baseDate, nil
 }
 
+// schedulingLocation returns the timezone the chore's schedule is computed in, UTC when the chore
+// has none
+func schedulingLocation(ctx context.Context, chore *chModel.Chore) *time.Location {
+	if chore.FrequencyMetadataV2 == nil || chore.FrequencyMetadataV2.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(chore.FrequencyMetadataV2.Timezone)
+	if err != nil {
+		log := logging.FromContext(ctx)
+		log.Error("error loading timezone from frequency metadata", "error", err, "timezone", chore.FrequencyMetadataV2.Timezone, "chore_id", chore.ID)
+		return time.UTC
+	}
+	return loc
+}
+
+// applyScheduleTimezone validates the timezone a chore is scheduled in. A chore without one is
+// scheduled in its assignee's timezone, or the timezone of the user saving it.
+func applyScheduleTimezone(metadata *chModel.FrequencyMetadata, assignedTo *int, userTimezone string, circleUsers []*cModel.UserCircleDetail) error {
+	if metadata == nil {
+		return nil
+	}
+	if metadata.Timezone == "" {
+		metadata.Timezone = userTimezone
+		if assignedTo != nil {
+			for _, circleUser := range circleUsers {
+				if circleUser.UserID == *assignedTo && circleUser.Timezone != "" {
+					metadata.Timezone = circleUser.Timezone
+					break
+				}
+			}
+		}
+	}
+	if metadata.Timezone == "" {
+		return nil
+	}
+	if _, err := time.LoadLocation(metadata.Timezone); err != nil {
+		return fmt.Errorf("invalid timezone: %s", metadata.Timezone)
+	}
+	return nil
+}
+
 // getOccurrences returns the occurrences from metadata, supporting both new and legacy formats
 func getOccurrences(metadata *chModel.FrequencyMetadata) []string {
 	// Prefer new Occurrences field
@@ -251,14 +288,16 @@ func findNextDueDateForOccurrencePattern(baseDate time.Time, days []*string, occ
 				occurrence := getNthOccurrenceInMonth(currentDate, currentDate.Weekday())
 				if occurrenceMap[fmt.Sprintf("%d", occurrence)] ||
 					(occurrenceMap["last"] && isLastOccurrenceInMonth(currentDate, currentDate.Weekday())) {
-					return &currentDate, nil
+					nextDueDate := currentDate.UTC()
+					return &nextDueDate, nil
 				}
 			} else {
 				// Calculate occurrence within quarter
 				occurrence := getNthOccurrenceInQuarter(currentDate, currentDate.Weekday())
 				if occurrenceMap[fmt.Sprintf("%d", occurrence)] ||
 					(occurrenceMap["last"] && isLastOccurrenceInQuarter(currentDate, currentDate.Weekday())) {
-					return &currentDate, nil
+					nextDueDate := currentDate.UTC()
+					return &nextDueDate, nil
 				}
 			}
 		}
@@ -347,53 +386,130 @@ func isLastOccurrenceInQuarter(date time.Time, weekday time.Weekday) bool {
 
 	return false
 }
+
+const (
+	// AdaptiveHistoryLimit is how many past entries adaptive scheduling learns from
+	AdaptiveHistoryLimit = 10
+	defaultDecayFactor   = 0.5
+	// intervals further than this many scaled median absolute deviations from the median are outliers
+	outlierDeviations = 3.0
+	// deviations within a quarter of the median are never outliers, so a regular chore with one late
+	// completion still rejects it
+	minOutlierTolerance = 0.25
+	// minFallbackInterval keeps an on-time or early first completion from scheduling the chore at or
+	// before the completion when no minimum interval is set
+	minFallbackInterval = 24 * time.Hour
+)
+
 func scheduleAdaptiveNextDueDate(chore *chModel.Chore, completedDate time.Time, history []*chModel.ChoreHistory) (*time.Time, error) {
+	nextDueDate, _, err := explainAdaptiveNextDueDate(chore, completedDate, history)
+	return nextDueDate, err
+}
+
+// explainAdaptiveNextDueDate schedules an adaptive chore one learned interval after its completion.
+// The interval is an exponentially weighted average of the time between recent completions, newest
+// first. Skipped, rescheduled and missed entries don't count as completions, outlying intervals are
+// rejected, and the result is clamped to the chore's bounds.
+func explainAdaptiveNextDueDate(chore *chModel.Chore, completedDate time.Time, history []*chModel.ChoreHistory) (*time.Time, *chModel.AdaptiveExplanation, error) {
+	settings := &chModel.AdaptiveSettings{}
+	if chore.FrequencyMetadataV2 != nil && chore.FrequencyMetadataV2.Adaptive != nil {
+		settings = chore.FrequencyMetadataV2.Adaptive
+	}
+	explanation := &chModel.AdaptiveExplanation{DecayFactor: defaultDecayFactor}
+	if settings.DecayFactor != nil {
+		explanation.DecayFactor = *settings.DecayFactor
+	}
 
-	history = append([]*chModel.ChoreHistory{
-		{
-			PerformedAt: &completedDate,
-		},
-	}, history...)
-
-	if len(history) < 2 {
-		if chore.NextDueDate != nil {
-			diff := completedDate.UTC().Sub(chore.NextDueDate.UTC())
-			nextDueDate := completedDate.UTC().Add(diff)
-			return &nextDueDate, nil
+	completions := []time.Time{completedDate.UTC()}
+	for _, entry := range history {
+		switch {
+		case entry.Status == chModel.ChoreHistoryStatusSkipped || entry.Status == chModel.ChoreHistoryStatusRescheduled ||
+			entry.Status == chModel.ChoreHistoryStatusMissed || entry.Status == chModel.ChoreHistoryStatusRejected:
+			explanation.Ignored++
+		case entry.PerformedAt != nil:
+			completions = append(completions, entry.PerformedAt.UTC())
 		}
-		return nil, nil
 	}
 
-	var totalDelay float64
-	var totalWeight float64
-	decayFactor := 0.5 // Adjust this value to control the decay rate
+	intervals := make([]float64, 0, len(completions))
+	for i := 0; i < len(completions)-1; i++ {
+		intervals = append(intervals, completions[i].Sub(completions[i+1]).Seconds())
+	}
+	kept := rejectOutliers(intervals)
 
-	for i := 0; i < len(history)-1; i++ {
-		// Skip entries with nil PerformedAt
-		if history[i].PerformedAt == nil || history[i+1].PerformedAt == nil {
+	var totalDelay, totalWeight float64
+	for i, interval := range intervals {
+		if !kept[i] {
+			explanation.Outliers++
 			continue
 		}
-		delay := history[i].PerformedAt.UTC().Sub(history[i+1].PerformedAt.UTC()).Seconds()
-		weight := math.Pow(decayFactor, float64(i))
-		totalDelay += delay * weight
+		weight := math.Pow(explanation.DecayFactor, float64(i))
+		totalDelay += interval * weight
 		totalWeight += weight
+		explanation.Samples++
 	}
 
-	// If no valid history entries, fall back to default behavior
 	if totalWeight == 0 {
-		if chore.NextDueDate != nil {
-			diff := completedDate.UTC().Sub(chore.NextDueDate.UTC())
-			nextDueDate := completedDate.UTC().Add(diff)
-			return &nextDueDate, nil
+		// without history the chore keeps the delay between its due date and the completion
+		if chore.NextDueDate == nil {
+			return nil, explanation, nil
 		}
-		return nil, nil
+		explanation.Fallback = true
+		explanation.WeightedAverage = completedDate.UTC().Sub(chore.NextDueDate.UTC()).Seconds()
+	} else {
+		explanation.WeightedAverage = totalDelay / totalWeight
 	}
 
-	averageDelay := totalDelay / totalWeight
-	nextDueDate := completedDate.UTC().Add(time.Duration(averageDelay) * time.Second)
+	explanation.Interval = explanation.WeightedAverage
+	if settings.MinInterval != nil && explanation.Interval < float64(*settings.MinInterval) {
+		explanation.Interval = float64(*settings.MinInterval)
+		explanation.Clamp = "min"
+	} else if settings.MinInterval == nil && explanation.Fallback && explanation.Interval < minFallbackInterval.Seconds() {
+		explanation.Interval = minFallbackInterval.Seconds()
+		explanation.Clamp = "min"
+	}
+	if settings.MaxInterval != nil && explanation.Interval > float64(*settings.MaxInterval) {
+		explanation.Interval = float64(*settings.MaxInterval)
+		explanation.Clamp = "max"
+	}
 
-	return &nextDueDate, nil
+	nextDueDate := completedDate.UTC().Add(time.Duration(explanation.Interval) * time.Second)
+	return &nextDueDate, explanation, nil
 }
+
+// rejectOutliers marks the intervals within outlierDeviations scaled median absolute deviations of
+// the median. Fewer than three intervals have no meaningful median and are all kept.
+func rejectOutliers(intervals []float64) []bool {
+	kept := make([]bool, len(intervals))
+	for i := range kept {
+		kept[i] = true
+	}
+	if len(intervals) < 3 {
+		return kept
+	}
+	med := median(intervals)
+	deviations := make([]float64, len(intervals))
+	for i, interval := range intervals {
+		deviations[i] = math.Abs(interval - med)
+	}
+	// 1.4826 scales the MAD to the standard deviation of normally distributed intervals
+	tolerance := math.Max(outlierDeviations*1.4826*median(deviations), minOutlierTolerance*math.Abs(med))
+	for i, deviation := range deviations {
+		kept[i] = deviation <= tolerance
+	}
+	return kept
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
 func RemoveAssigneeAndReassign(chore *chModel.Chore, userID int) {
 	for i, assignee := range chore.Assignees {
 		if assignee.UserID == userID {
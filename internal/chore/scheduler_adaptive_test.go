@@ -128,3 +128,139 @@ func TestScheduleAdaptiveNextDueDate_NormalCase(t *testing.T) {
 	// (based on the pattern in history)
 	assert.InDelta(t, now.Add(24*time.Hour).Unix(), got.Unix(), 3600) // within 1 hour
 }
+
+func TestExplainAdaptiveNextDueDate(t *testing.T) {
+	day := 24 * time.Hour
+	now := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	// completions every week before now, oldest last
+	weekly := func(statuses ...chModel.ChoreHistoryStatus) []*chModel.ChoreHistory {
+		history := make([]*chModel.ChoreHistory, len(statuses))
+		for i, status := range statuses {
+			history[i] = &chModel.ChoreHistory{Status: status, PerformedAt: timePtr(now.Add(-time.Duration(i+1) * 7 * day))}
+		}
+		return history
+	}
+	completed := chModel.ChoreHistoryStatusCompleted
+	seconds := func(d time.Duration) *int { s := int(d.Seconds()); return &s }
+	decay := func(f float64) *float64 { return &f }
+
+	tests := []struct {
+		name          string
+		settings      *chModel.AdaptiveSettings
+		nextDueDate   *time.Time
+		completedDate time.Time
+		history       []*chModel.ChoreHistory
+		wantInterval  time.Duration
+		wantSamples   int
+		wantIgnored   int
+		wantOutliers  int
+		wantClamp     string
+	}{
+		{
+			name:          "regular weekly chore",
+			completedDate: now,
+			history:       weekly(completed, completed, completed),
+			wantInterval:  7 * day,
+			wantSamples:   3,
+		},
+		{
+			name:          "one forgotten week is rejected as an outlier",
+			completedDate: now.Add(7 * day),
+			history:       weekly(completed, completed, completed, completed),
+			wantInterval:  7 * day,
+			wantSamples:   3,
+			wantOutliers:  1,
+		},
+		{
+			name:          "skipped entries don't count as completions",
+			completedDate: now,
+			history: []*chModel.ChoreHistory{
+				{Status: chModel.ChoreHistoryStatusSkipped, PerformedAt: timePtr(now.Add(-2 * day))},
+				{Status: completed, PerformedAt: timePtr(now.Add(-7 * day))},
+				{Status: chModel.ChoreHistoryStatusRescheduled, PerformedAt: timePtr(now.Add(-10 * day))},
+				{Status: completed, PerformedAt: timePtr(now.Add(-14 * day))},
+			},
+			wantInterval: 7 * day,
+			wantSamples:  2,
+			wantIgnored:  2,
+		},
+		{
+			name:          "decay factor of 1 weighs every interval equally",
+			settings:      &chModel.AdaptiveSettings{DecayFactor: decay(1)},
+			completedDate: now,
+			history: []*chModel.ChoreHistory{
+				{Status: completed, PerformedAt: timePtr(now.Add(-4 * day))},
+				{Status: completed, PerformedAt: timePtr(now.Add(-10 * day))},
+			},
+			wantInterval: 5 * day,
+			wantSamples:  2,
+		},
+		{
+			name:          "clamped to the minimum",
+			settings:      &chModel.AdaptiveSettings{MinInterval: seconds(10 * day)},
+			completedDate: now,
+			history:       weekly(completed, completed),
+			wantInterval:  10 * day,
+			wantSamples:   2,
+			wantClamp:     "min",
+		},
+		{
+			name:          "clamped to the maximum",
+			settings:      &chModel.AdaptiveSettings{MaxInterval: seconds(5 * day)},
+			completedDate: now,
+			history:       weekly(completed, completed),
+			wantInterval:  5 * day,
+			wantSamples:   2,
+			wantClamp:     "max",
+		},
+		{
+			name:          "late first completion keeps its delay",
+			nextDueDate:   timePtr(now.Add(-3 * day)),
+			completedDate: now,
+			wantInterval:  3 * day,
+		},
+		{
+			name:          "early first completion falls back to a day",
+			nextDueDate:   timePtr(now.Add(2 * time.Hour)),
+			completedDate: now,
+			wantInterval:  day,
+			wantClamp:     "min",
+		},
+		{
+			name:          "early first completion uses the minimum when set",
+			settings:      &chModel.AdaptiveSettings{MinInterval: seconds(2 * time.Hour)},
+			nextDueDate:   timePtr(now),
+			completedDate: now,
+			wantInterval:  2 * time.Hour,
+			wantClamp:     "min",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chore := &chModel.Chore{
+				FrequencyType:       chModel.FrequencyTypeAdaptive,
+				FrequencyMetadataV2: &chModel.FrequencyMetadata{Adaptive: tt.settings},
+				NextDueDate:         tt.nextDueDate,
+			}
+			got, explanation, err := explainAdaptiveNextDueDate(chore, tt.completedDate, tt.history)
+			assert.NoError(t, err)
+			assert.NotNil(t, got)
+			assert.Equal(t, tt.completedDate.Add(tt.wantInterval), *got)
+			assert.Equal(t, tt.wantSamples, explanation.Samples)
+			assert.Equal(t, tt.wantIgnored, explanation.Ignored)
+			assert.Equal(t, tt.wantOutliers, explanation.Outliers)
+			assert.Equal(t, tt.wantClamp, explanation.Clamp)
+		})
+	}
+}
+
+func TestAdaptiveSettingsValidate(t *testing.T) {
+	value := func(i int) *int { return &i }
+	decay := func(f float64) *float64 { return &f }
+	assert.NoError(t, (*chModel.AdaptiveSettings)(nil).Validate())
+	assert.NoError(t, (&chModel.AdaptiveSettings{MinInterval: value(60), MaxInterval: value(120), DecayFactor: decay(0.8)}).Validate())
+	assert.Error(t, (&chModel.AdaptiveSettings{DecayFactor: decay(0)}).Validate())
+	assert.Error(t, (&chModel.AdaptiveSettings{DecayFactor: decay(1.5)}).Validate())
+	assert.Error(t, (&chModel.AdaptiveSettings{MinInterval: value(-1)}).Validate())
+	assert.Error(t, (&chModel.AdaptiveSettings{MinInterval: value(120), MaxInterval: value(60)}).Validate())
+}
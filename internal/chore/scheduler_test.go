@@ -7,6 +7,7 @@ import (
 	"time"
 
 	chModel "donetick.com/core/internal/chore/model"
+	cModel "donetick.com/core/internal/circle/model"
 )
 
 type scheduleTest struct {
@@ -654,3 +655,369 @@ func TestScheduleNextDueDateWeekPatterns(t *testing.T) {
 		})
 	}
 }
+
+func TestScheduleNextDueDateRRule(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("error loading location: %v", err)
+	}
+	rruleChore := func(recurrence string, nextDueDate time.Time) chModel.Chore {
+		return chModel.Chore{
+			FrequencyType: chModel.FrequencyTypeRRule,
+			NextDueDate:   timePtr(nextDueDate),
+			FrequencyMetadataV2: &chModel.FrequencyMetadata{
+				Timezone: "America/New_York",
+				RRule:    recurrence,
+			},
+		}
+	}
+
+	tests := []scheduleTest{
+		{
+			name: "Last weekday of the month",
+			chore: rruleChore("DTSTART:20250131T090000\nRRULE:FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+				time.Date(2025, 2, 28, 9, 0, 0, 0, newYork)),
+			completedDate: time.Date(2025, 2, 28, 10, 0, 0, 0, newYork),
+			// March 31st is a Monday, after the switch to daylight saving time
+			want: timePtr(time.Date(2025, 3, 31, 13, 0, 0, 0, time.UTC)),
+		},
+		{
+			name: "Last day of the month",
+			chore: rruleChore("DTSTART:20250131T090000\nRRULE:FREQ=MONTHLY;BYMONTHDAY=-1",
+				time.Date(2025, 1, 31, 9, 0, 0, 0, newYork)),
+			completedDate: time.Date(2025, 1, 31, 10, 0, 0, 0, newYork),
+			want:          timePtr(time.Date(2025, 2, 28, 14, 0, 0, 0, time.UTC)),
+		},
+		{
+			name: "2nd and 4th Thursday except December",
+			chore: rruleChore("DTSTART:20250109T190000\nRRULE:FREQ=MONTHLY;BYMONTH=1,2,3,4,5,6,7,8,9,10,11;BYDAY=2TH,4TH",
+				time.Date(2025, 11, 27, 19, 0, 0, 0, newYork)),
+			completedDate: time.Date(2025, 11, 27, 20, 0, 0, 0, newYork),
+			want:          timePtr(time.Date(2026, 1, 9, 0, 0, 0, 0, time.UTC)),
+		},
+		{
+			name: "Excluded date is skipped",
+			chore: rruleChore("DTSTART:20250101T080000\nRRULE:FREQ=DAILY\nEXDATE:20250103T080000",
+				time.Date(2025, 1, 2, 8, 0, 0, 0, newYork)),
+			completedDate: time.Date(2025, 1, 2, 9, 0, 0, 0, newYork),
+			want:          timePtr(time.Date(2025, 1, 4, 13, 0, 0, 0, time.UTC)),
+		},
+		{
+			name: "COUNT exhausted",
+			chore: rruleChore("DTSTART:20250101T080000\nRRULE:FREQ=DAILY;COUNT=2",
+				time.Date(2025, 1, 2, 8, 0, 0, 0, newYork)),
+			completedDate: time.Date(2025, 1, 2, 9, 0, 0, 0, newYork),
+			want:          nil,
+		},
+		{
+			name: "UNTIL exhausted",
+			chore: rruleChore("DTSTART:20250101T080000\nRRULE:FREQ=WEEKLY;UNTIL=20250110T000000Z",
+				time.Date(2025, 1, 8, 8, 0, 0, 0, newYork)),
+			completedDate: time.Date(2025, 1, 8, 9, 0, 0, 0, newYork),
+			want:          nil,
+		},
+		{
+			name: "Rolling continues after the completion",
+			chore: func() chModel.Chore {
+				chore := rruleChore("DTSTART:20250106T090000\nRRULE:FREQ=WEEKLY;BYDAY=MO",
+					time.Date(2025, 1, 6, 9, 0, 0, 0, newYork))
+				chore.IsRolling = true
+				return chore
+			}(),
+			completedDate: time.Date(2025, 1, 22, 9, 0, 0, 0, newYork),
+			want:          timePtr(time.Date(2025, 1, 27, 14, 0, 0, 0, time.UTC)),
+		},
+		{
+			name:          "Missing rule",
+			chore:         rruleChore("", time.Date(2025, 1, 2, 8, 0, 0, 0, newYork)),
+			completedDate: time.Date(2025, 1, 2, 9, 0, 0, 0, newYork),
+			wantErr:       true,
+			wantErrMsg:    "rrule frequency requires a recurrence rule",
+		},
+	}
+	executeTestTable(t, tests)
+}
+
+func TestFrequencyToRRule(t *testing.T) {
+	start := time.Date(2025, 1, 31, 14, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		chore   chModel.Chore
+		want    []time.Time
+		wantErr bool
+	}{
+		{
+			name: "Monthly on the 31st falls back to the last day",
+			chore: chModel.Chore{
+				FrequencyType:       chModel.FrequencyTypeMonthly,
+				NextDueDate:         timePtr(start),
+				FrequencyMetadataV2: &chModel.FrequencyMetadata{Timezone: "America/New_York"},
+			},
+			want: []time.Time{
+				time.Date(2025, 2, 28, 14, 0, 0, 0, time.UTC),
+				time.Date(2025, 3, 31, 13, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "Every 2 weeks",
+			chore: chModel.Chore{
+				FrequencyType:       chModel.FrequencyTypeInterval,
+				Frequency:           2,
+				NextDueDate:         timePtr(start),
+				FrequencyMetadataV2: &chModel.FrequencyMetadata{Unit: jsonPtr("weeks")},
+			},
+			want: []time.Time{
+				time.Date(2025, 2, 14, 14, 0, 0, 0, time.UTC),
+				time.Date(2025, 2, 28, 14, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "Second and last Thursday of the month",
+			chore: chModel.Chore{
+				FrequencyType: chModel.FrequencyTypeDayOfTheWeek,
+				NextDueDate:   timePtr(start),
+				FrequencyMetadataV2: &chModel.FrequencyMetadata{
+					Days:        []*string{jsonPtr("thursday")},
+					WeekPattern: func() *chModel.Weekpattern { p := chModel.WeekPatternWeekOfMonth; return &p }(),
+					Occurrences: []*int{intPtr(2), intPtr(-1)},
+				},
+			},
+			want: []time.Time{
+				time.Date(2025, 2, 13, 14, 0, 0, 0, time.UTC),
+				time.Date(2025, 2, 27, 14, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "Day of the month in selected months",
+			chore: chModel.Chore{
+				FrequencyType: chModel.FrequencyTypeDayOfTheMonth,
+				Frequency:     30,
+				NextDueDate:   timePtr(start),
+				FrequencyMetadataV2: &chModel.FrequencyMetadata{
+					Months: []*string{jsonPtr("february"), jsonPtr("april")},
+				},
+			},
+			want: []time.Time{
+				time.Date(2025, 2, 28, 14, 0, 0, 0, time.UTC),
+				time.Date(2025, 4, 30, 14, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "Adaptive has no equivalent",
+			chore: chModel.Chore{
+				FrequencyType: chModel.FrequencyTypeAdaptive,
+				NextDueDate:   timePtr(start),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recurrence, err := FrequencyToRRule(&tt.chore)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FrequencyToRRule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			metadata := &chModel.FrequencyMetadata{RRule: recurrence}
+			if tt.chore.FrequencyMetadataV2 != nil {
+				metadata.Timezone = tt.chore.FrequencyMetadataV2.Timezone
+			}
+			after := start
+			for _, want := range tt.want {
+				got, err := nextRRuleOccurrence(metadata, after)
+				if err != nil {
+					t.Fatalf("converted recurrence %q does not parse: %v", recurrence, err)
+				}
+				if got == nil || !got.Equal(want) {
+					t.Fatalf("recurrence %q: got %v after %v, want %v", recurrence, got, after, want)
+				}
+				after = *got
+			}
+		})
+	}
+}
+
+func TestScheduleNextDueDateAcrossDST(t *testing.T) {
+	load := func(name string) *time.Location {
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			t.Fatalf("error loading location: %v", err)
+		}
+		return loc
+	}
+	newYork, berlin, sydney, kolkata := load("America/New_York"), load("Europe/Berlin"), load("Australia/Sydney"), load("Asia/Kolkata")
+	weekOfMonth := chModel.WeekPatternWeekOfMonth
+
+	tests := []scheduleTest{
+		{
+			name: "Daily at 7pm in New York across spring forward",
+			chore: chModel.Chore{
+				FrequencyType:       chModel.FrequencyTypeDaily,
+				NextDueDate:         timePtr(time.Date(2025, 3, 8, 19, 0, 0, 0, newYork)),
+				FrequencyMetadataV2: &chModel.FrequencyMetadata{Timezone: "America/New_York"},
+			},
+			completedDate: time.Date(2025, 3, 8, 20, 0, 0, 0, newYork),
+			want:          timePtr(time.Date(2025, 3, 9, 23, 0, 0, 0, time.UTC)),
+		},
+		{
+			name: "Weekly at 7pm in New York across fall back",
+			chore: chModel.Chore{
+				FrequencyType:       chModel.FrequencyTypeWeekly,
+				NextDueDate:         timePtr(time.Date(2025, 10, 29, 19, 0, 0, 0, newYork)),
+				FrequencyMetadataV2: &chModel.FrequencyMetadata{Timezone: "America/New_York"},
+			},
+			completedDate: time.Date(2025, 10, 29, 20, 0, 0, 0, newYork),
+			want:          timePtr(time.Date(2025, 11, 6, 0, 0, 0, 0, time.UTC)),
+		},
+		{
+			name: "Monthly at 8am in Berlin across spring forward",
+			chore: chModel.Chore{
+				FrequencyType:       chModel.FrequencyTypeMonthly,
+				NextDueDate:         timePtr(time.Date(2025, 3, 15, 8, 0, 0, 0, berlin)),
+				FrequencyMetadataV2: &chModel.FrequencyMetadata{Timezone: "Europe/Berlin"},
+			},
+			completedDate: time.Date(2025, 3, 15, 9, 0, 0, 0, berlin),
+			want:          timePtr(time.Date(2025, 4, 15, 6, 0, 0, 0, time.UTC)),
+		},
+		{
+			name: "Every 3 days at 7pm in Berlin across fall back",
+			chore: chModel.Chore{
+				FrequencyType: chModel.FrequencyTypeInterval,
+				Frequency:     3,
+				NextDueDate:   timePtr(time.Date(2025, 10, 24, 19, 0, 0, 0, berlin)),
+				FrequencyMetadataV2: &chModel.FrequencyMetadata{
+					Unit:     jsonPtr("days"),
+					Time:     "2025-10-20T19:00:00+02:00",
+					Timezone: "Europe/Berlin",
+				},
+			},
+			completedDate: time.Date(2025, 10, 24, 20, 0, 0, 0, berlin),
+			want:          timePtr(time.Date(2025, 10, 27, 18, 0, 0, 0, time.UTC)),
+		},
+		{
+			name: "Monthly interval at 7am in Sydney as DST ends",
+			chore: chModel.Chore{
+				FrequencyType: chModel.FrequencyTypeInterval,
+				Frequency:     1,
+				NextDueDate:   timePtr(time.Date(2025, 3, 20, 7, 0, 0, 0, sydney)),
+				FrequencyMetadataV2: &chModel.FrequencyMetadata{
+					Unit:     jsonPtr("months"),
+					Time:     "2025-01-20T07:00:00+11:00",
+					Timezone: "Australia/Sydney",
+				},
+			},
+			completedDate: time.Date(2025, 3, 20, 8, 0, 0, 0, sydney),
+			want:          timePtr(time.Date(2025, 4, 19, 21, 0, 0, 0, time.UTC)),
+		},
+		{
+			name: "Hourly interval counts elapsed hours across spring forward",
+			chore: chModel.Chore{
+				FrequencyType: chModel.FrequencyTypeInterval,
+				Frequency:     12,
+				NextDueDate:   timePtr(time.Date(2025, 3, 9, 0, 0, 0, 0, newYork)),
+				FrequencyMetadataV2: &chModel.FrequencyMetadata{
+					Unit:     jsonPtr("hours"),
+					Time:     "2025-03-09T00:00:00-05:00",
+					Timezone: "America/New_York",
+				},
+			},
+			completedDate: time.Date(2025, 3, 9, 1, 0, 0, 0, newYork),
+			want:          timePtr(time.Date(2025, 3, 9, 17, 0, 0, 0, time.UTC)),
+		},
+		{
+			name: "Friday evening in New York is Saturday in UTC",
+			chore: chModel.Chore{
+				FrequencyType: chModel.FrequencyTypeDayOfTheWeek,
+				NextDueDate:   timePtr(time.Date(2025, 3, 7, 19, 0, 0, 0, newYork)),
+				FrequencyMetadataV2: &chModel.FrequencyMetadata{
+					Days:     []*string{jsonPtr("friday")},
+					Time:     "2025-01-03T19:00:00-05:00",
+					Timezone: "America/New_York",
+				},
+			},
+			completedDate: time.Date(2025, 3, 7, 20, 0, 0, 0, newYork),
+			want:          timePtr(time.Date(2025, 3, 14, 23, 0, 0, 0, time.UTC)),
+		},
+		{
+			name: "First Monday evening in New York across spring forward",
+			chore: chModel.Chore{
+				FrequencyType: chModel.FrequencyTypeDayOfTheWeek,
+				NextDueDate:   timePtr(time.Date(2025, 3, 3, 21, 0, 0, 0, newYork)),
+				FrequencyMetadataV2: &chModel.FrequencyMetadata{
+					Days:        []*string{jsonPtr("monday")},
+					Time:        "2025-01-06T21:00:00-05:00",
+					Timezone:    "America/New_York",
+					WeekPattern: &weekOfMonth,
+					Occurrences: []*int{intPtr(1)},
+				},
+			},
+			completedDate: time.Date(2025, 3, 3, 22, 0, 0, 0, newYork),
+			want:          timePtr(time.Date(2025, 4, 8, 1, 0, 0, 0, time.UTC)),
+		},
+		{
+			name: "First of the month just after midnight in Kolkata",
+			chore: chModel.Chore{
+				FrequencyType: chModel.FrequencyTypeDayOfTheMonth,
+				Frequency:     1,
+				NextDueDate:   timePtr(time.Date(2025, 1, 1, 0, 30, 0, 0, kolkata)),
+				FrequencyMetadataV2: &chModel.FrequencyMetadata{
+					Months:   []*string{jsonPtr("january"), jsonPtr("february")},
+					Time:     "2025-01-01T00:30:00+05:30",
+					Timezone: "Asia/Kolkata",
+				},
+			},
+			completedDate: time.Date(2025, 1, 1, 1, 0, 0, 0, kolkata),
+			want:          timePtr(time.Date(2025, 1, 31, 19, 0, 0, 0, time.UTC)),
+		},
+		{
+			name: "Last day of February in Sydney",
+			chore: chModel.Chore{
+				FrequencyType: chModel.FrequencyTypeDayOfTheMonth,
+				Frequency:     31,
+				NextDueDate:   timePtr(time.Date(2025, 1, 31, 7, 0, 0, 0, sydney)),
+				FrequencyMetadataV2: &chModel.FrequencyMetadata{
+					Months:   []*string{jsonPtr("january"), jsonPtr("february")},
+					Time:     "2025-01-31T07:00:00+11:00",
+					Timezone: "Australia/Sydney",
+				},
+			},
+			completedDate: time.Date(2025, 1, 31, 8, 0, 0, 0, sydney),
+			want:          timePtr(time.Date(2025, 2, 27, 20, 0, 0, 0, time.UTC)),
+		},
+	}
+	executeTestTable(t, tests)
+}
+
+func TestApplyScheduleTimezone(t *testing.T) {
+	assignee := 2
+	circleUsers := []*cModel.UserCircleDetail{
+		{UserCircle: cModel.UserCircle{UserID: 1}, Timezone: "Europe/Berlin"},
+		{UserCircle: cModel.UserCircle{UserID: 2}, Timezone: "America/New_York"},
+	}
+	tests := []struct {
+		name       string
+		timezone   string
+		assignedTo *int
+		want       string
+		wantErr    bool
+	}{
+		{name: "chore timezone is kept", timezone: "Asia/Tokyo", assignedTo: &assignee, want: "Asia/Tokyo"},
+		{name: "assignee timezone", assignedTo: &assignee, want: "America/New_York"},
+		{name: "saving user timezone without assignee", want: "Europe/Berlin"},
+		{name: "invalid timezone", timezone: "Mars/Olympus", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata := &chModel.FrequencyMetadata{Timezone: tt.timezone}
+			err := applyScheduleTimezone(metadata, tt.assignedTo, "Europe/Berlin", circleUsers)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyScheduleTimezone() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && metadata.Timezone != tt.want {
+				t.Errorf("timezone = %q, want %q", metadata.Timezone, tt.want)
+			}
+		})
+	}
+}
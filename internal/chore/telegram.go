@@ -0,0 +1,136 @@
+package chore
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	chModel "donetick.com/core/internal/chore/model"
+	"donetick.com/core/internal/notifier/service/telegram"
+	uModel "donetick.com/core/internal/user/model"
+	"gorm.io/gorm"
+)
+
+// TelegramActions lets the Telegram bot act on chores as the user who linked the Telegram account
+type TelegramActions struct {
+	h *Handler
+}
+
+func NewTelegramActions(h *Handler) telegram.ChoreActions {
+	return &TelegramActions{h: h}
+}
+
+func (a *TelegramActions) UserForTelegramAccount(c context.Context, telegramUserID int64) (*uModel.UserDetails, error) {
+	userIDs, err := a.h.uRepo.GetUserIDsByTelegramUser(c, telegramUserID)
+	if err != nil {
+		return nil, err
+	}
+	switch len(userIDs) {
+	case 0:
+		return nil, telegram.ErrNotLinked
+	case 1:
+	default:
+		return nil, telegram.ErrLinkedToSeveralUsers
+	}
+	user, err := a.h.uRepo.GetUserByID(c, userIDs[0])
+	if err != nil {
+		return nil, err
+	}
+	if user.Disabled {
+		return nil, errors.New("user is disabled")
+	}
+	return a.h.uRepo.GetUserByUsername(c, user.Username)
+}
+
+func (a *TelegramActions) LinkAccount(c context.Context, code string, telegramUserID int64) (string, error) {
+	userID, err := a.h.uRepo.RedeemTelegramLinkCode(c, code, telegramUserID, time.Now().UTC())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "This link has expired or was already used. Create a new one in your Donetick settings.", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	user, err := a.h.uRepo.GetUserByID(c, userID)
+	if err != nil {
+		return "", err
+	}
+	name := user.DisplayName
+	if name == "" {
+		name = user.Username
+	}
+	return "This Telegram account is now linked to " + name + " on Donetick.", nil
+}
+
+func (a *TelegramActions) CompleteChore(c context.Context, user *uModel.UserDetails, choreID int, dueDate int64) (string, error) {
+	chore, _, refusal := a.h.choreForAction(c, choreID, dueDate, &user.User)
+	if chore == nil {
+		return refusal, nil
+	}
+	return a.h.completeChoreAs(c, chore, user)
+}
+
+func (a *TelegramActions) SkipChore(c context.Context, user *uModel.UserDetails, choreID int, dueDate int64) (string, error) {
+	chore, _, refusal := a.h.choreForAction(c, choreID, dueDate, &user.User)
+	if chore == nil {
+		return refusal, nil
+	}
+	if _, err := a.h.skipChoreFor(c, chore, user); err != nil {
+		return "", err
+	}
+	return "Skipped " + chore.Name + ".", nil
+}
+
+func (a *TelegramActions) SnoozeChore(c context.Context, user *uModel.UserDetails, choreID int, dueDate int64) (string, error) {
+	chore, _, refusal := a.h.choreForAction(c, choreID, dueDate, &user.User)
+	if chore == nil {
+		return refusal, nil
+	}
+	snoozedUntil, err := a.h.snoozeChore(c, chore, &user.User)
+	if err != nil {
+		return "", err
+	}
+	return "Snoozed " + chore.Name + " until " + snoozedUntil.In(userLocation(&user.User)).Format("15:04") + ".", nil
+}
+
+func (a *TelegramActions) ChoresDueToday(c context.Context, user *uModel.UserDetails) ([]*chModel.Chore, error) {
+	chores, err := a.h.choreRepo.GetChores(c, user.CircleID, user.ID, false)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC().In(userLocation(&user.User))
+	endOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, 1)
+	due := make([]*chModel.Chore, 0)
+	for _, chore := range chores {
+		if chore.NextDueDate != nil && chore.NextDueDate.Before(endOfDay) {
+			due = append(due, chore)
+		}
+	}
+	sortByDueDate(due)
+	return due, nil
+}
+
+func (a *TelegramActions) AssignedChores(c context.Context, user *uModel.UserDetails) ([]*chModel.Chore, error) {
+	chores, err := a.h.choreRepo.GetChores(c, user.CircleID, user.ID, false)
+	if err != nil {
+		return nil, err
+	}
+	assigned := make([]*chModel.Chore, 0)
+	for _, chore := range chores {
+		if chore.AssignedTo != nil && *chore.AssignedTo == user.ID {
+			assigned = append(assigned, chore)
+		}
+	}
+	sortByDueDate(assigned)
+	return assigned, nil
+}
+
+// sortByDueDate orders chores by due date, chores without one last
+func sortByDueDate(chores []*chModel.Chore) {
+	sort.SliceStable(chores, func(i, j int) bool {
+		if chores[i].NextDueDate == nil || chores[j].NextDueDate == nil {
+			return chores[j].NextDueDate == nil && chores[i].NextDueDate != nil
+		}
+		return chores[i].NextDueDate.Before(*chores[j].NextDueDate)
+	})
+}
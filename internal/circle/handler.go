@@ -13,6 +13,7 @@ import (
 	cModel "donetick.com/core/internal/circle/model"
 	cRepo "donetick.com/core/internal/circle/repo"
 	pRepo "donetick.com/core/internal/points/repo"
+	"donetick.com/core/internal/realtime"
 	uModel "donetick.com/core/internal/user/model"
 	uRepo "donetick.com/core/internal/user/repo"
 	"donetick.com/core/logging"
@@ -27,10 +28,11 @@ type Handler struct {
 	isDonetickDotCom     bool
 	maxCircleMembers     int
 	plusMaxCircleMembers int
+	realTimeService      *realtime.RealTimeService
 }
 
 func NewHandler(cr *cRepo.CircleRepository, ur *uRepo.UserRepository, c *chRepo.ChoreRepository, pr *pRepo.PointsRepository,
-	config *config.Config) *Handler {
+	config *config.Config, rts *realtime.RealTimeService) *Handler {
 	return &Handler{
 		circleRepo:           cr,
 		userRepo:             ur,
@@ -39,6 +41,7 @@ func NewHandler(cr *cRepo.CircleRepository, ur *uRepo.UserRepository, c *chRepo.
 		isDonetickDotCom:     config.IsDoneTickDotCom,
 		maxCircleMembers:     config.FeatureLimits.MaxCircleMembers,
 		plusMaxCircleMembers: config.FeatureLimits.PlusCircleMaxMembers,
+		realTimeService:      rts,
 	}
 }
 
@@ -143,6 +146,8 @@ func (h *Handler) JoinCircle(c *gin.Context) {
 		return
 	}
 
+	h.broadcastMemberEvent(realtime.EventTypeCircleJoinRequested, circle.ID, currentUser.ID, string(cModel.UserRoleMember), &currentUser.User)
+
 	c.JSON(200, gin.H{
 		"res": "User Requested to join circle successfully",
 	})
@@ -219,6 +224,7 @@ func (h *Handler) LeaveCircle(c *gin.Context) {
 		})
 		return
 	}
+	h.broadcastMemberEvent(realtime.EventTypeCircleMemberLeft, circleID, currentUser.ID, "", &currentUser.User)
 	c.JSON(200, gin.H{
 		"res": "User left circle successfully",
 	})
@@ -329,6 +335,7 @@ func (h *Handler) DeleteCircleMember(c *gin.Context) {
 		})
 		return
 	}
+	h.broadcastMemberEvent(realtime.EventTypeCircleMemberRemoved, circleID, memberIDToDeleted, "", &currentUser.User)
 	c.JSON(200, gin.H{
 		"res": "User deleted from circle successfully",
 	})
@@ -550,6 +557,7 @@ func (h *Handler) AcceptJoinRequest(c *gin.Context) {
 		})
 		return
 	}
+	h.broadcastMemberEvent(realtime.EventTypeCircleMemberJoined, currentUser.CircleID, requestedCircle.UserID, string(requestedCircle.Role), &currentUser.User)
 
 	c.JSON(200, gin.H{
 		"res": "Join request accepted successfully",
@@ -674,6 +682,9 @@ func (h *Handler) RedeemPoints(c *gin.Context) {
 		})
 		return
 	}
+	if h.realTimeService != nil {
+		h.realTimeService.GetEventBroadcaster().BroadcastPointsRedeemed(currentUser.CircleID, redeemReq.UserID, redeemReq.Points, &currentUser.User)
+	}
 
 	c.JSON(200, gin.H{
 		"res": "Points redeemed successfully",
@@ -769,6 +780,7 @@ func (h *Handler) ChangeMemberRole(c *gin.Context) {
 		})
 		return
 	}
+	h.broadcastMemberEvent(realtime.EventTypeCircleMemberRole, currentUser.CircleID, req.MemberID, string(req.Role), &currentUser.User)
 
 	c.JSON(200, gin.H{
 		"res": "Member role changed successfully",
@@ -776,6 +788,13 @@ func (h *Handler) ChangeMemberRole(c *gin.Context) {
 
 }
 
+func (h *Handler) broadcastMemberEvent(eventType realtime.EventType, circleID, memberUserID int, role string, user *uModel.User) {
+	if h.realTimeService == nil {
+		return
+	}
+	h.realTimeService.GetEventBroadcaster().BroadcastCircleMemberEvent(eventType, circleID, memberUserID, role, user)
+}
+
 func Routes(router *gin.Engine, h *Handler, multiAuthMiddleware *auth.MultiAuthMiddleware) {
 	log.Println("Registering circle routes")
 
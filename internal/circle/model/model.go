@@ -15,6 +15,7 @@ type Circle struct {
 	InviteCode         string     `json:"invite_code" gorm:"column:invite_code"` // Invite code
 	Disabled           bool       `json:"disabled" gorm:"column:disabled"`       // Disabled
 	WebhookURL         *string    `json:"webhook_url" gorm:"column:webhook_url"` // Webhook URL
+	WebhookSecret      *string    `json:"-" gorm:"column:webhook_secret"`        // Secret used to sign webhook deliveries
 	SubscriptionStatus *string    `gorm:"column:status;<-:false"`                // read one column
 	ExpiredAt          *time.Time `gorm:"column:expired_at;<-:false"`            // read one column
 }
@@ -50,7 +51,10 @@ type UserCircleDetail struct {
 	DisplayName      string                      `json:"displayName" gorm:"column:display_name"`
 	NotificationType nModel.NotificationPlatform `json:"-" gorm:"column:notification_type"`
 	TargetID         string                      `json:"-" gorm:"column:target_id"` // Target ID
-	Image            string                      `json:"image" gorm:"column:image"` // Image
+	// ID of the member's primary notification target
+	NotificationTargetID int    `json:"-" gorm:"column:notification_target_id"`
+	Image                string `json:"image" gorm:"column:image"` // Image
+	Timezone             string `json:"-" gorm:"column:timezone"`
 }
 
 type Role string
@@ -53,9 +53,9 @@ func (r *CircleRepository) GetCircleUsers(c context.Context, circleID int) ([]*c
 	var circleUsers []*cModel.UserCircleDetail
 	if err := r.db.WithContext(c).
 		Table("user_circles uc").
-		Select("uc.*, u.username, u.display_name, u.chat_id, u.image, unt.user_id as user_id, unt.target_id as target_id, unt.type as notification_type").
+		Select("uc.*, u.username, u.display_name, u.chat_id, u.image, u.timezone, unt.user_id as user_id, unt.id as notification_target_id, unt.target_id as target_id, unt.type as notification_type").
 		Joins("left join users u on u.id = uc.user_id").
-		Joins("left join user_notification_targets unt on unt.user_id = u.id").
+		Joins("left join notification_targets unt on unt.user_id = u.id and unt.is_primary = ?", true).
 		Where("uc.circle_id = ?", circleID).
 		Scan(&circleUsers).Error; err != nil {
 		return nil, err
@@ -180,3 +180,7 @@ func (r *CircleRepository) RedeemPoints(c context.Context, circleID int, userID
 func (r *CircleRepository) SetWebhookURL(c context.Context, circleID int, webhookURL *string) error {
 	return r.db.WithContext(c).Model(&cModel.Circle{}).Where("id = ?", circleID).Update("webhook_url", webhookURL).Error
 }
+
+func (r *CircleRepository) SetWebhookSecret(c context.Context, circleID int, secret string) error {
+	return r.db.WithContext(c).Model(&cModel.Circle{}).Where("id = ?", circleID).Update("webhook_secret", secret).Error
+}
@@ -35,6 +35,11 @@ func convertLogLevelToGorm(appLogLevel string) logger.LogLevel {
 	}
 }
 
+// PostgresDSN returns the connection string for the configured Postgres database
+func PostgresDSN(cfg *config.Config) string {
+	return fmt.Sprintf("host=%s port=%v user=%s password=%s dbname=%s sslmode=disable TimeZone=Asia/Shanghai", cfg.Database.Host, cfg.Database.Port, cfg.Database.User, cfg.Database.Password, cfg.Database.Name)
+}
+
 func NewDatabase(cfg *config.Config) (*gorm.DB, error) {
 	var db *gorm.DB
 	var err error
@@ -58,7 +63,7 @@ func NewDatabase(cfg *config.Config) (*gorm.DB, error) {
 
 	switch cfg.Database.Type {
 	case "postgres":
-		dsn := fmt.Sprintf("host=%s port=%v user=%s password=%s dbname=%s sslmode=disable TimeZone=Asia/Shanghai", cfg.Database.Host, cfg.Database.Port, cfg.Database.User, cfg.Database.Password, cfg.Database.Name)
+		dsn := PostgresDSN(cfg)
 		for i := 0; i <= 30; i++ {
 			db, err = gorm.Open(postgres.Open(dsn), &gormConfig)
 			if err == nil {
@@ -10,13 +10,16 @@ import (
 
 	"donetick.com/core/config"
 	sModel "donetick.com/core/external/payment/model"
+	calModel "donetick.com/core/internal/calendar/model"
 	chModel "donetick.com/core/internal/chore/model"
 	cModel "donetick.com/core/internal/circle/model"
+	eModel "donetick.com/core/internal/events/model"
 	filterModel "donetick.com/core/internal/filter/model"
 	lModel "donetick.com/core/internal/label/model"
 	nModel "donetick.com/core/internal/notifier/model"
 	pModel "donetick.com/core/internal/points"
 	projModel "donetick.com/core/internal/project/model"
+	rtModel "donetick.com/core/internal/realtime/model"
 	storageModel "donetick.com/core/internal/storage/model"
 	stModel "donetick.com/core/internal/subtask/model"
 	tModel "donetick.com/core/internal/thing/model"
@@ -33,7 +36,12 @@ func Migration(db *gorm.DB) error {
 		cModel.Circle{},
 		cModel.UserCircle{},
 		chModel.ChoreAssignees{},
+		chModel.ChoreDependency{},
 		nModel.Notification{},
+		nModel.NotificationSettings{},
+		nModel.NotificationRule{},
+		nModel.DeliveryFailure{},
+		nModel.MessageTemplate{},
 		uModel.UserPasswordReset{},
 		sModel.StripeCustomer{},
 		sModel.StripeSubscription{},
@@ -48,18 +56,26 @@ func Migration(db *gorm.DB) error {
 		tModel.ThingChore{},
 		tModel.ThingHistory{},
 		uModel.APIToken{},
-		uModel.UserNotificationTarget{},
+		uModel.NotificationTarget{},
+		uModel.TelegramLink{},
 		lModel.Label{},
 		chModel.ChoreLabels{},
 		projModel.Project{},
 		filterModel.Filter{},
 		migrations.Migration{},
 		pModel.PointsHistory{},
+		pModel.Reward{},
+		pModel.RewardRedemption{},
+		rtModel.BrokerMessage{},
+		rtModel.ReplayEvent{},
 		stModel.SubTask{},
 		storageModel.StorageFile{},
 		storageModel.StorageUsage{},
+		eModel.WebhookDelivery{},
+		calModel.CalendarFeed{},
 		chModel.TimeSession{},
 		uModel.UserDeviceToken{},
+		uModel.WebPushSubscription{},
 	); err != nil {
 		return err
 	}
@@ -7,6 +7,7 @@ import (
 	auth "donetick.com/core/internal/auth"
 	dRepo "donetick.com/core/internal/device/repo"
 	errorx "donetick.com/core/internal/error"
+	"donetick.com/core/internal/notifier/service/webpush"
 	uModel "donetick.com/core/internal/user/model"
 	"donetick.com/core/logging"
 	jwt "github.com/appleboy/gin-jwt/v2"
@@ -16,6 +17,7 @@ import (
 
 type Handler struct {
 	deviceRepo *dRepo.DeviceRepository
+	webPush    *webpush.WebPushNotifier
 }
 
 type RegisterDeviceTokenRequest struct {
@@ -31,9 +33,22 @@ type UnregisterDeviceTokenRequest struct {
 	Token    string `json:"token,omitempty"`
 }
 
-func NewHandler(dr *dRepo.DeviceRepository) *Handler {
+type WebPushSubscriptionRequest struct {
+	Endpoint string `json:"endpoint" binding:"required"`
+	Keys     struct {
+		P256dh string `json:"p256dh" binding:"required"`
+		Auth   string `json:"auth" binding:"required"`
+	} `json:"keys" binding:"required"`
+}
+
+type UnsubscribeWebPushRequest struct {
+	Endpoint string `json:"endpoint" binding:"required"`
+}
+
+func NewHandler(dr *dRepo.DeviceRepository, wp *webpush.WebPushNotifier) *Handler {
 	return &Handler{
 		deviceRepo: dr,
+		webPush:    wp,
 	}
 }
 
@@ -232,6 +247,101 @@ func (h *Handler) CleanupInactiveTokens(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Cleanup completed successfully"})
 }
 
+// GetWebPushKey returns the VAPID public key the web app subscribes browsers with
+func (h *Handler) GetWebPushKey(c *gin.Context) {
+	publicKey := h.webPush.PublicKey()
+	if publicKey == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Web push is not configured on this server"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"res": gin.H{"publicKey": publicKey}})
+}
+
+// SubscribeWebPush stores the browser's push subscription, the body is its PushSubscription.toJSON()
+func (h *Handler) SubscribeWebPush(c *gin.Context) {
+	log := logging.FromContext(c)
+	currentUser, ok := auth.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+	if h.webPush == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Web push is not configured on this server"})
+		return
+	}
+
+	var req WebPushSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+	if err := webpush.ValidateSubscription(req.Endpoint, req.Keys.P256dh, req.Keys.Auth); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	subscription := &uModel.WebPushSubscription{
+		UserID:    currentUser.ID,
+		Endpoint:  req.Endpoint,
+		P256dh:    req.Keys.P256dh,
+		Auth:      req.Keys.Auth,
+		UserAgent: truncate(c.Request.UserAgent(), 255),
+	}
+	if err := h.deviceRepo.SaveWebPushSubscription(c, subscription); err != nil {
+		log.Errorw("Failed to save web push subscription", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save web push subscription"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"res": subscription})
+}
+
+// UnsubscribeWebPush removes the browser's push subscription
+func (h *Handler) UnsubscribeWebPush(c *gin.Context) {
+	log := logging.FromContext(c)
+	currentUser, ok := auth.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	var req UnsubscribeWebPushRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+	if err := h.deviceRepo.DeleteWebPushSubscription(c, currentUser.ID, req.Endpoint); err != nil {
+		log.Errorw("Failed to delete web push subscription", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete web push subscription"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Web push subscription removed"})
+}
+
+// GetWebPushSubscriptions lists the browsers subscribed to the current user's push notifications
+func (h *Handler) GetWebPushSubscriptions(c *gin.Context) {
+	log := logging.FromContext(c)
+	currentUser, ok := auth.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	subscriptions, err := h.deviceRepo.GetWebPushSubscriptions(c, currentUser.ID)
+	if err != nil {
+		log.Errorw("Failed to retrieve web push subscriptions", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve web push subscriptions"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"res": subscriptions})
+}
+
+func truncate(s string, max int) string {
+	if len(s) > max {
+		return s[:max]
+	}
+	return s
+}
+
 // Routes sets up the device token management routes
 func Routes(router *gin.Engine, h *Handler, auth *jwt.GinJWTMiddleware, limiter *limiter.Limiter) {
 	deviceRoutes := router.Group("api/v1/devices")
@@ -242,5 +352,9 @@ func Routes(router *gin.Engine, h *Handler, auth *jwt.GinJWTMiddleware, limiter
 		deviceRoutes.GET("/tokens", h.GetDeviceTokens)
 		deviceRoutes.GET("/count", h.GetDeviceCount)
 		deviceRoutes.PUT("/tokens/:deviceId/activity", h.UpdateDeviceActivity)
+		deviceRoutes.GET("/webpush/key", h.GetWebPushKey)
+		deviceRoutes.GET("/webpush/subscriptions", h.GetWebPushSubscriptions)
+		deviceRoutes.POST("/webpush/subscriptions", h.SubscribeWebPush)
+		deviceRoutes.DELETE("/webpush/subscriptions", h.UnsubscribeWebPush)
 	}
 }
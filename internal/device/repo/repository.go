@@ -11,7 +11,11 @@ import (
 	"gorm.io/gorm"
 )
 
-const MaxDevicesPerUser = 5
+const (
+	MaxDevicesPerUser = 5
+	// MaxWebPushSubscriptionsPerUser limits the browsers that receive push notifications
+	MaxWebPushSubscriptionsPerUser = 10
+)
 
 type IDeviceRepository interface {
 	RegisterDeviceToken(c context.Context, deviceToken *uModel.UserDeviceToken) error
@@ -199,3 +203,54 @@ func (r *DeviceRepository) CleanupInactiveTokens(c context.Context, inactiveDays
 	log.Info("Cleaned up inactive device tokens", "count", result.RowsAffected, "cutoff_days", inactiveDays)
 	return nil
 }
+
+// SaveWebPushSubscription stores a browser's push subscription for the user. A browser keeps its
+// endpoint until it unsubscribes, so an existing endpoint is moved to the user. Past the limit the
+// least recently used subscription is replaced.
+func (r *DeviceRepository) SaveWebPushSubscription(c context.Context, subscription *uModel.WebPushSubscription) error {
+	return r.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("endpoint = ?", subscription.Endpoint).Delete(&uModel.WebPushSubscription{}).Error; err != nil {
+			return err
+		}
+
+		var subscriptions []*uModel.WebPushSubscription
+		if err := tx.Where("user_id = ?", subscription.UserID).
+			Order("COALESCE(last_used_at, created_at) ASC").Find(&subscriptions).Error; err != nil {
+			return err
+		}
+		for i := 0; i <= len(subscriptions)-MaxWebPushSubscriptionsPerUser; i++ {
+			if err := tx.Delete(subscriptions[i]).Error; err != nil {
+				return err
+			}
+		}
+
+		subscription.ID = 0
+		subscription.CreatedAt = time.Now().UTC()
+		return tx.Create(subscription).Error
+	})
+}
+
+// GetWebPushSubscriptions retrieves the push subscriptions of all the user's browsers
+func (r *DeviceRepository) GetWebPushSubscriptions(c context.Context, userID int) ([]*uModel.WebPushSubscription, error) {
+	var subscriptions []*uModel.WebPushSubscription
+	if err := r.db.WithContext(c).Where("user_id = ?", userID).Order("created_at DESC").Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// DeleteWebPushSubscription removes the user's subscription with the endpoint, e.g. when the browser unsubscribes
+func (r *DeviceRepository) DeleteWebPushSubscription(c context.Context, userID int, endpoint string) error {
+	return r.db.WithContext(c).Where("user_id = ? AND endpoint = ?", userID, endpoint).Delete(&uModel.WebPushSubscription{}).Error
+}
+
+// DeleteWebPushSubscriptionByID removes a subscription the push service reported as expired
+func (r *DeviceRepository) DeleteWebPushSubscriptionByID(c context.Context, subscriptionID int) error {
+	return r.db.WithContext(c).Delete(&uModel.WebPushSubscription{}, subscriptionID).Error
+}
+
+// MarkWebPushSubscriptionUsed records a successful delivery to the subscription
+func (r *DeviceRepository) MarkWebPushSubscriptionUsed(c context.Context, subscriptionID int) error {
+	return r.db.WithContext(c).Model(&uModel.WebPushSubscription{}).Where("id = ?", subscriptionID).
+		Update("last_used_at", time.Now().UTC()).Error
+}
@@ -491,6 +491,18 @@ func (es *EmailSender) SendResetPasswordEmail(c context.Context, to, code string
 
 }
 
+// SendMultipartEmail sends an email with a plain text body and an HTML alternative, used for notifications
+func (es *EmailSender) SendMultipartEmail(c context.Context, to, subject, textBody, htmlBody string) error {
+	msg := gomail.NewMessage()
+	msg.SetHeader("From", es.fromMail)
+	msg.SetHeader("To", to)
+	msg.SetHeader("Subject", subject)
+	msg.SetBody("text/plain", textBody)
+	msg.AddAlternative("text/html", htmlBody)
+
+	return es.client.DialAndSend(msg)
+}
+
 // func (es *EmailSender) SendFeedbackRequestEmail(to, code string) error {
 // 	// msg := []byte(fmt.Sprintf("To: %s\r\nSubject: %s\r\n\r\n%s\r\n", to, subject, body))
 // 	msg := gomail.NewMessage()
@@ -0,0 +1,241 @@
+package events
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"donetick.com/core/internal/auth"
+	cRepo "donetick.com/core/internal/circle/repo"
+	eRepo "donetick.com/core/internal/events/repo"
+	"donetick.com/core/logging"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultDeliveriesLimit = 50
+	maxDeliveriesLimit     = 200
+)
+
+type Handler struct {
+	webhookRepo   *eRepo.WebhookRepository
+	circleRepo    *cRepo.CircleRepository
+	eventProducer *EventsProducer
+}
+
+func NewHandler(wr *eRepo.WebhookRepository, cr *cRepo.CircleRepository, ep *EventsProducer) *Handler {
+	return &Handler{
+		webhookRepo:   wr,
+		circleRepo:    cr,
+		eventProducer: ep,
+	}
+}
+
+func (h *Handler) isCircleAdmin(c *gin.Context, userID, circleID int) (bool, error) {
+	admins, err := h.circleRepo.GetCircleAdmins(c, circleID)
+	if err != nil {
+		return false, err
+	}
+	for _, admin := range admins {
+		if admin.ID == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// getDeliveries godoc
+//
+//	@Summary		List webhook deliveries
+//	@Description	Retrieves the most recent webhook deliveries of the current user's circle with their status codes
+//	@Tags			webhooks
+//	@Produce		json
+//	@Security		JWTKeyAuth
+//	@Security		APIKeyAuth
+//	@Param			limit	query		int											false	"Maximum number of deliveries (default 50, max 200)"
+//	@Success		200		{object}	map[string][]eModel.WebhookDelivery			"res: array of deliveries"
+//	@Failure		403		{object}	map[string]string							"error: You are not an admin"
+//	@Failure		500		{object}	map[string]string							"error: Failed to get webhook deliveries"
+//	@Router			/webhooks/deliveries [get]
+func (h *Handler) getDeliveries(c *gin.Context) {
+	log := logging.FromContext(c)
+	currentUser, ok := auth.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get current user"})
+		return
+	}
+
+	isAdmin, err := h.isCircleAdmin(c, currentUser.ID, currentUser.CircleID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get circle details"})
+		return
+	}
+	if !isAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not an admin"})
+		return
+	}
+
+	limit := defaultDeliveriesLimit
+	if rawLimit := c.Query("limit"); rawLimit != "" {
+		limit, err = strconv.Atoi(rawLimit)
+		if err != nil || limit <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		if limit > maxDeliveriesLimit {
+			limit = maxDeliveriesLimit
+		}
+	}
+
+	deliveries, err := h.webhookRepo.GetRecentDeliveries(c, currentUser.CircleID, limit)
+	if err != nil {
+		log.Errorw("Failed to get webhook deliveries", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get webhook deliveries"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"res": deliveries})
+}
+
+// redeliver godoc
+//
+//	@Summary		Redeliver a webhook
+//	@Description	Queues a new delivery with the same payload as an earlier delivery of the current user's circle
+//	@Tags			webhooks
+//	@Produce		json
+//	@Security		JWTKeyAuth
+//	@Security		APIKeyAuth
+//	@Param			id	path		int									true	"Delivery ID"
+//	@Success		200	{object}	map[string]eModel.WebhookDelivery	"res: the new delivery"
+//	@Failure		400	{object}	map[string]string					"error: Invalid delivery ID"
+//	@Failure		403	{object}	map[string]string					"error: You are not an admin"
+//	@Failure		404	{object}	map[string]string					"error: Delivery not found"
+//	@Failure		500	{object}	map[string]string					"error: Failed to redeliver webhook"
+//	@Router			/webhooks/deliveries/{id}/redeliver [post]
+func (h *Handler) redeliver(c *gin.Context) {
+	log := logging.FromContext(c)
+	currentUser, ok := auth.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get current user"})
+		return
+	}
+
+	deliveryID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid delivery ID"})
+		return
+	}
+
+	isAdmin, err := h.isCircleAdmin(c, currentUser.ID, currentUser.CircleID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get circle details"})
+		return
+	}
+	if !isAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not an admin"})
+		return
+	}
+
+	original, err := h.webhookRepo.GetDeliveryByID(c, currentUser.CircleID, deliveryID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Delivery not found"})
+		return
+	}
+
+	delivery, err := h.eventProducer.Redeliver(c, original)
+	if err != nil {
+		log.Errorw("Failed to redeliver webhook", "error", err, "deliveryID", deliveryID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to redeliver webhook"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"res": delivery})
+}
+
+// getSecret godoc
+//
+//	@Summary		Get webhook signing secret
+//	@Description	Returns the secret used to sign the circle's webhook deliveries, creating one if the circle has none
+//	@Tags			webhooks
+//	@Produce		json
+//	@Security		JWTKeyAuth
+//	@Security		APIKeyAuth
+//	@Success		200	{object}	map[string]string	"secret: the signing secret"
+//	@Failure		403	{object}	map[string]string	"error: You are not an admin"
+//	@Failure		500	{object}	map[string]string	"error: Failed to get webhook secret"
+//	@Router			/webhooks/secret [get]
+func (h *Handler) getSecret(c *gin.Context) {
+	h.handleSecret(c, false)
+}
+
+// rotateSecret godoc
+//
+//	@Summary		Rotate webhook signing secret
+//	@Description	Replaces the secret used to sign the circle's webhook deliveries
+//	@Tags			webhooks
+//	@Produce		json
+//	@Security		JWTKeyAuth
+//	@Security		APIKeyAuth
+//	@Success		200	{object}	map[string]string	"secret: the new signing secret"
+//	@Failure		403	{object}	map[string]string	"error: You are not an admin"
+//	@Failure		500	{object}	map[string]string	"error: Failed to rotate webhook secret"
+//	@Router			/webhooks/secret/rotate [post]
+func (h *Handler) rotateSecret(c *gin.Context) {
+	h.handleSecret(c, true)
+}
+
+func (h *Handler) handleSecret(c *gin.Context, rotate bool) {
+	log := logging.FromContext(c)
+	currentUser, ok := auth.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get current user"})
+		return
+	}
+
+	isAdmin, err := h.isCircleAdmin(c, currentUser.ID, currentUser.CircleID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get circle details"})
+		return
+	}
+	if !isAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not an admin"})
+		return
+	}
+
+	if !rotate {
+		secret, err := h.webhookRepo.GetCircleWebhookSecret(c, currentUser.CircleID)
+		if err != nil {
+			log.Errorw("Failed to get webhook secret", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get webhook secret"})
+			return
+		}
+		if secret != nil && *secret != "" {
+			c.JSON(http.StatusOK, gin.H{"secret": *secret})
+			return
+		}
+	}
+
+	secret, err := GenerateWebhookSecret()
+	if err != nil {
+		log.Errorw("Failed to generate webhook secret", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rotate webhook secret"})
+		return
+	}
+	if err := h.circleRepo.SetWebhookSecret(c, currentUser.CircleID, secret); err != nil {
+		log.Errorw("Failed to save webhook secret", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rotate webhook secret"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"secret": secret})
+}
+
+func Routes(router *gin.Engine, h *Handler, multiAuthMiddleware *auth.MultiAuthMiddleware) {
+	log.Println("Registering webhook routes")
+
+	webhookRoutes := router.Group("api/v1/webhooks")
+	webhookRoutes.Use(multiAuthMiddleware.MiddlewareFunc())
+	{
+		webhookRoutes.GET("/deliveries", h.getDeliveries)
+		webhookRoutes.POST("/deliveries/:id/redeliver", h.redeliver)
+		webhookRoutes.GET("/secret", h.getSecret)
+		webhookRoutes.POST("/secret/rotate", h.rotateSecret)
+	}
+}
@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+type DeliveryStatus int8
+
+const (
+	DeliveryStatusPending   DeliveryStatus = 0
+	DeliveryStatusDelivered DeliveryStatus = 1
+	DeliveryStatusFailed    DeliveryStatus = 2
+	// DeliveryStatusSending is a delivery claimed by an instance, its next_attempt_at is when the
+	// claim expires and another instance may take it over
+	DeliveryStatusSending DeliveryStatus = 3
+)
+
+// WebhookDelivery is a single webhook event queued for a circle. Deliveries are persisted
+// before they are sent so they survive restarts and can be retried or redelivered.
+type WebhookDelivery struct {
+	ID            int            `json:"id" gorm:"primary_key"`
+	CircleID      int            `json:"circleId" gorm:"column:circle_id;index;not null"`
+	EventType     string         `json:"eventType" gorm:"column:event_type;not null"`
+	URL           string         `json:"url" gorm:"column:url;not null"`
+	Payload       string         `json:"payload" gorm:"column:payload;type:text;not null"`
+	Status        DeliveryStatus `json:"status" gorm:"column:status;index;default:0"`
+	Attempts      int            `json:"attempts" gorm:"column:attempts;default:0"`
+	ResponseCode  *int           `json:"responseCode" gorm:"column:response_code"`
+	LastError     *string        `json:"lastError" gorm:"column:last_error"`
+	NextAttemptAt *time.Time     `json:"nextAttemptAt" gorm:"column:next_attempt_at;index"`
+	DeliveredAt   *time.Time     `json:"deliveredAt" gorm:"column:delivered_at"`
+	RedeliveryOf  *int           `json:"redeliveryOf" gorm:"column:redelivery_of"`
+	CreatedAt     time.Time      `json:"createdAt" gorm:"column:created_at"`
+	UpdatedAt     time.Time      `json:"updatedAt" gorm:"column:updated_at"`
+}
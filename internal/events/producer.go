@@ -1,15 +1,23 @@
 package events
 
 import (
-	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
-	"log"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"donetick.com/core/config"
 	chModel "donetick.com/core/internal/chore/model"
+	eModel "donetick.com/core/internal/events/model"
+	eRepo "donetick.com/core/internal/events/repo"
 	uModel "donetick.com/core/internal/user/model"
 	"donetick.com/core/logging"
 	"go.uber.org/zap"
@@ -19,6 +27,18 @@ const (
 	METHOD_POST       = "POST"
 	HEAD_CONTENT_TYPE = "Content-Type"
 	CONTENT_TYPE_JSON = "application/json"
+	HEAD_EVENT        = "X-Donetick-Event"
+	HEAD_DELIVERY     = "X-Donetick-Delivery"
+	HEAD_TIMESTAMP    = "X-Donetick-Timestamp"
+	HEAD_SIGNATURE    = "X-Donetick-Signature"
+)
+
+const (
+	pollInterval    = 15 * time.Second
+	cleanupInterval = 24 * time.Hour
+	// claimLease is how long a claimed delivery is left to the instance sending it before another
+	// instance may retry it, it outlasts the request timeout
+	claimLease = 5 * time.Minute
 )
 
 type EventType string
@@ -38,6 +58,7 @@ const (
 type Event struct {
 	Type      EventType   `json:"type"`
 	URL       string      `json:"-"`
+	CircleID  int         `json:"-"`
 	Timestamp time.Time   `json:"timestamp"`
 	Data      interface{} `json:"data"`
 }
@@ -49,10 +70,19 @@ type ChoreData struct {
 	Note        string         `json:"note"`
 }
 
+// EventsProducer persists webhook events as deliveries and sends them in the background,
+// retrying failed deliveries with exponential backoff.
 type EventsProducer struct {
-	client *http.Client
-	queue  chan Event
-	logger *zap.SugaredLogger
+	client          *http.Client
+	repo            *eRepo.WebhookRepository
+	wake            chan struct{}
+	done            chan bool
+	batchSize       int
+	maxAttempts     int
+	retryBackoff    time.Duration
+	maxRetryBackoff time.Duration
+	retention       time.Duration
+	logger          *zap.SugaredLogger
 }
 
 func (p *EventsProducer) Start(ctx context.Context) {
@@ -60,61 +90,252 @@ func (p *EventsProducer) Start(ctx context.Context) {
 	p.logger = logging.FromContext(ctx)
 
 	go func() {
-		for event := range p.queue {
-			p.processEvent(event)
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		cleanupTicker := time.NewTicker(cleanupInterval)
+		defer cleanupTicker.Stop()
+
+		// pick up anything left over from before a restart
+		p.processDueDeliveries(ctx)
+		for {
+			select {
+			case <-p.done:
+				return
+			case <-p.wake:
+				p.processDueDeliveries(ctx)
+			case <-ticker.C:
+				p.processDueDeliveries(ctx)
+			case <-cleanupTicker.C:
+				if err := p.repo.DeleteDeliveriesBefore(ctx, time.Now().UTC().Add(-p.retention)); err != nil {
+					p.logger.Errorw("Failed to clean up webhook deliveries", "error", err)
+				}
+			}
 		}
 	}()
 }
 
-func NewEventsProducer(cfg *config.Config) *EventsProducer {
+// Stop stops the delivery worker. Pending deliveries stay in the database and are sent on the next start.
+func (p *EventsProducer) Stop() {
+	p.done <- true
+}
+
+func NewEventsProducer(cfg *config.Config, repo *eRepo.WebhookRepository) *EventsProducer {
+	batchSize := cfg.WebhookConfig.QueueSize
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	maxAttempts := cfg.WebhookConfig.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 8
+	}
+	retryBackoff := cfg.WebhookConfig.RetryBackoff
+	if retryBackoff <= 0 {
+		retryBackoff = 30 * time.Second
+	}
+	maxRetryBackoff := cfg.WebhookConfig.MaxRetryBackoff
+	if maxRetryBackoff <= 0 {
+		maxRetryBackoff = time.Hour
+	}
+	retention := cfg.WebhookConfig.Retention
+	if retention <= 0 {
+		retention = 30 * 24 * time.Hour
+	}
 	return &EventsProducer{
 		client: &http.Client{
 			Timeout: cfg.WebhookConfig.Timeout,
 		},
-		queue: make(chan Event, cfg.WebhookConfig.QueueSize),
+		repo:            repo,
+		wake:            make(chan struct{}, 1),
+		done:            make(chan bool),
+		batchSize:       batchSize,
+		maxAttempts:     maxAttempts,
+		retryBackoff:    retryBackoff,
+		maxRetryBackoff: maxRetryBackoff,
+		retention:       retention,
+		logger:          zap.NewNop().Sugar(),
+	}
+}
+
+func (p *EventsProducer) publishEvent(ctx context.Context, event Event) {
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		p.logger.Errorw("Failed to marshal webhook event", "error", err)
+		return
+	}
+
+	now := time.Now().UTC()
+	delivery := &eModel.WebhookDelivery{
+		CircleID:      event.CircleID,
+		EventType:     string(event.Type),
+		URL:           event.URL,
+		Payload:       string(eventJSON),
+		Status:        eModel.DeliveryStatusPending,
+		NextAttemptAt: &now,
+	}
+	// the delivery must be stored even if the request that triggered it is cancelled
+	if err := p.repo.CreateDelivery(context.WithoutCancel(ctx), delivery); err != nil {
+		p.logger.Errorw("Failed to store webhook delivery, dropping event", "error", err, "type", event.Type)
+		return
 	}
+	p.notify()
 }
 
-func (p *EventsProducer) publishEvent(event Event) {
+// Redeliver queues a new delivery with the same payload as an earlier one.
+func (p *EventsProducer) Redeliver(ctx context.Context, original *eModel.WebhookDelivery) (*eModel.WebhookDelivery, error) {
+	now := time.Now().UTC()
+	delivery := &eModel.WebhookDelivery{
+		CircleID:      original.CircleID,
+		EventType:     original.EventType,
+		URL:           original.URL,
+		Payload:       original.Payload,
+		Status:        eModel.DeliveryStatusPending,
+		NextAttemptAt: &now,
+		RedeliveryOf:  &original.ID,
+	}
+	if err := p.repo.CreateDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+	p.notify()
+	return delivery, nil
+}
+
+func (p *EventsProducer) notify() {
 	select {
-	case p.queue <- event:
-		// Successfully added to queue
+	case p.wake <- struct{}{}:
 	default:
-		log.Println("Webhook queue is full, dropping event")
+		// worker already has a pending wake up
 	}
 }
 
-func (p *EventsProducer) processEvent(event Event) {
-	p.logger.Debugw("Sending webhook event", "type", event.Type, "url", event.URL)
-
-	eventJSON, err := json.Marshal(event)
+func (p *EventsProducer) processDueDeliveries(ctx context.Context) {
+	deliveries, err := p.repo.GetDueDeliveries(ctx, time.Now().UTC(), p.batchSize)
 	if err != nil {
-		p.logger.Errorw("Failed to marshal webhook event", "error", err)
+		p.logger.Errorw("Failed to get due webhook deliveries", "error", err)
 		return
 	}
+	for _, delivery := range deliveries {
+		// with several instances sharing the database only the one that claims a delivery sends it
+		now := time.Now().UTC()
+		claimed, err := p.repo.ClaimDelivery(ctx, delivery.ID, now, now.Add(claimLease))
+		if err != nil {
+			p.logger.Errorw("Failed to claim webhook delivery", "error", err, "deliveryID", delivery.ID)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		p.processDelivery(ctx, delivery)
+	}
+}
+
+func (p *EventsProducer) processDelivery(ctx context.Context, delivery *eModel.WebhookDelivery) {
+	p.logger.Debugw("Sending webhook event", "type", delivery.EventType, "url", delivery.URL, "deliveryID", delivery.ID)
 
-	// Pring the event and the url:
-	p.logger.Debug("Sending event to webhook", "url", event.URL, "event", event)
-	p.logger.Debug("Event: ", event)
+	statusCode, err := p.send(ctx, delivery)
+
+	now := time.Now().UTC()
+	delivery.Attempts++
+	delivery.ResponseCode = nil
+	if statusCode != 0 {
+		delivery.ResponseCode = &statusCode
+	}
+	if err == nil {
+		delivery.Status = eModel.DeliveryStatusDelivered
+		delivery.LastError = nil
+		delivery.NextAttemptAt = nil
+		delivery.DeliveredAt = &now
+	} else {
+		errMsg := err.Error()
+		delivery.LastError = &errMsg
+		if delivery.Attempts >= p.maxAttempts {
+			p.logger.Errorw("Webhook delivery failed, giving up", "error", err, "deliveryID", delivery.ID, "attempts", delivery.Attempts)
+			delivery.Status = eModel.DeliveryStatusFailed
+			delivery.NextAttemptAt = nil
+		} else {
+			p.logger.Debugw("Webhook delivery failed, will retry", "error", err, "deliveryID", delivery.ID, "attempts", delivery.Attempts)
+			delivery.Status = eModel.DeliveryStatusPending
+			nextAttempt := now.Add(p.backoff(delivery.Attempts))
+			delivery.NextAttemptAt = &nextAttempt
+		}
+	}
+
+	if err := p.repo.UpdateDeliveryAttempt(ctx, delivery); err != nil {
+		p.logger.Errorw("Failed to update webhook delivery", "error", err, "deliveryID", delivery.ID)
+	}
+}
+
+// backoff returns the delay before the next attempt after the given number of attempts.
+func (p *EventsProducer) backoff(attempts int) time.Duration {
+	delay := p.retryBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= p.maxRetryBackoff {
+			return p.maxRetryBackoff
+		}
+	}
+	return delay
+}
 
-	req, err := http.NewRequest(METHOD_POST, event.URL, bytes.NewBuffer(eventJSON))
+func (p *EventsProducer) send(ctx context.Context, delivery *eModel.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, METHOD_POST, delivery.URL, strings.NewReader(delivery.Payload))
 	if err != nil {
-		p.logger.Errorw("Failed to create webhook request", "error", err)
-		return
+		return 0, fmt.Errorf("failed to create webhook request: %w", err)
 	}
 	req.Header.Set(HEAD_CONTENT_TYPE, CONTENT_TYPE_JSON)
+	req.Header.Set(HEAD_EVENT, delivery.EventType)
+	req.Header.Set(HEAD_DELIVERY, strconv.Itoa(delivery.ID))
+
+	secret, err := p.repo.GetCircleWebhookSecret(ctx, delivery.CircleID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get webhook secret: %w", err)
+	}
+	if secret == nil || *secret == "" {
+		// circles that set their webhook before deliveries were signed get a secret on first use
+		newSecret, err := GenerateWebhookSecret()
+		if err != nil {
+			return 0, fmt.Errorf("failed to generate webhook secret: %w", err)
+		}
+		if secret, err = p.repo.SetCircleWebhookSecretIfMissing(ctx, delivery.CircleID, newSecret); err != nil {
+			return 0, fmt.Errorf("failed to store webhook secret: %w", err)
+		}
+	}
+	if secret != nil && *secret != "" {
+		timestamp := strconv.FormatInt(time.Now().UTC().Unix(), 10)
+		req.Header.Set(HEAD_TIMESTAMP, timestamp)
+		req.Header.Set(HEAD_SIGNATURE, SignPayload(*secret, timestamp, []byte(delivery.Payload)))
+	}
 
 	resp, err := p.client.Do(req)
 	if err != nil {
-		p.logger.Debugw("Failed to send webhook event", "error", err)
-		return
+		return 0, err
 	}
 	defer resp.Body.Close()
+	// drain a bit of the body so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
 
-	if resp.StatusCode >= 400 {
-		p.logger.Errorw("Webhook request failed", "status", resp.StatusCode)
-		return
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// SignPayload returns the value of the signature header for a payload: an HMAC-SHA256 over
+// "<timestamp>.<payload>" keyed with the circle's webhook secret.
+func SignPayload(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// GenerateWebhookSecret returns a new random secret for signing a circle's webhook deliveries.
+func GenerateWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
 	}
+	return "whsec_" + hex.EncodeToString(b), nil
 }
 
 func (p *EventsProducer) ChoreCreated(ctx context.Context, webhookURL *string, chore *chModel.Chore, creator *uModel.User) {
@@ -126,6 +347,7 @@ func (p *EventsProducer) ChoreCreated(ctx context.Context, webhookURL *string, c
 	event := Event{
 		Type:      EventTypeTaskCreated,
 		URL:       *webhookURL,
+		CircleID:  chore.CircleID,
 		Timestamp: time.Now().UTC(),
 		Data: ChoreData{
 			Chore:       chore,
@@ -133,7 +355,7 @@ func (p *EventsProducer) ChoreCreated(ctx context.Context, webhookURL *string, c
 			DisplayName: creator.DisplayName,
 		},
 	}
-	p.publishEvent(event)
+	p.publishEvent(ctx, event)
 }
 
 func (p *EventsProducer) ChoreCompleted(ctx context.Context, webhookURL *string, chore *chModel.Chore, performer *uModel.User) {
@@ -145,13 +367,14 @@ func (p *EventsProducer) ChoreCompleted(ctx context.Context, webhookURL *string,
 	event := Event{
 		Type:      EventTypeTaskCompleted,
 		URL:       *webhookURL,
+		CircleID:  chore.CircleID,
 		Timestamp: time.Now().UTC(),
 		Data: ChoreData{Chore: chore,
 			Username:    performer.Username,
 			DisplayName: performer.DisplayName,
 		},
 	}
-	p.publishEvent(event)
+	p.publishEvent(ctx, event)
 }
 
 func (p *EventsProducer) ChoreSkipped(ctx context.Context, webhookURL *string, chore *chModel.Chore, performer *uModel.User) {
@@ -163,47 +386,51 @@ func (p *EventsProducer) ChoreSkipped(ctx context.Context, webhookURL *string, c
 	event := Event{
 		Type:      EventTypeTaskSkipped,
 		URL:       *webhookURL,
+		CircleID:  chore.CircleID,
 		Timestamp: time.Now().UTC(),
 		Data: ChoreData{Chore: chore,
 			Username:    performer.Username,
 			DisplayName: performer.DisplayName,
 		},
 	}
-	p.publishEvent(event)
+	p.publishEvent(ctx, event)
 }
 
-func (p *EventsProducer) NotificationEvent(ctx context.Context, url string, event interface{}) {
+func (p *EventsProducer) NotificationEvent(ctx context.Context, circleID int, url string, event interface{}) {
 	// print the event and the url :
 	p.logger.Debug("Sending notification event")
 
-	p.publishEvent(Event{
+	p.publishEvent(ctx, Event{
 		URL:       url,
+		CircleID:  circleID,
 		Type:      EventTypeTaskReminder,
 		Timestamp: time.Now().UTC(),
 		Data:      event,
 	})
 }
 
-func (p *EventsProducer) ThingsUpdated(ctx context.Context, url *string, data interface{}) {
+func (p *EventsProducer) ThingsUpdated(ctx context.Context, circleID int, url *string, data interface{}) {
 	if url == nil {
 		p.logger.Debug("No subscribers for circle, skipping webhook")
 		return
 	}
-	p.publishEvent(Event{
+	p.publishEvent(ctx, Event{
 		URL:       *url,
+		CircleID:  circleID,
 		Type:      EventTypeThingChanged,
 		Timestamp: time.Now().UTC(),
 		Data:      data,
 	})
 }
 
-func (p *EventsProducer) SubtaskUpdated(ctx context.Context, url *string, data interface{}) {
+func (p *EventsProducer) SubtaskUpdated(ctx context.Context, circleID int, url *string, data interface{}) {
 	if url == nil {
 		p.logger.Debug("No subscribers for circle, skipping webhook")
 		return
 	}
-	p.publishEvent(Event{
+	p.publishEvent(ctx, Event{
 		URL:       *url,
+		CircleID:  circleID,
 		Type:      EventTypeSubTaskCompleted,
 		Timestamp: time.Now().UTC(),
 		Data:      data,
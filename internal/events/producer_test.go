@@ -0,0 +1,199 @@
+package events
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"donetick.com/core/config"
+	cModel "donetick.com/core/internal/circle/model"
+	"donetick.com/core/internal/database"
+	eModel "donetick.com/core/internal/events/model"
+	eRepo "donetick.com/core/internal/events/repo"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupProducer(t *testing.T) (*EventsProducer, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect database: %v", err)
+	}
+	if err := database.Migration(db); err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
+	}
+
+	cfg := &config.Config{WebhookConfig: config.WebhookConfig{
+		Timeout:         time.Second,
+		MaxAttempts:     2,
+		RetryBackoff:    time.Minute,
+		MaxRetryBackoff: time.Hour,
+	}}
+	return NewEventsProducer(cfg, eRepo.NewWebhookRepository(db)), db
+}
+
+func TestDeliverySignedWithCircleSecret(t *testing.T) {
+	p, db := setupProducer(t)
+	ctx := context.Background()
+
+	secret := "whsec_test"
+	if err := db.Create(&cModel.Circle{ID: 1, Name: "Home", WebhookSecret: &secret}).Error; err != nil {
+		t.Fatalf("failed to create circle: %v", err)
+	}
+
+	var gotSignature, gotTimestamp string
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSignature = r.Header.Get(HEAD_SIGNATURE)
+		gotTimestamp = r.Header.Get(HEAD_TIMESTAMP)
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	url := server.URL
+	p.ThingsUpdated(ctx, 1, &url, map[string]interface{}{"id": 1})
+	p.processDueDeliveries(ctx)
+
+	if gotSignature == "" || gotSignature != SignPayload(secret, gotTimestamp, gotBody) {
+		t.Errorf("unexpected signature %q", gotSignature)
+	}
+
+	var delivery eModel.WebhookDelivery
+	db.First(&delivery)
+	if delivery.Status != eModel.DeliveryStatusDelivered {
+		t.Errorf("expected delivery to be delivered, got status %d", delivery.Status)
+	}
+	if delivery.ResponseCode == nil || *delivery.ResponseCode != http.StatusNoContent {
+		t.Errorf("expected response code 204, got %v", delivery.ResponseCode)
+	}
+}
+
+func TestDeliverySignedWithSecretCreatedOnFirstUse(t *testing.T) {
+	p, db := setupProducer(t)
+	ctx := context.Background()
+
+	if err := db.Create(&cModel.Circle{ID: 1, Name: "Home"}).Error; err != nil {
+		t.Fatalf("failed to create circle: %v", err)
+	}
+
+	var gotSignature, gotTimestamp string
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSignature = r.Header.Get(HEAD_SIGNATURE)
+		gotTimestamp = r.Header.Get(HEAD_TIMESTAMP)
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	url := server.URL
+	p.ThingsUpdated(ctx, 1, &url, map[string]interface{}{"id": 1})
+	p.processDueDeliveries(ctx)
+
+	var circle cModel.Circle
+	db.First(&circle, 1)
+	if circle.WebhookSecret == nil || *circle.WebhookSecret == "" {
+		t.Fatalf("expected a webhook secret to be stored for the circle")
+	}
+	if gotSignature == "" || gotSignature != SignPayload(*circle.WebhookSecret, gotTimestamp, gotBody) {
+		t.Errorf("unexpected signature %q", gotSignature)
+	}
+}
+
+func TestDeliveryClaimedOnce(t *testing.T) {
+	p, db := setupProducer(t)
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	delivery := &eModel.WebhookDelivery{CircleID: 1, EventType: "thing.changed", URL: "http://example.invalid", Payload: "{}", NextAttemptAt: &now}
+	if err := db.Create(delivery).Error; err != nil {
+		t.Fatalf("failed to create delivery: %v", err)
+	}
+
+	claimed, err := p.repo.ClaimDelivery(ctx, delivery.ID, now, now.Add(claimLease))
+	if err != nil || !claimed {
+		t.Fatalf("expected first claim to succeed, got %v %v", claimed, err)
+	}
+	claimed, err = p.repo.ClaimDelivery(ctx, delivery.ID, now, now.Add(claimLease))
+	if err != nil || claimed {
+		t.Fatalf("expected second claim to fail, got %v %v", claimed, err)
+	}
+
+	// a claim left behind by a stopped instance is taken over once it expires
+	later := now.Add(claimLease + time.Second)
+	due, err := p.repo.GetDueDeliveries(ctx, later, 10)
+	if err != nil || len(due) != 1 {
+		t.Fatalf("expected the expired claim to be due, got %d %v", len(due), err)
+	}
+	claimed, err = p.repo.ClaimDelivery(ctx, delivery.ID, later, later.Add(claimLease))
+	if err != nil || !claimed {
+		t.Errorf("expected expired claim to be taken over, got %v %v", claimed, err)
+	}
+}
+
+func TestFailedDeliveryRetriesThenFails(t *testing.T) {
+	p, db := setupProducer(t)
+	ctx := context.Background()
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	url := server.URL
+	p.ThingsUpdated(ctx, 1, &url, map[string]interface{}{"id": 1})
+	p.processDueDeliveries(ctx)
+
+	var delivery eModel.WebhookDelivery
+	db.First(&delivery)
+	if delivery.Status != eModel.DeliveryStatusPending || delivery.Attempts != 1 {
+		t.Fatalf("expected pending delivery after 1 attempt, got status %d attempts %d", delivery.Status, delivery.Attempts)
+	}
+	if delivery.NextAttemptAt == nil || delivery.NextAttemptAt.Before(time.Now().UTC().Add(30*time.Second)) {
+		t.Errorf("expected retry to be backed off, got %v", delivery.NextAttemptAt)
+	}
+
+	// not due yet, nothing should be sent
+	p.processDueDeliveries(ctx)
+	if atomic.LoadInt32(&calls) != 1 {
+		t.Fatalf("expected 1 call before backoff elapsed, got %d", calls)
+	}
+
+	db.Model(&delivery).Update("next_attempt_at", time.Now().UTC().Add(-time.Second))
+	p.processDueDeliveries(ctx)
+
+	db.First(&delivery, delivery.ID)
+	if delivery.Status != eModel.DeliveryStatusFailed || delivery.Attempts != 2 {
+		t.Errorf("expected failed delivery after 2 attempts, got status %d attempts %d", delivery.Status, delivery.Attempts)
+	}
+	if delivery.ResponseCode == nil || *delivery.ResponseCode != http.StatusInternalServerError {
+		t.Errorf("expected response code 500, got %v", delivery.ResponseCode)
+	}
+}
+
+func TestBackoffIsExponentialAndCapped(t *testing.T) {
+	p := &EventsProducer{retryBackoff: time.Second, maxRetryBackoff: 10 * time.Second}
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{4, 8 * time.Second},
+		{5, 10 * time.Second},
+		{20, 10 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := p.backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
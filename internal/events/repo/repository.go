@@ -0,0 +1,114 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	eModel "donetick.com/core/internal/events/model"
+	"gorm.io/gorm"
+)
+
+type WebhookRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) *WebhookRepository {
+	return &WebhookRepository{db}
+}
+
+func (r *WebhookRepository) CreateDelivery(c context.Context, delivery *eModel.WebhookDelivery) error {
+	return r.db.WithContext(c).Create(delivery).Error
+}
+
+func (r *WebhookRepository) GetDeliveryByID(c context.Context, circleID, deliveryID int) (*eModel.WebhookDelivery, error) {
+	var delivery eModel.WebhookDelivery
+	if err := r.db.WithContext(c).Where("id = ? AND circle_id = ?", deliveryID, circleID).First(&delivery).Error; err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func (r *WebhookRepository) GetRecentDeliveries(c context.Context, circleID int, limit int) ([]*eModel.WebhookDelivery, error) {
+	var deliveries []*eModel.WebhookDelivery
+	if err := r.db.WithContext(c).
+		Where("circle_id = ?", circleID).
+		Order("created_at desc, id desc").
+		Limit(limit).
+		Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// GetDueDeliveries returns pending deliveries whose next attempt is due, and deliveries whose claim
+// expired, oldest first. A delivery must be claimed before it is sent.
+func (r *WebhookRepository) GetDueDeliveries(c context.Context, now time.Time, limit int) ([]*eModel.WebhookDelivery, error) {
+	var deliveries []*eModel.WebhookDelivery
+	if err := r.db.WithContext(c).
+		Where("status IN ? AND next_attempt_at <= ?", []eModel.DeliveryStatus{eModel.DeliveryStatusPending, eModel.DeliveryStatusSending}, now).
+		Order("next_attempt_at asc, id asc").
+		Limit(limit).
+		Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// ClaimDelivery marks a due delivery as being sent until leaseUntil. The update only matches while
+// the delivery is still due, so when several instances race for it exactly one gets true.
+func (r *WebhookRepository) ClaimDelivery(c context.Context, deliveryID int, now time.Time, leaseUntil time.Time) (bool, error) {
+	result := r.db.WithContext(c).Model(&eModel.WebhookDelivery{}).
+		Where("id = ? AND status IN ? AND next_attempt_at <= ?", deliveryID, []eModel.DeliveryStatus{eModel.DeliveryStatusPending, eModel.DeliveryStatusSending}, now).
+		Updates(map[string]interface{}{
+			"status":          eModel.DeliveryStatusSending,
+			"next_attempt_at": leaseUntil,
+			"updated_at":      now,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *WebhookRepository) UpdateDeliveryAttempt(c context.Context, delivery *eModel.WebhookDelivery) error {
+	return r.db.WithContext(c).Model(&eModel.WebhookDelivery{}).
+		Where("id = ?", delivery.ID).
+		Updates(map[string]interface{}{
+			"status":          delivery.Status,
+			"attempts":        delivery.Attempts,
+			"response_code":   delivery.ResponseCode,
+			"last_error":      delivery.LastError,
+			"next_attempt_at": delivery.NextAttemptAt,
+			"delivered_at":    delivery.DeliveredAt,
+			"updated_at":      time.Now().UTC(),
+		}).Error
+}
+
+// GetCircleWebhookSecret returns the signing secret of the circle, or nil if it has none.
+func (r *WebhookRepository) GetCircleWebhookSecret(c context.Context, circleID int) (*string, error) {
+	var secret *string
+	if err := r.db.WithContext(c).Table("circles").
+		Select("webhook_secret").
+		Where("id = ?", circleID).
+		Scan(&secret).Error; err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// SetCircleWebhookSecretIfMissing stores the secret for a circle that has none yet and returns the
+// circle's secret, which is another instance's when it got there first.
+func (r *WebhookRepository) SetCircleWebhookSecretIfMissing(c context.Context, circleID int, secret string) (*string, error) {
+	if err := r.db.WithContext(c).Table("circles").
+		Where("id = ? AND (webhook_secret IS NULL OR webhook_secret = '')", circleID).
+		Update("webhook_secret", secret).Error; err != nil {
+		return nil, err
+	}
+	return r.GetCircleWebhookSecret(c, circleID)
+}
+
+func (r *WebhookRepository) DeleteDeliveriesBefore(c context.Context, before time.Time) error {
+	return r.db.WithContext(c).
+		Where("status NOT IN ? AND created_at < ?", []eModel.DeliveryStatus{eModel.DeliveryStatusPending, eModel.DeliveryStatusSending}, before).
+		Delete(&eModel.WebhookDelivery{}).Error
+}
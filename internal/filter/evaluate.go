@@ -0,0 +1,325 @@
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	chModel "donetick.com/core/internal/chore/model"
+	fModel "donetick.com/core/internal/filter/model"
+)
+
+// EvalContext carries what relative conditions are evaluated against: "me" resolves to UserID
+// and due date ranges like isDueToday are computed in Location.
+type EvalContext struct {
+	UserID   int
+	Now      time.Time
+	Location *time.Location
+}
+
+// MatchChores returns the chores matching the filter's conditions, combined with its logical operator.
+func MatchChores(filter *fModel.Filter, chores []*chModel.Chore, ec EvalContext) ([]*chModel.Chore, error) {
+	matched := make([]*chModel.Chore, 0, len(chores))
+	for _, chore := range chores {
+		ok, err := MatchChore(filter, chore, ec)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matched = append(matched, chore)
+		}
+	}
+	return matched, nil
+}
+
+// MatchChore reports whether a single chore matches the filter. A filter without conditions matches everything.
+func MatchChore(filter *fModel.Filter, chore *chModel.Chore, ec EvalContext) (bool, error) {
+	if len(filter.Conditions) == 0 {
+		return true, nil
+	}
+	if ec.Location == nil {
+		ec.Location = time.UTC
+	}
+	isOr := filter.Operator == fModel.LogicalOperatorOR
+	for i, condition := range filter.Conditions {
+		ok, err := matchCondition(condition, chore, ec)
+		if err != nil {
+			return false, fmt.Errorf("condition[%d]: %w", i, err)
+		}
+		if isOr && ok {
+			return true, nil
+		}
+		if !isOr && !ok {
+			return false, nil
+		}
+	}
+	return !isOr, nil
+}
+
+func matchCondition(condition fModel.FilterCondition, chore *chModel.Chore, ec EvalContext) (bool, error) {
+	op := fModel.ConditionOperator(condition.Operator)
+	switch fModel.ConditionType(condition.Type) {
+	case fModel.ConditionTypeAssignee:
+		values, err := userIDValues(condition.Value, ec.UserID)
+		if err != nil {
+			return false, err
+		}
+		return matchMembership(op, values, optionalInt(chore.AssignedTo))
+	case fModel.ConditionTypeCreatedBy:
+		values, err := userIDValues(condition.Value, ec.UserID)
+		if err != nil {
+			return false, err
+		}
+		return matchMembership(op, values, []int{chore.CreatedBy})
+	case fModel.ConditionTypeStatus:
+		values, err := intValues(condition.Value)
+		if err != nil {
+			return false, err
+		}
+		return matchMembership(op, values, []int{int(chore.Status)})
+	case fModel.ConditionTypeProject:
+		values, err := intValues(condition.Value)
+		if err != nil {
+			return false, err
+		}
+		return matchMembership(op, values, optionalInt(chore.ProjectID))
+	case fModel.ConditionTypeLabel:
+		values, err := intValues(condition.Value)
+		if err != nil {
+			return false, err
+		}
+		var labelIDs []int
+		if chore.LabelsV2 != nil {
+			for _, label := range *chore.LabelsV2 {
+				labelIDs = append(labelIDs, label.ID)
+			}
+		}
+		return matchMembership(op, values, labelIDs)
+	case fModel.ConditionTypePriority:
+		return matchNumber(op, condition.Value, chore.Priority)
+	case fModel.ConditionTypePoints:
+		points := 0
+		if chore.Points != nil {
+			points = *chore.Points
+		}
+		return matchNumber(op, condition.Value, points)
+	case fModel.ConditionTypeDueDate:
+		return matchDueDate(op, condition.Value, chore.NextDueDate, ec)
+	default:
+		return false, fmt.Errorf("unsupported condition type %q", condition.Type)
+	}
+}
+
+// matchMembership compares the chore's values (e.g. its label IDs) with the condition's values.
+// is/has/or match when any value is present, and requires all of them, isNot/doesNotHave when none is.
+func matchMembership(op fModel.ConditionOperator, want []int, have []int) (bool, error) {
+	present := make(map[int]bool, len(have))
+	for _, v := range have {
+		present[v] = true
+	}
+	switch op {
+	case fModel.OperatorIs, fModel.OperatorEquals, fModel.OperatorHas, fModel.OperatorOr:
+		for _, v := range want {
+			if present[v] {
+				return true, nil
+			}
+		}
+		return false, nil
+	case fModel.OperatorAnd:
+		for _, v := range want {
+			if !present[v] {
+				return false, nil
+			}
+		}
+		return true, nil
+	case fModel.OperatorIsNot, fModel.OperatorDoesNotHave:
+		for _, v := range want {
+			if present[v] {
+				return false, nil
+			}
+		}
+		return true, nil
+	default:
+		return false, fmt.Errorf("unsupported operator %q", op)
+	}
+}
+
+func matchNumber(op fModel.ConditionOperator, value interface{}, actual int) (bool, error) {
+	switch op {
+	case fModel.OperatorIs, fModel.OperatorIsNot:
+		values, err := intValues(value)
+		if err != nil {
+			return false, err
+		}
+		return matchMembership(op, values, []int{actual})
+	}
+
+	want, err := toInt(value)
+	if err != nil {
+		return false, err
+	}
+	switch op {
+	case fModel.OperatorEquals:
+		return actual == want, nil
+	case fModel.OperatorGreaterThan:
+		return actual > want, nil
+	case fModel.OperatorLessThan:
+		return actual < want, nil
+	case fModel.OperatorGreaterThanOrEqual:
+		return actual >= want, nil
+	case fModel.OperatorLessThanOrEqual:
+		return actual <= want, nil
+	default:
+		return false, fmt.Errorf("unsupported operator %q", op)
+	}
+}
+
+func matchDueDate(op fModel.ConditionOperator, value interface{}, dueDate *time.Time, ec EvalContext) (bool, error) {
+	switch op {
+	case fModel.OperatorHasNoDueDate:
+		return dueDate == nil, nil
+	case fModel.OperatorHasDueDate:
+		return dueDate != nil, nil
+	}
+	if dueDate == nil {
+		// validate the operator even when there is nothing to compare against
+		switch op {
+		case fModel.OperatorIsOverdue, fModel.OperatorIsDueToday, fModel.OperatorIsDueTomorrow,
+			fModel.OperatorIsDueThisWeek, fModel.OperatorIsDueThisMonth,
+			fModel.OperatorBefore, fModel.OperatorAfter, fModel.OperatorBetween:
+			return false, nil
+		default:
+			return false, fmt.Errorf("unsupported operator %q", op)
+		}
+	}
+
+	now := ec.Now.In(ec.Location)
+	due := dueDate.In(ec.Location)
+	startOfToday := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, ec.Location)
+
+	switch op {
+	case fModel.OperatorIsOverdue:
+		return due.Before(now), nil
+	case fModel.OperatorIsDueToday:
+		return inRange(due, startOfToday, startOfToday.AddDate(0, 0, 1)), nil
+	case fModel.OperatorIsDueTomorrow:
+		return inRange(due, startOfToday.AddDate(0, 0, 1), startOfToday.AddDate(0, 0, 2)), nil
+	case fModel.OperatorIsDueThisWeek:
+		// weeks start on Monday
+		startOfWeek := startOfToday.AddDate(0, 0, -((int(now.Weekday()) + 6) % 7))
+		return inRange(due, startOfWeek, startOfWeek.AddDate(0, 0, 7)), nil
+	case fModel.OperatorIsDueThisMonth:
+		startOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, ec.Location)
+		return inRange(due, startOfMonth, startOfMonth.AddDate(0, 1, 0)), nil
+	case fModel.OperatorBefore:
+		t, err := toTime(value, ec.Location)
+		if err != nil {
+			return false, err
+		}
+		return due.Before(t), nil
+	case fModel.OperatorAfter:
+		t, err := toTime(value, ec.Location)
+		if err != nil {
+			return false, err
+		}
+		return due.After(t), nil
+	case fModel.OperatorBetween:
+		bounds, ok := value.([]interface{})
+		if !ok || len(bounds) != 2 {
+			return false, fmt.Errorf("operator %q requires a [from, to] value", op)
+		}
+		from, err := toTime(bounds[0], ec.Location)
+		if err != nil {
+			return false, err
+		}
+		to, err := toTime(bounds[1], ec.Location)
+		if err != nil {
+			return false, err
+		}
+		return !due.Before(from) && !due.After(to), nil
+	default:
+		return false, fmt.Errorf("unsupported operator %q", op)
+	}
+}
+
+func inRange(t, from, to time.Time) bool {
+	return !t.Before(from) && t.Before(to)
+}
+
+func optionalInt(v *int) []int {
+	if v == nil {
+		return nil
+	}
+	return []int{*v}
+}
+
+// userIDValues is like intValues but also accepts "me" for the user evaluating the filter.
+func userIDValues(value interface{}, userID int) ([]int, error) {
+	items := asList(value)
+	ids := make([]int, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok && strings.EqualFold(s, "me") {
+			ids = append(ids, userID)
+			continue
+		}
+		id, err := toInt(item)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// intValues accepts a single value or a list of values, as numbers or numeric strings.
+func intValues(value interface{}) ([]int, error) {
+	items := asList(value)
+	ids := make([]int, 0, len(items))
+	for _, item := range items {
+		id, err := toInt(item)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func asList(value interface{}) []interface{} {
+	if list, ok := value.([]interface{}); ok {
+		return list
+	}
+	return []interface{}{value}
+}
+
+func toInt(value interface{}) (int, error) {
+	switch v := value.(type) {
+	case float64:
+		return int(v), nil
+	case int:
+		return v, nil
+	case string:
+		i, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number %q", v)
+		}
+		return i, nil
+	default:
+		return 0, fmt.Errorf("invalid number %v", value)
+	}
+}
+
+func toTime(value interface{}, loc *time.Location) (time.Time, error) {
+	s, ok := value.(string)
+	if !ok {
+		return time.Time{}, fmt.Errorf("invalid date %v", value)
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, loc); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", s)
+}
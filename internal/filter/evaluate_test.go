@@ -0,0 +1,154 @@
+package filter
+
+import (
+	"testing"
+	"time"
+
+	chModel "donetick.com/core/internal/chore/model"
+	fModel "donetick.com/core/internal/filter/model"
+	lModel "donetick.com/core/internal/label/model"
+)
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func TestMatchChore(t *testing.T) {
+	// Wednesday
+	now := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+	ec := EvalContext{UserID: 1, Now: now, Location: time.UTC}
+
+	chore := &chModel.Chore{
+		ID:          1,
+		AssignedTo:  intPtr(1),
+		CreatedBy:   2,
+		Priority:    2,
+		Points:      intPtr(10),
+		ProjectID:   intPtr(7),
+		NextDueDate: timePtr(time.Date(2026, 3, 8, 9, 0, 0, 0, time.UTC)), // Sunday
+		LabelsV2:    &[]lModel.Label{{ID: 3}, {ID: 4}},
+	}
+
+	tests := []struct {
+		name       string
+		operator   fModel.LogicalOperator
+		conditions fModel.FilterConditions
+		want       bool
+	}{
+		{
+			name:       "assignee is me",
+			conditions: fModel.FilterConditions{{Type: "assignee", Operator: "is", Value: "me"}},
+			want:       true,
+		},
+		{
+			name:       "assignee is not any of",
+			conditions: fModel.FilterConditions{{Type: "assignee", Operator: "isNot", Value: []interface{}{float64(1), float64(5)}}},
+			want:       false,
+		},
+		{
+			name:       "priority greater than",
+			conditions: fModel.FilterConditions{{Type: "priority", Operator: "greaterThan", Value: float64(1)}},
+			want:       true,
+		},
+		{
+			name:       "points less than or equal",
+			conditions: fModel.FilterConditions{{Type: "points", Operator: "lessThanOrEqual", Value: "5"}},
+			want:       false,
+		},
+		{
+			name:       "due this week ends on sunday",
+			conditions: fModel.FilterConditions{{Type: "dueDate", Operator: "isDueThisWeek"}},
+			want:       true,
+		},
+		{
+			name:       "not due today",
+			conditions: fModel.FilterConditions{{Type: "dueDate", Operator: "isDueToday"}},
+			want:       false,
+		},
+		{
+			name:       "due between",
+			conditions: fModel.FilterConditions{{Type: "dueDate", Operator: "between", Value: []interface{}{"2026-03-01", "2026-03-10"}}},
+			want:       true,
+		},
+		{
+			name:       "label has all",
+			conditions: fModel.FilterConditions{{Type: "label", Operator: "and", Value: []interface{}{float64(3), float64(9)}}},
+			want:       false,
+		},
+		{
+			name:       "label does not have",
+			conditions: fModel.FilterConditions{{Type: "label", Operator: "doesNotHave", Value: float64(9)}},
+			want:       true,
+		},
+		{
+			name:     "AND requires every condition",
+			operator: fModel.LogicalOperatorAND,
+			conditions: fModel.FilterConditions{
+				{Type: "project", Operator: "is", Value: float64(7)},
+				{Type: "createdBy", Operator: "is", Value: "me"},
+			},
+			want: false,
+		},
+		{
+			name:     "OR requires any condition",
+			operator: fModel.LogicalOperatorOR,
+			conditions: fModel.FilterConditions{
+				{Type: "project", Operator: "is", Value: float64(7)},
+				{Type: "createdBy", Operator: "is", Value: "me"},
+			},
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := &fModel.Filter{Operator: tt.operator, Conditions: tt.conditions}
+			if filter.Operator == "" {
+				filter.Operator = fModel.LogicalOperatorAND
+			}
+			got, err := MatchChore(filter, chore, ec)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("MatchChore() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchChoreDueTodayUsesLocation(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("timezone data not available")
+	}
+	// 23:00 in New York is already the next day in UTC
+	now := time.Date(2026, 3, 4, 23, 0, 0, 0, loc)
+	chore := &chModel.Chore{NextDueDate: timePtr(time.Date(2026, 3, 4, 22, 0, 0, 0, loc).UTC())}
+	filter := &fModel.Filter{
+		Operator:   fModel.LogicalOperatorAND,
+		Conditions: fModel.FilterConditions{{Type: "dueDate", Operator: "isDueToday"}},
+	}
+
+	got, err := MatchChore(filter, chore, EvalContext{Now: now.UTC(), Location: loc})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !got {
+		t.Errorf("expected chore to be due today in the user's timezone")
+	}
+}
+
+func TestMatchChoreRejectsUnknownOperator(t *testing.T) {
+	filter := &fModel.Filter{
+		Operator:   fModel.LogicalOperatorAND,
+		Conditions: fModel.FilterConditions{{Type: "priority", Operator: "near", Value: float64(1)}},
+	}
+	if _, err := MatchChore(filter, &chModel.Chore{}, EvalContext{Now: time.Now().UTC()}); err == nil {
+		t.Error("expected an error for an unsupported operator")
+	}
+}
@@ -2,20 +2,32 @@ package filter
 
 import (
 	"strconv"
+	"time"
 
 	auth "donetick.com/core/internal/auth"
+	chModel "donetick.com/core/internal/chore/model"
+	chRepo "donetick.com/core/internal/chore/repo"
+	cRepo "donetick.com/core/internal/circle/repo"
 	fModel "donetick.com/core/internal/filter/model"
 	fRepo "donetick.com/core/internal/filter/repo"
+	"donetick.com/core/internal/realtime"
+	"donetick.com/core/logging"
 	"github.com/gin-gonic/gin"
 )
 
 type Handler struct {
-	fRepo *fRepo.FilterRepository
+	fRepo           *fRepo.FilterRepository
+	choreRepo       *chRepo.ChoreRepository
+	circleRepo      *cRepo.CircleRepository
+	realTimeService *realtime.RealTimeService
 }
 
-func NewHandler(fRepo *fRepo.FilterRepository) *Handler {
+func NewHandler(fRepo *fRepo.FilterRepository, choreRepo *chRepo.ChoreRepository, circleRepo *cRepo.CircleRepository, rts *realtime.RealTimeService) *Handler {
 	return &Handler{
-		fRepo: fRepo,
+		fRepo:           fRepo,
+		choreRepo:       choreRepo,
+		circleRepo:      circleRepo,
+		realTimeService: rts,
 	}
 }
 
@@ -102,6 +114,105 @@ func (h *Handler) getFilterByID(c *gin.Context) {
 	c.JSON(200, filter)
 }
 
+// getFilterChores godoc
+//
+//	@Summary		Get chores matching a filter
+//	@Description	Evaluates the filter's conditions against the chores of the current user's circle and returns the matching chores the user can view
+//	@Tags			filters
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWTKeyAuth
+//	@Security		APIKeyAuth
+//	@Param			id				path		int							true	"Filter ID"
+//	@Param			timezone		query		string						false	"IANA timezone for relative due date conditions, defaults to the user's timezone"
+//	@Param			includeArchived	query		bool						false	"Include archived chores"
+//	@Success		200				{object}	map[string][]chModel.Chore	"res: array of matching chores"
+//	@Failure		400				{object}	map[string]string			"error: Invalid filter ID | Invalid timezone | Invalid filter conditions"
+//	@Failure		404				{object}	map[string]string			"error: Filter not found"
+//	@Failure		500				{object}	map[string]string			"error: Error getting current user | Error getting chores"
+//	@Router			/filters/{id}/chores [get]
+func (h *Handler) getFilterChores(c *gin.Context) {
+	log := logging.FromContext(c)
+	currentUser, ok := auth.CurrentUser(c)
+	if !ok {
+		c.JSON(500, gin.H{
+			"error": "Error getting current user",
+		})
+		return
+	}
+
+	filterID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{
+			"error": "Invalid filter ID",
+		})
+		return
+	}
+
+	filter, err := h.fRepo.GetFilterByID(c, filterID, currentUser.CircleID)
+	if err != nil {
+		c.JSON(404, gin.H{
+			"error": "Filter not found",
+		})
+		return
+	}
+
+	timezone := c.Query("timezone")
+	if timezone == "" {
+		timezone = currentUser.Timezone
+	}
+	loc := time.UTC
+	if timezone != "" {
+		loc, err = time.LoadLocation(timezone)
+		if err != nil {
+			c.JSON(400, gin.H{
+				"error": "Invalid timezone",
+			})
+			return
+		}
+	}
+
+	chores, err := h.choreRepo.GetChores(c, currentUser.CircleID, currentUser.ID, c.Query("includeArchived") == "true")
+	if err != nil {
+		log.Errorw("Failed to get chores for filter", "error", err, "filterID", filterID)
+		c.JSON(500, gin.H{
+			"error": "Error getting chores",
+		})
+		return
+	}
+	circleUsers, err := h.circleRepo.GetCircleUsers(c, currentUser.CircleID)
+	if err != nil {
+		log.Errorw("Failed to get circle users for filter", "error", err, "filterID", filterID)
+		c.JSON(500, gin.H{
+			"error": "Error getting chores",
+		})
+		return
+	}
+
+	visible := make([]*chModel.Chore, 0, len(chores))
+	for _, chore := range chores {
+		if chore.CanView(currentUser.ID, circleUsers) {
+			visible = append(visible, chore)
+		}
+	}
+
+	matched, err := MatchChores(filter, visible, EvalContext{
+		UserID:   currentUser.ID,
+		Now:      time.Now().UTC(),
+		Location: loc,
+	})
+	if err != nil {
+		c.JSON(400, gin.H{
+			"error": "Invalid filter conditions: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"res": matched,
+	})
+}
+
 // createFilter godoc
 //
 //	@Summary		Create a new filter
@@ -180,6 +291,10 @@ func (h *Handler) createFilter(c *gin.Context) {
 		return
 	}
 
+	if h.realTimeService != nil {
+		h.realTimeService.GetEventBroadcaster().BroadcastFilterEvent(realtime.EventTypeFilterCreated, currentUser.CircleID, filter.ID, filter, &currentUser.User)
+	}
+
 	c.JSON(200, gin.H{
 		"res": filter,
 	})
@@ -287,6 +402,10 @@ func (h *Handler) updateFilter(c *gin.Context) {
 		return
 	}
 
+	if h.realTimeService != nil {
+		h.realTimeService.GetEventBroadcaster().BroadcastFilterEvent(realtime.EventTypeFilterUpdated, currentUser.CircleID, filterID, updatedFilter, &currentUser.User)
+	}
+
 	c.JSON(200, gin.H{
 		"res": updatedFilter,
 	})
@@ -338,6 +457,10 @@ func (h *Handler) deleteFilter(c *gin.Context) {
 		return
 	}
 
+	if h.realTimeService != nil {
+		h.realTimeService.GetEventBroadcaster().BroadcastFilterEvent(realtime.EventTypeFilterDeleted, currentUser.CircleID, filterID, nil, &currentUser.User)
+	}
+
 	c.JSON(200, gin.H{
 		"res": "Filter deleted successfully",
 	})
@@ -390,6 +513,12 @@ func (h *Handler) toggleFilterPin(c *gin.Context) {
 		return
 	}
 
+	if h.realTimeService != nil {
+		if updatedFilter, err := h.fRepo.GetFilterByID(c, filterID, currentUser.CircleID); err == nil {
+			h.realTimeService.GetEventBroadcaster().BroadcastFilterEvent(realtime.EventTypeFilterUpdated, currentUser.CircleID, filterID, updatedFilter, &currentUser.User)
+		}
+	}
+
 	c.JSON(200, gin.H{
 		"res": gin.H{
 			"isPinned": isPinned,
@@ -470,6 +599,7 @@ func Routes(r *gin.Engine, h *Handler, multiAuthMiddleware *auth.MultiAuthMiddle
 		filterRoutes.GET("/pinned", h.getPinnedFilters)
 		filterRoutes.GET("/by-usage", h.getFiltersByUsage)
 		filterRoutes.GET("/:id", h.getFilterByID)
+		filterRoutes.GET("/:id/chores", h.getFilterChores)
 		filterRoutes.POST("", h.createFilter)
 		filterRoutes.PUT("/:id", h.updateFilter)
 		filterRoutes.DELETE("/:id", h.deleteFilter)
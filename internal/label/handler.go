@@ -6,6 +6,7 @@ import (
 	auth "donetick.com/core/internal/auth"
 	lModel "donetick.com/core/internal/label/model"
 	lRepo "donetick.com/core/internal/label/repo"
+	"donetick.com/core/internal/realtime"
 	jwt "github.com/appleboy/gin-jwt/v2"
 	"github.com/gin-gonic/gin"
 )
@@ -21,12 +22,14 @@ type UpdateLabelReq struct {
 }
 
 type Handler struct {
-	lRepo *lRepo.LabelRepository
+	lRepo           *lRepo.LabelRepository
+	realTimeService *realtime.RealTimeService
 }
 
-func NewHandler(lRepo *lRepo.LabelRepository) *Handler {
+func NewHandler(lRepo *lRepo.LabelRepository, rts *realtime.RealTimeService) *Handler {
 	return &Handler{
-		lRepo: lRepo,
+		lRepo:           lRepo,
+		realTimeService: rts,
 	}
 }
 
@@ -83,6 +86,10 @@ func (h *Handler) createLabel(c *gin.Context) {
 		return
 	}
 
+	if h.realTimeService != nil {
+		h.realTimeService.GetEventBroadcaster().BroadcastLabelEvent(realtime.EventTypeLabelCreated, currentUser.CircleID, label.ID, label, &currentUser.User)
+	}
+
 	c.JSON(200, gin.H{
 		"res": label,
 	})
@@ -118,6 +125,10 @@ func (h *Handler) updateLabel(c *gin.Context) {
 		return
 	}
 
+	if h.realTimeService != nil {
+		h.realTimeService.GetEventBroadcaster().BroadcastLabelEvent(realtime.EventTypeLabelUpdated, currentUser.CircleID, label.ID, label, &currentUser.User)
+	}
+
 	c.JSON(200, gin.H{
 		"res": label,
 	})
@@ -158,6 +169,10 @@ func (h *Handler) deleteLabel(c *gin.Context) {
 		return
 	}
 
+	if h.realTimeService != nil {
+		h.realTimeService.GetEventBroadcaster().BroadcastLabelEvent(realtime.EventTypeLabelDeleted, currentUser.CircleID, labelID, nil, &currentUser.User)
+	}
+
 	c.JSON(200, gin.H{
 		"res": "Label deleted",
 	})
@@ -0,0 +1,612 @@
+package notifier
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"donetick.com/core/internal/auth"
+	cRepo "donetick.com/core/internal/circle/repo"
+	nModel "donetick.com/core/internal/notifier/model"
+	nRepo "donetick.com/core/internal/notifier/repo"
+	nps "donetick.com/core/internal/notifier/service"
+	"donetick.com/core/internal/notifier/service/message"
+	"donetick.com/core/logging"
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	notificationRepo *nRepo.NotificationRepository
+	circleRepo       *cRepo.CircleRepository
+}
+
+func NewHandler(nr *nRepo.NotificationRepository, cr *cRepo.CircleRepository) *Handler {
+	return &Handler{
+		notificationRepo: nr,
+		circleRepo:       cr,
+	}
+}
+
+type settingsReq struct {
+	QuietHoursEnabled bool   `json:"quietHoursEnabled"`
+	QuietHoursStart   string `json:"quietHoursStart"`
+	QuietHoursEnd     string `json:"quietHoursEnd"`
+	DigestEnabled     bool   `json:"digestEnabled"`
+	DigestTime        string `json:"digestTime"`
+}
+
+// getSettings godoc
+//
+//	@Summary		Get notification settings
+//	@Description	Retrieves the current user's quiet hours and daily digest settings
+//	@Tags			notifications
+//	@Produce		json
+//	@Security		JWTKeyAuth
+//	@Security		APIKeyAuth
+//	@Success		200	{object}	map[string]nModel.NotificationSettings	"res: the notification settings"
+//	@Failure		500	{object}	map[string]string						"error: Failed to get notification settings"
+//	@Router			/notifications/settings [get]
+func (h *Handler) getSettings(c *gin.Context) {
+	log := logging.FromContext(c)
+	currentUser, ok := auth.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get current user"})
+		return
+	}
+
+	settings, err := h.notificationRepo.GetNotificationSettings(c, currentUser.ID)
+	if err != nil {
+		log.Errorw("Failed to get notification settings", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get notification settings"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"res": settings})
+}
+
+// updateSettings godoc
+//
+//	@Summary		Update notification settings
+//	@Description	Sets the current user's quiet hours and daily digest. Times are HH:MM in the user's timezone. Reminders due during quiet hours are delivered when they end; with the digest enabled the day's reminders are sent as one message at the digest time
+//	@Tags			notifications
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWTKeyAuth
+//	@Security		APIKeyAuth
+//	@Param			settings	body		settingsReq								true	"Notification settings"
+//	@Success		200			{object}	map[string]nModel.NotificationSettings	"res: the updated settings"
+//	@Failure		400			{object}	map[string]string						"error: Invalid request"
+//	@Failure		500			{object}	map[string]string						"error: Failed to save notification settings"
+//	@Router			/notifications/settings [put]
+func (h *Handler) updateSettings(c *gin.Context) {
+	log := logging.FromContext(c)
+	currentUser, ok := auth.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get current user"})
+		return
+	}
+
+	var req settingsReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	settings, err := h.notificationRepo.GetNotificationSettings(c, currentUser.ID)
+	if err != nil {
+		log.Errorw("Failed to get notification settings", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get notification settings"})
+		return
+	}
+	if req.DigestEnabled && (!settings.DigestEnabled || settings.DigestTime != req.DigestTime) {
+		// the first digest goes out at the next digest time rather than right away
+		now := time.Now().UTC()
+		settings.LastDigestAt = &now
+		settings.DigestAttempts = 0
+		settings.DigestRetryAt = nil
+	}
+	settings.QuietHoursEnabled = req.QuietHoursEnabled
+	settings.QuietHoursStart = req.QuietHoursStart
+	settings.QuietHoursEnd = req.QuietHoursEnd
+	settings.DigestEnabled = req.DigestEnabled
+	settings.DigestTime = req.DigestTime
+	if err := settings.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.notificationRepo.SaveNotificationSettings(c, settings); err != nil {
+		log.Errorw("Failed to save notification settings", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save notification settings"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"res": settings})
+}
+
+type ruleReq struct {
+	Name       string                `json:"name"`
+	TargetID   int                   `json:"targetId" binding:"required"`
+	Conditions nModel.RuleConditions `json:"conditions"`
+}
+
+// routableEventTypes are the event types rules can match
+var routableEventTypes = []nps.EventType{
+	nps.EventTypePreDue,
+	nps.EventTypeDue,
+	nps.EventTypeOverdue,
+	nps.EventTypeNagging,
+	nps.EventTypeEscalation,
+	nps.EventTypeCompleted,
+	nps.EventTypeNudge,
+	nps.EventTypeApproval,
+}
+
+func (req *ruleReq) validate() error {
+	for _, eventType := range req.Conditions.EventTypes {
+		valid := false
+		for _, routable := range routableEventTypes {
+			if nps.EventType(eventType) == routable {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("unknown event type %q", eventType)
+		}
+	}
+	for _, priority := range req.Conditions.Priorities {
+		if priority < 0 || priority > 4 {
+			return fmt.Errorf("priority must be between 0 and 4, got %d", priority)
+		}
+	}
+	return nil
+}
+
+// bindRule reads and validates a rule request, writing the error response when it fails
+func (h *Handler) bindRule(c *gin.Context, userID int) (*ruleReq, bool) {
+	var req ruleReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return nil, false
+	}
+	if err := req.validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	exists, err := h.notificationRepo.NotificationTargetExists(c, userID, req.TargetID)
+	if err != nil {
+		logging.FromContext(c).Errorw("Failed to get notification target", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get notification target"})
+		return nil, false
+	}
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Notification target not found"})
+		return nil, false
+	}
+	return &req, true
+}
+
+// getRules godoc
+//
+//	@Summary		List notification routing rules
+//	@Description	Retrieves the current user's rules routing notifications to their targets
+//	@Tags			notifications
+//	@Produce		json
+//	@Security		JWTKeyAuth
+//	@Security		APIKeyAuth
+//	@Success		200	{object}	map[string][]nModel.NotificationRule	"res: the routing rules"
+//	@Failure		500	{object}	map[string]string						"error: Failed to get notification rules"
+//	@Router			/notifications/rules [get]
+func (h *Handler) getRules(c *gin.Context) {
+	currentUser, ok := auth.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get current user"})
+		return
+	}
+	rules, err := h.notificationRepo.GetNotificationRules(c, currentUser.ID)
+	if err != nil {
+		logging.FromContext(c).Errorw("Failed to get notification rules", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get notification rules"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"res": rules})
+}
+
+// createRule godoc
+//
+//	@Summary		Create a notification routing rule
+//	@Description	Routes the current user's notifications matching all conditions (event types, chore priorities, labels) to one of their targets. Notifications matching several rules go to each rule's target, the rest go to the primary target. Nudge and approval notifications are only sent to targets with a rule for them
+//	@Tags			notifications
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWTKeyAuth
+//	@Security		APIKeyAuth
+//	@Param			rule	body		ruleReq								true	"Routing rule"
+//	@Success		200		{object}	map[string]nModel.NotificationRule	"res: the created rule"
+//	@Failure		400		{object}	map[string]string					"error: Invalid request"
+//	@Failure		500		{object}	map[string]string					"error: Failed to save notification rule"
+//	@Router			/notifications/rules [post]
+func (h *Handler) createRule(c *gin.Context) {
+	currentUser, ok := auth.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get current user"})
+		return
+	}
+	req, ok := h.bindRule(c, currentUser.ID)
+	if !ok {
+		return
+	}
+	rule := &nModel.NotificationRule{
+		UserID:               currentUser.ID,
+		Name:                 req.Name,
+		NotificationTargetID: req.TargetID,
+		Conditions:           req.Conditions,
+	}
+	if err := h.notificationRepo.SaveNotificationRule(c, rule); err != nil {
+		logging.FromContext(c).Errorw("Failed to save notification rule", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save notification rule"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"res": rule})
+}
+
+// updateRule godoc
+//
+//	@Summary		Update a notification routing rule
+//	@Tags			notifications
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWTKeyAuth
+//	@Security		APIKeyAuth
+//	@Param			id		path		int									true	"Rule ID"
+//	@Param			rule	body		ruleReq								true	"Routing rule"
+//	@Success		200		{object}	map[string]nModel.NotificationRule	"res: the updated rule"
+//	@Failure		400		{object}	map[string]string					"error: Invalid request"
+//	@Failure		404		{object}	map[string]string					"error: Notification rule not found"
+//	@Router			/notifications/rules/{id} [put]
+func (h *Handler) updateRule(c *gin.Context) {
+	currentUser, ok := auth.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get current user"})
+		return
+	}
+	ruleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rule ID"})
+		return
+	}
+	rule, err := h.notificationRepo.GetNotificationRule(c, currentUser.ID, ruleID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notification rule not found"})
+		return
+	}
+	req, ok := h.bindRule(c, currentUser.ID)
+	if !ok {
+		return
+	}
+	rule.Name = req.Name
+	rule.NotificationTargetID = req.TargetID
+	rule.Conditions = req.Conditions
+	if err := h.notificationRepo.SaveNotificationRule(c, rule); err != nil {
+		logging.FromContext(c).Errorw("Failed to save notification rule", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save notification rule"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"res": rule})
+}
+
+// deleteRule godoc
+//
+//	@Summary		Delete a notification routing rule
+//	@Tags			notifications
+//	@Security		JWTKeyAuth
+//	@Security		APIKeyAuth
+//	@Param			id	path	int	true	"Rule ID"
+//	@Success		200
+//	@Failure		400	{object}	map[string]string	"error: Invalid rule ID"
+//	@Failure		500	{object}	map[string]string	"error: Failed to delete notification rule"
+//	@Router			/notifications/rules/{id} [delete]
+func (h *Handler) deleteRule(c *gin.Context) {
+	currentUser, ok := auth.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get current user"})
+		return
+	}
+	ruleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rule ID"})
+		return
+	}
+	if err := h.notificationRepo.DeleteNotificationRule(c, currentUser.ID, ruleID); err != nil {
+		logging.FromContext(c).Errorw("Failed to delete notification rule", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete notification rule"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+const (
+	defaultFailureDays = 7
+	maxFailureDays     = 30
+	maxFailures        = 100
+)
+
+// getDeliveryFailures godoc
+//
+//	@Summary		List notification delivery failures
+//	@Description	Retrieves the current user's recent failed notification deliveries, latest first. Failed notifications are retried with backoff; the last attempt is marked as a dead letter
+//	@Tags			notifications
+//	@Produce		json
+//	@Security		JWTKeyAuth
+//	@Security		APIKeyAuth
+//	@Param			days	query		int										false	"How many days back to look, 7 by default and at most 30"
+//	@Success		200		{object}	map[string][]nModel.DeliveryFailure	"res: the delivery failures"
+//	@Failure		400		{object}	map[string]string						"error: Invalid days"
+//	@Failure		500		{object}	map[string]string						"error: Failed to get delivery failures"
+//	@Router			/notifications/failures [get]
+func (h *Handler) getDeliveryFailures(c *gin.Context) {
+	currentUser, ok := auth.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get current user"})
+		return
+	}
+	days := defaultFailureDays
+	if rawDays := c.Query("days"); rawDays != "" {
+		parsed, err := strconv.Atoi(rawDays)
+		if err != nil || parsed < 1 || parsed > maxFailureDays {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("days must be between 1 and %d", maxFailureDays)})
+			return
+		}
+		days = parsed
+	}
+
+	since := time.Now().UTC().AddDate(0, 0, -days)
+	failures, err := h.notificationRepo.GetDeliveryFailures(c, currentUser.ID, since, maxFailures)
+	if err != nil {
+		logging.FromContext(c).Errorw("Failed to get delivery failures", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get delivery failures"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"res": failures})
+}
+
+type messageTemplateRes struct {
+	EventType string `json:"eventType"`
+	Body      string `json:"body"`
+	// the built-in template in the current user's language
+	Default  string `json:"default"`
+	IsCustom bool   `json:"isCustom"`
+}
+
+// getMessageTemplates godoc
+//
+//	@Summary		List notification message templates
+//	@Description	Retrieves the message template of each event type for the current user's circle, with the built-in template used when the circle has none. Templates use Go text/template syntax over the notification's raw event fields, e.g. {{bold .name}} is due {{date .due_date}} at {{time .due_date}}
+//	@Tags			notifications
+//	@Produce		json
+//	@Security		JWTKeyAuth
+//	@Security		APIKeyAuth
+//	@Success		200	{object}	map[string][]messageTemplateRes	"res: the templates"
+//	@Failure		500	{object}	map[string]string				"error: Failed to get message templates"
+//	@Router			/notifications/templates [get]
+func (h *Handler) getMessageTemplates(c *gin.Context) {
+	currentUser, ok := auth.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get current user"})
+		return
+	}
+	templates, err := h.notificationRepo.GetCircleMessageTemplates(c, currentUser.CircleID)
+	if err != nil {
+		logging.FromContext(c).Errorw("Failed to get message templates", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get message templates"})
+		return
+	}
+	custom := make(map[string]string, len(templates))
+	for _, template := range templates {
+		custom[template.EventType] = template.Body
+	}
+
+	res := make([]messageTemplateRes, 0)
+	for _, eventType := range message.EventTypes() {
+		builtin := message.BuiltinTemplate(currentUser.Locale, eventType)
+		body, isCustom := custom[eventType]
+		if !isCustom {
+			body = builtin
+		}
+		res = append(res, messageTemplateRes{EventType: eventType, Body: body, Default: builtin, IsCustom: isCustom})
+	}
+	c.JSON(http.StatusOK, gin.H{"res": res})
+}
+
+// requireCircleManager responds with an error unless the current user manages their circle
+func (h *Handler) requireCircleManager(c *gin.Context, userID, circleID int) bool {
+	members, err := h.circleRepo.GetCircleUsers(c, circleID)
+	if err != nil {
+		logging.FromContext(c).Errorw("Failed to get circle members", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get circle members"})
+		return false
+	}
+	for _, member := range members {
+		if member.UserID == userID && member.IsActive && member.IsManagerOrAdmin() {
+			return true
+		}
+	}
+	c.JSON(http.StatusForbidden, gin.H{"error": "Only circle admins and managers can change message templates"})
+	return false
+}
+
+type messageTemplateReq struct {
+	Body string `json:"body" binding:"required"`
+}
+
+// updateMessageTemplate godoc
+//
+//	@Summary		Set a notification message template
+//	@Description	Replaces the built-in message of the event type for everyone in the current user's circle. Available to circle admins and managers
+//	@Tags			notifications
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWTKeyAuth
+//	@Security		APIKeyAuth
+//	@Param			eventType	path		string								true	"Event type, e.g. due or overdue"
+//	@Param			template	body		messageTemplateReq					true	"Template"
+//	@Success		200			{object}	map[string]nModel.MessageTemplate	"res: the saved template"
+//	@Failure		400			{object}	map[string]string					"error: Invalid template"
+//	@Failure		403			{object}	map[string]string					"error: Only circle admins and managers can change message templates"
+//	@Failure		500			{object}	map[string]string					"error: Failed to save message template"
+//	@Router			/notifications/templates/{eventType} [put]
+func (h *Handler) updateMessageTemplate(c *gin.Context) {
+	currentUser, ok := auth.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get current user"})
+		return
+	}
+	eventType := c.Param("eventType")
+	if !message.Templated(eventType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported event type"})
+		return
+	}
+	var req messageTemplateReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	if err := message.Validate(req.Body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template: " + err.Error()})
+		return
+	}
+	if !h.requireCircleManager(c, currentUser.ID, currentUser.CircleID) {
+		return
+	}
+
+	template := &nModel.MessageTemplate{
+		CircleID:  currentUser.CircleID,
+		EventType: eventType,
+		Body:      req.Body,
+		UpdatedBy: currentUser.ID,
+	}
+	if err := h.notificationRepo.SaveMessageTemplate(c, template); err != nil {
+		logging.FromContext(c).Errorw("Failed to save message template", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save message template"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"res": template})
+}
+
+// deleteMessageTemplate godoc
+//
+//	@Summary		Reset a notification message template
+//	@Description	Removes the circle's template of the event type, so the built-in message is sent again. Available to circle admins and managers
+//	@Tags			notifications
+//	@Produce		json
+//	@Security		JWTKeyAuth
+//	@Security		APIKeyAuth
+//	@Param			eventType	path		string				true	"Event type, e.g. due or overdue"
+//	@Success		200			{object}	map[string]string	"empty response"
+//	@Failure		403			{object}	map[string]string	"error: Only circle admins and managers can change message templates"
+//	@Failure		500			{object}	map[string]string	"error: Failed to delete message template"
+//	@Router			/notifications/templates/{eventType} [delete]
+func (h *Handler) deleteMessageTemplate(c *gin.Context) {
+	currentUser, ok := auth.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get current user"})
+		return
+	}
+	if !h.requireCircleManager(c, currentUser.ID, currentUser.CircleID) {
+		return
+	}
+	if err := h.notificationRepo.DeleteMessageTemplate(c, currentUser.CircleID, c.Param("eventType")); err != nil {
+		logging.FromContext(c).Errorw("Failed to delete message template", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete message template"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+type previewReq struct {
+	EventType string `json:"eventType" binding:"required"`
+	// the template to preview, the circle's or the built-in one when empty
+	Body     string                      `json:"body"`
+	Locale   string                      `json:"locale"`
+	Platform nModel.NotificationPlatform `json:"platform"`
+}
+
+// previewMessageTemplate godoc
+//
+//	@Summary		Preview a notification message template
+//	@Description	Renders a template with sample chore data as it would be sent on the platform, in the given locale or the current user's one and in their timezone
+//	@Tags			notifications
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWTKeyAuth
+//	@Security		APIKeyAuth
+//	@Param			preview	body		previewReq			true	"Template to preview"
+//	@Success		200		{object}	map[string]string	"res: the rendered message"
+//	@Failure		400		{object}	map[string]string	"error: Invalid template"
+//	@Failure		500		{object}	map[string]string	"error: Failed to get message templates"
+//	@Router			/notifications/templates/preview [post]
+func (h *Handler) previewMessageTemplate(c *gin.Context) {
+	currentUser, ok := auth.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get current user"})
+		return
+	}
+	var req previewReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	if !message.Templated(req.EventType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported event type"})
+		return
+	}
+	locale := currentUser.Locale
+	if req.Locale != "" {
+		locale = req.Locale
+	}
+
+	body := req.Body
+	if body != "" {
+		if err := message.Validate(body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template: " + err.Error()})
+			return
+		}
+	} else {
+		templates, err := h.notificationRepo.GetMessageTemplates(c, []int{currentUser.CircleID})
+		if err != nil {
+			logging.FromContext(c).Errorw("Failed to get message templates", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get message templates"})
+			return
+		}
+		body = templates[currentUser.CircleID][req.EventType]
+	}
+
+	loc, err := time.LoadLocation(currentUser.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	text, err := message.Preview(req.EventType, body, locale, message.FormatFor(req.Platform), loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"res": text})
+}
+
+func Routes(r *gin.Engine, h *Handler, multiAuthMiddleware *auth.MultiAuthMiddleware) {
+	notificationRoutes := r.Group("api/v1/notifications")
+	notificationRoutes.Use(multiAuthMiddleware.MiddlewareFunc())
+	{
+		notificationRoutes.GET("/settings", h.getSettings)
+		notificationRoutes.PUT("/settings", h.updateSettings)
+		notificationRoutes.GET("/rules", h.getRules)
+		notificationRoutes.POST("/rules", h.createRule)
+		notificationRoutes.PUT("/rules/:id", h.updateRule)
+		notificationRoutes.DELETE("/rules/:id", h.deleteRule)
+		notificationRoutes.GET("/failures", h.getDeliveryFailures)
+		notificationRoutes.GET("/templates", h.getMessageTemplates)
+		notificationRoutes.POST("/templates/preview", h.previewMessageTemplate)
+		notificationRoutes.PUT("/templates/:eventType", h.updateMessageTemplate)
+		notificationRoutes.DELETE("/templates/:eventType", h.deleteMessageTemplate)
+	}
+}
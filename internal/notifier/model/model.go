@@ -19,11 +19,38 @@ type Notification struct {
 	ScheduledFor time.Time            `json:"scheduled_for" gorm:"column:scheduled_for;index"`
 	CreatedAt    time.Time            `json:"created_at" gorm:"column:created_at"`
 	RawEvent     JSONB                `json:"raw_event" gorm:"column:raw_event;type:jsonb"`
+	// the user's notification target the notification is routed to, nil for circle group notifications
+	NotificationTargetID *int `json:"notification_target_id,omitempty" gorm:"column:notification_target_id"`
+	// failed deliveries are retried with backoff, a dead letter is no longer retried
+	Attempts     int     `json:"attempts" gorm:"column:attempts;default:0"`
+	LastError    *string `json:"last_error,omitempty" gorm:"column:last_error"`
+	IsDeadLetter bool    `json:"is_dead_letter" gorm:"column:is_dead_letter;index;default:false"`
+}
+
+// DeliveryFailure records a failed attempt to deliver a notification. Failures are kept apart from
+// notifications, which are deleted whenever a chore's notifications are regenerated.
+type DeliveryFailure struct {
+	ID                   int                  `json:"id" gorm:"primaryKey"`
+	NotificationID       int                  `json:"notificationId" gorm:"column:notification_id;index"`
+	ChoreID              int                  `json:"choreId" gorm:"column:chore_id"`
+	UserID               int                  `json:"userId" gorm:"column:user_id;index"`
+	NotificationTargetID *int                 `json:"targetId,omitempty" gorm:"column:notification_target_id"`
+	TypeID               NotificationPlatform `json:"type" gorm:"column:type"`
+	TargetID             string               `json:"target" gorm:"column:target_id"`
+	Attempt              int                  `json:"attempt" gorm:"column:attempt"`
+	Error                string               `json:"error" gorm:"column:error"`
+	DeadLetter           bool                 `json:"deadLetter" gorm:"column:dead_letter"` // the last attempt, no retry follows
+	AttemptedAt          time.Time            `json:"attemptedAt" gorm:"column:attempted_at;index"`
 }
 type NotificationDetails struct {
 	Notification
 	WebhookURL *string `json:"webhook_url" gorm:"column:webhook_url;<-:null"` // read-only, will only be used if webhook enabled
-
+	// read-only, custom headers and body template of the user's webhook notification target
+	WebhookHeaders      WebhookHeaders `json:"-" gorm:"column:webhook_headers;type:text;<-:null"`
+	WebhookBodyTemplate *string        `json:"-" gorm:"column:webhook_body_template;<-:null"`
+	// read-only, the recipient's language and timezone the text is rendered in
+	Locale   string `json:"-" gorm:"column:locale;<-:null"`
+	Timezone string `json:"-" gorm:"column:timezone;<-:null"`
 }
 
 func (n *Notification) IsValid() bool {
@@ -39,6 +66,9 @@ const (
 	NotificationPlatformWebhook
 	NotificationPlatformDiscord
 	NotificationPlatformFCM
+	NotificationPlatformEmail
+	// sent to every browser the user subscribed to push notifications
+	NotificationPlatformWebPush
 )
 
 type JSONB map[string]interface{}
@@ -61,3 +91,255 @@ func (j *JSONB) Scan(value interface{}) error {
 		return errors.New("type assertion to []byte or string failed")
 	}
 }
+
+// WebhookHeaders are custom headers sent with webhook notifications, e.g. an Authorization token
+type WebhookHeaders map[string]string
+
+func (h WebhookHeaders) Value() (driver.Value, error) {
+	if h == nil {
+		return nil, nil
+	}
+	value, err := json.Marshal(h)
+	if err != nil {
+		return nil, err
+	}
+	return string(value), nil
+}
+
+func (h *WebhookHeaders) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*h = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, h)
+	case string:
+		return json.Unmarshal([]byte(v), h)
+	default:
+		return errors.New("type assertion to []byte or string failed")
+	}
+}
+
+// NotificationSettings are a user's delivery preferences. Times are "15:04" in the user's timezone.
+type NotificationSettings struct {
+	UserID            int        `json:"userId" gorm:"column:user_id;primaryKey"`
+	QuietHoursEnabled bool       `json:"quietHoursEnabled" gorm:"column:quiet_hours_enabled;default:false"`
+	QuietHoursStart   string     `json:"quietHoursStart" gorm:"column:quiet_hours_start"`
+	QuietHoursEnd     string     `json:"quietHoursEnd" gorm:"column:quiet_hours_end"`
+	DigestEnabled     bool       `json:"digestEnabled" gorm:"column:digest_enabled;index;default:false"`
+	DigestTime        string     `json:"digestTime" gorm:"column:digest_time"`
+	LastDigestAt      *time.Time `json:"lastDigestAt" gorm:"column:last_digest_at"`
+	// failed attempts at today's digest, it isn't retried before DigestRetryAt
+	DigestAttempts int        `json:"-" gorm:"column:digest_attempts;default:0"`
+	DigestRetryAt  *time.Time `json:"-" gorm:"column:digest_retry_at"`
+	UpdatedAt      time.Time  `json:"updatedAt" gorm:"column:updated_at"`
+}
+
+// NotificationSettingsDetails adds the user's timezone and notification target to their settings
+type NotificationSettingsDetails struct {
+	NotificationSettings
+	Timezone            string               `gorm:"column:timezone;<-:false"`
+	CircleID            int                  `gorm:"column:circle_id;<-:false"`
+	TargetRowID         *int                 `gorm:"column:target_row_id;<-:false"` // ID of the primary target, nil without one
+	TargetType          NotificationPlatform `gorm:"column:target_type;<-:false"`
+	TargetID            string               `gorm:"column:target_id;<-:false"`
+	WebhookHeaders      WebhookHeaders       `gorm:"column:webhook_headers;type:text;<-:false"`
+	WebhookBodyTemplate *string              `gorm:"column:webhook_body_template;<-:false"`
+}
+
+const clockLayout = "15:04"
+
+func (s *NotificationSettings) Validate() error {
+	if s.QuietHoursEnabled {
+		start, err := time.Parse(clockLayout, s.QuietHoursStart)
+		if err != nil {
+			return errors.New("quiet hours start must be in HH:MM format")
+		}
+		end, err := time.Parse(clockLayout, s.QuietHoursEnd)
+		if err != nil {
+			return errors.New("quiet hours end must be in HH:MM format")
+		}
+		if start.Equal(end) {
+			return errors.New("quiet hours start and end must differ")
+		}
+	}
+	if s.DigestEnabled {
+		if _, err := time.Parse(clockLayout, s.DigestTime); err != nil {
+			return errors.New("digest time must be in HH:MM format")
+		}
+	}
+	return nil
+}
+
+// Location returns the user's timezone, falling back to UTC when it is unset or unknown
+func (s *NotificationSettingsDetails) Location() *time.Location {
+	if s.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// QuietUntil returns when the quiet hours containing now end. Windows may cross midnight,
+// e.g. 22:00-07:00.
+func (s *NotificationSettings) QuietUntil(now time.Time, loc *time.Location) (time.Time, bool) {
+	if !s.QuietHoursEnabled {
+		return time.Time{}, false
+	}
+	local := now.In(loc)
+	start, ok := clockOn(local, s.QuietHoursStart)
+	if !ok {
+		return time.Time{}, false
+	}
+	end, ok := clockOn(local, s.QuietHoursEnd)
+	if !ok || start.Equal(end) {
+		return time.Time{}, false
+	}
+
+	if start.Before(end) {
+		if !local.Before(start) && local.Before(end) {
+			return end.UTC(), true
+		}
+		return time.Time{}, false
+	}
+	if local.Before(end) {
+		return end.UTC(), true
+	}
+	if !local.Before(start) {
+		tomorrow, _ := clockOn(local.AddDate(0, 0, 1), s.QuietHoursEnd)
+		return tomorrow.UTC(), true
+	}
+	return time.Time{}, false
+}
+
+// DigestDue reports whether today's digest should be sent, returning its scheduled time
+func (s *NotificationSettings) DigestDue(now time.Time, loc *time.Location) (time.Time, bool) {
+	if !s.DigestEnabled {
+		return time.Time{}, false
+	}
+	digestAt, ok := clockOn(now.In(loc), s.DigestTime)
+	if !ok || now.Before(digestAt) {
+		return time.Time{}, false
+	}
+	if s.LastDigestAt != nil && !s.LastDigestAt.Before(digestAt) {
+		return time.Time{}, false
+	}
+	if s.DigestRetryAt != nil && now.Before(*s.DigestRetryAt) {
+		return time.Time{}, false
+	}
+	return digestAt.UTC(), true
+}
+
+// clockOn returns the wall clock time on the day of t, in t's location
+func clockOn(t time.Time, clock string) (time.Time, bool) {
+	parsed, err := time.Parse(clockLayout, clock)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), parsed.Hour(), parsed.Minute(), 0, 0, t.Location()), true
+}
+
+// MessageTemplate replaces the built-in text of a circle's notifications of one event type. The
+// body is a Go text/template over the notification's raw event.
+type MessageTemplate struct {
+	ID        int       `json:"id" gorm:"primaryKey"`
+	CircleID  int       `json:"circleId" gorm:"column:circle_id;uniqueIndex:idx_message_template_event"`
+	EventType string    `json:"eventType" gorm:"column:event_type;uniqueIndex:idx_message_template_event"`
+	Body      string    `json:"body" gorm:"column:body;type:text"`
+	UpdatedBy int       `json:"updatedBy" gorm:"column:updated_by"`
+	UpdatedAt time.Time `json:"updatedAt" gorm:"column:updated_at"`
+}
+
+// NotificationRule routes a user's notifications matching its conditions to one of their
+// notification targets. Notifications no rule matches go to the user's primary target.
+type NotificationRule struct {
+	ID                   int            `json:"id" gorm:"primaryKey"`
+	UserID               int            `json:"userId" gorm:"column:user_id;index"`
+	Name                 string         `json:"name" gorm:"column:name"`
+	NotificationTargetID int            `json:"targetId" gorm:"column:notification_target_id;index"`
+	Conditions           RuleConditions `json:"conditions" gorm:"column:conditions;type:text"`
+	CreatedAt            time.Time      `json:"createdAt" gorm:"column:created_at"`
+}
+
+// NotificationRoute is a rule joined with the target it routes to
+type NotificationRoute struct {
+	NotificationRule
+	TargetType          NotificationPlatform `gorm:"column:target_type;<-:false"`
+	TargetAddress       string               `gorm:"column:target_address;<-:false"`
+	WebhookHeaders      WebhookHeaders       `gorm:"column:webhook_headers;type:text;<-:false"`
+	WebhookBodyTemplate *string              `gorm:"column:webhook_body_template;<-:false"`
+}
+
+// RuleConditions must all match for a rule to apply, an empty condition matches anything
+type RuleConditions struct {
+	EventTypes []string `json:"eventTypes,omitempty"`
+	Priorities []int    `json:"priorities,omitempty"`
+	LabelIDs   []int    `json:"labelIds,omitempty"`
+}
+
+// Matches reports whether a notification of the event type about a chore with the priority and
+// labels satisfies the conditions
+func (r RuleConditions) Matches(eventType string, priority int, labelIDs []int) bool {
+	if len(r.EventTypes) > 0 && !containsString(r.EventTypes, eventType) {
+		return false
+	}
+	if len(r.Priorities) > 0 && !containsInt(r.Priorities, priority) {
+		return false
+	}
+	if len(r.LabelIDs) > 0 {
+		matched := false
+		for _, labelID := range labelIDs {
+			if containsInt(r.LabelIDs, labelID) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+func (r RuleConditions) Value() (driver.Value, error) {
+	value, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	return string(value), nil
+}
+
+func (r *RuleConditions) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*r = RuleConditions{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, r)
+	case string:
+		return json.Unmarshal([]byte(v), r)
+	default:
+		return errors.New("type assertion to []byte or string failed")
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -6,9 +6,12 @@ import (
 	"donetick.com/core/internal/events"
 	nModel "donetick.com/core/internal/notifier/model"
 	"donetick.com/core/internal/notifier/service/discord"
+	"donetick.com/core/internal/notifier/service/email"
 	"donetick.com/core/internal/notifier/service/fcm"
 	pushover "donetick.com/core/internal/notifier/service/pushover"
 	telegram "donetick.com/core/internal/notifier/service/telegram"
+	"donetick.com/core/internal/notifier/service/webhook"
+	"donetick.com/core/internal/notifier/service/webpush"
 
 	"donetick.com/core/logging"
 )
@@ -18,16 +21,22 @@ type Notifier struct {
 	Pushover       *pushover.Pushover
 	discord        *discord.DiscordNotifier
 	FCM            *fcm.FCMNotifier
+	Webhook        *webhook.WebhookNotifier
+	Email          *email.EmailNotifier
+	WebPush        *webpush.WebPushNotifier
 	eventsProducer *events.EventsProducer
 }
 
-func NewNotifier(t *telegram.TelegramNotifier, p *pushover.Pushover, ep *events.EventsProducer, d *discord.DiscordNotifier, f *fcm.FCMNotifier) *Notifier {
+func NewNotifier(t *telegram.TelegramNotifier, p *pushover.Pushover, ep *events.EventsProducer, d *discord.DiscordNotifier, f *fcm.FCMNotifier, w *webhook.WebhookNotifier, e *email.EmailNotifier, wp *webpush.WebPushNotifier) *Notifier {
 	return &Notifier{
 		Telegram:       t,
 		Pushover:       p,
 		eventsProducer: ep,
 		discord:        d,
 		FCM:            f,
+		Webhook:        w,
+		Email:          e,
+		WebPush:        wp,
 	}
 }
 
@@ -61,18 +70,35 @@ func (n *Notifier) SendNotification(c context.Context, notification *nModel.Noti
 		err = n.FCM.SendNotification(c, notification)
 
 	case nModel.NotificationPlatformWebhook:
-		// TODO: Implement webhook notification
-		// currently we have eventProducer to send events always as a webhook
-		// if NotificationPlatform is selected. this a case to catch
-		// when we only want to send a webhook
+		// the user's own webhook target. The circle webhook still receives the raw event
+		// from the scheduler independently of the platform.
+		if n.Webhook == nil {
+			log.Error("Webhook notifier is not initialized, Skipping sending message")
+			return nil
+		}
+		err = n.Webhook.SendNotification(c, notification)
+
+	case nModel.NotificationPlatformEmail:
+		if n.Email == nil {
+			log.Error("Email is not configured, Skipping sending message")
+			return nil
+		}
+		err = n.Email.SendNotification(c, notification)
+
+	case nModel.NotificationPlatformWebPush:
+		if n.WebPush == nil {
+			log.Error("Web push is not configured, Skipping sending message")
+			return nil
+		}
+		err = n.WebPush.SendNotification(c, notification)
 
 	default:
 		log.Error("Unknown notification type", "type", notification.TypeID)
 		return nil
 	}
 	if err != nil {
-		log.Error("Failed to send notification", "err", err)
+		log.Errorw("Failed to send notification", "err", err, "notification_id", notification.ID, "type", notification.TypeID)
 	}
 
-	return nil
+	return err
 }
@@ -6,6 +6,7 @@ import (
 
 	nModel "donetick.com/core/internal/notifier/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type NotificationRepository struct {
@@ -37,15 +38,267 @@ func (r *NotificationRepository) GetPendingNotification(c context.Context, lookb
 	start := time.Now().UTC().Add(-lookback)
 	end := time.Now().UTC()
 	if err := r.db.Table("notifications").
-		Select("notifications.*, circles.webhook_url as webhook_url").
+		Select("notifications.*, circles.webhook_url as webhook_url, nt.headers as webhook_headers, nt.body_template as webhook_body_template, users.locale as locale, users.timezone as timezone").
 		Joins("left join circles on circles.id = notifications.circle_id").
-		Where("notifications.is_sent = ? AND notifications.scheduled_for < ? AND notifications.scheduled_for > ?", false, end, start).
+		Joins("left join users on users.id = notifications.user_id").
+		// notifications without a routed target go to the user's primary target
+		Joins("left join notification_targets nt on nt.id = notifications.notification_target_id or (notifications.notification_target_id is null and nt.user_id = notifications.user_id and nt.is_primary = ?)", true).
+		Where("notifications.is_sent = ? AND notifications.is_dead_letter = ? AND notifications.scheduled_for < ? AND notifications.scheduled_for > ?", false, false, end, start).
 		Find(&notifications).Error; err != nil {
 		return nil, err
 	}
 	return notifications, nil
 }
 
+// DeleteSentNotifications deletes the notifications sent or dead-lettered before since, along with
+// older delivery failures
 func (r *NotificationRepository) DeleteSentNotifications(c context.Context, since time.Time) error {
-	return r.db.WithContext(c).Where("is_sent = ? AND scheduled_for < ?", true, since).Delete(&nModel.Notification{}).Error
+	return r.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("(is_sent = ? OR is_dead_letter = ?) AND scheduled_for < ?", true, true, since).Delete(&nModel.Notification{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("attempted_at < ?", since).Delete(&nModel.DeliveryFailure{}).Error
+	})
+}
+
+// RecordDeliveryFailure stores a failed delivery attempt and moves the notification to retryAt, or
+// dead-letters it when there is no retry
+func (r *NotificationRepository) RecordDeliveryFailure(c context.Context, failure *nModel.DeliveryFailure, retryAt *time.Time) error {
+	return r.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		updates := map[string]interface{}{
+			"attempts":   failure.Attempt,
+			"last_error": failure.Error,
+		}
+		if retryAt != nil {
+			updates["scheduled_for"] = *retryAt
+		} else {
+			updates["is_dead_letter"] = true
+		}
+		if err := tx.Model(&nModel.Notification{}).Where("id = ?", failure.NotificationID).Updates(updates).Error; err != nil {
+			return err
+		}
+		return tx.Create(failure).Error
+	})
+}
+
+// GetDeliveryFailures returns the user's delivery failures since the given time, latest first
+func (r *NotificationRepository) GetDeliveryFailures(c context.Context, userID int, since time.Time, limit int) ([]*nModel.DeliveryFailure, error) {
+	var failures []*nModel.DeliveryFailure
+	if err := r.db.WithContext(c).Where("user_id = ? AND attempted_at > ?", userID, since).Order("attempted_at desc, id desc").Limit(limit).Find(&failures).Error; err != nil {
+		return nil, err
+	}
+	return failures, nil
+}
+
+// RescheduleNotification moves an unsent notification, e.g. to the end of the user's quiet hours
+func (r *NotificationRepository) RescheduleNotification(c context.Context, notificationID int, scheduledFor time.Time) error {
+	return r.db.WithContext(c).Model(&nModel.Notification{}).Where("id = ? AND is_sent = ?", notificationID, false).Update("scheduled_for", scheduledFor).Error
+}
+
+func (r *NotificationRepository) GetNotificationSettings(c context.Context, userID int) (*nModel.NotificationSettings, error) {
+	settings := &nModel.NotificationSettings{UserID: userID}
+	if err := r.db.WithContext(c).Where("user_id = ?", userID).Limit(1).Find(settings).Error; err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+func (r *NotificationRepository) SaveNotificationSettings(c context.Context, settings *nModel.NotificationSettings) error {
+	return r.db.WithContext(c).Save(settings).Error
+}
+
+func (r *NotificationRepository) settingsDetailsQuery(c context.Context) *gorm.DB {
+	return r.db.WithContext(c).Table("notification_settings").
+		Select("notification_settings.*, users.timezone as timezone, users.circle_id as circle_id, nt.id as target_row_id, nt.type as target_type, nt.target_id as target_id, nt.headers as webhook_headers, nt.body_template as webhook_body_template").
+		Joins("join users on users.id = notification_settings.user_id").
+		Joins("left join notification_targets nt on nt.user_id = notification_settings.user_id and nt.is_primary = ?", true)
+}
+
+// GetNotificationSettingsForUsers returns the settings of the given users keyed by user ID. Users
+// without settings are missing from the map.
+func (r *NotificationRepository) GetNotificationSettingsForUsers(c context.Context, userIDs []int) (map[int]*nModel.NotificationSettingsDetails, error) {
+	settings := make(map[int]*nModel.NotificationSettingsDetails)
+	if len(userIDs) == 0 {
+		return settings, nil
+	}
+	var rows []*nModel.NotificationSettingsDetails
+	if err := r.settingsDetailsQuery(c).Where("notification_settings.user_id IN (?)", userIDs).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		settings[row.UserID] = row
+	}
+	return settings, nil
+}
+
+// GetDigestSettings returns the settings of every user who opted in to the daily digest
+func (r *NotificationRepository) GetDigestSettings(c context.Context) ([]*nModel.NotificationSettingsDetails, error) {
+	var rows []*nModel.NotificationSettingsDetails
+	if err := r.settingsDetailsQuery(c).Where("notification_settings.digest_enabled = ?", true).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *NotificationRepository) MarkDigestSent(c context.Context, userID int, sentAt time.Time) error {
+	return r.db.WithContext(c).Model(&nModel.NotificationSettings{}).Where("user_id = ?", userID).Updates(map[string]interface{}{
+		"last_digest_at":  sentAt,
+		"digest_attempts": 0,
+		"digest_retry_at": nil,
+	}).Error
+}
+
+// RecordDigestFailure stores a failed digest and retries it at retryAt, or gives up on today's
+// digest when there is no retry
+func (r *NotificationRepository) RecordDigestFailure(c context.Context, failure *nModel.DeliveryFailure, retryAt *time.Time) error {
+	return r.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		updates := map[string]interface{}{
+			"digest_attempts": failure.Attempt,
+			"digest_retry_at": retryAt,
+		}
+		if retryAt == nil {
+			updates["last_digest_at"] = failure.AttemptedAt
+			updates["digest_attempts"] = 0
+			updates["digest_retry_at"] = nil
+		}
+		if err := tx.Model(&nModel.NotificationSettings{}).Where("user_id = ?", failure.UserID).Updates(updates).Error; err != nil {
+			return err
+		}
+		return tx.Create(failure).Error
+	})
+}
+
+func (r *NotificationRepository) GetNotificationRules(c context.Context, userID int) ([]*nModel.NotificationRule, error) {
+	var rules []*nModel.NotificationRule
+	if err := r.db.WithContext(c).Where("user_id = ?", userID).Order("id asc").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *NotificationRepository) GetNotificationRule(c context.Context, userID int, ruleID int) (*nModel.NotificationRule, error) {
+	var rule nModel.NotificationRule
+	if err := r.db.WithContext(c).Where("id = ? AND user_id = ?", ruleID, userID).First(&rule).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *NotificationRepository) SaveNotificationRule(c context.Context, rule *nModel.NotificationRule) error {
+	if rule.ID == 0 {
+		rule.CreatedAt = time.Now().UTC()
+	}
+	return r.db.WithContext(c).Save(rule).Error
+}
+
+func (r *NotificationRepository) DeleteNotificationRule(c context.Context, userID int, ruleID int) error {
+	return r.db.WithContext(c).Where("id = ? AND user_id = ?", ruleID, userID).Delete(&nModel.NotificationRule{}).Error
+}
+
+// GetNotificationRoutes returns the routing rules of the given users joined with their targets,
+// keyed by user ID
+func (r *NotificationRepository) GetNotificationRoutes(c context.Context, userIDs []int) (map[int][]*nModel.NotificationRoute, error) {
+	routes := make(map[int][]*nModel.NotificationRoute)
+	if len(userIDs) == 0 {
+		return routes, nil
+	}
+	var rows []*nModel.NotificationRoute
+	if err := r.db.WithContext(c).Table("notification_rules").
+		Select("notification_rules.*, nt.type as target_type, nt.target_id as target_address, nt.headers as webhook_headers, nt.body_template as webhook_body_template").
+		Joins("join notification_targets nt on nt.id = notification_rules.notification_target_id and nt.user_id = notification_rules.user_id").
+		Where("notification_rules.user_id IN (?)", userIDs).
+		Order("notification_rules.id asc").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		routes[row.UserID] = append(routes[row.UserID], row)
+	}
+	return routes, nil
+}
+
+// GetDigestFailures returns the failures of the user's digest attempt since the digest time, one
+// per target the attempt failed on
+func (r *NotificationRepository) GetDigestFailures(c context.Context, userID int, attempt int, since time.Time) ([]*nModel.DeliveryFailure, error) {
+	var failures []*nModel.DeliveryFailure
+	// digests aren't stored as notifications, their failures have no notification ID
+	if err := r.db.WithContext(c).Where("user_id = ? AND notification_id = ? AND attempt = ? AND attempted_at >= ?", userID, 0, attempt, since).Find(&failures).Error; err != nil {
+		return nil, err
+	}
+	return failures, nil
+}
+
+// GetChoresLabelIDs returns the label IDs of the given chores keyed by chore ID
+func (r *NotificationRepository) GetChoresLabelIDs(c context.Context, choreIDs []int) (map[int][]int, error) {
+	labelIDs := make(map[int][]int)
+	if len(choreIDs) == 0 {
+		return labelIDs, nil
+	}
+	var rows []struct {
+		ChoreID int
+		LabelID int
+	}
+	if err := r.db.WithContext(c).Table("chore_labels").Select("DISTINCT chore_id, label_id").Where("chore_id IN (?)", choreIDs).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		labelIDs[row.ChoreID] = append(labelIDs[row.ChoreID], row.LabelID)
+	}
+	return labelIDs, nil
+}
+
+func (r *NotificationRepository) GetChoreLabelIDs(c context.Context, choreID int) ([]int, error) {
+	var labelIDs []int
+	if err := r.db.WithContext(c).Table("chore_labels").Where("chore_id = ?", choreID).Distinct().Pluck("label_id", &labelIDs).Error; err != nil {
+		return nil, err
+	}
+	return labelIDs, nil
+}
+
+func (r *NotificationRepository) NotificationTargetExists(c context.Context, userID int, targetID int) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(c).Table("notification_targets").Where("id = ? AND user_id = ?", targetID, userID).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *NotificationRepository) GetCircleMessageTemplates(c context.Context, circleID int) ([]*nModel.MessageTemplate, error) {
+	var templates []*nModel.MessageTemplate
+	if err := r.db.WithContext(c).Where("circle_id = ?", circleID).Order("event_type asc").Find(&templates).Error; err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+// GetMessageTemplates returns the template bodies of the given circles keyed by circle ID and event type
+func (r *NotificationRepository) GetMessageTemplates(c context.Context, circleIDs []int) (map[int]map[string]string, error) {
+	bodies := make(map[int]map[string]string)
+	if len(circleIDs) == 0 {
+		return bodies, nil
+	}
+	var templates []*nModel.MessageTemplate
+	if err := r.db.WithContext(c).Where("circle_id IN (?)", circleIDs).Find(&templates).Error; err != nil {
+		return nil, err
+	}
+	for _, template := range templates {
+		if bodies[template.CircleID] == nil {
+			bodies[template.CircleID] = make(map[string]string)
+		}
+		bodies[template.CircleID][template.EventType] = template.Body
+	}
+	return bodies, nil
+}
+
+// SaveMessageTemplate creates or replaces the circle's template for the event type
+func (r *NotificationRepository) SaveMessageTemplate(c context.Context, template *nModel.MessageTemplate) error {
+	template.UpdatedAt = time.Now().UTC()
+	return r.db.WithContext(c).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "circle_id"}, {Name: "event_type"}},
+		DoUpdates: clause.AssignmentColumns([]string{"body", "updated_by", "updated_at"}),
+	}).Create(template).Error
+}
+
+func (r *NotificationRepository) DeleteMessageTemplate(c context.Context, circleID int, eventType string) error {
+	return r.db.WithContext(c).Where("circle_id = ? AND event_type = ?", circleID, eventType).Delete(&nModel.MessageTemplate{}).Error
 }
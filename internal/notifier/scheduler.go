@@ -6,9 +6,13 @@ import (
 	"time"
 
 	"donetick.com/core/config"
+	chModel "donetick.com/core/internal/chore/model"
 	chRepo "donetick.com/core/internal/chore/repo"
 	"donetick.com/core/internal/events"
+	nModel "donetick.com/core/internal/notifier/model"
 	nRepo "donetick.com/core/internal/notifier/repo"
+	nps "donetick.com/core/internal/notifier/service"
+	"donetick.com/core/internal/notifier/service/message"
 	uRepo "donetick.com/core/internal/user/repo"
 	"donetick.com/core/logging"
 )
@@ -26,10 +30,11 @@ type Scheduler struct {
 	notifier         *Notifier
 	eventsProducer   *events.EventsProducer
 	notificationRepo *nRepo.NotificationRepository
+	planner          *nps.NotificationPlanner
 	SchedulerJobs    config.SchedulerConfig
 }
 
-func NewScheduler(cfg *config.Config, ur *uRepo.UserRepository, cr *chRepo.ChoreRepository, n *Notifier, nr *nRepo.NotificationRepository, ep *events.EventsProducer) *Scheduler {
+func NewScheduler(cfg *config.Config, ur *uRepo.UserRepository, cr *chRepo.ChoreRepository, n *Notifier, nr *nRepo.NotificationRepository, ep *events.EventsProducer, planner *nps.NotificationPlanner) *Scheduler {
 	return &Scheduler{
 		choreRepo:        cr,
 		userRepo:         ur,
@@ -37,6 +42,7 @@ func NewScheduler(cfg *config.Config, ur *uRepo.UserRepository, cr *chRepo.Chore
 		notifier:         n,
 		notificationRepo: nr,
 		eventsProducer:   ep,
+		planner:          planner,
 		SchedulerJobs:    cfg.SchedulerJobs,
 	}
 }
@@ -45,6 +51,7 @@ func (s *Scheduler) Start(c context.Context) {
 	log := logging.FromContext(c)
 	log.Debug("Scheduler started")
 	go s.runScheduler(c, " NOTIFICATION_SCHEDULER ", s.loadAndSendNotificationJob, 3*time.Minute)
+	go s.runScheduler(c, " NOTIFICATION_DIGEST ", s.sendDigestsJob, 5*time.Minute)
 	go s.runScheduler(c, " NOTIFICATION_CLEANUP ", s.cleanupSentNotifications, 24*time.Hour*30)
 }
 func (s *Scheduler) cleanupSentNotifications(c context.Context) (time.Duration, error) {
@@ -69,23 +76,299 @@ func (s *Scheduler) loadAndSendNotificationJob(c context.Context) (time.Duration
 		return time.Since(startTime), err
 	}
 
+	settings, err := s.notificationRepo.GetNotificationSettingsForUsers(c, notificationUserIDs(getAllPendingNotifications))
+	if err != nil {
+		log.Error("Error getting notification settings")
+		return time.Since(startTime), err
+	}
+
+	templates, err := s.notificationRepo.GetMessageTemplates(c, notificationCircleIDs(getAllPendingNotifications))
+	if err != nil {
+		// the built-in templates still apply
+		log.Errorw("Error getting message templates", "error", err)
+	}
+
+	handled := make([]*nModel.NotificationDetails, 0, len(getAllPendingNotifications))
 	for _, notification := range getAllPendingNotifications {
+		eventType, _ := notification.RawEvent["type"].(string)
+		if userSettings := settings[notification.UserID]; userSettings != nil && (notification.NotificationTargetID != nil || notification.TargetID == userSettings.TargetID) {
+			// the user's settings only apply to their own targets, not to the circle group
+			if userSettings.DigestEnabled && nps.IsDigestible(nps.EventType(eventType)) {
+				log.Debugw("Folding notification into the daily digest", "notification_id", notification.ID, "user_id", notification.UserID)
+				handled = append(handled, notification)
+				continue
+			}
+			if until, quiet := userSettings.QuietUntil(startTime, userSettings.Location()); quiet {
+				log.Debugw("Deferring notification until quiet hours end", "notification_id", notification.ID, "until", until)
+				if err := s.notificationRepo.RescheduleNotification(c, notification.ID, until); err != nil {
+					log.Errorw("Error deferring notification", "notification_id", notification.ID, "error", err)
+				}
+				continue
+			}
+		}
+
+		renderMessage(c, notification, templates[notification.CircleID])
 		err := s.notifier.SendNotification(c, notification)
 		if err != nil {
-			log.Error("Error sending notification", err)
+			s.recordDeliveryFailure(c, notification, err, startTime)
 			continue
 		}
 		if notification.RawEvent != nil && notification.WebhookURL != nil {
 			// if we have a webhook url, we should send the event to the webhook
-			s.eventsProducer.NotificationEvent(c, *notification.WebhookURL, notification.RawEvent)
+			s.eventsProducer.NotificationEvent(c, notification.CircleID, *notification.WebhookURL, notification.RawEvent)
+		}
+
+		if eventType == string(nps.EventTypeNagging) {
+			s.scheduleNextNag(c, notification)
 		}
 
 		notification.IsSent = true
+		handled = append(handled, notification)
 	}
 
-	s.notificationRepo.MarkNotificationsAsSent(getAllPendingNotifications)
+	if len(handled) > 0 {
+		s.notificationRepo.MarkNotificationsAsSent(handled)
+	}
 	return time.Since(startTime), nil
 }
+
+const (
+	maxDeliveryAttempts = 5
+	maxRetryDelay       = time.Hour
+	// failure messages are stored for the user to read, long response bodies are cut
+	maxDeliveryErrorLength = 500
+)
+
+// retryDelay returns the backoff before retrying a notification that failed `attempts` times
+func retryDelay(attempts int) time.Duration {
+	delay := 5 * time.Minute << (attempts - 1)
+	if delay > maxRetryDelay || delay <= 0 {
+		return maxRetryDelay
+	}
+	return delay
+}
+
+// recordDeliveryFailure schedules a retry of the notification, or dead-letters it once it failed
+// maxDeliveryAttempts times
+func (s *Scheduler) recordDeliveryFailure(c context.Context, notification *nModel.NotificationDetails, sendErr error, now time.Time) {
+	log := logging.FromContext(c)
+	message := deliveryErrorMessage(sendErr)
+	failure := &nModel.DeliveryFailure{
+		NotificationID:       notification.ID,
+		ChoreID:              notification.ChoreID,
+		UserID:               notification.UserID,
+		NotificationTargetID: notification.NotificationTargetID,
+		TypeID:               notification.TypeID,
+		TargetID:             notification.TargetID,
+		Attempt:              notification.Attempts + 1,
+		Error:                message,
+		AttemptedAt:          now,
+	}
+	var retryAt *time.Time
+	if failure.Attempt < maxDeliveryAttempts {
+		next := now.Add(retryDelay(failure.Attempt))
+		retryAt = &next
+	} else {
+		failure.DeadLetter = true
+	}
+	log.Warnw("Notification delivery failed", "notification_id", notification.ID, "attempt", failure.Attempt, "retry_at", retryAt, "error", message)
+	if err := s.notificationRepo.RecordDeliveryFailure(c, failure, retryAt); err != nil {
+		log.Errorw("Error recording notification delivery failure", "notification_id", notification.ID, "error", err)
+	}
+}
+
+// recordDigestFailure retries the user's digest with the same backoff as notifications, and gives up
+// on today's digest once it failed maxDeliveryAttempts times
+func (s *Scheduler) recordDigestFailure(c context.Context, settings *nModel.NotificationSettingsDetails, digest *nModel.NotificationDetails, sendErr error, now time.Time) {
+	log := logging.FromContext(c)
+	message := deliveryErrorMessage(sendErr)
+	failure := &nModel.DeliveryFailure{
+		UserID:               settings.UserID,
+		NotificationTargetID: digest.NotificationTargetID,
+		TypeID:               digest.TypeID,
+		TargetID:             digest.TargetID,
+		Attempt:              settings.DigestAttempts + 1,
+		Error:                message,
+		AttemptedAt:          now,
+	}
+	var retryAt *time.Time
+	if failure.Attempt < maxDeliveryAttempts {
+		next := now.Add(retryDelay(failure.Attempt))
+		retryAt = &next
+	} else {
+		failure.DeadLetter = true
+	}
+	log.Warnw("Digest delivery failed", "user_id", settings.UserID, "attempt", failure.Attempt, "retry_at", retryAt, "error", message)
+	if err := s.notificationRepo.RecordDigestFailure(c, failure, retryAt); err != nil {
+		log.Errorw("Error recording digest delivery failure", "user_id", settings.UserID, "error", err)
+	}
+}
+
+func deliveryErrorMessage(err error) string {
+	message := err.Error()
+	if len(message) > maxDeliveryErrorLength {
+		message = message[:maxDeliveryErrorLength]
+	}
+	return message
+}
+
+func notificationUserIDs(notifications []*nModel.NotificationDetails) []int {
+	seen := make(map[int]bool)
+	userIDs := make([]int, 0)
+	for _, notification := range notifications {
+		if !seen[notification.UserID] {
+			seen[notification.UserID] = true
+			userIDs = append(userIDs, notification.UserID)
+		}
+	}
+	return userIDs
+}
+
+func notificationCircleIDs(notifications []*nModel.NotificationDetails) []int {
+	seen := make(map[int]bool)
+	circleIDs := make([]int, 0)
+	for _, notification := range notifications {
+		if !seen[notification.CircleID] {
+			seen[notification.CircleID] = true
+			circleIDs = append(circleIDs, notification.CircleID)
+		}
+	}
+	return circleIDs
+}
+
+// renderMessage replaces the planned text with the circle's template for the event type, or the
+// built-in one in the recipient's language, formatted for the platform. The planned text is kept
+// when the message has custom text or the template fails to render.
+func renderMessage(c context.Context, notification *nModel.NotificationDetails, circleTemplates map[string]string) {
+	eventType, _ := notification.RawEvent["type"].(string)
+	if custom, _ := notification.RawEvent["custom"].(bool); custom || !message.Templated(eventType) {
+		return
+	}
+	body, ok := circleTemplates[eventType]
+	if !ok {
+		body = message.BuiltinTemplate(notification.Locale, eventType)
+	}
+	loc := time.UTC
+	if notification.Timezone != "" {
+		if userLoc, err := time.LoadLocation(notification.Timezone); err == nil {
+			loc = userLoc
+		}
+	}
+	text, err := message.Render(body, notification.RawEvent, notification.Locale, message.FormatFor(notification.TypeID), loc)
+	if err != nil || text == "" {
+		logging.FromContext(c).Errorw("Error rendering notification template", "notification_id", notification.ID, "circle_id", notification.CircleID, "error", err)
+		return
+	}
+	notification.Text = text
+}
+
+// sendDigestsJob sends the daily digest of every opted-in user whose local digest time has passed
+func (s *Scheduler) sendDigestsJob(c context.Context) (time.Duration, error) {
+	log := logging.FromContext(c)
+	startTime := time.Now().UTC()
+	digestSettings, err := s.notificationRepo.GetDigestSettings(c)
+	if err != nil {
+		log.Error("Error getting digest settings")
+		return time.Since(startTime), err
+	}
+
+	routes, err := s.notificationRepo.GetNotificationRoutes(c, digestUserIDs(digestSettings))
+	if err != nil {
+		// without the rules every digest goes to the primary target
+		log.Errorw("Error getting notification routes for digests", "error", err)
+	}
+
+	for _, settings := range digestSettings {
+		loc := settings.Location()
+		digestAt, due := settings.DigestDue(startTime, loc)
+		if !due {
+			continue
+		}
+
+		chores, err := s.choreRepo.GetAssignedChoresDueBefore(c, settings.UserID, nps.DigestEnd(startTime, loc))
+		if err != nil {
+			log.Errorw("Error getting chores for digest", "user_id", settings.UserID, "error", err)
+			continue
+		}
+		var labelIDs map[int][]int
+		if len(routes[settings.UserID]) > 0 {
+			if labelIDs, err = s.notificationRepo.GetChoresLabelIDs(c, choreIDs(chores)); err != nil {
+				log.Errorw("Error getting chore labels for digest", "user_id", settings.UserID, "error", err)
+			}
+		}
+		digests := nps.BuildDigests(settings, chores, routes[settings.UserID], labelIDs, startTime)
+		if settings.DigestAttempts > 0 {
+			if digests, err = s.digestsToRetry(c, settings, digestAt, digests); err != nil {
+				log.Errorw("Error getting failed digests", "user_id", settings.UserID, "error", err)
+				continue
+			}
+		}
+
+		failed := false
+		for _, digest := range digests {
+			if err := s.notifier.SendNotification(c, digest); err != nil {
+				s.recordDigestFailure(c, settings, digest, err, startTime)
+				failed = true
+			}
+		}
+		if failed {
+			continue
+		}
+		if err := s.notificationRepo.MarkDigestSent(c, settings.UserID, startTime); err != nil {
+			log.Errorw("Error marking digest as sent", "user_id", settings.UserID, "error", err)
+		}
+	}
+	return time.Since(startTime), nil
+}
+
+// digestsToRetry keeps the digests to the targets the user's last attempt failed on, the others
+// already received today's digest
+func (s *Scheduler) digestsToRetry(c context.Context, settings *nModel.NotificationSettingsDetails, digestAt time.Time, digests []*nModel.NotificationDetails) ([]*nModel.NotificationDetails, error) {
+	failures, err := s.notificationRepo.GetDigestFailures(c, settings.UserID, settings.DigestAttempts, digestAt)
+	if err != nil {
+		return nil, err
+	}
+	retry := make([]*nModel.NotificationDetails, 0, len(failures))
+	for _, digest := range digests {
+		for _, failure := range failures {
+			if failure.TypeID == digest.TypeID && failure.TargetID == digest.TargetID {
+				retry = append(retry, digest)
+				break
+			}
+		}
+	}
+	return retry, nil
+}
+
+func digestUserIDs(settings []*nModel.NotificationSettingsDetails) []int {
+	userIDs := make([]int, 0, len(settings))
+	for _, userSettings := range settings {
+		userIDs = append(userIDs, userSettings.UserID)
+	}
+	return userIDs
+}
+
+func choreIDs(chores []*chModel.Chore) []int {
+	ids := make([]int, 0, len(chores))
+	for _, chore := range chores {
+		ids = append(ids, chore.ID)
+	}
+	return ids
+}
+
+// scheduleNextNag queues the next nagging reminder while the chore is still overdue
+func (s *Scheduler) scheduleNextNag(c context.Context, notification *nModel.NotificationDetails) {
+	log := logging.FromContext(c)
+	chore, err := s.choreRepo.GetChore(c, notification.ChoreID, notification.UserID, notification.CircleID)
+	if err != nil {
+		log.Debugw("Not scheduling next nagging reminder, chore not found", "chore_id", notification.ChoreID, "error", err)
+		return
+	}
+	if err := s.planner.ScheduleNextNag(c, notification, chore); err != nil {
+		log.Errorw("Error scheduling next nagging reminder", "chore_id", notification.ChoreID, "error", err)
+	}
+}
+
 func (s *Scheduler) runScheduler(c context.Context, jobName string, job func(c context.Context) (time.Duration, error), interval time.Duration) {
 
 	for {
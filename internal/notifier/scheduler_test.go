@@ -0,0 +1,481 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"donetick.com/core/config"
+	chModel "donetick.com/core/internal/chore/model"
+	chRepo "donetick.com/core/internal/chore/repo"
+	"donetick.com/core/internal/database"
+	nModel "donetick.com/core/internal/notifier/model"
+	nRepo "donetick.com/core/internal/notifier/repo"
+	"donetick.com/core/internal/notifier/service/telegram"
+	uModel "donetick.com/core/internal/user/model"
+	"github.com/glebarez/sqlite"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"gorm.io/gorm"
+)
+
+// setupScheduler returns a scheduler on an in-memory database, sending through the notifier or
+// through no platform when it is nil
+func setupScheduler(t *testing.T, notifier *Notifier) (*Scheduler, *gorm.DB, *nRepo.NotificationRepository) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect database: %v", err)
+	}
+	if err := database.Migration(db); err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
+	}
+	if notifier == nil {
+		notifier = NewNotifier(nil, nil, nil, nil, nil, nil, nil, nil)
+	}
+	cfg := config.NewConfig()
+	nr := nRepo.NewNotificationRepository(db)
+	return NewScheduler(cfg, nil, chRepo.NewChoreRepository(db, cfg), notifier, nr, nil, nil), db, nr
+}
+
+func TestQuietUntil(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("timezone data not available")
+	}
+	local := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 3, day, hour, minute, 0, 0, ny)
+	}
+
+	tests := []struct {
+		name      string
+		start     string
+		end       string
+		now       time.Time
+		wantUntil time.Time
+		wantQuiet bool
+	}{
+		{name: "overnight window, late evening", start: "22:00", end: "07:00", now: local(10, 23, 30), wantUntil: local(11, 7, 0), wantQuiet: true},
+		{name: "overnight window, early morning", start: "22:00", end: "07:00", now: local(10, 3, 0), wantUntil: local(10, 7, 0), wantQuiet: true},
+		{name: "overnight window, daytime", start: "22:00", end: "07:00", now: local(10, 12, 0)},
+		{name: "overnight window, at the end", start: "22:00", end: "07:00", now: local(10, 7, 0)},
+		{name: "same day window", start: "13:00", end: "15:00", now: local(10, 13, 0), wantUntil: local(10, 15, 0), wantQuiet: true},
+		{name: "same day window, outside", start: "13:00", end: "15:00", now: local(10, 16, 0)},
+		{name: "window ends after the DST change", start: "22:00", end: "07:00", now: local(7, 23, 0), wantUntil: local(8, 7, 0), wantQuiet: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := &nModel.NotificationSettings{QuietHoursEnabled: true, QuietHoursStart: tt.start, QuietHoursEnd: tt.end}
+			until, quiet := settings.QuietUntil(tt.now.UTC(), ny)
+			if quiet != tt.wantQuiet {
+				t.Fatalf("expected quiet=%v, got %v", tt.wantQuiet, quiet)
+			}
+			if quiet && !until.Equal(tt.wantUntil) {
+				t.Errorf("expected quiet hours to end at %v, got %v", tt.wantUntil.UTC(), until)
+			}
+		})
+	}
+}
+
+func TestDigestDue(t *testing.T) {
+	digestAt := time.Date(2026, 3, 10, 8, 0, 0, 0, time.UTC)
+	yesterday := digestAt.Add(-24 * time.Hour)
+	sentToday := digestAt.Add(time.Minute)
+	retryAt := digestAt.Add(10 * time.Minute)
+
+	tests := []struct {
+		name    string
+		now     time.Time
+		last    *time.Time
+		retryAt *time.Time
+		wantDue bool
+	}{
+		{name: "before the digest time", now: digestAt.Add(-time.Minute), last: &yesterday},
+		{name: "digest time passed", now: digestAt.Add(3 * time.Minute), last: &yesterday, wantDue: true},
+		{name: "never sent", now: digestAt.Add(time.Hour), wantDue: true},
+		{name: "already sent today", now: digestAt.Add(time.Hour), last: &sentToday},
+		{name: "failed, retry not due yet", now: digestAt.Add(5 * time.Minute), last: &yesterday, retryAt: &retryAt},
+		{name: "failed, retry due", now: digestAt.Add(10 * time.Minute), last: &yesterday, retryAt: &retryAt, wantDue: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := &nModel.NotificationSettings{DigestEnabled: true, DigestTime: "08:00", LastDigestAt: tt.last, DigestRetryAt: tt.retryAt}
+			at, due := settings.DigestDue(tt.now, time.UTC)
+			if due != tt.wantDue {
+				t.Fatalf("expected due=%v, got %v", tt.wantDue, due)
+			}
+			if due && !at.Equal(digestAt) {
+				t.Errorf("expected digest at %v, got %v", digestAt, at)
+			}
+		})
+	}
+}
+
+func TestLoadAndSendNotificationJobAppliesSettings(t *testing.T) {
+	bot := telegram.NewStubBot()
+	scheduler, db, nr := setupScheduler(t, NewNotifier(telegram.NewTelegramNotifierWithBot(bot, false), nil, nil, nil, nil, nil, nil, nil))
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	quietUser, digestUser, plainUser := 1, 2, 3
+	for _, userID := range []int{quietUser, digestUser, plainUser} {
+		db.Create(&uModel.User{ID: userID, Username: string(rune('a' + userID)), CircleID: 1, Timezone: "UTC"})
+		db.Create(&uModel.NotificationTarget{UserID: userID, IsPrimary: true, Type: nModel.NotificationPlatformTelegram, TargetID: "42"})
+	}
+	nr.SaveNotificationSettings(ctx, &nModel.NotificationSettings{
+		UserID:            quietUser,
+		QuietHoursEnabled: true,
+		QuietHoursStart:   now.Add(-time.Hour).Format("15:04"),
+		QuietHoursEnd:     now.Add(time.Hour).Format("15:04"),
+	})
+	nr.SaveNotificationSettings(ctx, &nModel.NotificationSettings{UserID: digestUser, DigestEnabled: true, DigestTime: "08:00"})
+
+	notification := func(id, userID int, targetID string, eventType string) *nModel.Notification {
+		return &nModel.Notification{
+			ID:           id,
+			ChoreID:      id,
+			CircleID:     1,
+			UserID:       userID,
+			TargetID:     targetID,
+			TypeID:       nModel.NotificationPlatformTelegram,
+			Text:         fmt.Sprintf("notification %d", id),
+			ScheduledFor: now.Add(-time.Minute),
+			// custom messages keep their text, which tells the delivered messages apart
+			RawEvent: nModel.JSONB{"type": eventType, "custom": true},
+		}
+	}
+	nr.BatchInsertNotifications([]*nModel.Notification{
+		notification(1, quietUser, "42", "due"),
+		notification(2, quietUser, "-100", "due"),
+		notification(3, digestUser, "42", "overdue"),
+		notification(4, digestUser, "42", "escalation"),
+		notification(5, plainUser, "42", "due"),
+		notification(6, digestUser, "42", "nagging"),
+	})
+
+	if _, err := scheduler.loadAndSendNotificationJob(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	delivered := make(map[string]bool)
+	for _, sent := range bot.Sent() {
+		delivered[sent.(tgbotapi.MessageConfig).Text] = true
+	}
+
+	tests := []struct {
+		id            int
+		wantSent      bool
+		wantDelivered bool
+		wantDeferred  bool
+	}{
+		{id: 1, wantDeferred: true},
+		{id: 2, wantSent: true, wantDelivered: true}, // circle group target, the user's quiet hours don't apply
+		{id: 3, wantSent: true},                      // folded into the digest
+		{id: 4, wantSent: true, wantDelivered: true},
+		{id: 5, wantSent: true, wantDelivered: true},
+		{id: 6, wantSent: true, wantDelivered: true}, // nagging continues only once a reminder is sent
+	}
+	for _, tt := range tests {
+		if delivered[fmt.Sprintf("notification %d", tt.id)] != tt.wantDelivered {
+			t.Errorf("notification %d: expected delivered=%v", tt.id, tt.wantDelivered)
+		}
+		var stored nModel.Notification
+		if err := db.First(&stored, tt.id).Error; err != nil {
+			t.Fatalf("notification %d: %v", tt.id, err)
+		}
+		if stored.IsSent != tt.wantSent {
+			t.Errorf("notification %d: expected sent=%v, got %v", tt.id, tt.wantSent, stored.IsSent)
+		}
+		if deferred := stored.ScheduledFor.After(now); deferred != tt.wantDeferred {
+			t.Errorf("notification %d: expected deferred=%v, scheduled for %v", tt.id, tt.wantDeferred, stored.ScheduledFor)
+		}
+	}
+}
+
+func TestSendDigestsJob(t *testing.T) {
+	scheduler, db, nr := setupScheduler(t, nil)
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	if now.Hour() == 0 && now.Minute() == 0 {
+		t.Skip("a digest time one minute ago would fall on the previous day")
+	}
+	userID := 1
+	db.Create(&uModel.User{ID: userID, Username: "digest", CircleID: 1, Timezone: "UTC"})
+	db.Create(&uModel.NotificationTarget{UserID: userID, IsPrimary: true, Type: nModel.NotificationPlatformTelegram, TargetID: "42"})
+	overdue := now.Add(-time.Hour)
+	db.Create(&chModel.Chore{Name: "Dishes", CircleID: 1, CreatedBy: userID, AssignedTo: &userID, NextDueDate: &overdue, IsActive: true})
+	yesterday := now.Add(-24 * time.Hour)
+	nr.SaveNotificationSettings(ctx, &nModel.NotificationSettings{
+		UserID:        userID,
+		DigestEnabled: true,
+		DigestTime:    now.Add(-time.Minute).Format("15:04"),
+		LastDigestAt:  &yesterday,
+	})
+
+	if _, err := scheduler.sendDigestsJob(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	settings, err := nr.GetNotificationSettings(ctx, userID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if settings.LastDigestAt == nil || settings.LastDigestAt.Before(now) {
+		t.Fatalf("expected the digest to be marked as sent, last digest at %v", settings.LastDigestAt)
+	}
+
+	// a second run on the same day sends nothing
+	sentAt := *settings.LastDigestAt
+	if _, err := scheduler.sendDigestsJob(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	settings, _ = nr.GetNotificationSettings(ctx, userID)
+	if !settings.LastDigestAt.Equal(sentAt) {
+		t.Errorf("expected no second digest, last digest at %v", settings.LastDigestAt)
+	}
+}
+
+func TestSendDigestsJobRoutesChoresToTargets(t *testing.T) {
+	bot := telegram.NewStubBot()
+	scheduler, db, nr := setupScheduler(t, NewNotifier(telegram.NewTelegramNotifierWithBot(bot, false), nil, nil, nil, nil, nil, nil, nil))
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	if now.Hour() == 0 && now.Minute() == 0 {
+		t.Skip("a digest time one minute ago would fall on the previous day")
+	}
+	userID := 1
+	db.Create(&uModel.User{ID: userID, Username: "digest", CircleID: 1, Timezone: "UTC"})
+	db.Create(&uModel.NotificationTarget{UserID: userID, IsPrimary: true, Type: nModel.NotificationPlatformTelegram, TargetID: "42"})
+	phone := &uModel.NotificationTarget{UserID: userID, Type: nModel.NotificationPlatformTelegram, TargetID: "77"}
+	db.Create(phone)
+	nr.SaveNotificationRule(ctx, &nModel.NotificationRule{UserID: userID, NotificationTargetID: phone.ID, Conditions: nModel.RuleConditions{Priorities: []int{1}}})
+	overdue := now.Add(-time.Hour)
+	db.Create(&chModel.Chore{Name: "Dishes", CircleID: 1, CreatedBy: userID, AssignedTo: &userID, NextDueDate: &overdue, IsActive: true})
+	db.Create(&chModel.Chore{Name: "Pay rent", Priority: 1, CircleID: 1, CreatedBy: userID, AssignedTo: &userID, NextDueDate: &overdue, IsActive: true})
+	nr.SaveNotificationSettings(ctx, &nModel.NotificationSettings{UserID: userID, DigestEnabled: true, DigestTime: now.Add(-time.Minute).Format("15:04")})
+
+	if _, err := scheduler.sendDigestsJob(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	digests := make(map[int64]string)
+	for _, sent := range bot.Sent() {
+		msg := sent.(tgbotapi.MessageConfig)
+		digests[msg.ChatID] = msg.Text
+	}
+	if len(digests) != 2 {
+		t.Fatalf("expected one digest per target, got %v", digests)
+	}
+	if !strings.Contains(digests[42], "Dishes") || strings.Contains(digests[42], "Pay rent") {
+		t.Errorf("expected the primary target to get the unrouted chore, got %q", digests[42])
+	}
+	if !strings.Contains(digests[77], "Pay rent") || strings.Contains(digests[77], "Dishes") {
+		t.Errorf("expected the routed target to get the high priority chore, got %q", digests[77])
+	}
+}
+
+func TestDigestsToRetry(t *testing.T) {
+	scheduler, db, _ := setupScheduler(t, nil)
+	digestAt := time.Now().UTC().Add(-time.Hour)
+	db.Create(&nModel.DeliveryFailure{UserID: 1, TypeID: nModel.NotificationPlatformTelegram, TargetID: "77", Attempt: 1, AttemptedAt: digestAt.Add(time.Minute)})
+	// yesterday's failure doesn't make today's digest to the target a retry
+	db.Create(&nModel.DeliveryFailure{UserID: 1, TypeID: nModel.NotificationPlatformTelegram, TargetID: "42", Attempt: 1, AttemptedAt: digestAt.Add(-24 * time.Hour)})
+
+	digest := func(targetID string) *nModel.NotificationDetails {
+		return &nModel.NotificationDetails{Notification: nModel.Notification{UserID: 1, TypeID: nModel.NotificationPlatformTelegram, TargetID: targetID}}
+	}
+	settings := &nModel.NotificationSettingsDetails{NotificationSettings: nModel.NotificationSettings{UserID: 1, DigestAttempts: 1}}
+	retry, err := scheduler.digestsToRetry(context.Background(), settings, digestAt, []*nModel.NotificationDetails{digest("42"), digest("77")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(retry) != 1 || retry[0].TargetID != "77" {
+		t.Errorf("expected only the failed target to be retried, got %+v", retry)
+	}
+}
+
+func TestLoadAndSendNotificationJobRetriesFailures(t *testing.T) {
+	bot := telegram.NewStubBot()
+	bot.SendErr = errors.New("Forbidden: bot was blocked by the user")
+	scheduler, db, nr := setupScheduler(t, NewNotifier(telegram.NewTelegramNotifierWithBot(bot, false), nil, nil, nil, nil, nil, nil, nil))
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	tests := []struct {
+		name           string
+		attempts       int
+		wantDeadLetter bool
+	}{
+		{name: "first failure is retried", attempts: 0},
+		{name: "last attempt is dead-lettered", attempts: maxDeliveryAttempts - 1, wantDeadLetter: true},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := i + 1
+			nr.BatchInsertNotifications([]*nModel.Notification{{
+				ID:           id,
+				ChoreID:      id,
+				UserID:       1,
+				TargetID:     "42",
+				TypeID:       nModel.NotificationPlatformTelegram,
+				ScheduledFor: now.Add(-time.Minute),
+				Attempts:     tt.attempts,
+				RawEvent:     nModel.JSONB{"type": "due"},
+			}})
+			if _, err := scheduler.loadAndSendNotificationJob(ctx); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var stored nModel.Notification
+			db.First(&stored, id)
+			if stored.IsSent {
+				t.Fatalf("expected the failed notification not to be marked as sent")
+			}
+			if stored.Attempts != tt.attempts+1 || stored.LastError == nil || *stored.LastError != bot.SendErr.Error() {
+				t.Errorf("unexpected attempts %d and last error %v", stored.Attempts, stored.LastError)
+			}
+			if stored.IsDeadLetter != tt.wantDeadLetter {
+				t.Errorf("expected dead letter=%v", tt.wantDeadLetter)
+			}
+			if !tt.wantDeadLetter && !stored.ScheduledFor.After(now) {
+				t.Errorf("expected the retry to be scheduled with backoff, got %v", stored.ScheduledFor)
+			}
+
+			failures, err := nr.GetDeliveryFailures(ctx, 1, now.Add(-time.Hour), 10)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(failures) != id || failures[0].NotificationID != id || failures[0].DeadLetter != tt.wantDeadLetter {
+				t.Errorf("unexpected delivery failures %+v", failures)
+			}
+		})
+	}
+}
+
+func TestSendDigestsJobRecordsFailures(t *testing.T) {
+	bot := telegram.NewStubBot()
+	bot.SendErr = errors.New("Forbidden: bot was blocked by the user")
+	scheduler, db, nr := setupScheduler(t, NewNotifier(telegram.NewTelegramNotifierWithBot(bot, false), nil, nil, nil, nil, nil, nil, nil))
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	if now.Hour() == 0 && now.Minute() == 0 {
+		t.Skip("a digest time one minute ago would fall on the previous day")
+	}
+	userID := 1
+	db.Create(&uModel.User{ID: userID, Username: "digest", CircleID: 1, Timezone: "UTC"})
+	db.Create(&uModel.NotificationTarget{UserID: userID, IsPrimary: true, Type: nModel.NotificationPlatformTelegram, TargetID: "42"})
+	overdue := now.Add(-time.Hour)
+	db.Create(&chModel.Chore{Name: "Dishes", CircleID: 1, CreatedBy: userID, AssignedTo: &userID, NextDueDate: &overdue, IsActive: true})
+	yesterday := now.Add(-24 * time.Hour)
+	nr.SaveNotificationSettings(ctx, &nModel.NotificationSettings{
+		UserID:        userID,
+		DigestEnabled: true,
+		DigestTime:    now.Add(-time.Minute).Format("15:04"),
+		LastDigestAt:  &yesterday,
+	})
+
+	if _, err := scheduler.sendDigestsJob(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	settings, _ := nr.GetNotificationSettings(ctx, userID)
+	if settings.DigestAttempts != 1 || settings.DigestRetryAt == nil || !settings.DigestRetryAt.After(now) {
+		t.Fatalf("expected the digest to be retried with backoff, got attempts %d retry at %v", settings.DigestAttempts, settings.DigestRetryAt)
+	}
+	failures, _ := nr.GetDeliveryFailures(ctx, userID, now.Add(-time.Hour), 10)
+	if len(failures) != 1 || failures[0].Error != bot.SendErr.Error() || failures[0].DeadLetter {
+		t.Fatalf("unexpected delivery failures %+v", failures)
+	}
+
+	// nothing is sent again before the retry is due
+	if _, err := scheduler.sendDigestsJob(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if failures, _ = nr.GetDeliveryFailures(ctx, userID, now.Add(-time.Hour), 10); len(failures) != 1 {
+		t.Fatalf("expected no attempt before the retry is due, got %d failures", len(failures))
+	}
+
+	// the last attempt gives up on today's digest
+	db.Model(&nModel.NotificationSettings{}).Where("user_id = ?", userID).Updates(map[string]interface{}{
+		"digest_attempts": maxDeliveryAttempts - 1,
+		"digest_retry_at": now.Add(-time.Second),
+	})
+	db.Model(&nModel.DeliveryFailure{}).Where("user_id = ?", userID).Update("attempt", maxDeliveryAttempts-1)
+	if _, err := scheduler.sendDigestsJob(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	settings, _ = nr.GetNotificationSettings(ctx, userID)
+	if settings.LastDigestAt == nil || settings.LastDigestAt.Before(now) || settings.DigestAttempts != 0 || settings.DigestRetryAt != nil {
+		t.Errorf("expected today's digest to be given up, got %+v", settings)
+	}
+	failures, _ = nr.GetDeliveryFailures(ctx, userID, now.Add(-time.Hour), 10)
+	if len(failures) != 2 || !failures[0].DeadLetter {
+		t.Errorf("expected a dead-lettered digest failure, got %+v", failures)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	for attempts, want := range map[int]time.Duration{
+		1:  5 * time.Minute,
+		2:  10 * time.Minute,
+		4:  40 * time.Minute,
+		5:  maxRetryDelay,
+		70: maxRetryDelay,
+	} {
+		if got := retryDelay(attempts); got != want {
+			t.Errorf("retryDelay(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}
+
+func TestRenderMessage(t *testing.T) {
+	due := time.Date(2026, 3, 10, 18, 0, 0, 0, time.UTC).Format(time.RFC3339)
+	tests := []struct {
+		name      string
+		platform  nModel.NotificationPlatform
+		locale    string
+		rawEvent  nModel.JSONB
+		templates map[string]string
+		want      string
+	}{
+		{
+			name:     "built-in template in the user's locale",
+			platform: nModel.NotificationPlatformPushover,
+			locale:   "fr",
+			rawEvent: nModel.JSONB{"type": "completed", "name": "Vaisselle", "completed_by": "Sam"},
+			want:     "🎉 Vaisselle a été terminée par Sam.",
+		},
+		{
+			name:      "circle template formatted for telegram",
+			platform:  nModel.NotificationPlatformTelegram,
+			rawEvent:  nModel.JSONB{"type": "due", "name": "Dishes", "due_date": due},
+			templates: map[string]string{"due": "{{bold .name}} at {{time .due_date}}"},
+			want:      "*Dishes* at 18:00",
+		},
+		{
+			name:     "custom escalation message is kept",
+			platform: nModel.NotificationPlatformPushover,
+			rawEvent: nModel.JSONB{"type": "escalation", "name": "Dishes", "custom": true},
+			want:     "planned",
+		},
+		{
+			name:      "invalid circle template keeps the planned text",
+			platform:  nModel.NotificationPlatformPushover,
+			rawEvent:  nModel.JSONB{"type": "due", "name": "Dishes"},
+			templates: map[string]string{"due": "{{.name | shout}}"},
+			want:      "planned",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notification := &nModel.NotificationDetails{
+				Notification: nModel.Notification{TypeID: tt.platform, Text: "planned", RawEvent: tt.rawEvent},
+				Locale:       tt.locale,
+			}
+			renderMessage(context.Background(), notification, tt.templates)
+			if notification.Text != tt.want {
+				t.Errorf("got %q, want %q", notification.Text, tt.want)
+			}
+		})
+	}
+}
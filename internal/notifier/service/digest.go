@@ -0,0 +1,131 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	chModel "donetick.com/core/internal/chore/model"
+	nModel "donetick.com/core/internal/notifier/model"
+)
+
+// IsDigestible reports whether a reminder of this type is folded into the daily digest for users
+// who opted in. Escalations go to other people and nagging reminders, which only continue once one
+// is sent, were asked for on top of the digest, so both are always sent on their own.
+func IsDigestible(eventType EventType) bool {
+	switch eventType {
+	case EventTypeDue, EventTypePreDue, EventTypeOverdue, EventTypeUnknown, "":
+		return true
+	default:
+		return false
+	}
+}
+
+// DigestEnd returns the end of the user's local day, chores due before it are part of the digest
+func DigestEnd(now time.Time, loc *time.Location) time.Time {
+	local := now.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, loc).UTC()
+}
+
+// BuildDigests batches the user's due and overdue chores into one notification per target. A chore
+// goes to the targets the user's routing rules pick for its due or overdue reminder and to the
+// primary target when no rule matches, the targets its reminders would have reached. It returns
+// nothing when there is nothing to report.
+func BuildDigests(settings *nModel.NotificationSettingsDetails, chores []*chModel.Chore, routes []*nModel.NotificationRoute, labelIDs map[int][]int, now time.Time) []*nModel.NotificationDetails {
+	// digests by target ID, so a rule naming the primary target doesn't send it a second digest
+	routed := make(map[int]*nModel.NotificationDetails)
+	var primary *nModel.NotificationDetails
+	if settings.TargetType != nModel.NotificationPlatformNone {
+		primary = digestTo(settings, settings.TargetRowID, settings.TargetType, settings.TargetID, settings.WebhookHeaders, settings.WebhookBodyTemplate, now)
+		if settings.TargetRowID != nil {
+			routed[*settings.TargetRowID] = primary
+		}
+	}
+
+	digests := make([]*nModel.NotificationDetails, 0)
+	choresByDigest := make(map[*nModel.NotificationDetails][]*chModel.Chore)
+	add := func(digest *nModel.NotificationDetails, chore *chModel.Chore) {
+		if _, exists := choresByDigest[digest]; !exists {
+			digests = append(digests, digest)
+		}
+		choresByDigest[digest] = append(choresByDigest[digest], chore)
+	}
+	for _, chore := range chores {
+		if chore.NextDueDate == nil {
+			continue
+		}
+		eventType := EventTypeDue
+		if chore.NextDueDate.Before(now) {
+			eventType = EventTypeOverdue
+		}
+		seen := make(map[int]bool)
+		for _, route := range routes {
+			if seen[route.NotificationTargetID] || !route.Conditions.Matches(string(eventType), chore.Priority, labelIDs[chore.ID]) {
+				continue
+			}
+			seen[route.NotificationTargetID] = true
+			digest, exists := routed[route.NotificationTargetID]
+			if !exists {
+				targetID := route.NotificationTargetID
+				digest = digestTo(settings, &targetID, route.TargetType, route.TargetAddress, route.WebhookHeaders, route.WebhookBodyTemplate, now)
+				routed[route.NotificationTargetID] = digest
+			}
+			add(digest, chore)
+		}
+		if len(seen) == 0 && primary != nil {
+			add(primary, chore)
+		}
+	}
+
+	for _, digest := range digests {
+		fillDigest(digest, choresByDigest[digest], settings.Location(), now)
+	}
+	return digests
+}
+
+// digestTo returns an empty digest of the user addressed to one of their targets
+func digestTo(settings *nModel.NotificationSettingsDetails, targetRowID *int, targetType nModel.NotificationPlatform, targetID string, headers nModel.WebhookHeaders, bodyTemplate *string, now time.Time) *nModel.NotificationDetails {
+	return &nModel.NotificationDetails{
+		Notification: nModel.Notification{
+			UserID:               settings.UserID,
+			CircleID:             settings.CircleID,
+			TargetID:             targetID,
+			TypeID:               targetType,
+			NotificationTargetID: targetRowID,
+			ScheduledFor:         now,
+			CreatedAt:            now,
+		},
+		WebhookHeaders:      headers,
+		WebhookBodyTemplate: bodyTemplate,
+	}
+}
+
+// fillDigest writes the digest's text and event listing the chores, overdue ones first
+func fillDigest(digest *nModel.NotificationDetails, chores []*chModel.Chore, loc *time.Location, now time.Time) {
+	var overdue, dueToday []string
+	choreIDs := make([]int, 0, len(chores))
+	for _, chore := range chores {
+		choreIDs = append(choreIDs, chore.ID)
+		if chore.NextDueDate.Before(now) {
+			overdue = append(overdue, fmt.Sprintf("• *%s* (due %s)", chore.Name, chore.NextDueDate.In(loc).Format("Jan 2 15:04")))
+		} else {
+			dueToday = append(dueToday, fmt.Sprintf("• *%s* at %s", chore.Name, chore.NextDueDate.In(loc).Format("15:04")))
+		}
+	}
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("🗓️ Daily digest: %s to do today.", pluralize(len(choreIDs), "chore")))
+	if len(overdue) > 0 {
+		text.WriteString("\n\nOverdue:\n" + strings.Join(overdue, "\n"))
+	}
+	if len(dueToday) > 0 {
+		text.WriteString("\n\nDue today:\n" + strings.Join(dueToday, "\n"))
+	}
+	digest.Text = text.String()
+	digest.RawEvent = map[string]interface{}{
+		"type":      EventTypeDigest,
+		"chore_ids": choreIDs,
+		"overdue":   len(overdue),
+		"due_today": len(dueToday),
+	}
+}
@@ -46,17 +46,45 @@ func (dn *DiscordNotifier) SendNotification(c context.Context, notification *nMo
 		return errors.New("unable to send notification, text is empty")
 	}
 
-	return dn.sendMessage(c, notification.TargetID, notification.Text)
+	name, _ := notification.RawEvent["name"].(string)
+	if name == "" {
+		return dn.sendMessage(c, notification.TargetID, notification.Text)
+	}
+	eventType, _ := notification.RawEvent["type"].(string)
+	return dn.sendPayload(c, notification.TargetID, map[string]interface{}{
+		"embeds": []embed{{Title: name, Description: notification.Text, Color: embedColor(eventType)}},
+	})
+}
+
+// embed is a Discord rich embed, chore notifications are sent as one titled with the chore name
+type embed struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Color       int    `json:"color"`
+}
+
+func embedColor(eventType string) int {
+	switch eventType {
+	case "overdue", "nagging", "escalation":
+		return 0xE53935
+	case "completed", "approval":
+		return 0x43A047
+	default:
+		return 0x1E88E5
+	}
 }
 
 func (dn *DiscordNotifier) sendMessage(c context.Context, webhookURL string, message string) error {
+	return dn.sendPayload(c, webhookURL, map[string]string{"content": message})
+}
+
+func (dn *DiscordNotifier) sendPayload(c context.Context, webhookURL string, payload interface{}) error {
 	log := logging.FromContext(c)
 
 	if webhookURL == "" {
 		return errors.New("unable to send notification, webhook URL is empty")
 	}
 
-	payload := map[string]string{"content": message}
 	jsonData, err := json.Marshal(payload)
 	if err != nil {
 		log.Error("Error marshaling JSON:", err)
@@ -0,0 +1,167 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"net/mail"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"donetick.com/core/config"
+	"donetick.com/core/internal/email"
+	nModel "donetick.com/core/internal/notifier/model"
+	"donetick.com/core/logging"
+)
+
+// Mailer sends a multipart email, implemented by email.EmailSender
+type Mailer interface {
+	SendMultipartEmail(c context.Context, to, subject, textBody, htmlBody string) error
+}
+
+// EmailNotifier delivers reminders over the configured SMTP server, for users who would rather
+// not install a chat app
+type EmailNotifier struct {
+	mailer Mailer
+	signer *ActionSigner
+}
+
+func NewEmailNotifier(cfg *config.Config, sender *email.EmailSender, signer *ActionSigner) *EmailNotifier {
+	if cfg.EmailConfig.Host == "" {
+		return nil
+	}
+	return &EmailNotifier{
+		mailer: sender,
+		signer: signer,
+	}
+}
+
+// ValidateTarget checks an email notification target before it is saved
+func ValidateTarget(address string) error {
+	parsed, err := mail.ParseAddress(address)
+	if err != nil || parsed.Address != address {
+		return errors.New("email target must be a valid email address")
+	}
+	return nil
+}
+
+// message is the data available to the email templates
+type message struct {
+	Subject   string
+	Heading   string
+	Lines     []string
+	DueDate   string
+	DoneURL   string
+	SnoozeURL string
+}
+
+func (e *EmailNotifier) SendNotification(c context.Context, notification *nModel.NotificationDetails) error {
+	if e == nil {
+		return errors.New("email notifier is not initialized")
+	}
+	if notification.TargetID == "" {
+		return errors.New("unable to send notification, email address is empty")
+	}
+
+	msg := e.newMessage(notification)
+	var text bytes.Buffer
+	if err := textTemplate.Execute(&text, msg); err != nil {
+		return fmt.Errorf("failed to render email text: %w", err)
+	}
+	var html bytes.Buffer
+	if err := htmlTemplate.Execute(&html, msg); err != nil {
+		return fmt.Errorf("failed to render email html: %w", err)
+	}
+
+	if err := e.mailer.SendMultipartEmail(c, notification.TargetID, msg.Subject, text.String(), html.String()); err != nil {
+		logging.FromContext(c).Debugw("Error sending email notification", "error", err)
+		return err
+	}
+	return nil
+}
+
+func (e *EmailNotifier) newMessage(notification *nModel.NotificationDetails) *message {
+	eventType, _ := notification.RawEvent["type"].(string)
+	name, _ := notification.RawEvent["name"].(string)
+	msg := &message{
+		Lines: strings.Split(strings.ReplaceAll(notification.Text, "*", ""), "\n"),
+	}
+
+	switch eventType {
+	case "completed":
+		msg.Subject = fmt.Sprintf("Done: %s", name)
+		msg.Heading = "A chore was completed"
+		return msg
+	case "digest":
+		msg.Subject = "Your chores for today"
+		msg.Heading = "Daily digest"
+		return msg
+	}
+
+	msg.Subject = "Reminder from Donetick"
+	if name != "" {
+		msg.Subject = fmt.Sprintf("Reminder: %s", name)
+	}
+	msg.Heading = "Chore reminder"
+	dueDate := rawEventTime(notification.RawEvent["due_date"])
+	if dueDate != nil {
+		msg.DueDate = dueDate.UTC().Format("Mon Jan 2, 15:04 MST")
+	}
+	if notification.ChoreID != 0 {
+		msg.DoneURL, _ = e.signer.URL(ActionDone, notification.ChoreID, notification.UserID, dueDate)
+		msg.SnoozeURL, _ = e.signer.URL(ActionSnooze, notification.ChoreID, notification.UserID, dueDate)
+	}
+	return msg
+}
+
+// rawEventTime reads a time from a notification's raw event, which holds a string once it has been
+// stored and loaded again
+func rawEventTime(value interface{}) *time.Time {
+	switch v := value.(type) {
+	case *time.Time:
+		return v
+	case time.Time:
+		return &v
+	case string:
+		if parsed, err := time.Parse(time.RFC3339, v); err == nil {
+			return &parsed
+		}
+	}
+	return nil
+}
+
+var textTemplate = texttemplate.Must(texttemplate.New("text").Parse(`{{.Heading}}
+
+{{range .Lines}}{{.}}
+{{end}}{{if .DueDate}}
+Due: {{.DueDate}}
+{{end}}{{if .DoneURL}}
+Mark as done: {{.DoneURL}}
+{{end}}{{if .SnoozeURL}}Snooze for an hour: {{.SnoozeURL}}
+{{end}}
+--
+Sent by Donetick. You can change how you get reminders in your notification settings.
+`))
+
+var htmlTemplate = htmltemplate.Must(htmltemplate.New("html").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="utf-8"><meta name="viewport" content="width=device-width"><title>{{.Subject}}</title></head>
+<body style="margin:0;padding:24px;background:#f4f4f5;font-family:BlinkMacSystemFont,Segoe UI,Helvetica Neue,Arial,sans-serif;color:#18181b;">
+<table role="presentation" cellpadding="0" cellspacing="0" align="center" style="max-width:480px;width:100%;background:#ffffff;border-radius:8px;">
+<tr><td style="padding:24px;">
+<h1 style="margin:0 0 16px;font-size:20px;">{{.Heading}}</h1>
+{{range .Lines}}<p style="margin:0 0 8px;font-size:15px;line-height:22px;">{{.}}</p>
+{{end}}{{if .DueDate}}<p style="margin:16px 0 0;font-size:13px;color:#52525b;">Due {{.DueDate}}</p>
+{{end}}{{if or .DoneURL .SnoozeURL}}<p style="margin:24px 0 0;">
+{{if .DoneURL}}<a href="{{.DoneURL}}" style="display:inline-block;padding:10px 18px;margin-right:8px;background:#06b6d4;color:#ffffff;border-radius:6px;text-decoration:none;font-weight:600;">Mark as done</a>{{end}}
+{{if .SnoozeURL}}<a href="{{.SnoozeURL}}" style="display:inline-block;padding:10px 18px;background:#e4e4e7;color:#18181b;border-radius:6px;text-decoration:none;font-weight:600;">Snooze 1 hour</a>{{end}}
+</p>
+{{end}}</td></tr>
+</table>
+<p style="text-align:center;font-size:12px;color:#71717a;">Sent by Donetick. You can change how you get reminders in your notification settings.</p>
+</body>
+</html>
+`))
@@ -0,0 +1,127 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"donetick.com/core/config"
+	nModel "donetick.com/core/internal/notifier/model"
+)
+
+type fakeMailer struct {
+	to, subject, text, html string
+}
+
+func (m *fakeMailer) SendMultipartEmail(c context.Context, to, subject, textBody, htmlBody string) error {
+	m.to, m.subject, m.text, m.html = to, subject, textBody, htmlBody
+	return nil
+}
+
+func newTestSigner() *ActionSigner {
+	cfg := config.NewConfig()
+	cfg.EmailConfig.AppHost = "https://donetick.example.com/"
+	return NewActionSigner(cfg)
+}
+
+func TestActionSigner(t *testing.T) {
+	signer := newTestSigner()
+	due := time.Date(2026, 3, 10, 18, 0, 0, 0, time.UTC)
+
+	link, ok := signer.URL(ActionDone, 7, 3, &due)
+	if !ok || !strings.HasPrefix(link, "https://donetick.example.com"+ActionRoutePrefix) {
+		t.Fatalf("unexpected link %q", link)
+	}
+	token := strings.TrimPrefix(link, "https://donetick.example.com"+ActionRoutePrefix)
+	expired, _ := signer.Sign(ActionClaims{Action: ActionSnooze, ChoreID: 7, UserID: 3, Expires: time.Now().UTC().Add(-time.Minute).Unix()})
+	forged, _ := (&ActionSigner{secret: []byte("other")}).Sign(ActionClaims{Action: ActionDone, ChoreID: 7, UserID: 3, Expires: time.Now().UTC().Add(time.Hour).Unix()})
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{name: "valid", token: token},
+		{name: "expired", token: expired, wantErr: ErrExpiredActionToken},
+		{name: "signed with another secret", token: forged, wantErr: ErrInvalidActionToken},
+		{name: "tampered payload", token: "x" + token, wantErr: ErrInvalidActionToken},
+		{name: "garbage", token: "not-a-token", wantErr: ErrInvalidActionToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := signer.Verify(tt.token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr == nil && (claims.Action != ActionDone || claims.ChoreID != 7 || claims.UserID != 3 || claims.DueDate != due.Unix()) {
+				t.Errorf("unexpected claims %+v", claims)
+			}
+		})
+	}
+}
+
+func TestSendNotification(t *testing.T) {
+	tests := []struct {
+		name        string
+		rawEvent    nModel.JSONB
+		wantSubject string
+		wantLinks   bool
+	}{
+		{
+			name:        "reminder",
+			rawEvent:    nModel.JSONB{"type": "due", "name": "Dishes <3", "due_date": "2026-03-10T18:00:00Z"},
+			wantSubject: "Reminder: Dishes <3",
+			wantLinks:   true,
+		},
+		{
+			name:        "completion notice",
+			rawEvent:    nModel.JSONB{"type": "completed", "name": "Dishes <3"},
+			wantSubject: "Done: Dishes <3",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mailer := &fakeMailer{}
+			notifier := &EmailNotifier{mailer: mailer, signer: newTestSigner()}
+			err := notifier.SendNotification(context.Background(), &nModel.NotificationDetails{
+				Notification: nModel.Notification{
+					ChoreID:  7,
+					UserID:   3,
+					TargetID: "ann@example.com",
+					Text:     "📅 Reminder: *Dishes <3* is due today",
+					RawEvent: tt.rawEvent,
+				},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if mailer.to != "ann@example.com" || mailer.subject != tt.wantSubject {
+				t.Errorf("unexpected recipient %q or subject %q", mailer.to, mailer.subject)
+			}
+			if !strings.Contains(mailer.text, "Reminder: Dishes <3 is due today") {
+				t.Errorf("expected the plain text reminder without markdown, got %q", mailer.text)
+			}
+			if !strings.Contains(mailer.html, "Dishes &lt;3") {
+				t.Errorf("expected the chore name to be escaped in the html body")
+			}
+			if hasLinks := strings.Contains(mailer.html, ActionRoutePrefix); hasLinks != tt.wantLinks {
+				t.Errorf("expected action links=%v", tt.wantLinks)
+			}
+		})
+	}
+}
+
+func TestValidateTarget(t *testing.T) {
+	for address, valid := range map[string]bool{
+		"ann@example.com":         true,
+		"Ann <ann@example.com>":   false,
+		"not an email":            false,
+		"ann@example.com\r\nBcc:": false,
+	} {
+		if err := ValidateTarget(address); (err == nil) != valid {
+			t.Errorf("%q: expected valid=%v, got %v", address, valid, err)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package email
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"donetick.com/core/config"
+)
+
+// ActionRoutePrefix is where the chore handler serves the links in notification emails
+const ActionRoutePrefix = "/api/v1/actions/"
+
+const (
+	actionLinkTTL = 7 * 24 * time.Hour
+	// SnoozeDuration is how far the snooze link pushes the chore's due date
+	SnoozeDuration = time.Hour
+)
+
+type Action string
+
+const (
+	ActionDone   Action = "done"
+	ActionSnooze Action = "snooze"
+)
+
+var (
+	ErrInvalidActionToken = errors.New("invalid action link")
+	ErrExpiredActionToken = errors.New("action link has expired")
+)
+
+// ActionClaims identify what a link in a notification email does and for whom. DueDate is the due
+// date the email was sent for, so an old link doesn't act on a later occurrence of the chore.
+type ActionClaims struct {
+	Action  Action `json:"a"`
+	ChoreID int    `json:"c"`
+	UserID  int    `json:"u"`
+	DueDate int64  `json:"d,omitempty"`
+	Expires int64  `json:"e"`
+}
+
+// ActionSigner signs the one-click links in notification emails. The link is the credential, the
+// recipient isn't logged in when they click it.
+type ActionSigner struct {
+	secret  []byte
+	baseURL string
+}
+
+func NewActionSigner(cfg *config.Config) *ActionSigner {
+	baseURL := ""
+	if cfg.EmailConfig.AppHost != "" {
+		baseURL = strings.TrimSuffix(cfg.EmailConfig.AppHost, "/") + ActionRoutePrefix
+	}
+	return &ActionSigner{
+		secret:  []byte(cfg.Jwt.Secret),
+		baseURL: baseURL,
+	}
+}
+
+// URL returns a signed link performing the action, or false when no app host is configured
+func (s *ActionSigner) URL(action Action, choreID, userID int, dueDate *time.Time) (string, bool) {
+	if s == nil || s.baseURL == "" {
+		return "", false
+	}
+	claims := ActionClaims{
+		Action:  action,
+		ChoreID: choreID,
+		UserID:  userID,
+		Expires: time.Now().UTC().Add(actionLinkTTL).Unix(),
+	}
+	if dueDate != nil {
+		claims.DueDate = dueDate.Unix()
+	}
+	token, err := s.Sign(claims)
+	if err != nil {
+		return "", false
+	}
+	return s.baseURL + token, true
+}
+
+func (s *ActionSigner) Sign(claims ActionClaims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + s.sign(encoded), nil
+}
+
+// Verify checks the token's signature and expiry and returns its claims
+func (s *ActionSigner) Verify(token string) (*ActionClaims, error) {
+	encoded, sig, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(s.sign(encoded)), []byte(sig)) {
+		return nil, ErrInvalidActionToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidActionToken
+	}
+	claims := &ActionClaims{}
+	if err := json.Unmarshal(payload, claims); err != nil {
+		return nil, ErrInvalidActionToken
+	}
+	if claims.Action != ActionDone && claims.Action != ActionSnooze {
+		return nil, ErrInvalidActionToken
+	}
+	if time.Now().UTC().Unix() > claims.Expires {
+		return nil, ErrExpiredActionToken
+	}
+	return claims, nil
+}
+
+func (s *ActionSigner) sign(encoded string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte("email-action:" + encoded))
+	return hex.EncodeToString(mac.Sum(nil))
+}
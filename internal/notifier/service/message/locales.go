@@ -0,0 +1,105 @@
+package message
+
+import (
+	"sort"
+	"strings"
+)
+
+const DefaultLocale = "en"
+
+// builtinTemplates are the reminder texts used when a circle has no template of its own, by
+// locale and event type. Every locale must cover the event types of the default locale.
+var builtinTemplates = map[string]map[string]string{
+	"en": {
+		"pre_due":    "⏰ Heads up: {{bold .name}} is due on {{date .due_date}} at {{time .due_date}} and assigned to {{.assignee}}.",
+		"due":        "📅 Reminder: {{bold .name}} is due today at {{time .due_date}} and assigned to {{.assignee}}.",
+		"overdue":    "⚠️ {{bold .name}} was due on {{date .due_date}} at {{time .due_date}} and is still assigned to {{.assignee}}.",
+		"nagging":    "🔔 Reminder: {{bold .name}} is overdue and still assigned to {{.assignee}}.",
+		"escalation": "⚠️ Escalation: {{bold .name}} assigned to {{.assignee}} is still overdue.",
+		"completed":  "🎉 {{bold .name}} was completed by {{.completed_by}}.",
+		"approval":   "✅ {{bold .name}} was completed by {{.completed_by}} and is waiting for approval.",
+	},
+	"de": {
+		"pre_due":    "⏰ Vorschau: {{bold .name}} ist am {{date .due_date}} um {{time .due_date}} fällig und {{.assignee}} zugewiesen.",
+		"due":        "📅 Erinnerung: {{bold .name}} ist heute um {{time .due_date}} fällig und {{.assignee}} zugewiesen.",
+		"overdue":    "⚠️ {{bold .name}} war am {{date .due_date}} um {{time .due_date}} fällig und ist weiterhin {{.assignee}} zugewiesen.",
+		"nagging":    "🔔 Erinnerung: {{bold .name}} ist überfällig und weiterhin {{.assignee}} zugewiesen.",
+		"escalation": "⚠️ Eskalation: {{bold .name}} ({{.assignee}}) ist immer noch überfällig.",
+		"completed":  "🎉 {{bold .name}} wurde von {{.completed_by}} erledigt.",
+		"approval":   "✅ {{bold .name}} wurde von {{.completed_by}} erledigt und wartet auf Freigabe.",
+	},
+	"es": {
+		"pre_due":    "⏰ Aviso: {{bold .name}} vence el {{date .due_date}} a las {{time .due_date}} y está asignada a {{.assignee}}.",
+		"due":        "📅 Recordatorio: {{bold .name}} vence hoy a las {{time .due_date}} y está asignada a {{.assignee}}.",
+		"overdue":    "⚠️ {{bold .name}} venció el {{date .due_date}} a las {{time .due_date}} y sigue asignada a {{.assignee}}.",
+		"nagging":    "🔔 Recordatorio: {{bold .name}} está vencida y sigue asignada a {{.assignee}}.",
+		"escalation": "⚠️ Escalado: {{bold .name}}, asignada a {{.assignee}}, sigue vencida.",
+		"completed":  "🎉 {{.completed_by}} completó {{bold .name}}.",
+		"approval":   "✅ {{.completed_by}} completó {{bold .name}}, pendiente de aprobación.",
+	},
+	"fr": {
+		"pre_due":    "⏰ À venir : {{bold .name}} est prévue le {{date .due_date}} à {{time .due_date}} et assignée à {{.assignee}}.",
+		"due":        "📅 Rappel : {{bold .name}} est prévue aujourd'hui à {{time .due_date}} et assignée à {{.assignee}}.",
+		"overdue":    "⚠️ {{bold .name}} était prévue le {{date .due_date}} à {{time .due_date}} et reste assignée à {{.assignee}}.",
+		"nagging":    "🔔 Rappel : {{bold .name}} est en retard et reste assignée à {{.assignee}}.",
+		"escalation": "⚠️ Escalade : {{bold .name}}, assignée à {{.assignee}}, est toujours en retard.",
+		"completed":  "🎉 {{bold .name}} a été terminée par {{.completed_by}}.",
+		"approval":   "✅ {{bold .name}} a été terminée par {{.completed_by}} et attend une validation.",
+	},
+}
+
+// dateLayouts format the date function per locale, Go doesn't translate month names
+var dateLayouts = map[string]string{
+	"en": "Jan 2",
+	"de": "02.01.",
+	"es": "02/01",
+	"fr": "02/01",
+}
+
+// SupportedLocales returns the locales with built-in templates
+func SupportedLocales() []string {
+	locales := make([]string, 0, len(builtinTemplates))
+	for locale := range builtinTemplates {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// NormalizeLocale maps a locale such as "de-AT" to a supported one, or "" when unsupported
+func NormalizeLocale(locale string) string {
+	language := strings.ToLower(strings.TrimSpace(locale))
+	if i := strings.IndexAny(language, "-_"); i >= 0 {
+		language = language[:i]
+	}
+	if _, ok := builtinTemplates[language]; !ok {
+		return ""
+	}
+	return language
+}
+
+// EventTypes returns the event types rendered from templates
+func EventTypes() []string {
+	eventTypes := make([]string, 0, len(builtinTemplates[DefaultLocale]))
+	for eventType := range builtinTemplates[DefaultLocale] {
+		eventTypes = append(eventTypes, eventType)
+	}
+	sort.Strings(eventTypes)
+	return eventTypes
+}
+
+// Templated reports whether notifications of the event type are rendered from templates. Digests
+// and nudges carry their own text.
+func Templated(eventType string) bool {
+	_, ok := builtinTemplates[DefaultLocale][eventType]
+	return ok
+}
+
+// BuiltinTemplate returns the built-in template of the event type in the locale, falling back to
+// the default locale
+func BuiltinTemplate(locale, eventType string) string {
+	if body, ok := builtinTemplates[NormalizeLocale(locale)][eventType]; ok {
+		return body
+	}
+	return builtinTemplates[DefaultLocale][eventType]
+}
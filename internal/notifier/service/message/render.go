@@ -0,0 +1,265 @@
+// Package message renders notification text from per-circle or built-in templates. Templates use
+// Go text/template syntax over the notification's raw event, e.g. "{{bold .name}} is due
+// {{date .due_date}}", and are rendered for the platform and locale of each recipient.
+package message
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	tparse "text/template/parse"
+	"time"
+
+	nModel "donetick.com/core/internal/notifier/model"
+)
+
+// Format is the markup a platform understands
+type Format string
+
+const (
+	FormatPlain    Format = "plain"
+	FormatTelegram Format = "telegram"
+	FormatDiscord  Format = "discord"
+
+	MaxTemplateLength = 1000
+	// maxMessageLength caps a rendered message while it is rendered, Telegram's message limit
+	maxMessageLength = 4096
+)
+
+var errMessageTooLong = errors.New("rendered message is too long")
+
+// logicFuncs are the text/template built-ins templates may call besides the message functions.
+// They compare values without looping or producing output of their own.
+var logicFuncs = map[string]bool{"eq": true, "ne": true, "not": true, "and": true, "or": true}
+
+// fields are the raw event keys templates may use. They're always set when rendering, so a
+// template written for one event type doesn't print "<no value>" for another.
+var fields = []string{
+	"id", "type", "name", "due_date", "assignee", "assignee_username",
+	"completed_by", "completed_by_username", "level", "nag_count",
+}
+
+// FormatFor returns the format notifications are sent in on the platform
+func FormatFor(platform nModel.NotificationPlatform) Format {
+	switch platform {
+	case nModel.NotificationPlatformTelegram:
+		return FormatTelegram
+	case nModel.NotificationPlatformDiscord:
+		return FormatDiscord
+	default:
+		return FormatPlain
+	}
+}
+
+// Validate checks that the template parses, only uses known fields and renders within the length limit
+func Validate(body string) error {
+	if strings.TrimSpace(body) == "" {
+		return errors.New("template is empty")
+	}
+	if len(body) > MaxTemplateLength {
+		return fmt.Errorf("template is longer than %d characters", MaxTemplateLength)
+	}
+	tmpl, err := parse(body, DefaultLocale, FormatPlain, time.UTC)
+	if err != nil {
+		return err
+	}
+	out := &limitedWriter{limit: MaxTemplateLength * 2}
+	return tmpl.Option("missingkey=error").Execute(out, SampleData("due", time.Now().UTC()))
+}
+
+// Render executes the template over the raw event. String values are escaped for the format, so
+// a chore named "clean_up *now*" doesn't break the platform's markup.
+func Render(body string, data nModel.JSONB, locale string, format Format, loc *time.Location) (string, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	tmpl, err := parse(body, locale, format, loc)
+	if err != nil {
+		return "", err
+	}
+	values := make(map[string]interface{}, len(fields)+len(data))
+	for _, field := range fields {
+		values[field] = ""
+	}
+	for key, value := range data {
+		if s, ok := value.(string); ok {
+			value = escape(s, format)
+		}
+		values[key] = value
+	}
+	out := &limitedWriter{limit: maxMessageLength}
+	if err := tmpl.Execute(out, values); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out.sb.String()), nil
+}
+
+// limitedWriter fails the render as soon as the message outgrows its limit
+type limitedWriter struct {
+	sb    strings.Builder
+	limit int
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if w.sb.Len()+len(p) > w.limit {
+		return 0, errMessageTooLong
+	}
+	return w.sb.Write(p)
+}
+
+// Preview renders the template, or the built-in one when body is empty, over sample data
+func Preview(eventType, body, locale string, format Format, loc *time.Location) (string, error) {
+	if body == "" {
+		body = BuiltinTemplate(locale, eventType)
+	}
+	return Render(body, SampleData(eventType, time.Now().UTC()), locale, format, loc)
+}
+
+// SampleData is a raw event as the planner would generate it for a chore due in an hour
+func SampleData(eventType string, now time.Time) nModel.JSONB {
+	return nModel.JSONB{
+		"id":                    1,
+		"type":                  eventType,
+		"name":                  "Take out the trash",
+		"due_date":              now.Add(time.Hour).Truncate(time.Minute).Format(time.RFC3339),
+		"assignee":              "Alex",
+		"assignee_username":     "alex",
+		"completed_by":          "Sam",
+		"completed_by_username": "sam",
+		"level":                 1,
+		"nag_count":             2,
+	}
+}
+
+func parse(body, locale string, format Format, loc *time.Location) (*template.Template, error) {
+	layout, ok := dateLayouts[NormalizeLocale(locale)]
+	if !ok {
+		layout = dateLayouts[DefaultLocale]
+	}
+	funcs := messageFuncs(format, loc, layout)
+	tmpl, err := template.New("message").Funcs(funcs).Parse(body)
+	if err != nil {
+		return nil, err
+	}
+	if len(tmpl.Templates()) > 1 {
+		return nil, errors.New("templates cannot define other templates")
+	}
+	if err := checkNode(tmpl.Tree.Root, funcs); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// checkNode only lets templates print fields through the message functions and choose text with
+// if/else, so rendering takes time in proportion to the template's length. range over a number,
+// printf padding or template calls could otherwise stall the delivery of every notification.
+func checkNode(node tparse.Node, funcs template.FuncMap) error {
+	switch n := node.(type) {
+	case nil:
+		return nil
+	case *tparse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := checkNode(child, funcs); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *tparse.TextNode, *tparse.CommentNode:
+		return nil
+	case *tparse.ActionNode:
+		return checkNode(n.Pipe, funcs)
+	case *tparse.IfNode:
+		if err := checkNode(n.Pipe, funcs); err != nil {
+			return err
+		}
+		if err := checkNode(n.List, funcs); err != nil {
+			return err
+		}
+		return checkNode(n.ElseList, funcs)
+	case *tparse.PipeNode:
+		if n == nil {
+			return nil
+		}
+		if len(n.Decl) > 0 {
+			return errors.New("templates cannot declare variables")
+		}
+		for _, cmd := range n.Cmds {
+			for _, arg := range cmd.Args {
+				if err := checkNode(arg, funcs); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	case *tparse.IdentifierNode:
+		if _, ok := funcs[n.Ident]; ok || logicFuncs[n.Ident] {
+			return nil
+		}
+		return fmt.Errorf("function %q is not available in templates", n.Ident)
+	case *tparse.FieldNode, *tparse.DotNode, *tparse.StringNode, *tparse.NumberNode, *tparse.BoolNode, *tparse.NilNode:
+		return nil
+	default:
+		return fmt.Errorf("templates can only use fields, bold, date, time and if/else, got %s", node)
+	}
+}
+
+func messageFuncs(format Format, loc *time.Location, layout string) template.FuncMap {
+	return template.FuncMap{
+		"bold": func(s interface{}) string {
+			switch format {
+			case FormatTelegram:
+				return "*" + fmt.Sprint(s) + "*"
+			case FormatDiscord:
+				return "**" + fmt.Sprint(s) + "**"
+			}
+			return fmt.Sprint(s)
+		},
+		"date": func(v interface{}) string {
+			if t := timeOf(v); t != nil {
+				return t.In(loc).Format(layout)
+			}
+			return ""
+		},
+		"time": func(v interface{}) string {
+			if t := timeOf(v); t != nil {
+				return t.In(loc).Format("15:04")
+			}
+			return ""
+		},
+	}
+}
+
+// timeOf reads a raw event date, a time before the notification is stored and a string after
+func timeOf(v interface{}) *time.Time {
+	switch t := v.(type) {
+	case *time.Time:
+		return t
+	case time.Time:
+		return &t
+	case string:
+		if parsed, err := time.Parse(time.RFC3339, t); err == nil {
+			return &parsed
+		}
+	}
+	return nil
+}
+
+func escape(s string, format Format) string {
+	switch format {
+	case FormatTelegram:
+		return telegramEscaper.Replace(s)
+	case FormatDiscord:
+		return discordEscaper.Replace(s)
+	}
+	return s
+}
+
+var (
+	// Telegram's legacy Markdown, which the Telegram notifier sends
+	telegramEscaper = strings.NewReplacer("_", "\\_", "*", "\\*", "`", "\\`", "[", "\\[")
+	discordEscaper  = strings.NewReplacer("\\", "\\\\", "*", "\\*", "_", "\\_", "~", "\\~", "`", "\\`", "|", "\\|", ">", "\\>")
+)
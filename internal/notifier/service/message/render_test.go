@@ -0,0 +1,125 @@
+package message
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	nModel "donetick.com/core/internal/notifier/model"
+)
+
+func TestRender(t *testing.T) {
+	due := time.Date(2026, 3, 10, 18, 30, 0, 0, time.UTC)
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	tests := []struct {
+		name   string
+		body   string
+		data   nModel.JSONB
+		locale string
+		format Format
+		loc    *time.Location
+		want   string
+	}{
+		{
+			name:   "built-in template as plain text",
+			body:   BuiltinTemplate("en", "due"),
+			data:   nModel.JSONB{"name": "Dishes", "due_date": due.Format(time.RFC3339), "assignee": "Alex"},
+			format: FormatPlain,
+			want:   "📅 Reminder: Dishes is due today at 18:30 and assigned to Alex.",
+		},
+		{
+			name:   "telegram escapes markdown in values",
+			body:   "{{bold .name}} by {{.assignee}}",
+			data:   nModel.JSONB{"name": "clean_up *now*", "assignee": "Alex"},
+			format: FormatTelegram,
+			want:   "*clean\\_up \\*now\\** by Alex",
+		},
+		{
+			name:   "discord bold",
+			body:   "{{bold .name}}",
+			data:   nModel.JSONB{"name": "Dishes"},
+			format: FormatDiscord,
+			want:   "**Dishes**",
+		},
+		{
+			name:   "dates in the user's timezone and locale",
+			body:   BuiltinTemplate("de", "pre_due"),
+			data:   nModel.JSONB{"name": "Müll", "due_date": &due, "assignee": "Alex"},
+			locale: "de-AT",
+			format: FormatPlain,
+			loc:    berlin,
+			want:   "⏰ Vorschau: Müll ist am 10.03. um 19:30 fällig und Alex zugewiesen.",
+		},
+		{
+			name:   "fields missing from the event render empty",
+			body:   "{{.name}} done by {{.completed_by}}!",
+			data:   nModel.JSONB{"name": "Dishes"},
+			format: FormatPlain,
+			want:   "Dishes done by !",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Render(tt.body, tt.data, tt.locale, tt.format, tt.loc)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuiltinTemplates(t *testing.T) {
+	for _, locale := range SupportedLocales() {
+		for _, eventType := range EventTypes() {
+			body, ok := builtinTemplates[locale][eventType]
+			if !ok {
+				t.Errorf("locale %s has no %s template", locale, eventType)
+				continue
+			}
+			if err := Validate(body); err != nil {
+				t.Errorf("%s %s template is invalid: %v", locale, eventType, err)
+			}
+		}
+	}
+	if got := BuiltinTemplate("pt-BR", "due"); got != builtinTemplates[DefaultLocale]["due"] {
+		t.Errorf("unsupported locales should fall back to %s, got %q", DefaultLocale, got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr bool
+	}{
+		{name: "valid", body: "{{bold .name}} is due {{date .due_date}}"},
+		{name: "empty", body: "  ", wantErr: true},
+		{name: "syntax error", body: "{{.name", wantErr: true},
+		{name: "unknown field", body: "{{.nmae}} is due", wantErr: true},
+		{name: "unknown function", body: "{{shout .name}}", wantErr: true},
+		{name: "if on a field", body: "{{if eq .type \"due\"}}Due{{else}}Done{{end}} {{.name}}"},
+		{name: "range over a number", body: "{{range 300000000}}{{end}}x", wantErr: true},
+		{name: "with", body: "{{with .name}}{{.}}{{end}}", wantErr: true},
+		{name: "printf padding", body: "{{printf \"%0999999d\" 1}}", wantErr: true},
+		{name: "template definitions", body: "{{define \"x\"}}{{.name}}{{end}}{{template \"x\" .}}", wantErr: true},
+		{name: "variables", body: "{{$n := .name}}{{$n}}", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Validate(tt.body); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRenderStopsAtMessageLimit(t *testing.T) {
+	_, err := Render("{{.name}}{{.name}}", nModel.JSONB{"name": strings.Repeat("x", maxMessageLength/2+1)}, "", FormatPlain, nil)
+	if !errors.Is(err, errMessageTooLong) {
+		t.Errorf("expected the render to stop at the message limit, got %v", err)
+	}
+}
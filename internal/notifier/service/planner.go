@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	chModel "donetick.com/core/internal/chore/model"
@@ -41,7 +42,8 @@ func (n *NotificationPlanner) GenerateNotifications(c context.Context, chore *ch
 	}
 	n.nRepo.DeleteAllChoreNotifications(chore.ID)
 	notifications := make([]*nModel.Notification, 0)
-	if !chore.Notification || chore.FrequencyType == "trigger" {
+	// a blocked chore isn't reminded of until its prerequisites are completed
+	if !chore.Notification || chore.FrequencyType == "trigger" || chore.IsBlocked {
 
 		return true
 	}
@@ -58,11 +60,105 @@ func (n *NotificationPlanner) GenerateNotifications(c context.Context, chore *ch
 		notifications = append(notifications, generateNotificationsFromTemplate(chore, assignedUser, chore.NotificationMetadataV2.CircleGroupID)...)
 	}
 
+	if len(chore.NotificationMetadataV2.Escalations) > 0 && assignedUser != nil {
+		notifications = append(notifications, generateEscalationNotifications(chore, assignedUser, circleMembers)...)
+	}
+
+	if chore.NotificationMetadataV2.Nagging && assignedUser != nil {
+		if nag := generateNagNotification(chore, assignedUser, time.Now().UTC(), 1); nag != nil {
+			notifications = append(notifications, nag)
+		}
+	}
+
+	notifications = n.routeNotifications(c, chore, notifications)
 	log.Debug("Generated notifications", "count", len(notifications))
 	n.nRepo.BatchInsertNotifications(notifications)
 	return true
 }
 
+// routeNotifications fans the notifications sent to a user's primary target out to the targets of
+// the user's matching routing rules. Notifications to circle groups aren't routed.
+func (n *NotificationPlanner) routeNotifications(c context.Context, chore *chModel.Chore, notifications []*nModel.Notification) []*nModel.Notification {
+	userIDs := make([]int, 0)
+	for _, notification := range notifications {
+		if notification.NotificationTargetID != nil {
+			userIDs = append(userIDs, notification.UserID)
+		}
+	}
+	if len(userIDs) == 0 {
+		return notifications
+	}
+	log := logging.FromContext(c)
+	routes, err := n.nRepo.GetNotificationRoutes(c, userIDs)
+	if err != nil {
+		log.Error("Error getting notification routes", err)
+		routes = nil
+	}
+	var labelIDs []int
+	if len(routes) > 0 {
+		if labelIDs, err = n.nRepo.GetChoreLabelIDs(c, chore.ID); err != nil {
+			log.Error("Error getting chore labels", err)
+		}
+	}
+
+	routed := make([]*nModel.Notification, 0, len(notifications))
+	for _, notification := range notifications {
+		if notification.NotificationTargetID == nil {
+			routed = append(routed, notification)
+			continue
+		}
+		routed = append(routed, routeNotification(notification, chore.Priority, labelIDs, routes[notification.UserID])...)
+	}
+	return routed
+}
+
+// routeNotification returns a copy of the notification for each target of the matching rules, or
+// the notification itself when no rule matches. Opt-in event types are dropped without a rule.
+func routeNotification(notification *nModel.Notification, priority int, labelIDs []int, routes []*nModel.NotificationRoute) []*nModel.Notification {
+	eventType := rawEventType(notification.RawEvent)
+	routed := make([]*nModel.Notification, 0)
+	seen := make(map[int]bool)
+	for _, route := range routes {
+		if seen[route.NotificationTargetID] || !route.Conditions.Matches(eventType, priority, labelIDs) {
+			continue
+		}
+		seen[route.NotificationTargetID] = true
+		targetID := route.NotificationTargetID
+		copied := *notification
+		copied.TypeID = route.TargetType
+		copied.TargetID = route.TargetAddress
+		copied.NotificationTargetID = &targetID
+		routed = append(routed, &copied)
+	}
+	if len(routed) > 0 {
+		return routed
+	}
+	if IsOptIn(EventType(eventType)) {
+		return nil
+	}
+	return []*nModel.Notification{notification}
+}
+
+func rawEventType(rawEvent nModel.JSONB) string {
+	switch eventType := rawEvent["type"].(type) {
+	case EventType:
+		return string(eventType)
+	case string:
+		return eventType
+	default:
+		return ""
+	}
+}
+
+// primaryTarget returns the ID of the member's primary notification target, nil when they have none
+func primaryTarget(member *cModel.UserCircleDetail) *int {
+	if member.NotificationTargetID == 0 {
+		return nil
+	}
+	targetID := member.NotificationTargetID
+	return &targetID
+}
+
 func getEventTypeFromTemplate(template *chModel.NotificationTemplate) EventType {
 	switch {
 	case template == nil:
@@ -76,6 +172,148 @@ func getEventTypeFromTemplate(template *chModel.NotificationTemplate) EventType
 	}
 }
 
+// GenerateCompletionNotifications tells the chore's creator that someone else completed it, when
+// completion notifications are enabled for the chore
+func (n *NotificationPlanner) GenerateCompletionNotifications(c context.Context, chore *chModel.Chore, completedBy int) {
+	log := logging.FromContext(c)
+	if !chore.Notification || chore.NotificationMetadataV2 == nil || !chore.NotificationMetadataV2.Completion || chore.CreatedBy == completedBy {
+		return
+	}
+	circleMembers, err := n.cRepo.GetCircleUsers(c, chore.CircleID)
+	if err != nil {
+		log.Error("Error getting circle members", err)
+		return
+	}
+	if notification := generateCompletionNotification(chore, completedBy, circleMembers, time.Now().UTC()); notification != nil {
+		n.nRepo.BatchInsertNotifications(n.routeNotifications(c, chore, []*nModel.Notification{notification}))
+	}
+}
+
+// GenerateNudgeNotifications forwards a nudge to the users' targets that have a routing rule for
+// nudges. Nudges are pushed to the users' devices either way.
+func (n *NotificationPlanner) GenerateNudgeNotifications(c context.Context, chore *chModel.Chore, userIDs []int, message string) {
+	circleMembers, err := n.cRepo.GetCircleUsers(c, chore.CircleID)
+	if err != nil {
+		logging.FromContext(c).Error("Error getting circle members", err)
+		return
+	}
+	now := time.Now().UTC()
+	notifications := make([]*nModel.Notification, 0)
+	for _, member := range circleMembers {
+		if member.NotificationTargetID == 0 || !containsUser(userIDs, member.UserID) {
+			continue
+		}
+		notifications = append(notifications, &nModel.Notification{
+			ChoreID:              chore.ID,
+			IsSent:               false,
+			ScheduledFor:         now,
+			CreatedAt:            now,
+			TypeID:               member.NotificationType,
+			UserID:               member.UserID,
+			CircleID:             chore.CircleID,
+			TargetID:             member.TargetID,
+			NotificationTargetID: primaryTarget(member),
+			Text:                 message,
+			RawEvent: map[string]interface{}{
+				"id":   chore.ID,
+				"type": EventTypeNudge,
+				"name": chore.Name,
+			},
+		})
+	}
+	if routed := n.routeNotifications(c, chore, notifications); len(routed) > 0 {
+		n.nRepo.BatchInsertNotifications(routed)
+	}
+}
+
+// GenerateApprovalNotifications tells the circle's managers and admins that a chore is waiting for
+// their approval, on the targets that have a routing rule for approvals
+func (n *NotificationPlanner) GenerateApprovalNotifications(c context.Context, chore *chModel.Chore, submittedBy int) {
+	circleMembers, err := n.cRepo.GetCircleUsers(c, chore.CircleID)
+	if err != nil {
+		logging.FromContext(c).Error("Error getting circle members", err)
+		return
+	}
+	submitter := ""
+	for _, member := range circleMembers {
+		if member.UserID == submittedBy {
+			submitter = member.DisplayName
+		}
+	}
+	now := time.Now().UTC()
+	notifications := make([]*nModel.Notification, 0)
+	for _, member := range circleMembers {
+		if !member.IsActive || !member.IsManagerOrAdmin() || member.UserID == submittedBy || member.NotificationTargetID == 0 {
+			continue
+		}
+		notifications = append(notifications, &nModel.Notification{
+			ChoreID:              chore.ID,
+			IsSent:               false,
+			ScheduledFor:         now,
+			CreatedAt:            now,
+			TypeID:               member.NotificationType,
+			UserID:               member.UserID,
+			CircleID:             chore.CircleID,
+			TargetID:             member.TargetID,
+			NotificationTargetID: primaryTarget(member),
+			Text:                 fmt.Sprintf("✅ *%s* was completed by %s and is waiting for approval.", chore.Name, submitter),
+			RawEvent: map[string]interface{}{
+				"id":           chore.ID,
+				"type":         EventTypeApproval,
+				"name":         chore.Name,
+				"completed_by": submitter,
+			},
+		})
+	}
+	if routed := n.routeNotifications(c, chore, notifications); len(routed) > 0 {
+		n.nRepo.BatchInsertNotifications(routed)
+	}
+}
+
+func containsUser(userIDs []int, userID int) bool {
+	for _, id := range userIDs {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+func generateCompletionNotification(chore *chModel.Chore, completedBy int, circleMembers []*cModel.UserCircleDetail, now time.Time) *nModel.Notification {
+	var creator, completer *cModel.UserCircleDetail
+	for _, member := range circleMembers {
+		switch member.UserID {
+		case chore.CreatedBy:
+			creator = member
+		case completedBy:
+			completer = member
+		}
+	}
+	if creator == nil || completer == nil || creator.NotificationType == nModel.NotificationPlatformNone {
+		return nil
+	}
+	return &nModel.Notification{
+		ChoreID:      chore.ID,
+		IsSent:       false,
+		ScheduledFor: now,
+		CreatedAt:    now,
+		TypeID:       creator.NotificationType,
+		UserID:       creator.UserID,
+		CircleID:     chore.CircleID,
+		TargetID:     creator.TargetID,
+		Text:         fmt.Sprintf("🎉 *%s* was completed by %s.", chore.Name, completer.DisplayName),
+		// routed like the creator's other notifications
+		NotificationTargetID: primaryTarget(creator),
+		RawEvent: map[string]interface{}{
+			"id":                    chore.ID,
+			"type":                  EventTypeCompleted,
+			"name":                  chore.Name,
+			"completed_by":          completer.DisplayName,
+			"completed_by_username": completer.Username,
+		},
+	}
+}
+
 // calculateDuration calculates duration based on unit and value
 func calculateDuration(value int, unit chModel.NotificationTemplateUnit) (time.Duration, error) {
 	switch unit {
@@ -109,8 +347,10 @@ func generateNotificationsFromTemplate(chore *chModel.Chore, assignedUser *cMode
 		return nil // No templates to process
 	}
 	targetID := assignedUser.TargetID
+	notificationTargetID := primaryTarget(assignedUser)
 	if overrideTarget != nil {
 		targetID = fmt.Sprint(*overrideTarget)
+		notificationTargetID = nil
 	}
 	notifications := make([]*nModel.Notification, 0)
 
@@ -136,6 +376,8 @@ func generateNotificationsFromTemplate(chore *chModel.Chore, assignedUser *cMode
 			CircleID:     assignedUser.CircleID,
 			TargetID:     targetID,
 			Text:         fmt.Sprintf("📅 Reminder: *%s* is due today and assigned to %s.", chore.Name, assignedUser.DisplayName),
+			// nil for circle group reminders, which aren't routed
+			NotificationTargetID: notificationTargetID,
 			RawEvent: map[string]interface{}{
 				"id":                chore.ID,
 				"type":              eventType,
@@ -154,8 +396,230 @@ func generateNotificationsFromTemplate(chore *chModel.Chore, assignedUser *cMode
 type EventType string
 
 const (
-	EventTypeUnknown EventType = "unknown"
-	EventTypeDue     EventType = "due"
-	EventTypePreDue  EventType = "pre_due"
-	EventTypeOverdue EventType = "overdue"
+	EventTypeUnknown    EventType = "unknown"
+	EventTypeDue        EventType = "due"
+	EventTypePreDue     EventType = "pre_due"
+	EventTypeOverdue    EventType = "overdue"
+	EventTypeNagging    EventType = "nagging"
+	EventTypeEscalation EventType = "escalation"
+	EventTypeDigest     EventType = "digest"
+	EventTypeCompleted  EventType = "completed"
+	EventTypeNudge      EventType = "nudge"
+	EventTypeApproval   EventType = "approval"
 )
+
+// IsOptIn reports whether notifications of the event type are only sent to targets with a routing
+// rule naming it. Nudges are pushed to the app and approvals are shown there already.
+func IsOptIn(eventType EventType) bool {
+	return eventType == EventTypeNudge || eventType == EventTypeApproval
+}
+
+const (
+	defaultNaggingInterval = time.Hour
+	minNaggingInterval     = 15 * time.Minute
+)
+
+// ScheduleNextNag queues the reminder following a nagging reminder that was just sent. Only one
+// nagging reminder is pending at a time, so completing or skipping the chore (which regenerates
+// its notifications) or reaching the deadline stops the nagging.
+func (n *NotificationPlanner) ScheduleNextNag(c context.Context, sent *nModel.NotificationDetails, chore *chModel.Chore) error {
+	if !chore.IsActive || !chore.Notification || chore.NotificationMetadataV2 == nil || !chore.NotificationMetadataV2.Nagging {
+		return nil
+	}
+	if chore.AssignedTo == nil || *chore.AssignedTo != sent.UserID {
+		return nil
+	}
+	// a chore that isn't overdue was completed or rescheduled, regenerating its notifications
+	// already queued the first nagging reminder of the new occurrence
+	if chore.NextDueDate == nil || chore.NextDueDate.After(time.Now().UTC()) {
+		return nil
+	}
+
+	count := 1
+	if previous, ok := sent.RawEvent["nag_count"].(float64); ok {
+		count = int(previous) + 1
+	} else if previous, ok := sent.RawEvent["nag_count"].(int); ok {
+		count = previous + 1
+	}
+
+	after := time.Now().UTC()
+	if sent.ScheduledFor.After(after) {
+		after = sent.ScheduledFor
+	}
+	next, ok := nextNagTime(chore, after)
+	if !ok {
+		return nil
+	}
+
+	nag := &nModel.Notification{
+		ChoreID:      chore.ID,
+		IsSent:       false,
+		ScheduledFor: next,
+		CreatedAt:    time.Now().UTC(),
+		TypeID:       sent.TypeID,
+		UserID:       sent.UserID,
+		CircleID:     sent.CircleID,
+		TargetID:     sent.TargetID,
+		Text:         sent.Text,
+		// the sent reminder was routed already
+		NotificationTargetID: sent.NotificationTargetID,
+		RawEvent:             nagRawEvent(chore, sent.RawEvent["assignee"], sent.RawEvent["assignee_username"], count),
+	}
+	return n.nRepo.BatchInsertNotifications([]*nModel.Notification{nag})
+}
+
+// naggingInterval returns the time between nagging reminders of the chore
+func naggingInterval(metadata *chModel.NotificationMetadata) time.Duration {
+	if metadata == nil || metadata.NaggingInterval <= 0 {
+		return defaultNaggingInterval
+	}
+	interval := time.Duration(metadata.NaggingInterval) * time.Minute
+	if interval < minNaggingInterval {
+		return minNaggingInterval
+	}
+	return interval
+}
+
+// nextNagTime returns the first nagging reminder time after `after`, stepping by the nagging
+// interval from the due date. It returns false when the chore isn't due or the deadline comes first.
+func nextNagTime(chore *chModel.Chore, after time.Time) (time.Time, bool) {
+	if chore.NextDueDate == nil {
+		return time.Time{}, false
+	}
+	interval := naggingInterval(chore.NotificationMetadataV2)
+	due := *chore.NextDueDate
+
+	next := due.Add(interval)
+	if !next.After(after) {
+		steps := after.Sub(due)/interval + 1
+		next = due.Add(steps * interval)
+	}
+
+	if deadline := chore.GetDeadline(); deadline != nil && next.After(*deadline) {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+func generateNagNotification(chore *chModel.Chore, assignedUser *cModel.UserCircleDetail, after time.Time, count int) *nModel.Notification {
+	next, ok := nextNagTime(chore, after)
+	if !ok {
+		return nil
+	}
+	return &nModel.Notification{
+		ChoreID:              chore.ID,
+		IsSent:               false,
+		ScheduledFor:         next,
+		CreatedAt:            time.Now().UTC(),
+		TypeID:               assignedUser.NotificationType,
+		UserID:               assignedUser.UserID,
+		CircleID:             assignedUser.CircleID,
+		TargetID:             assignedUser.TargetID,
+		Text:                 fmt.Sprintf("🔔 Reminder: *%s* is overdue and still assigned to %s.", chore.Name, assignedUser.DisplayName),
+		NotificationTargetID: primaryTarget(assignedUser),
+		RawEvent:             nagRawEvent(chore, assignedUser.DisplayName, assignedUser.Username, count),
+	}
+}
+
+func nagRawEvent(chore *chModel.Chore, assignee, assigneeUsername interface{}, count int) map[string]interface{} {
+	return map[string]interface{}{
+		"id":                chore.ID,
+		"type":              EventTypeNagging,
+		"name":              chore.Name,
+		"due_date":          chore.NextDueDate,
+		"assignee":          assignee,
+		"assignee_username": assigneeUsername,
+		"nag_count":         count,
+	}
+}
+
+// generateEscalationNotifications schedules a notification for each escalation level of an
+// overdue chore. Levels past the deadline are skipped, the deadline job takes over from there.
+func generateEscalationNotifications(chore *chModel.Chore, assignedUser *cModel.UserCircleDetail, circleMembers []*cModel.UserCircleDetail) []*nModel.Notification {
+	notifications := make([]*nModel.Notification, 0)
+	now := time.Now().UTC()
+	deadline := chore.GetDeadline()
+
+	for i, level := range chore.NotificationMetadataV2.Escalations {
+		delay, err := calculateDuration(level.Value, level.Unit)
+		if err != nil || delay <= 0 {
+			continue
+		}
+		scheduledTime := chore.NextDueDate.Add(delay)
+		if scheduledTime.Before(now) || (deadline != nil && scheduledTime.After(*deadline)) {
+			continue
+		}
+
+		text := escalationText(chore, assignedUser, level, delay)
+		newNotification := func(userID int, platform nModel.NotificationPlatform, targetID string, notificationTargetID *int) *nModel.Notification {
+			return &nModel.Notification{
+				ChoreID:              chore.ID,
+				IsSent:               false,
+				ScheduledFor:         scheduledTime,
+				CreatedAt:            now,
+				TypeID:               platform,
+				UserID:               userID,
+				CircleID:             chore.CircleID,
+				TargetID:             targetID,
+				NotificationTargetID: notificationTargetID,
+				Text:                 text,
+				RawEvent: map[string]interface{}{
+					"id":                chore.ID,
+					"type":              EventTypeEscalation,
+					"name":              chore.Name,
+					"due_date":          chore.NextDueDate,
+					"assignee":          assignedUser.DisplayName,
+					"assignee_username": assignedUser.Username,
+					"level":             i + 1,
+					"target":            level.Target,
+					// the level's own message takes precedence over the circle's template
+					"custom": level.Message != "",
+				},
+			}
+		}
+
+		switch level.Target {
+		case chModel.EscalationTargetManagers:
+			for _, member := range circleMembers {
+				if !member.IsActive || !member.IsManagerOrAdmin() || member.UserID == assignedUser.UserID ||
+					member.NotificationType == nModel.NotificationPlatformNone {
+					continue
+				}
+				notifications = append(notifications, newNotification(member.UserID, member.NotificationType, member.TargetID, primaryTarget(member)))
+			}
+		case chModel.EscalationTargetCircleGroup:
+			// the group chat is reached through the assignee's platform, like circle group reminders
+			if chore.NotificationMetadataV2.CircleGroupID == nil {
+				continue
+			}
+			notifications = append(notifications, newNotification(assignedUser.UserID, assignedUser.NotificationType,
+				fmt.Sprint(*chore.NotificationMetadataV2.CircleGroupID), nil))
+		}
+	}
+	return notifications
+}
+
+func escalationText(chore *chModel.Chore, assignedUser *cModel.UserCircleDetail, level *chModel.EscalationLevel, overdueFor time.Duration) string {
+	if level.Message != "" {
+		return strings.NewReplacer("{chore}", chore.Name, "{assignee}", assignedUser.DisplayName).Replace(level.Message)
+	}
+	return fmt.Sprintf("⚠️ Escalation: *%s* assigned to %s has been overdue for %s.", chore.Name, assignedUser.DisplayName, formatOverdue(overdueFor))
+}
+
+func formatOverdue(d time.Duration) string {
+	switch {
+	case d%(24*time.Hour) == 0:
+		return pluralize(int(d/(24*time.Hour)), "day")
+	case d%time.Hour == 0:
+		return pluralize(int(d/time.Hour), "hour")
+	default:
+		return pluralize(int(d/time.Minute), "minute")
+	}
+}
+
+func pluralize(n int, unit string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", unit)
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
@@ -0,0 +1,368 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	chModel "donetick.com/core/internal/chore/model"
+	cModel "donetick.com/core/internal/circle/model"
+	cRepo "donetick.com/core/internal/circle/repo"
+	"donetick.com/core/internal/database"
+	nModel "donetick.com/core/internal/notifier/model"
+	nRepo "donetick.com/core/internal/notifier/repo"
+	uModel "donetick.com/core/internal/user/model"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestNextNagTime(t *testing.T) {
+	due := time.Date(2026, 3, 10, 18, 0, 0, 0, time.UTC)
+	deadlineOffset := 3 * 3600
+
+	tests := []struct {
+		name     string
+		interval int
+		deadline *int
+		after    time.Time
+		want     time.Time
+		wantOK   bool
+	}{
+		{name: "before due date", interval: 30, after: due.Add(-time.Hour), want: due.Add(30 * time.Minute), wantOK: true},
+		{name: "default interval", after: due, want: due.Add(time.Hour), wantOK: true},
+		{name: "interval below minimum", interval: 1, after: due, want: due.Add(15 * time.Minute), wantOK: true},
+		{name: "already overdue steps past now", interval: 60, after: due.Add(150 * time.Minute), want: due.Add(3 * time.Hour), wantOK: true},
+		{name: "exactly on a step", interval: 60, after: due.Add(2 * time.Hour), want: due.Add(3 * time.Hour), wantOK: true},
+		{name: "last nag at the deadline", interval: 60, deadline: &deadlineOffset, after: due.Add(2 * time.Hour), want: due.Add(3 * time.Hour), wantOK: true},
+		{name: "deadline passed", interval: 60, deadline: &deadlineOffset, after: due.Add(3 * time.Hour), wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chore := &chModel.Chore{
+				NextDueDate:            &due,
+				DeadlineOffset:         tt.deadline,
+				NotificationMetadataV2: &chModel.NotificationMetadata{Nagging: true, NaggingInterval: tt.interval},
+			}
+			got, ok := nextNagTime(chore, tt.after)
+			if ok != tt.wantOK {
+				t.Fatalf("expected ok=%v, got %v", tt.wantOK, ok)
+			}
+			if ok && !got.Equal(tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestScheduleNextNag(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect database: %v", err)
+	}
+	if err := database.Migration(db); err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
+	}
+	planner := NewNotificationPlanner(nRepo.NewNotificationRepository(db), cRepo.NewCircleRepository(db))
+	ctx := context.Background()
+	assignee := 1
+
+	now := time.Now().UTC()
+	overdue := now.Add(-90 * time.Minute)
+	upcoming := now.Add(24 * time.Hour)
+	tests := []struct {
+		name      string
+		dueDate   time.Time
+		nagging   bool
+		active    bool
+		wantNext  bool
+		wantCount int
+	}{
+		{name: "still overdue", dueDate: overdue, nagging: true, active: true, wantNext: true, wantCount: 3},
+		{name: "completed, next occurrence not due", dueDate: upcoming, nagging: true, active: true},
+		{name: "nagging turned off", dueDate: overdue, nagging: false, active: true},
+		{name: "archived", dueDate: overdue, nagging: true, active: false},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chore := &chModel.Chore{
+				ID:                     i + 1,
+				Name:                   "Trash night",
+				NextDueDate:            &tt.dueDate,
+				IsActive:               tt.active,
+				Notification:           true,
+				AssignedTo:             &assignee,
+				NotificationMetadataV2: &chModel.NotificationMetadata{Nagging: tt.nagging, NaggingInterval: 60},
+			}
+			sent := &nModel.NotificationDetails{Notification: nModel.Notification{
+				ChoreID:      chore.ID,
+				UserID:       assignee,
+				CircleID:     1,
+				ScheduledFor: now.Add(-30 * time.Minute),
+				RawEvent:     nModel.JSONB{"type": "nagging", "nag_count": float64(2)},
+			}}
+			if err := planner.ScheduleNextNag(ctx, sent, chore); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var pending []nModel.Notification
+			db.Where("chore_id = ? AND is_sent = ?", chore.ID, false).Find(&pending)
+			if !tt.wantNext {
+				if len(pending) != 0 {
+					t.Errorf("expected no reminder, got %d", len(pending))
+				}
+				return
+			}
+			if len(pending) != 1 {
+				t.Fatalf("expected one pending reminder, got %d", len(pending))
+			}
+			if !pending[0].ScheduledFor.After(now) {
+				t.Errorf("expected the reminder to be scheduled in the future, got %v", pending[0].ScheduledFor)
+			}
+			if count, _ := pending[0].RawEvent["nag_count"].(float64); int(count) != tt.wantCount {
+				t.Errorf("expected nag count %d, got %v", tt.wantCount, pending[0].RawEvent["nag_count"])
+			}
+		})
+	}
+}
+
+func TestGenerateEscalationNotifications(t *testing.T) {
+	due := time.Now().UTC().Add(-time.Hour).Truncate(time.Minute)
+	groupID := int64(-100)
+	member := func(userID int, role cModel.UserRole, platform nModel.NotificationPlatform) *cModel.UserCircleDetail {
+		return &cModel.UserCircleDetail{
+			UserCircle:       cModel.UserCircle{UserID: userID, CircleID: 1, Role: role, IsActive: true},
+			DisplayName:      "Kid",
+			NotificationType: platform,
+			TargetID:         "target",
+		}
+	}
+	kid := member(1, cModel.UserRoleMember, nModel.NotificationPlatformTelegram)
+	members := []*cModel.UserCircleDetail{
+		kid,
+		member(2, cModel.UserRoleAdmin, nModel.NotificationPlatformTelegram),
+		member(3, cModel.UserRoleManager, nModel.NotificationPlatformPushover),
+		member(4, cModel.UserRoleAdmin, nModel.NotificationPlatformNone),
+		member(5, cModel.UserRoleMember, nModel.NotificationPlatformTelegram),
+	}
+
+	tests := []struct {
+		name        string
+		escalations []*chModel.EscalationLevel
+		deadline    *int
+		wantUsers   []int
+		wantTargets []string
+		wantText    string
+	}{
+		{
+			name:        "managers after a day",
+			escalations: []*chModel.EscalationLevel{{Value: 1, Unit: chModel.NotificationTemplateUnitDay, Target: chModel.EscalationTargetManagers}},
+			wantUsers:   []int{2, 3},
+			wantTargets: []string{"target", "target"},
+			wantText:    "⚠️ Escalation: *Trash* assigned to Kid has been overdue for 1 day.",
+		},
+		{
+			name:        "circle group with custom message",
+			escalations: []*chModel.EscalationLevel{{Value: 3, Unit: chModel.NotificationTemplateUnitHour, Target: chModel.EscalationTargetCircleGroup, Message: "{assignee} still hasn't done {chore}"}},
+			wantUsers:   []int{1},
+			wantTargets: []string{"-100"},
+			wantText:    "Kid still hasn't done Trash",
+		},
+		{
+			name:        "level already passed",
+			escalations: []*chModel.EscalationLevel{{Value: 30, Unit: chModel.NotificationTemplateUnitMinute, Target: chModel.EscalationTargetManagers}},
+		},
+		{
+			name:        "level after the deadline",
+			escalations: []*chModel.EscalationLevel{{Value: 1, Unit: chModel.NotificationTemplateUnitDay, Target: chModel.EscalationTargetManagers}},
+			deadline:    intPtr(7200),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chore := &chModel.Chore{
+				ID:             1,
+				Name:           "Trash",
+				CircleID:       1,
+				NextDueDate:    &due,
+				DeadlineOffset: tt.deadline,
+				NotificationMetadataV2: &chModel.NotificationMetadata{
+					CircleGroupID: &groupID,
+					Escalations:   tt.escalations,
+				},
+			}
+			notifications := generateEscalationNotifications(chore, kid, members)
+			if len(notifications) != len(tt.wantUsers) {
+				t.Fatalf("expected %d notifications, got %d", len(tt.wantUsers), len(notifications))
+			}
+			for i, n := range notifications {
+				if n.UserID != tt.wantUsers[i] || n.TargetID != tt.wantTargets[i] || n.Text != tt.wantText {
+					t.Errorf("notification %d: expected user %d target %s text %q, got user %d target %s text %q",
+						i, tt.wantUsers[i], tt.wantTargets[i], tt.wantText, n.UserID, n.TargetID, n.Text)
+				}
+			}
+		})
+	}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func TestGenerateCompletionNotification(t *testing.T) {
+	now := time.Now().UTC()
+	member := func(userID int, platform nModel.NotificationPlatform) *cModel.UserCircleDetail {
+		return &cModel.UserCircleDetail{
+			UserCircle:       cModel.UserCircle{UserID: userID, CircleID: 1, IsActive: true},
+			DisplayName:      "Member",
+			NotificationType: platform,
+			TargetID:         "target",
+		}
+	}
+	members := []*cModel.UserCircleDetail{
+		member(1, nModel.NotificationPlatformEmail),
+		member(2, nModel.NotificationPlatformTelegram),
+		member(3, nModel.NotificationPlatformNone),
+	}
+
+	tests := []struct {
+		name        string
+		createdBy   int
+		completedBy int
+		wantUser    int
+	}{
+		{name: "notifies the creator", createdBy: 1, completedBy: 2, wantUser: 1},
+		{name: "creator without a notification target", createdBy: 3, completedBy: 2},
+		{name: "completer is not in the circle", createdBy: 1, completedBy: 9},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chore := &chModel.Chore{ID: 5, Name: "Dishes", CircleID: 1, CreatedBy: tt.createdBy}
+			notification := generateCompletionNotification(chore, tt.completedBy, members, now)
+			if tt.wantUser == 0 {
+				if notification != nil {
+					t.Fatalf("expected no notification, got one for user %d", notification.UserID)
+				}
+				return
+			}
+			if notification == nil {
+				t.Fatalf("expected a notification")
+			}
+			if notification.UserID != tt.wantUser || notification.TypeID != nModel.NotificationPlatformEmail || notification.RawEvent["type"] != EventTypeCompleted {
+				t.Errorf("unexpected notification %+v", notification)
+			}
+		})
+	}
+}
+
+func TestRouteNotification(t *testing.T) {
+	primary := 1
+	route := func(targetID int, platform nModel.NotificationPlatform, conditions nModel.RuleConditions) *nModel.NotificationRoute {
+		return &nModel.NotificationRoute{
+			NotificationRule: nModel.NotificationRule{NotificationTargetID: targetID, Conditions: conditions},
+			TargetType:       platform,
+			TargetAddress:    "target-" + string(rune('0'+targetID)),
+		}
+	}
+	phone := route(2, nModel.NotificationPlatformFCM, nModel.RuleConditions{Priorities: []int{1}})
+	discord := route(3, nModel.NotificationPlatformDiscord, nModel.RuleConditions{EventTypes: []string{"overdue", "nudge"}})
+	chores := route(4, nModel.NotificationPlatformEmail, nModel.RuleConditions{LabelIDs: []int{7}})
+
+	tests := []struct {
+		name        string
+		eventType   EventType
+		priority    int
+		labelIDs    []int
+		routes      []*nModel.NotificationRoute
+		wantTargets []int
+	}{
+		{name: "no rules", eventType: EventTypeDue, routes: nil, wantTargets: []int{primary}},
+		{name: "no rule matches", eventType: EventTypeDue, priority: 3, routes: []*nModel.NotificationRoute{phone, discord}, wantTargets: []int{primary}},
+		{name: "priority rule", eventType: EventTypeDue, priority: 1, routes: []*nModel.NotificationRoute{phone, discord}, wantTargets: []int{2}},
+		{name: "several rules match", eventType: EventTypeOverdue, priority: 1, routes: []*nModel.NotificationRoute{phone, discord}, wantTargets: []int{2, 3}},
+		{name: "label rule", eventType: EventTypeDue, labelIDs: []int{5, 7}, routes: []*nModel.NotificationRoute{chores}, wantTargets: []int{4}},
+		{name: "target named by two rules", eventType: EventTypeOverdue, routes: []*nModel.NotificationRoute{discord, discord}, wantTargets: []int{3}},
+		{name: "opt-in event without a rule", eventType: EventTypeApproval, routes: []*nModel.NotificationRoute{phone}},
+		{name: "opt-in event with a rule", eventType: EventTypeNudge, routes: []*nModel.NotificationRoute{discord}, wantTargets: []int{3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notification := &nModel.Notification{
+				UserID:               1,
+				TypeID:               nModel.NotificationPlatformTelegram,
+				TargetID:             "42",
+				NotificationTargetID: &primary,
+				RawEvent:             nModel.JSONB{"type": tt.eventType},
+			}
+			routed := routeNotification(notification, tt.priority, tt.labelIDs, tt.routes)
+			if len(routed) != len(tt.wantTargets) {
+				t.Fatalf("expected %d notifications, got %d", len(tt.wantTargets), len(routed))
+			}
+			for i, want := range tt.wantTargets {
+				if routed[i].NotificationTargetID == nil || *routed[i].NotificationTargetID != want {
+					t.Errorf("notification %d: expected target %d, got %v", i, want, routed[i].NotificationTargetID)
+				}
+				if want != primary && routed[i].TargetID != "target-"+string(rune('0'+want)) {
+					t.Errorf("notification %d: expected the rule's target address, got %q", i, routed[i].TargetID)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateNotificationsRoutesToTargets(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect database: %v", err)
+	}
+	if err := database.Migration(db); err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
+	}
+	planner := NewNotificationPlanner(nRepo.NewNotificationRepository(db), cRepo.NewCircleRepository(db))
+	ctx := context.Background()
+
+	userID := 1
+	db.Create(&uModel.User{ID: userID, Username: "ann", DisplayName: "Ann", CircleID: 1})
+	db.Create(&cModel.UserCircle{UserID: userID, CircleID: 1, IsActive: true})
+	telegram := &uModel.NotificationTarget{UserID: userID, Type: nModel.NotificationPlatformTelegram, TargetID: "42", IsPrimary: true}
+	phone := &uModel.NotificationTarget{UserID: userID, Type: nModel.NotificationPlatformFCM, TargetID: "phone"}
+	db.Create(telegram)
+	db.Create(phone)
+	db.Create(&nModel.NotificationRule{UserID: userID, NotificationTargetID: phone.ID, Conditions: nModel.RuleConditions{Priorities: []int{1}}})
+
+	due := time.Now().UTC().Add(24 * time.Hour)
+	tests := []struct {
+		name       string
+		priority   int
+		wantTarget int
+		wantType   nModel.NotificationPlatform
+	}{
+		{name: "high priority goes to the phone", priority: 1, wantTarget: phone.ID, wantType: nModel.NotificationPlatformFCM},
+		{name: "everything else goes to the primary target", priority: 3, wantTarget: telegram.ID, wantType: nModel.NotificationPlatformTelegram},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chore := &chModel.Chore{
+				ID:           i + 1,
+				Name:         "Dishes",
+				CircleID:     1,
+				NextDueDate:  &due,
+				Priority:     tt.priority,
+				AssignedTo:   &userID,
+				Notification: true,
+				NotificationMetadataV2: &chModel.NotificationMetadata{Templates: []*chModel.NotificationTemplate{
+					{Value: 0, Unit: chModel.NotificationTemplateUnitHour},
+				}},
+			}
+			planner.GenerateNotifications(ctx, chore)
+
+			var pending []nModel.Notification
+			db.Where("chore_id = ?", chore.ID).Find(&pending)
+			if len(pending) != 1 {
+				t.Fatalf("expected one notification, got %d", len(pending))
+			}
+			if pending[0].NotificationTargetID == nil || *pending[0].NotificationTargetID != tt.wantTarget || pending[0].TypeID != tt.wantType {
+				t.Errorf("expected target %d on platform %d, got %v on %d", tt.wantTarget, tt.wantType, pending[0].NotificationTargetID, pending[0].TypeID)
+			}
+		})
+	}
+}
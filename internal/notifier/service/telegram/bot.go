@@ -0,0 +1,295 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"donetick.com/core/config"
+	chModel "donetick.com/core/internal/chore/model"
+	uModel "donetick.com/core/internal/user/model"
+	"donetick.com/core/logging"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// BotAPI is the part of the Telegram Bot API the notifier and the bot use. *tgbotapi.BotAPI
+// implements it, StubBot replaces it in tests.
+type BotAPI interface {
+	Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+	Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error)
+	GetUpdatesChan(config tgbotapi.UpdateConfig) tgbotapi.UpdatesChannel
+	StopReceivingUpdates()
+}
+
+// ChoreActions performs what users ask for in chat. The chore package implements it, so chores are
+// completed the same way as in the app. The action methods return the message to show the user;
+// an error means something went wrong rather than the action being refused.
+type ChoreActions interface {
+	// UserForTelegramAccount returns the user who linked the Telegram account with a link code,
+	// ErrNotLinked or ErrLinkedToSeveralUsers when there isn't exactly one
+	UserForTelegramAccount(c context.Context, telegramUserID int64) (*uModel.UserDetails, error)
+	// LinkAccount redeems a link code created in Donetick for the Telegram account that sent it
+	LinkAccount(c context.Context, code string, telegramUserID int64) (string, error)
+	CompleteChore(c context.Context, user *uModel.UserDetails, choreID int, dueDate int64) (string, error)
+	SkipChore(c context.Context, user *uModel.UserDetails, choreID int, dueDate int64) (string, error)
+	SnoozeChore(c context.Context, user *uModel.UserDetails, choreID int, dueDate int64) (string, error)
+	// ChoresDueToday returns the chores of the user's circle due by the end of their day, overdue ones included
+	ChoresDueToday(c context.Context, user *uModel.UserDetails) ([]*chModel.Chore, error)
+	// AssignedChores returns the active chores assigned to the user
+	AssignedChores(c context.Context, user *uModel.UserDetails) ([]*chModel.Chore, error)
+}
+
+var (
+	// ErrNotLinked means no user linked the Telegram account
+	ErrNotLinked = errors.New("telegram account is not linked")
+	// ErrLinkedToSeveralUsers means the bot can't tell which user to act as
+	ErrLinkedToSeveralUsers = errors.New("telegram account is linked to several users")
+)
+
+const (
+	actionDone   = "done"
+	actionSkip   = "skip"
+	actionSnooze = "snooze"
+
+	updateTimeout = 30 * time.Second
+)
+
+// Bot long polls Telegram for the buttons pressed on reminders and the commands sent to the bot
+type Bot struct {
+	api     BotAPI
+	actions ChoreActions
+}
+
+// NewBot returns nil unless the bot is enabled, only one instance may poll a bot token
+func NewBot(cfg *config.Config, tn *TelegramNotifier, actions ChoreActions) *Bot {
+	if !cfg.Telegram.EnableBot || tn == nil {
+		return nil
+	}
+	return &Bot{api: tn.bot, actions: actions}
+}
+
+func (b *Bot) Start(c context.Context) {
+	if b == nil {
+		return
+	}
+	logging.FromContext(c).Info("Starting Telegram bot")
+	go b.Run(c)
+}
+
+// Run handles updates until the bot stops receiving them
+func (b *Bot) Run(c context.Context) {
+	config := tgbotapi.NewUpdate(0)
+	config.Timeout = 60
+	config.AllowedUpdates = []string{"message", "callback_query"}
+	for update := range b.api.GetUpdatesChan(config) {
+		ctx, cancel := context.WithTimeout(c, updateTimeout)
+		b.handleUpdate(ctx, update)
+		cancel()
+	}
+}
+
+func (b *Bot) Stop() {
+	if b == nil {
+		return
+	}
+	b.api.StopReceivingUpdates()
+}
+
+func (b *Bot) handleUpdate(c context.Context, update tgbotapi.Update) {
+	switch {
+	case update.CallbackQuery != nil:
+		b.handleCallback(c, update.CallbackQuery)
+	case update.Message != nil && update.Message.IsCommand():
+		b.handleCommand(c, update.Message)
+	}
+}
+
+// handleCallback performs the action of a reminder button. The user is the one who pressed it,
+// which in a group chat isn't necessarily the chore's assignee.
+func (b *Bot) handleCallback(c context.Context, query *tgbotapi.CallbackQuery) {
+	log := logging.FromContext(c)
+	action, choreID, dueDate, ok := parseCallbackData(query.Data)
+	if !ok {
+		b.answer(c, query, "This button is no longer supported.")
+		return
+	}
+	user, refusal := b.userFor(c, query.From.ID)
+	if user == nil {
+		b.answer(c, query, refusal)
+		return
+	}
+
+	var message string
+	var err error
+	switch action {
+	case actionDone:
+		message, err = b.actions.CompleteChore(c, user, choreID, dueDate)
+	case actionSkip:
+		message, err = b.actions.SkipChore(c, user, choreID, dueDate)
+	case actionSnooze:
+		message, err = b.actions.SnoozeChore(c, user, choreID, dueDate)
+	}
+	if err != nil {
+		log.Errorw("Failed to perform Telegram action", "error", err, "action", action, "choreID", choreID, "userID", user.ID)
+		message = "Something went wrong, please try again."
+	}
+	b.answer(c, query, message)
+	if query.Message != nil {
+		reply := tgbotapi.NewMessage(query.Message.Chat.ID, message)
+		reply.ReplyToMessageID = query.Message.MessageID
+		b.send(c, reply)
+	}
+}
+
+// userFor returns the user who linked the Telegram account, or why the bot won't act for it
+func (b *Bot) userFor(c context.Context, telegramUserID int64) (*uModel.UserDetails, string) {
+	user, err := b.actions.UserForTelegramAccount(c, telegramUserID)
+	switch {
+	case err == nil:
+		return user, ""
+	case errors.Is(err, ErrLinkedToSeveralUsers):
+		return nil, "This Telegram account is linked to several Donetick accounts. Unlink it from the ones you don't use it with."
+	case !errors.Is(err, ErrNotLinked):
+		logging.FromContext(c).Errorw("Failed to get the user of a Telegram account", "error", err, "telegramUserID", telegramUserID)
+	}
+	return nil, "This Telegram account isn't linked to Donetick. Send /start to the bot to see how to link it."
+}
+
+func (b *Bot) answer(c context.Context, query *tgbotapi.CallbackQuery, text string) {
+	if _, err := b.api.Request(tgbotapi.NewCallback(query.ID, text)); err != nil {
+		logging.FromContext(c).Errorw("Failed to answer Telegram callback", "error", err)
+	}
+}
+
+func (b *Bot) send(c context.Context, msg tgbotapi.MessageConfig) {
+	if _, err := b.api.Send(msg); err != nil {
+		logging.FromContext(c).Errorw("Failed to send Telegram message", "error", err, "chatID", msg.ChatID)
+	}
+}
+
+func (b *Bot) handleCommand(c context.Context, message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	if message.From == nil {
+		return
+	}
+	switch message.Command() {
+	case "today", "mine":
+	case "start":
+		if code := message.CommandArguments(); code != "" {
+			b.linkAccount(c, chatID, code, message.From.ID)
+			return
+		}
+		fallthrough
+	default:
+		b.send(c, tgbotapi.NewMessage(chatID, fmt.Sprintf(
+			"Hi! I send your Donetick reminders, with buttons to mark chores done, skip or snooze them.\n\n"+
+				"To use the buttons, link your Telegram account from your Donetick settings. "+
+				"To get reminders in this chat, add a Telegram notification target with the chat ID %d.\n\n"+
+				"/today - chores due today\n/mine - chores assigned to you", chatID)))
+		return
+	}
+
+	user, refusal := b.userFor(c, message.From.ID)
+	if user == nil {
+		b.send(c, tgbotapi.NewMessage(chatID, refusal))
+		return
+	}
+
+	var err error
+	var chores []*chModel.Chore
+	var title string
+	if message.Command() == "today" {
+		title = "Due today"
+		chores, err = b.actions.ChoresDueToday(c, user)
+	} else {
+		title = "Assigned to you"
+		chores, err = b.actions.AssignedChores(c, user)
+	}
+	if err != nil {
+		logging.FromContext(c).Errorw("Failed to list chores for Telegram", "error", err, "userID", user.ID)
+		b.send(c, tgbotapi.NewMessage(chatID, "Something went wrong, please try again."))
+		return
+	}
+	b.send(c, tgbotapi.NewMessage(chatID, formatChoreList(title, chores, time.Now().UTC(), userLocation(user))))
+}
+
+// linkAccount links the sender's Telegram account with the code from a "/start <code>" deep link
+func (b *Bot) linkAccount(c context.Context, chatID int64, code string, telegramUserID int64) {
+	message, err := b.actions.LinkAccount(c, code, telegramUserID)
+	if err != nil {
+		logging.FromContext(c).Errorw("Failed to link Telegram account", "error", err, "telegramUserID", telegramUserID)
+		message = "Something went wrong, please try again."
+	}
+	b.send(c, tgbotapi.NewMessage(chatID, message))
+}
+
+// formatChoreList lists the chores as plain text, so chore names don't need escaping
+func formatChoreList(title string, chores []*chModel.Chore, now time.Time, loc *time.Location) string {
+	if len(chores) == 0 {
+		return title + ": nothing, enjoy your day!"
+	}
+	var sb strings.Builder
+	sb.WriteString(title + ":\n")
+	for _, chore := range chores {
+		sb.WriteString("• " + chore.Name)
+		switch {
+		case chore.NextDueDate == nil:
+		case chore.NextDueDate.Before(now):
+			sb.WriteString(" (overdue)")
+		default:
+			due := chore.NextDueDate.In(loc)
+			if y, m, d := now.In(loc).Date(); due.Year() == y && due.Month() == m && due.Day() == d {
+				sb.WriteString(" at " + due.Format("15:04"))
+			} else {
+				sb.WriteString(", due " + due.Format("Mon Jan 2 15:04"))
+			}
+		}
+		sb.WriteString("\n")
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+func userLocation(user *uModel.UserDetails) *time.Location {
+	if loc, err := time.LoadLocation(user.Timezone); err == nil && user.Timezone != "" {
+		return loc
+	}
+	return time.UTC
+}
+
+// reminderKeyboard holds the buttons attached to chore reminders. They carry the due date the
+// reminder was sent for, so a stale button can't act on a later occurrence.
+func reminderKeyboard(choreID int, dueDate int64) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("✅ Done", callbackData(actionDone, choreID, dueDate)),
+		tgbotapi.NewInlineKeyboardButtonData("⏭ Skip", callbackData(actionSkip, choreID, dueDate)),
+		tgbotapi.NewInlineKeyboardButtonData("⏰ Snooze 1h", callbackData(actionSnooze, choreID, dueDate)),
+	))
+}
+
+func callbackData(action string, choreID int, dueDate int64) string {
+	return fmt.Sprintf("%s:%d:%d", action, choreID, dueDate)
+}
+
+func parseCallbackData(data string) (string, int, int64, bool) {
+	parts := strings.Split(data, ":")
+	if len(parts) != 3 {
+		return "", 0, 0, false
+	}
+	switch parts[0] {
+	case actionDone, actionSkip, actionSnooze:
+	default:
+		return "", 0, 0, false
+	}
+	choreID, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return "", 0, 0, false
+	}
+	dueDate, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return "", 0, 0, false
+	}
+	return parts[0], choreID, dueDate, true
+}
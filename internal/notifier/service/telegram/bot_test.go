@@ -0,0 +1,202 @@
+package telegram
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	chModel "donetick.com/core/internal/chore/model"
+	nModel "donetick.com/core/internal/notifier/model"
+	uModel "donetick.com/core/internal/user/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+type fakeActions struct {
+	linkedChat int64
+	performed  []string
+	chores     []*chModel.Chore
+}
+
+// the fake links 42 to one user and 7 to two
+func (a *fakeActions) UserForTelegramAccount(c context.Context, telegramUserID int64) (*uModel.UserDetails, error) {
+	switch telegramUserID {
+	case a.linkedChat:
+		return &uModel.UserDetails{User: uModel.User{ID: 3, Timezone: "UTC"}}, nil
+	case 7:
+		return nil, ErrLinkedToSeveralUsers
+	}
+	return nil, ErrNotLinked
+}
+
+func (a *fakeActions) LinkAccount(c context.Context, code string, telegramUserID int64) (string, error) {
+	if code != "valid" {
+		return "expired", nil
+	}
+	a.linkedChat = telegramUserID
+	return "linked", nil
+}
+
+func (a *fakeActions) perform(action string, choreID int, dueDate int64) (string, error) {
+	a.performed = append(a.performed, callbackData(action, choreID, dueDate))
+	return action + " ok", nil
+}
+
+func (a *fakeActions) CompleteChore(c context.Context, user *uModel.UserDetails, choreID int, dueDate int64) (string, error) {
+	return a.perform(actionDone, choreID, dueDate)
+}
+
+func (a *fakeActions) SkipChore(c context.Context, user *uModel.UserDetails, choreID int, dueDate int64) (string, error) {
+	return a.perform(actionSkip, choreID, dueDate)
+}
+
+func (a *fakeActions) SnoozeChore(c context.Context, user *uModel.UserDetails, choreID int, dueDate int64) (string, error) {
+	return a.perform(actionSnooze, choreID, dueDate)
+}
+
+func (a *fakeActions) ChoresDueToday(c context.Context, user *uModel.UserDetails) ([]*chModel.Chore, error) {
+	return a.chores, nil
+}
+
+func (a *fakeActions) AssignedChores(c context.Context, user *uModel.UserDetails) ([]*chModel.Chore, error) {
+	return a.chores, nil
+}
+
+func callbackUpdate(from int64, data string) tgbotapi.Update {
+	return tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+		ID:      "q",
+		From:    &tgbotapi.User{ID: from},
+		Data:    data,
+		Message: &tgbotapi.Message{MessageID: 9, Chat: &tgbotapi.Chat{ID: from}},
+	}}
+}
+
+func commandUpdate(from int64, command string) tgbotapi.Update {
+	return tgbotapi.Update{Message: &tgbotapi.Message{
+		From:     &tgbotapi.User{ID: from},
+		Chat:     &tgbotapi.Chat{ID: from},
+		Text:     "/" + command,
+		Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(strings.Fields(command)[0]) + 1}},
+	}}
+}
+
+// texts returns the text of the messages and callback answers the bot sent
+func texts(sent []tgbotapi.Chattable) []string {
+	texts := make([]string, 0, len(sent))
+	for _, c := range sent {
+		switch msg := c.(type) {
+		case tgbotapi.MessageConfig:
+			texts = append(texts, msg.Text)
+		case tgbotapi.CallbackConfig:
+			texts = append(texts, msg.Text)
+		}
+	}
+	return texts
+}
+
+func TestBotCallbacks(t *testing.T) {
+	tests := []struct {
+		name          string
+		from          int64
+		data          string
+		wantPerformed string
+		wantText      string
+	}{
+		{name: "done", from: 42, data: "done:7:1773165600", wantPerformed: "done:7:1773165600", wantText: "done ok"},
+		{name: "skip", from: 42, data: "skip:7:0", wantPerformed: "skip:7:0", wantText: "skip ok"},
+		{name: "snooze", from: 42, data: "snooze:7:1773165600", wantPerformed: "snooze:7:1773165600", wantText: "snooze ok"},
+		{name: "unlinked account", from: 5, data: "done:7:0", wantText: "isn't linked"},
+		{name: "account linked to several users", from: 7, data: "done:7:0", wantText: "several Donetick accounts"},
+		{name: "unknown action", from: 42, data: "delete:7:0", wantText: "no longer supported"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := NewStubBot()
+			actions := &fakeActions{linkedChat: 42}
+			bot := &Bot{api: stub, actions: actions}
+
+			stub.Push(callbackUpdate(tt.from, tt.data))
+			stub.StopReceivingUpdates()
+			bot.Run(context.Background())
+
+			if tt.wantPerformed == "" && len(actions.performed) > 0 {
+				t.Errorf("expected no action, got %v", actions.performed)
+			}
+			if tt.wantPerformed != "" && (len(actions.performed) != 1 || actions.performed[0] != tt.wantPerformed) {
+				t.Errorf("expected %s, got %v", tt.wantPerformed, actions.performed)
+			}
+			sent := texts(stub.Sent())
+			if len(sent) == 0 || !strings.Contains(sent[0], tt.wantText) {
+				t.Errorf("expected the callback to be answered with %q, got %v", tt.wantText, sent)
+			}
+		})
+	}
+}
+
+func TestBotCommands(t *testing.T) {
+	due := time.Now().UTC().Add(-time.Hour)
+	tests := []struct {
+		name     string
+		from     int64
+		command  string
+		chores   []*chModel.Chore
+		wantText string
+	}{
+		{name: "today", from: 42, command: "today", chores: []*chModel.Chore{{Name: "Dishes", NextDueDate: &due}}, wantText: "Due today:\n• Dishes (overdue)"},
+		{name: "mine, nothing assigned", from: 42, command: "mine", wantText: "Assigned to you: nothing"},
+		{name: "start shows the chat ID", from: 5, command: "start", wantText: "chat ID 5"},
+		{name: "start with a code links the account", from: 5, command: "start valid", wantText: "linked"},
+		{name: "start with an expired code", from: 5, command: "start stale", wantText: "expired"},
+		{name: "unlinked account", from: 5, command: "today", wantText: "isn't linked"},
+		{name: "account linked to several users", from: 7, command: "mine", wantText: "several Donetick accounts"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := NewStubBot()
+			bot := &Bot{api: stub, actions: &fakeActions{linkedChat: 42, chores: tt.chores}}
+
+			stub.Push(commandUpdate(tt.from, tt.command))
+			stub.StopReceivingUpdates()
+			bot.Run(context.Background())
+
+			sent := texts(stub.Sent())
+			if len(sent) != 1 || !strings.Contains(sent[0], tt.wantText) {
+				t.Errorf("expected a reply containing %q, got %v", tt.wantText, sent)
+			}
+		})
+	}
+}
+
+func TestSendNotificationButtons(t *testing.T) {
+	due := time.Date(2026, 3, 10, 18, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		buttons     bool
+		rawEvent    nModel.JSONB
+		wantButtons bool
+	}{
+		{name: "reminder", buttons: true, rawEvent: nModel.JSONB{"type": "due", "due_date": due.Format(time.RFC3339)}, wantButtons: true},
+		{name: "completion notice", buttons: true, rawEvent: nModel.JSONB{"type": "completed"}},
+		{name: "bot disabled", rawEvent: nModel.JSONB{"type": "due", "due_date": due.Format(time.RFC3339)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := NewStubBot()
+			notifier := &TelegramNotifier{bot: stub, buttons: tt.buttons}
+			err := notifier.SendNotification(context.Background(), &nModel.NotificationDetails{
+				Notification: nModel.Notification{ChoreID: 7, TargetID: "42", Text: "Reminder", RawEvent: tt.rawEvent},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			msg := stub.Sent()[0].(tgbotapi.MessageConfig)
+			keyboard, hasButtons := msg.ReplyMarkup.(tgbotapi.InlineKeyboardMarkup)
+			if hasButtons != tt.wantButtons {
+				t.Fatalf("expected buttons=%v", tt.wantButtons)
+			}
+			if hasButtons && *keyboard.InlineKeyboard[0][0].CallbackData != callbackData(actionDone, 7, due.Unix()) {
+				t.Errorf("unexpected callback data %q", *keyboard.InlineKeyboard[0][0].CallbackData)
+			}
+		})
+	}
+}
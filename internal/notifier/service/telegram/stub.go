@@ -0,0 +1,62 @@
+package telegram
+
+import (
+	"sync"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// StubBot is an in-memory BotAPI for tests. Pushed updates are delivered to the bot's update loop
+// and everything sent to Telegram is recorded.
+type StubBot struct {
+	// SendErr fails every message sent, e.g. to simulate a chat that blocked the bot
+	SendErr  error
+	mu       sync.Mutex
+	sent     []tgbotapi.Chattable
+	updates  chan tgbotapi.Update
+	stopOnce sync.Once
+}
+
+func NewStubBot() *StubBot {
+	return &StubBot{updates: make(chan tgbotapi.Update, 100)}
+}
+
+func (s *StubBot) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	if s.SendErr != nil {
+		return tgbotapi.Message{}, s.SendErr
+	}
+	s.record(c)
+	return tgbotapi.Message{}, nil
+}
+
+func (s *StubBot) Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	s.record(c)
+	return &tgbotapi.APIResponse{Ok: true}, nil
+}
+
+func (s *StubBot) GetUpdatesChan(config tgbotapi.UpdateConfig) tgbotapi.UpdatesChannel {
+	return s.updates
+}
+
+// StopReceivingUpdates ends the update loop once the pushed updates are handled
+func (s *StubBot) StopReceivingUpdates() {
+	s.stopOnce.Do(func() { close(s.updates) })
+}
+
+// Push queues an update, as if a user pressed a button or sent a command
+func (s *StubBot) Push(update tgbotapi.Update) {
+	s.updates <- update
+}
+
+// Sent returns the messages, callback answers and edits sent so far
+func (s *StubBot) Sent() []tgbotapi.Chattable {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]tgbotapi.Chattable(nil), s.sent...)
+}
+
+func (s *StubBot) record(c tgbotapi.Chattable) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, c)
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"time"
 
 	"donetick.com/core/config"
 	chModel "donetick.com/core/internal/chore/model"
@@ -15,7 +16,9 @@ import (
 )
 
 type TelegramNotifier struct {
-	bot *tgbotapi.BotAPI
+	bot BotAPI
+	// reminders get Done, Skip and Snooze buttons when the bot is running to handle them
+	buttons bool
 }
 
 func NewTelegramNotifier(config *config.Config) *TelegramNotifier {
@@ -26,10 +29,16 @@ func NewTelegramNotifier(config *config.Config) *TelegramNotifier {
 	}
 
 	return &TelegramNotifier{
-		bot: bot,
+		bot:     bot,
+		buttons: config.Telegram.EnableBot,
 	}
 }
 
+// NewTelegramNotifierWithBot sends through the given bot, e.g. a StubBot in tests
+func NewTelegramNotifierWithBot(bot BotAPI, buttons bool) *TelegramNotifier {
+	return &TelegramNotifier{bot: bot, buttons: buttons}
+}
+
 func (tn *TelegramNotifier) SendChoreCompletion(c context.Context, chore *chModel.Chore, user *uModel.User) {
 
 	log := logging.FromContext(c)
@@ -78,6 +87,9 @@ func (tn *TelegramNotifier) SendNotification(c context.Context, notification *nM
 
 	msg := tgbotapi.NewMessage(chatID, notification.Text)
 	msg.ParseMode = "Markdown"
+	if tn.buttons && notification.ChoreID != 0 && isReminder(notification.RawEvent) {
+		msg.ReplyMarkup = reminderKeyboard(notification.ChoreID, dueDateOf(notification.RawEvent))
+	}
 	_, err = tn.bot.Send(msg)
 	if err != nil {
 		log.Error("Error sending message to user: ", err)
@@ -86,3 +98,30 @@ func (tn *TelegramNotifier) SendNotification(c context.Context, notification *nM
 	}
 	return nil
 }
+
+// isReminder reports whether the notification asks for the chore to be done, as opposed to e.g. a
+// completion notice
+func isReminder(rawEvent nModel.JSONB) bool {
+	eventType, _ := rawEvent["type"].(string)
+	switch eventType {
+	case "pre_due", "due", "overdue", "nagging", "escalation":
+		return true
+	}
+	return false
+}
+
+func dueDateOf(rawEvent nModel.JSONB) int64 {
+	switch v := rawEvent["due_date"].(type) {
+	case *time.Time:
+		if v != nil {
+			return v.Unix()
+		}
+	case time.Time:
+		return v.Unix()
+	case string:
+		if parsed, err := time.Parse(time.RFC3339, v); err == nil {
+			return parsed.Unix()
+		}
+	}
+	return 0
+}
@@ -0,0 +1,189 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"text/template"
+	"time"
+
+	"donetick.com/core/config"
+	nModel "donetick.com/core/internal/notifier/model"
+	"donetick.com/core/logging"
+)
+
+const maxBodyTemplateLength = 4096
+
+// Payload is the default JSON body of a webhook notification and the data available to body templates
+type Payload struct {
+	Type         string       `json:"type"`
+	Text         string       `json:"text"`
+	ChoreID      int          `json:"chore_id"`
+	CircleID     int          `json:"circle_id"`
+	UserID       int          `json:"user_id"`
+	ScheduledFor time.Time    `json:"scheduled_for"`
+	Event        nModel.JSONB `json:"event,omitempty"`
+}
+
+// WebhookNotifier POSTs reminders to a user's own URL, e.g. ntfy, Gotify or a Matrix bridge
+type WebhookNotifier struct {
+	client *http.Client
+}
+
+func NewWebhookNotifier(cfg *config.Config) *WebhookNotifier {
+	timeout := cfg.WebhookConfig.Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	client := &http.Client{Timeout: timeout}
+	if cfg.IsDoneTickDotCom {
+		// on the hosted service a webhook must not reach the server's own network
+		client.Transport = ExternalTransport(timeout)
+	}
+	return &WebhookNotifier{
+		client: client,
+	}
+}
+
+// ExternalTransport connects to public addresses only, for requests to URLs users choose
+func ExternalTransport(timeout time.Duration) *http.Transport {
+	dialer := &net.Dialer{Timeout: timeout, Control: rejectInternalAddress}
+	return &http.Transport{
+		DialContext:         dialer.DialContext,
+		TLSHandshakeTimeout: timeout,
+	}
+}
+
+// rejectInternalAddress refuses connections to loopback, private and link-local addresses. It runs
+// after DNS resolution, so a public name pointing at an internal address is refused too.
+func rejectInternalAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || isInternalIP(ip) {
+		return fmt.Errorf("destination %s is not allowed", host)
+	}
+	return nil
+}
+
+func isInternalIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsUnspecified()
+}
+
+// ValidateTarget checks a webhook target before it is saved
+func ValidateTarget(targetURL string, headers nModel.WebhookHeaders, bodyTemplate string) error {
+	u, err := url.Parse(targetURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("webhook target must be an http or https URL")
+	}
+	for name, value := range headers {
+		if name == "" || strings.ContainsAny(name, " \t\r\n:") {
+			return fmt.Errorf("invalid header name %q", name)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("invalid value for header %q", name)
+		}
+	}
+	if len(bodyTemplate) > maxBodyTemplateLength {
+		return fmt.Errorf("body template must be at most %d characters", maxBodyTemplateLength)
+	}
+	if _, err := parseBodyTemplate(bodyTemplate); err != nil {
+		return fmt.Errorf("invalid body template: %w", err)
+	}
+	return nil
+}
+
+func (w *WebhookNotifier) SendNotification(c context.Context, notification *nModel.NotificationDetails) error {
+	if w == nil {
+		return errors.New("webhook notifier is not initialized")
+	}
+	if notification.TargetID == "" {
+		return errors.New("unable to send notification, webhook URL is empty")
+	}
+
+	bodyTemplate := ""
+	if notification.WebhookBodyTemplate != nil {
+		bodyTemplate = *notification.WebhookBodyTemplate
+	}
+	body, err := renderBody(bodyTemplate, newPayload(notification))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(c, http.MethodPost, notification.TargetID, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Donetick")
+	// custom headers go last so they can override the content type, e.g. text/plain for ntfy
+	for name, value := range notification.WebhookHeaders {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		logging.FromContext(c).Debugw("Error sending webhook notification", "error", err)
+		return err
+	}
+	defer resp.Body.Close()
+
+	// drain a bit of the body so the connection can be reused, the response itself is never reported
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func newPayload(notification *nModel.NotificationDetails) *Payload {
+	return &Payload{
+		Type:         "reminder",
+		Text:         notification.Text,
+		ChoreID:      notification.ChoreID,
+		CircleID:     notification.CircleID,
+		UserID:       notification.UserID,
+		ScheduledFor: notification.ScheduledFor,
+		Event:        notification.RawEvent,
+	}
+}
+
+// renderBody renders the body template, or encodes the payload as JSON when there is none
+func renderBody(bodyTemplate string, payload *Payload) ([]byte, error) {
+	if bodyTemplate == "" {
+		return json.Marshal(payload)
+	}
+
+	tmpl, err := parseBodyTemplate(bodyTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid body template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, payload); err != nil {
+		return nil, fmt.Errorf("failed to render body template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// parseBodyTemplate parses a Go text/template. The json function encodes a value as JSON, so
+// {{json .Text}} produces a correctly quoted string inside a JSON body.
+func parseBodyTemplate(bodyTemplate string) (*template.Template, error) {
+	return template.New("body").Option("missingkey=zero").Funcs(template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+	}).Parse(bodyTemplate)
+}
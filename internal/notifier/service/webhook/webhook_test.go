@@ -0,0 +1,154 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"donetick.com/core/config"
+	nModel "donetick.com/core/internal/notifier/model"
+)
+
+func TestSendNotification(t *testing.T) {
+	tests := []struct {
+		name            string
+		headers         nModel.WebhookHeaders
+		bodyTemplate    string
+		status          int
+		wantBody        string
+		wantContentType string
+		wantErr         bool
+	}{
+		{
+			name:            "default JSON body",
+			status:          http.StatusOK,
+			wantBody:        `"text":"Dishes are due"`,
+			wantContentType: "application/json",
+		},
+		{
+			name:            "templated body with custom headers",
+			headers:         nModel.WebhookHeaders{"Authorization": "Bearer secret", "Content-Type": "text/plain"},
+			bodyTemplate:    `{{.Event.name}} for {{.Event.assignee}}: {{json .Text}}`,
+			status:          http.StatusAccepted,
+			wantBody:        `Dishes for Ann: "Dishes are due"`,
+			wantContentType: "text/plain",
+		},
+		{
+			name:    "error status",
+			status:  http.StatusInternalServerError,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotBody, gotContentType, gotAuth string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				gotBody = string(body)
+				gotContentType = r.Header.Get("Content-Type")
+				gotAuth = r.Header.Get("Authorization")
+				w.WriteHeader(tt.status)
+				w.Write([]byte("internal response"))
+			}))
+			defer server.Close()
+
+			notification := &nModel.NotificationDetails{
+				Notification: nModel.Notification{
+					ChoreID:  1,
+					TargetID: server.URL,
+					Text:     "Dishes are due",
+					TypeID:   nModel.NotificationPlatformWebhook,
+					RawEvent: nModel.JSONB{"name": "Dishes", "assignee": "Ann"},
+				},
+				WebhookHeaders:      tt.headers,
+				WebhookBodyTemplate: &tt.bodyTemplate,
+			}
+			err := NewWebhookNotifier(config.NewConfig()).SendNotification(context.Background(), notification)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error=%v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr {
+				if strings.Contains(err.Error(), "internal response") {
+					t.Errorf("expected the response body to stay out of the error, got %v", err)
+				}
+				return
+			}
+			if !strings.Contains(gotBody, tt.wantBody) {
+				t.Errorf("expected body to contain %s, got %s", tt.wantBody, gotBody)
+			}
+			if gotContentType != tt.wantContentType {
+				t.Errorf("expected content type %s, got %s", tt.wantContentType, gotContentType)
+			}
+			if gotAuth != tt.headers["Authorization"] {
+				t.Errorf("expected authorization %q, got %q", tt.headers["Authorization"], gotAuth)
+			}
+		})
+	}
+}
+
+func TestValidateTarget(t *testing.T) {
+	tests := []struct {
+		name         string
+		url          string
+		headers      nModel.WebhookHeaders
+		bodyTemplate string
+		wantErr      bool
+	}{
+		{name: "valid", url: "https://ntfy.sh/chores", headers: nModel.WebhookHeaders{"Title": "Donetick"}, bodyTemplate: "{{.Text}}"},
+		{name: "not http", url: "ftp://example.com", wantErr: true},
+		{name: "no host", url: "https://", wantErr: true},
+		{name: "header injection", url: "https://example.com", headers: nModel.WebhookHeaders{"X-Test": "a\r\nHost: evil"}, wantErr: true},
+		{name: "invalid header name", url: "https://example.com", headers: nModel.WebhookHeaders{"Bad Header": "a"}, wantErr: true},
+		{name: "broken template", url: "https://example.com", bodyTemplate: "{{.Text", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTarget(tt.url, tt.headers, tt.bodyTemplate)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error=%v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestSendNotificationRejectsInternalAddressOnHostedService(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	cfg := config.NewConfig()
+	cfg.IsDoneTickDotCom = true
+	notification := &nModel.NotificationDetails{
+		Notification: nModel.Notification{TargetID: server.URL, Text: "Dishes are due"},
+	}
+	if err := NewWebhookNotifier(cfg).SendNotification(context.Background(), notification); err == nil {
+		t.Fatalf("expected loopback destination to be refused")
+	}
+	if called {
+		t.Errorf("expected no request to reach the loopback server")
+	}
+}
+
+func TestIsInternalIP(t *testing.T) {
+	for address, want := range map[string]bool{
+		"127.0.0.1":       true,
+		"10.1.2.3":        true,
+		"192.168.1.10":    true,
+		"169.254.169.254": true,
+		"::1":             true,
+		"fd00::1":         true,
+		"0.0.0.0":         true,
+		"93.184.216.34":   false,
+		"2606:4700::1111": false,
+	} {
+		if got := isInternalIP(net.ParseIP(address)); got != want {
+			t.Errorf("isInternalIP(%s) = %v, want %v", address, got, want)
+		}
+	}
+}
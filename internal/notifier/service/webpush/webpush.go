@@ -0,0 +1,199 @@
+package webpush
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"donetick.com/core/config"
+	dRepo "donetick.com/core/internal/device/repo"
+	nModel "donetick.com/core/internal/notifier/model"
+	"donetick.com/core/internal/notifier/service/webhook"
+	uModel "donetick.com/core/internal/user/model"
+	"donetick.com/core/logging"
+	webpush "github.com/SherClockHolmes/webpush-go"
+)
+
+const (
+	// push services keep undelivered messages for a day, a reminder is stale after that
+	messageTTL  = 24 * 60 * 60
+	sendTimeout = 10 * time.Second
+)
+
+// SubscriptionStore is the part of the device repository the notifier uses
+type SubscriptionStore interface {
+	GetWebPushSubscriptions(c context.Context, userID int) ([]*uModel.WebPushSubscription, error)
+	DeleteWebPushSubscriptionByID(c context.Context, subscriptionID int) error
+	MarkWebPushSubscriptionUsed(c context.Context, subscriptionID int) error
+}
+
+type WebPushNotifier struct {
+	store   SubscriptionStore
+	client  webpush.HTTPClient
+	cfg     config.WebPushConfig
+	timeout time.Duration
+}
+
+// Payload is the JSON the web app's service worker shows as a notification
+type Payload struct {
+	Title string            `json:"title"`
+	Body  string            `json:"body"`
+	Tag   string            `json:"tag,omitempty"`
+	Data  map[string]string `json:"data,omitempty"`
+}
+
+// NewWebPushNotifier returns nil unless a VAPID key pair is configured
+func NewWebPushNotifier(cfg *config.Config, dr *dRepo.DeviceRepository) *WebPushNotifier {
+	if cfg.WebPush.PublicKey == "" || cfg.WebPush.PrivateKey == "" {
+		return nil
+	}
+	return NewWebPushNotifierWithClient(cfg.WebPush, dr, newClient(cfg))
+}
+
+func newClient(cfg *config.Config) *http.Client {
+	client := &http.Client{Timeout: sendTimeout}
+	if cfg.IsDoneTickDotCom {
+		// subscription endpoints come from browsers, they must not reach the server's own network
+		client.Transport = webhook.ExternalTransport(sendTimeout)
+	}
+	return client
+}
+
+// NewWebPushNotifierWithClient sends through the given client, e.g. to a local push service in tests
+func NewWebPushNotifierWithClient(cfg config.WebPushConfig, store SubscriptionStore, client webpush.HTTPClient) *WebPushNotifier {
+	return &WebPushNotifier{store: store, client: client, cfg: cfg, timeout: sendTimeout}
+}
+
+// PublicKey is the VAPID key browsers subscribe with
+func (w *WebPushNotifier) PublicKey() string {
+	if w == nil {
+		return ""
+	}
+	return w.cfg.PublicKey
+}
+
+// SendNotification pushes the notification to every browser the user subscribed. Subscriptions the
+// push service reports as gone are deleted. It fails only when no browser received the message.
+func (w *WebPushNotifier) SendNotification(c context.Context, notification *nModel.NotificationDetails) error {
+	log := logging.FromContext(c)
+	subscriptions, err := w.store.GetWebPushSubscriptions(c, notification.UserID)
+	if err != nil {
+		return err
+	}
+	if len(subscriptions) == 0 {
+		return errors.New("no browser is subscribed to push notifications")
+	}
+	message, err := json.Marshal(payloadFor(notification))
+	if err != nil {
+		return err
+	}
+
+	delivered := 0
+	var errs []error
+	for _, subscription := range subscriptions {
+		err := w.send(c, message, subscription)
+		switch {
+		case err == nil:
+			delivered++
+			if err := w.store.MarkWebPushSubscriptionUsed(c, subscription.ID); err != nil {
+				log.Errorw("Failed to mark web push subscription as used", "subscription_id", subscription.ID, "error", err)
+			}
+		case errors.Is(err, errSubscriptionGone):
+			log.Debugw("Deleting expired web push subscription", "subscription_id", subscription.ID, "user_id", subscription.UserID)
+			if err := w.store.DeleteWebPushSubscriptionByID(c, subscription.ID); err != nil {
+				log.Errorw("Failed to delete expired web push subscription", "subscription_id", subscription.ID, "error", err)
+			}
+			errs = append(errs, err)
+		default:
+			errs = append(errs, err)
+		}
+	}
+	if delivered == 0 {
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+var errSubscriptionGone = errors.New("web push subscription expired")
+
+func (w *WebPushNotifier) send(c context.Context, message []byte, subscription *uModel.WebPushSubscription) error {
+	ctx, cancel := context.WithTimeout(c, w.timeout)
+	defer cancel()
+	resp, err := webpush.SendNotificationWithContext(ctx, message, &webpush.Subscription{
+		Endpoint: subscription.Endpoint,
+		Keys:     webpush.Keys{Auth: subscription.Auth, P256dh: subscription.P256dh},
+	}, &webpush.Options{
+		HTTPClient:      w.client,
+		Subscriber:      w.cfg.Subject,
+		VAPIDPublicKey:  w.cfg.PublicKey,
+		VAPIDPrivateKey: w.cfg.PrivateKey,
+		TTL:             messageTTL,
+		Urgency:         webpush.UrgencyNormal,
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// drain a bit of the body so the connection can be reused, failures are stored for users to
+	// read so the response itself is never reported
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	switch {
+	case resp.StatusCode == http.StatusGone || resp.StatusCode == http.StatusNotFound:
+		return errSubscriptionGone
+	case resp.StatusCode >= 300:
+		return fmt.Errorf("push service responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func payloadFor(notification *nModel.NotificationDetails) Payload {
+	payload := Payload{Title: "Donetick", Body: notification.Text}
+	if name, ok := notification.RawEvent["name"].(string); ok && name != "" {
+		payload.Title = name
+	}
+	if notification.ChoreID != 0 {
+		// one notification per chore, a newer reminder replaces the previous one
+		payload.Tag = fmt.Sprintf("chore-%d", notification.ChoreID)
+		payload.Data = map[string]string{
+			"choreId": fmt.Sprint(notification.ChoreID),
+			"url":     fmt.Sprintf("/chores/%d", notification.ChoreID),
+		}
+		if eventType, ok := notification.RawEvent["type"].(string); ok {
+			payload.Data["type"] = eventType
+		}
+	}
+	return payload
+}
+
+// ValidateSubscription checks a PushSubscription sent by a browser. Push services are reached
+// over HTTPS only.
+func ValidateSubscription(endpoint, p256dh, auth string) error {
+	if len(endpoint) > 1024 {
+		return errors.New("endpoint is too long")
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return errors.New("endpoint must be an https URL")
+	}
+	if key, err := decodeKey(p256dh); err != nil || len(key) != 65 {
+		return errors.New("p256dh must be an uncompressed P-256 public key")
+	}
+	if secret, err := decodeKey(auth); err != nil || len(secret) != 16 {
+		return errors.New("auth must be a 16 byte secret")
+	}
+	return nil
+}
+
+// decodeKey decodes a key of PushSubscription.toJSON(), which is base64url without padding
+// though some browsers pad it
+func decodeKey(key string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(key, "="))
+}
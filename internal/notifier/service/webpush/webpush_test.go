@@ -0,0 +1,265 @@
+package webpush
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"donetick.com/core/config"
+	nModel "donetick.com/core/internal/notifier/model"
+	uModel "donetick.com/core/internal/user/model"
+	webpush "github.com/SherClockHolmes/webpush-go"
+	"golang.org/x/crypto/hkdf"
+)
+
+type memoryStore struct {
+	subscriptions []*uModel.WebPushSubscription
+	deleted       []int
+	used          []int
+}
+
+func (s *memoryStore) GetWebPushSubscriptions(c context.Context, userID int) ([]*uModel.WebPushSubscription, error) {
+	return s.subscriptions, nil
+}
+
+func (s *memoryStore) DeleteWebPushSubscriptionByID(c context.Context, subscriptionID int) error {
+	s.deleted = append(s.deleted, subscriptionID)
+	return nil
+}
+
+func (s *memoryStore) MarkWebPushSubscriptionUsed(c context.Context, subscriptionID int) error {
+	s.used = append(s.used, subscriptionID)
+	return nil
+}
+
+// browser holds the keys of a push subscription, so the test can decrypt what the push service received
+type browser struct {
+	key    *ecdh.PrivateKey
+	secret []byte
+}
+
+func newBrowser(t *testing.T) *browser {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := make([]byte, 16)
+	if _, err := rand.Read(secret); err != nil {
+		t.Fatal(err)
+	}
+	return &browser{key: key, secret: secret}
+}
+
+func (b *browser) subscription(id int, endpoint string) *uModel.WebPushSubscription {
+	return &uModel.WebPushSubscription{
+		ID:       id,
+		UserID:   1,
+		Endpoint: endpoint,
+		P256dh:   base64.RawURLEncoding.EncodeToString(b.key.PublicKey().Bytes()),
+		Auth:     base64.RawURLEncoding.EncodeToString(b.secret),
+	}
+}
+
+// decrypt reverses the aes128gcm content encoding of RFC 8291
+func (b *browser) decrypt(t *testing.T, body []byte) []byte {
+	salt, keyIDLength := body[:16], int(body[20])
+	serverKey, err := ecdh.P256().NewPublicKey(body[21 : 21+keyIDLength])
+	if err != nil {
+		t.Fatal(err)
+	}
+	shared, err := b.key.ECDH(serverKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expand := func(secret, salt, info []byte, length int) []byte {
+		out := make([]byte, length)
+		if _, err := io.ReadFull(hkdf.New(sha256.New, secret, salt, info), out); err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+	keyInfo := append(append([]byte("WebPush: info\x00"), b.key.PublicKey().Bytes()...), serverKey.Bytes()...)
+	ikm := expand(shared, b.secret, keyInfo, 32)
+	cek := expand(ikm, salt, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	nonce := expand(ikm, salt, []byte("Content-Encoding: nonce\x00"), 12)
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := gcm.Open(nil, nonce, body[21+keyIDLength:], nil)
+	if err != nil {
+		t.Fatalf("failed to decrypt the payload: %v", err)
+	}
+	// the record ends with the 0x02 delimiter and padding
+	return plain[:strings.LastIndexByte(string(plain), 2)]
+}
+
+// pushService is a local push service answering each endpoint path with a fixed status
+type pushService struct {
+	mu       sync.Mutex
+	statuses map[string]int
+	received map[string][]byte
+	headers  map[string]http.Header
+}
+
+func (p *pushService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	p.mu.Lock()
+	p.received[r.URL.Path] = body
+	p.headers[r.URL.Path] = r.Header
+	status := p.statuses[r.URL.Path]
+	p.mu.Unlock()
+	w.WriteHeader(status)
+	if status >= 300 {
+		io.WriteString(w, "internal details")
+	}
+}
+
+func TestSendNotification(t *testing.T) {
+	privateKey, publicKey, err := webpush.GenerateVAPIDKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name        string
+		statuses    map[string]int
+		wantErr     bool
+		wantDeleted []int
+		wantUsed    []int
+	}{
+		{name: "delivered", statuses: map[string]int{"/a": http.StatusCreated}, wantUsed: []int{1}},
+		{name: "expired subscription is pruned", statuses: map[string]int{"/a": http.StatusGone}, wantErr: true, wantDeleted: []int{1}},
+		{name: "one of two browsers expired", statuses: map[string]int{"/a": http.StatusGone, "/b": http.StatusCreated}, wantDeleted: []int{1}, wantUsed: []int{2}},
+		{name: "push service error is kept for retry", statuses: map[string]int{"/a": http.StatusInternalServerError}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &pushService{statuses: tt.statuses, received: map[string][]byte{}, headers: map[string]http.Header{}}
+			server := httptest.NewTLSServer(service)
+			defer server.Close()
+
+			browsers := map[string]*browser{}
+			store := &memoryStore{}
+			for i, path := range []string{"/a", "/b"} {
+				if _, ok := tt.statuses[path]; !ok {
+					continue
+				}
+				browsers[path] = newBrowser(t)
+				store.subscriptions = append(store.subscriptions, browsers[path].subscription(i+1, server.URL+path))
+			}
+			notifier := NewWebPushNotifierWithClient(config.WebPushConfig{
+				PublicKey: publicKey, PrivateKey: privateKey, Subject: "mailto:admin@example.com",
+			}, store, server.Client())
+
+			err := notifier.SendNotification(context.Background(), &nModel.NotificationDetails{
+				Notification: nModel.Notification{ChoreID: 7, UserID: 1, Text: "Dishes are due", RawEvent: nModel.JSONB{"type": "due", "name": "Dishes"}},
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SendNotification() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && strings.Contains(err.Error(), "internal details") {
+				t.Errorf("expected the push service response to stay out of the error, got %v", err)
+			}
+			if !equalIDs(store.deleted, tt.wantDeleted) {
+				t.Errorf("deleted subscriptions %v, want %v", store.deleted, tt.wantDeleted)
+			}
+			if !equalIDs(store.used, tt.wantUsed) {
+				t.Errorf("used subscriptions %v, want %v", store.used, tt.wantUsed)
+			}
+
+			for path, b := range browsers {
+				if !strings.HasPrefix(service.headers[path].Get("Authorization"), "vapid t=") {
+					t.Errorf("%s: missing VAPID authorization, got %q", path, service.headers[path].Get("Authorization"))
+				}
+				var payload Payload
+				if err := json.Unmarshal(b.decrypt(t, service.received[path]), &payload); err != nil {
+					t.Fatal(err)
+				}
+				if payload.Title != "Dishes" || payload.Body != "Dishes are due" || payload.Data["choreId"] != "7" {
+					t.Errorf("%s: unexpected payload %+v", path, payload)
+				}
+			}
+		})
+	}
+}
+
+func TestSendNotificationRejectsInternalEndpointOnHostedService(t *testing.T) {
+	privateKey, publicKey, err := webpush.GenerateVAPIDKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := &pushService{statuses: map[string]int{"/a": http.StatusCreated}, received: map[string][]byte{}, headers: map[string]http.Header{}}
+	server := httptest.NewTLSServer(service)
+	defer server.Close()
+
+	cfg := config.NewConfig()
+	cfg.IsDoneTickDotCom = true
+	cfg.WebPush = config.WebPushConfig{PublicKey: publicKey, PrivateKey: privateKey, Subject: "mailto:admin@example.com"}
+	store := &memoryStore{subscriptions: []*uModel.WebPushSubscription{newBrowser(t).subscription(1, server.URL+"/a")}}
+
+	client := newClient(cfg)
+	// trust the test certificate so only the dialer can refuse the endpoint
+	client.Transport.(*http.Transport).TLSClientConfig = server.Client().Transport.(*http.Transport).TLSClientConfig
+	err = NewWebPushNotifierWithClient(cfg.WebPush, store, client).SendNotification(context.Background(), &nModel.NotificationDetails{
+		Notification: nModel.Notification{ChoreID: 7, UserID: 1, Text: "Dishes are due"},
+	})
+	if err == nil {
+		t.Fatalf("expected loopback endpoint to be refused")
+	}
+	if _, ok := service.received["/a"]; ok {
+		t.Errorf("expected no request to reach the loopback push service")
+	}
+}
+
+func TestValidateSubscription(t *testing.T) {
+	b := newBrowser(t)
+	valid := b.subscription(1, "https://push.example.com/send/abc")
+	tests := []struct {
+		name     string
+		endpoint string
+		p256dh   string
+		auth     string
+		wantErr  bool
+	}{
+		{name: "valid", endpoint: valid.Endpoint, p256dh: valid.P256dh, auth: valid.Auth},
+		{name: "padded keys", endpoint: valid.Endpoint, p256dh: base64.URLEncoding.EncodeToString(b.key.PublicKey().Bytes()), auth: base64.URLEncoding.EncodeToString(b.secret)},
+		{name: "plain http", endpoint: "http://push.example.com/send/abc", p256dh: valid.P256dh, auth: valid.Auth, wantErr: true},
+		{name: "short key", endpoint: valid.Endpoint, p256dh: valid.Auth, auth: valid.Auth, wantErr: true},
+		{name: "bad auth", endpoint: valid.Endpoint, p256dh: valid.P256dh, auth: "not base64!", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateSubscription(tt.endpoint, tt.p256dh, tt.auth); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateSubscription() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func equalIDs(got, want []int) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -10,6 +10,8 @@ type PointsHistory struct {
 	CreatedBy int                 `json:"created_by" gorm:"column:created_by"`     // Created by
 	UserID    int                 `json:"user_id" gorm:"column:user_id;index"`     // User ID
 	CircleID  int                 `json:"circle_id" gorm:"column:circle_id;index"` // Circle ID with index
+	// RedemptionID links redeem entries to the reward redemption they paid for
+	RedemptionID *int `json:"redemption_id,omitempty" gorm:"column:redemption_id"`
 }
 
 type PointsHistoryAction int8
@@ -19,3 +21,49 @@ const (
 	PointsHistoryActionRemove
 	PointsHistoryActionRedeem
 )
+
+// Reward is an item in a circle's rewards catalog that members can buy with their points.
+type Reward struct {
+	ID          int        `json:"id" gorm:"primary_key"`
+	CircleID    int        `json:"circleId" gorm:"column:circle_id;index;not null"`
+	Name        string     `json:"name" gorm:"column:name;not null"`
+	Description *string    `json:"description" gorm:"column:description"`
+	Cost        int        `json:"cost" gorm:"column:cost;not null"`
+	Stock       *int       `json:"stock" gorm:"column:stock"` // nil means unlimited
+	Image       *string    `json:"image" gorm:"column:image"`
+	IsActive    bool       `json:"isActive" gorm:"column:is_active;default:true"`
+	CreatedBy   int        `json:"createdBy" gorm:"column:created_by"`
+	CreatedAt   time.Time  `json:"createdAt" gorm:"column:created_at"`
+	UpdatedAt   *time.Time `json:"updatedAt" gorm:"column:updated_at"`
+}
+
+type RedemptionStatus int8
+
+const (
+	RedemptionStatusPending RedemptionStatus = iota
+	RedemptionStatusApproved
+	RedemptionStatusRejected
+	RedemptionStatusCancelled
+)
+
+// RewardRedemption is a member's request to buy a reward. Points are only deducted once an admin approves it.
+type RewardRedemption struct {
+	ID         int              `json:"id" gorm:"primary_key"`
+	RewardID   int              `json:"rewardId" gorm:"column:reward_id;index;not null"`
+	Reward     *Reward          `json:"reward,omitempty" gorm:"foreignKey:RewardID;references:ID"`
+	CircleID   int              `json:"circleId" gorm:"column:circle_id;index;not null"`
+	UserID     int              `json:"userId" gorm:"column:user_id;index;not null"`
+	Cost       int              `json:"cost" gorm:"column:cost;not null"` // Cost at the time of the request
+	Status     RedemptionStatus `json:"status" gorm:"column:status;index;default:0"`
+	Note       *string          `json:"note" gorm:"column:note"`
+	ReviewNote *string          `json:"reviewNote" gorm:"column:review_note"`
+	ReviewedBy *int             `json:"reviewedBy" gorm:"column:reviewed_by"`
+	ReviewedAt *time.Time       `json:"reviewedAt" gorm:"column:reviewed_at"`
+	CreatedAt  time.Time        `json:"createdAt" gorm:"column:created_at"`
+}
+
+// LedgerEntry is a PointsHistory row with the user's balance after it was applied.
+type LedgerEntry struct {
+	PointsHistory
+	Balance int `json:"balance"`
+}
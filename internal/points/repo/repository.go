@@ -2,11 +2,21 @@ package points
 
 import (
 	"context"
+	"errors"
+	"time"
 
+	cModel "donetick.com/core/internal/circle/model"
 	pModel "donetick.com/core/internal/points"
 	"gorm.io/gorm"
 )
 
+var (
+	ErrNotEnoughPoints      = errors.New("not enough points")
+	ErrRewardOutOfStock     = errors.New("reward is out of stock")
+	ErrRewardUnavailable    = errors.New("reward is not available")
+	ErrRedemptionNotPending = errors.New("redemption is not pending")
+)
+
 type PointsRepository struct {
 	db *gorm.DB
 }
@@ -22,3 +32,234 @@ func (r *PointsRepository) CreatePointsHistory(c context.Context, tx *gorm.DB, p
 
 	return r.db.WithContext(c).Save(pointsHistory).Error
 }
+
+// GetPointsLedger returns the user's points history in the circle, newest first, with the balance after each entry.
+func (r *PointsRepository) GetPointsLedger(c context.Context, circleID int, userID int) ([]*pModel.LedgerEntry, error) {
+	var history []*pModel.PointsHistory
+	if err := r.db.WithContext(c).
+		Where("circle_id = ? AND user_id = ?", circleID, userID).
+		Order("created_at asc, id asc").
+		Find(&history).Error; err != nil {
+		return nil, err
+	}
+
+	ledger := make([]*pModel.LedgerEntry, len(history))
+	balance := 0
+	for i, entry := range history {
+		switch entry.Action {
+		case pModel.PointsHistoryActionAdd:
+			balance += entry.Points
+		case pModel.PointsHistoryActionRemove, pModel.PointsHistoryActionRedeem:
+			balance -= entry.Points
+		}
+		// newest first
+		ledger[len(history)-1-i] = &pModel.LedgerEntry{PointsHistory: *entry, Balance: balance}
+	}
+	return ledger, nil
+}
+
+func (r *PointsRepository) GetCircleRewards(c context.Context, circleID int, includeInactive bool) ([]*pModel.Reward, error) {
+	var rewards []*pModel.Reward
+	query := r.db.WithContext(c).Where("circle_id = ?", circleID)
+	if !includeInactive {
+		query = query.Where("is_active = ?", true)
+	}
+	if err := query.Order("cost asc, id asc").Find(&rewards).Error; err != nil {
+		return nil, err
+	}
+	return rewards, nil
+}
+
+func (r *PointsRepository) GetRewardByID(c context.Context, circleID int, rewardID int) (*pModel.Reward, error) {
+	var reward pModel.Reward
+	if err := r.db.WithContext(c).Where("id = ? AND circle_id = ?", rewardID, circleID).First(&reward).Error; err != nil {
+		return nil, err
+	}
+	return &reward, nil
+}
+
+func (r *PointsRepository) CreateReward(c context.Context, reward *pModel.Reward) error {
+	return r.db.WithContext(c).Create(reward).Error
+}
+
+func (r *PointsRepository) UpdateReward(c context.Context, reward *pModel.Reward) error {
+	now := time.Now().UTC()
+	reward.UpdatedAt = &now
+	return r.db.WithContext(c).Model(&pModel.Reward{}).
+		Where("id = ? AND circle_id = ?", reward.ID, reward.CircleID).
+		Select("name", "description", "cost", "stock", "image", "is_active", "updated_at").
+		Updates(reward).Error
+}
+
+// GetPendingCost returns the total cost of the user's pending redemptions, which is held back from their balance.
+func (r *PointsRepository) GetPendingCost(c context.Context, tx *gorm.DB, circleID int, userID int) (int, error) {
+	if tx == nil {
+		tx = r.db.WithContext(c)
+	}
+	var pending int64
+	if err := tx.Model(&pModel.RewardRedemption{}).
+		Select("COALESCE(SUM(cost), 0)").
+		Where("circle_id = ? AND user_id = ? AND status = ?", circleID, userID, pModel.RedemptionStatusPending).
+		Scan(&pending).Error; err != nil {
+		return 0, err
+	}
+	return int(pending), nil
+}
+
+func (r *PointsRepository) getAvailablePoints(tx *gorm.DB, circleID int, userID int) (int, error) {
+	var member cModel.UserCircle
+	if err := tx.Where("circle_id = ? AND user_id = ?", circleID, userID).First(&member).Error; err != nil {
+		return 0, err
+	}
+	return member.Points - member.PointsRedeemed, nil
+}
+
+// CreateRedemption requests a reward for a member. The member's balance minus their other pending
+// requests must cover the cost, so the same points can't be promised twice.
+func (r *PointsRepository) CreateRedemption(c context.Context, redemption *pModel.RewardRedemption) error {
+	return r.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		var reward pModel.Reward
+		if err := tx.Where("id = ? AND circle_id = ?", redemption.RewardID, redemption.CircleID).First(&reward).Error; err != nil {
+			return err
+		}
+		if !reward.IsActive {
+			return ErrRewardUnavailable
+		}
+		if reward.Stock != nil && *reward.Stock <= 0 {
+			return ErrRewardOutOfStock
+		}
+
+		available, err := r.getAvailablePoints(tx, redemption.CircleID, redemption.UserID)
+		if err != nil {
+			return err
+		}
+		pending, err := r.GetPendingCost(c, tx, redemption.CircleID, redemption.UserID)
+		if err != nil {
+			return err
+		}
+		if available-pending < reward.Cost {
+			return ErrNotEnoughPoints
+		}
+
+		redemption.Cost = reward.Cost
+		redemption.Status = pModel.RedemptionStatusPending
+		redemption.CreatedAt = time.Now().UTC()
+		return tx.Create(redemption).Error
+	})
+}
+
+func (r *PointsRepository) GetRedemptionByID(c context.Context, circleID int, redemptionID int) (*pModel.RewardRedemption, error) {
+	var redemption pModel.RewardRedemption
+	if err := r.db.WithContext(c).Preload("Reward").Where("id = ? AND circle_id = ?", redemptionID, circleID).First(&redemption).Error; err != nil {
+		return nil, err
+	}
+	return &redemption, nil
+}
+
+// GetRedemptions returns the circle's redemptions, newest first. userID and status are optional filters.
+func (r *PointsRepository) GetRedemptions(c context.Context, circleID int, userID *int, status *pModel.RedemptionStatus) ([]*pModel.RewardRedemption, error) {
+	var redemptions []*pModel.RewardRedemption
+	query := r.db.WithContext(c).Preload("Reward").Where("circle_id = ?", circleID)
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+	if err := query.Order("created_at desc, id desc").Find(&redemptions).Error; err != nil {
+		return nil, err
+	}
+	return redemptions, nil
+}
+
+// ApproveRedemption takes the reward out of stock, deducts the points and records the redeem in the points history.
+func (r *PointsRepository) ApproveRedemption(c context.Context, redemption *pModel.RewardRedemption, adminUserID int, reviewNote *string) error {
+	return r.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UTC()
+		res := tx.Model(&pModel.RewardRedemption{}).
+			Where("id = ? AND status = ?", redemption.ID, pModel.RedemptionStatusPending).
+			Updates(map[string]interface{}{
+				"status":      pModel.RedemptionStatusApproved,
+				"reviewed_by": adminUserID,
+				"reviewed_at": now,
+				"review_note": reviewNote,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrRedemptionNotPending
+		}
+
+		res = tx.Model(&pModel.Reward{}).
+			Where("id = ? AND stock IS NOT NULL AND stock > 0", redemption.RewardID).
+			Update("stock", gorm.Expr("stock - 1"))
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			// either unlimited or sold out in the meantime
+			var reward pModel.Reward
+			if err := tx.Select("stock").Where("id = ?", redemption.RewardID).First(&reward).Error; err != nil {
+				return err
+			}
+			if reward.Stock != nil {
+				return ErrRewardOutOfStock
+			}
+		}
+
+		available, err := r.getAvailablePoints(tx, redemption.CircleID, redemption.UserID)
+		if err != nil {
+			return err
+		}
+		if available < redemption.Cost {
+			return ErrNotEnoughPoints
+		}
+
+		if err := tx.Model(&cModel.UserCircle{}).
+			Where("user_id = ? AND circle_id = ?", redemption.UserID, redemption.CircleID).
+			Update("points_redeemed", gorm.Expr("points_redeemed + ?", redemption.Cost)).Error; err != nil {
+			return err
+		}
+		return tx.Create(&pModel.PointsHistory{
+			Action:       pModel.PointsHistoryActionRedeem,
+			Points:       redemption.Cost,
+			CreatedAt:    now,
+			CreatedBy:    adminUserID,
+			UserID:       redemption.UserID,
+			CircleID:     redemption.CircleID,
+			RedemptionID: &redemption.ID,
+		}).Error
+	})
+}
+
+func (r *PointsRepository) RejectRedemption(c context.Context, redemption *pModel.RewardRedemption, adminUserID int, reviewNote *string) error {
+	res := r.db.WithContext(c).Model(&pModel.RewardRedemption{}).
+		Where("id = ? AND status = ?", redemption.ID, pModel.RedemptionStatusPending).
+		Updates(map[string]interface{}{
+			"status":      pModel.RedemptionStatusRejected,
+			"reviewed_by": adminUserID,
+			"reviewed_at": time.Now().UTC(),
+			"review_note": reviewNote,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrRedemptionNotPending
+	}
+	return nil
+}
+
+func (r *PointsRepository) CancelRedemption(c context.Context, redemption *pModel.RewardRedemption) error {
+	res := r.db.WithContext(c).Model(&pModel.RewardRedemption{}).
+		Where("id = ? AND user_id = ? AND status = ?", redemption.ID, redemption.UserID, pModel.RedemptionStatusPending).
+		Update("status", pModel.RedemptionStatusCancelled)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrRedemptionNotPending
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package points
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	cModel "donetick.com/core/internal/circle/model"
+	"donetick.com/core/internal/database"
+	pModel "donetick.com/core/internal/points"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupPointsRepo(t *testing.T, points int) (*PointsRepository, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect database: %v", err)
+	}
+	if err := database.Migration(db); err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
+	}
+	member := &cModel.UserCircle{UserID: 2, CircleID: 1, Role: cModel.UserRoleMember, IsActive: true, Points: points}
+	if err := db.Create(member).Error; err != nil {
+		t.Fatalf("failed to create member: %v", err)
+	}
+	if err := db.Create(&pModel.PointsHistory{Action: pModel.PointsHistoryActionAdd, Points: points, CreatedAt: time.Now().UTC().Add(-time.Hour), CreatedBy: 2, UserID: 2, CircleID: 1}).Error; err != nil {
+		t.Fatalf("failed to create points history: %v", err)
+	}
+	return NewPointsRepository(db), db
+}
+
+func createReward(t *testing.T, r *PointsRepository, cost int, stock *int) *pModel.Reward {
+	reward := &pModel.Reward{CircleID: 1, Name: "Movie night", Cost: cost, Stock: stock, IsActive: true, CreatedBy: 1}
+	if err := r.CreateReward(context.Background(), reward); err != nil {
+		t.Fatalf("failed to create reward: %v", err)
+	}
+	return reward
+}
+
+func TestRedemptionApproveDeductsPointsAndStock(t *testing.T) {
+	r, db := setupPointsRepo(t, 30)
+	ctx := context.Background()
+	stock := 1
+	reward := createReward(t, r, 20, &stock)
+
+	redemption := &pModel.RewardRedemption{RewardID: reward.ID, CircleID: 1, UserID: 2}
+	if err := r.CreateRedemption(ctx, redemption); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if redemption.Cost != 20 || redemption.Status != pModel.RedemptionStatusPending {
+		t.Fatalf("unexpected redemption %+v", redemption)
+	}
+
+	// the pending request holds 20 of the 30 points
+	second := &pModel.RewardRedemption{RewardID: reward.ID, CircleID: 1, UserID: 2}
+	if err := r.CreateRedemption(ctx, second); !errors.Is(err, ErrNotEnoughPoints) {
+		t.Fatalf("expected ErrNotEnoughPoints, got %v", err)
+	}
+
+	if err := r.ApproveRedemption(ctx, redemption, 1, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.ApproveRedemption(ctx, redemption, 1, nil); !errors.Is(err, ErrRedemptionNotPending) {
+		t.Fatalf("expected ErrRedemptionNotPending, got %v", err)
+	}
+
+	var member cModel.UserCircle
+	db.Where("user_id = ? AND circle_id = ?", 2, 1).First(&member)
+	if member.PointsRedeemed != 20 {
+		t.Errorf("expected 20 points redeemed, got %d", member.PointsRedeemed)
+	}
+	updated, _ := r.GetRewardByID(ctx, 1, reward.ID)
+	if updated.Stock == nil || *updated.Stock != 0 {
+		t.Errorf("expected stock 0, got %v", updated.Stock)
+	}
+
+	ledger, err := r.GetPointsLedger(ctx, 1, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ledger) != 2 {
+		t.Fatalf("expected 2 ledger entries, got %d", len(ledger))
+	}
+	if ledger[0].Action != pModel.PointsHistoryActionRedeem || ledger[0].RedemptionID == nil || *ledger[0].RedemptionID != redemption.ID {
+		t.Errorf("expected newest entry to be the redeem, got %+v", ledger[0].PointsHistory)
+	}
+	if ledger[0].Balance != 10 || ledger[1].Balance != 30 {
+		t.Errorf("expected balances 10 and 30, got %d and %d", ledger[0].Balance, ledger[1].Balance)
+	}
+}
+
+func TestCreateRedemptionChecks(t *testing.T) {
+	zero := 0
+	tests := []struct {
+		name    string
+		cost    int
+		stock   *int
+		active  bool
+		wantErr error
+	}{
+		{name: "unlimited stock", cost: 10, active: true},
+		{name: "out of stock", cost: 10, stock: &zero, active: true, wantErr: ErrRewardOutOfStock},
+		{name: "inactive", cost: 10, active: false, wantErr: ErrRewardUnavailable},
+		{name: "too expensive", cost: 50, active: true, wantErr: ErrNotEnoughPoints},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, db := setupPointsRepo(t, 30)
+			reward := createReward(t, r, tt.cost, tt.stock)
+			if !tt.active {
+				db.Model(reward).Update("is_active", false)
+			}
+			err := r.CreateRedemption(context.Background(), &pModel.RewardRedemption{RewardID: reward.ID, CircleID: 1, UserID: 2})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRejectAndCancelReleaseHeldPoints(t *testing.T) {
+	r, _ := setupPointsRepo(t, 20)
+	ctx := context.Background()
+	reward := createReward(t, r, 20, nil)
+
+	first := &pModel.RewardRedemption{RewardID: reward.ID, CircleID: 1, UserID: 2}
+	if err := r.CreateRedemption(ctx, first); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	note := "not this week"
+	if err := r.RejectRedemption(ctx, first, 1, &note); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	second := &pModel.RewardRedemption{RewardID: reward.ID, CircleID: 1, UserID: 2}
+	if err := r.CreateRedemption(ctx, second); err != nil {
+		t.Fatalf("expected points to be released after rejection, got %v", err)
+	}
+	if err := r.CancelRedemption(ctx, second); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pending, err := r.GetPendingCost(ctx, nil, 1, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pending != 0 {
+		t.Errorf("expected no pending cost, got %d", pending)
+	}
+}
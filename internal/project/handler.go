@@ -6,16 +6,19 @@ import (
 	"donetick.com/core/internal/auth"
 	pModel "donetick.com/core/internal/project/model"
 	pRepo "donetick.com/core/internal/project/repo"
+	"donetick.com/core/internal/realtime"
 	"github.com/gin-gonic/gin"
 )
 
 type Handler struct {
-	pRepo *pRepo.ProjectRepository
+	pRepo           *pRepo.ProjectRepository
+	realTimeService *realtime.RealTimeService
 }
 
-func NewHandler(pRepo *pRepo.ProjectRepository) *Handler {
+func NewHandler(pRepo *pRepo.ProjectRepository, rts *realtime.RealTimeService) *Handler {
 	return &Handler{
-		pRepo: pRepo,
+		pRepo:           pRepo,
+		realTimeService: rts,
 	}
 }
 
@@ -100,6 +103,10 @@ func (h *Handler) createProject(c *gin.Context) {
 		return
 	}
 
+	if h.realTimeService != nil {
+		h.realTimeService.GetEventBroadcaster().BroadcastProjectEvent(realtime.EventTypeProjectCreated, currentUser.CircleID, project.ID, project, &currentUser.User)
+	}
+
 	c.JSON(200, gin.H{
 		"res": project,
 	})
@@ -169,6 +176,10 @@ func (h *Handler) updateProject(c *gin.Context) {
 		return
 	}
 
+	if h.realTimeService != nil {
+		h.realTimeService.GetEventBroadcaster().BroadcastProjectEvent(realtime.EventTypeProjectUpdated, currentUser.CircleID, projectID, updatedProject, &currentUser.User)
+	}
+
 	c.JSON(200, gin.H{
 		"res": updatedProject,
 	})
@@ -213,6 +224,10 @@ func (h *Handler) deleteProject(c *gin.Context) {
 		return
 	}
 
+	if h.realTimeService != nil {
+		h.realTimeService.GetEventBroadcaster().BroadcastProjectEvent(realtime.EventTypeProjectDeleted, currentUser.CircleID, projectID, nil, &currentUser.User)
+	}
+
 	c.JSON(200, gin.H{
 		"res": "Project deleted successfully",
 	})
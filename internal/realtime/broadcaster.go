@@ -1,12 +1,15 @@
 package realtime
 
 import (
-	"crypto/rand"
-	"encoding/hex"
 	"time"
 
 	"donetick.com/core/config"
 	chModel "donetick.com/core/internal/chore/model"
+	fModel "donetick.com/core/internal/filter/model"
+	lModel "donetick.com/core/internal/label/model"
+	pModel "donetick.com/core/internal/points"
+	projModel "donetick.com/core/internal/project/model"
+	tModel "donetick.com/core/internal/thing/model"
 	uModel "donetick.com/core/internal/user/model"
 )
 
@@ -31,9 +34,7 @@ func (b *EventBroadcaster) BroadcastChoreCreated(chore *chModel.Chore, user *uMo
 	}
 
 	event := NewChoreCreatedEvent(chore, user)
-	event.ID = b.generateEventID()
-
-	b.service.BroadcastToCircle(chore.CircleID, event)
+	b.publishChore(chore, event)
 }
 
 // BroadcastChoreUpdated broadcasts a chore update event
@@ -43,21 +44,17 @@ func (b *EventBroadcaster) BroadcastChoreUpdated(chore *chModel.Chore, user *uMo
 	}
 
 	event := NewChoreUpdatedEvent(chore, user, changes, note)
-	event.ID = b.generateEventID()
-
-	b.service.BroadcastToCircle(chore.CircleID, event)
+	b.publishChore(chore, event)
 }
 
 // BroadcastChoreDeleted broadcasts a chore deletion event
-func (b *EventBroadcaster) BroadcastChoreDeleted(choreID int, choreName string, circleID int, user *uModel.User) {
+func (b *EventBroadcaster) BroadcastChoreDeleted(chore *chModel.Chore, user *uModel.User) {
 	if !b.service.config.Enabled {
 		return
 	}
 
-	event := NewChoreDeletedEvent(choreID, choreName, circleID, user)
-	event.ID = b.generateEventID()
-
-	b.service.BroadcastToCircle(circleID, event)
+	event := NewChoreDeletedEvent(chore.ID, chore.Name, chore.CircleID, user)
+	b.publishChore(chore, event)
 }
 
 // BroadcastChoreCompleted broadcasts a chore completion event
@@ -67,9 +64,7 @@ func (b *EventBroadcaster) BroadcastChoreCompleted(chore *chModel.Chore, user *u
 	}
 
 	event := NewChoreCompletedEvent(chore, user, history, note)
-	event.ID = b.generateEventID()
-
-	b.service.BroadcastToCircle(chore.CircleID, event)
+	b.publishChore(chore, event)
 }
 
 // BroadcastChoreStarted broadcasts a chore start event
@@ -79,9 +74,7 @@ func (b *EventBroadcaster) BroadcastChoreStatus(chore *chModel.Chore, user *uMod
 	}
 
 	event := NewChoreStatusChangedEvent(chore, user, changes, nil)
-	event.ID = b.generateEventID()
-
-	b.service.BroadcastToCircle(chore.CircleID, event)
+	b.publishChore(chore, event)
 }
 
 // BroadcastChoreSkipped broadcasts a chore skip event
@@ -91,38 +84,188 @@ func (b *EventBroadcaster) BroadcastChoreSkipped(chore *chModel.Chore, user *uMo
 	}
 
 	event := NewChoreSkippedEvent(chore, user, history, note)
-	event.ID = b.generateEventID()
+	b.publishChore(chore, event)
+}
 
-	b.service.BroadcastToCircle(chore.CircleID, event)
+// BroadcastChoreMissed broadcasts a chore missed event
+func (b *EventBroadcaster) BroadcastChoreMissed(chore *chModel.Chore, history *chModel.ChoreHistory) {
+	if !b.service.config.Enabled {
+		return
+	}
+
+	event := NewChoreMissedEvent(chore, history)
+	b.publishChore(chore, event)
 }
 
 // BroadcastSubtaskUpdated broadcasts a subtask update event
-func (b *EventBroadcaster) BroadcastSubtaskUpdated(choreID, subtaskID int, completedAt *time.Time, user *uModel.User, circleID int) {
+func (b *EventBroadcaster) BroadcastSubtaskUpdated(chore *chModel.Chore, subtaskID int, completedAt *time.Time, user *uModel.User) {
 	if !b.service.config.Enabled {
 		return
 	}
 
-	event := NewSubtaskUpdatedEvent(choreID, subtaskID, completedAt, user, circleID)
-	event.ID = b.generateEventID()
-
-	b.service.BroadcastToCircle(circleID, event)
+	event := NewSubtaskUpdatedEvent(chore.ID, subtaskID, completedAt, user, chore.CircleID)
+	b.publishChore(chore, event)
 }
 
 // BroadcastSubtaskCompleted broadcasts a subtask completion event
-func (b *EventBroadcaster) BroadcastSubtaskCompleted(choreID, subtaskID int, completedAt *time.Time, user *uModel.User, circleID int) {
+func (b *EventBroadcaster) BroadcastSubtaskCompleted(chore *chModel.Chore, subtaskID int, completedAt *time.Time, user *uModel.User) {
 	if !b.service.config.Enabled {
 		return
 	}
 
-	event := NewSubtaskCompletedEvent(choreID, subtaskID, completedAt, user, circleID)
-	event.ID = b.generateEventID()
+	event := NewSubtaskCompletedEvent(chore.ID, subtaskID, completedAt, user, chore.CircleID)
+	b.publishChore(chore, event)
+}
+
+// BroadcastChoreChanged broadcasts a chore event for a specific kind of change, such as
+// EventTypeChoreArchived or EventTypeChoreDueDateChanged
+func (b *EventBroadcaster) BroadcastChoreChanged(eventType EventType, chore *chModel.Chore, user *uModel.User, changes map[string]interface{}, history *chModel.ChoreHistory, note *string) {
+	b.publishChore(chore, NewEvent(eventType, chore.CircleID, &ChoreEventData{
+		Chore:   chore,
+		User:    user,
+		Changes: changes,
+		History: history,
+		Note:    note,
+	}))
+}
+
+// BroadcastChoreNudged broadcasts that members were nudged about a chore
+func (b *EventBroadcaster) BroadcastChoreNudged(chore *chModel.Chore, userIDs []int, user *uModel.User) {
+	b.publishChore(chore, NewEvent(EventTypeChoreNudged, chore.CircleID, &ChoreNudgeData{
+		ChoreID:   chore.ID,
+		ChoreName: chore.Name,
+		UserIDs:   userIDs,
+		User:      user,
+	}))
+}
+
+// BroadcastChoreHistoryChanged broadcasts a chore history edit or deletion
+func (b *EventBroadcaster) BroadcastChoreHistoryChanged(eventType EventType, chore *chModel.Chore, historyID int, history *chModel.ChoreHistory, user *uModel.User) {
+	b.publishChore(chore, NewEvent(eventType, chore.CircleID, &ChoreHistoryEventData{
+		ChoreID:   chore.ID,
+		HistoryID: historyID,
+		History:   history,
+		User:      user,
+	}))
+}
+
+// BroadcastTimeSessionChanged broadcasts a time session edit or deletion
+func (b *EventBroadcaster) BroadcastTimeSessionChanged(eventType EventType, chore *chModel.Chore, sessionID int, session *chModel.TimeSession, user *uModel.User) {
+	b.publishChore(chore, NewEvent(eventType, chore.CircleID, &TimeSessionEventData{
+		ChoreID:   chore.ID,
+		SessionID: sessionID,
+		Session:   session,
+		User:      user,
+	}))
+}
+
+// BroadcastThingEvent broadcasts a thing event. thing is nil for deletions.
+func (b *EventBroadcaster) BroadcastThingEvent(eventType EventType, circleID, thingID int, thing *tModel.Thing, user *uModel.User) {
+	b.publish(circleID, NewEvent(eventType, circleID, &ThingEventData{
+		ThingID: thingID,
+		Thing:   thing,
+		User:    user,
+	}))
+}
+
+// BroadcastLabelEvent broadcasts a label event. label is nil for deletions.
+func (b *EventBroadcaster) BroadcastLabelEvent(eventType EventType, circleID, labelID int, label *lModel.Label, user *uModel.User) {
+	b.publish(circleID, NewEvent(eventType, circleID, &LabelEventData{
+		LabelID: labelID,
+		Label:   label,
+		User:    user,
+	}))
+}
+
+// BroadcastProjectEvent broadcasts a project event. project is nil for deletions.
+func (b *EventBroadcaster) BroadcastProjectEvent(eventType EventType, circleID, projectID int, project *projModel.Project, user *uModel.User) {
+	b.publish(circleID, NewEvent(eventType, circleID, &ProjectEventData{
+		ProjectID: projectID,
+		Project:   project,
+		User:      user,
+	}))
+}
+
+// BroadcastFilterEvent broadcasts a filter event. filter is nil for deletions.
+func (b *EventBroadcaster) BroadcastFilterEvent(eventType EventType, circleID, filterID int, filter *fModel.Filter, user *uModel.User) {
+	b.publish(circleID, NewEvent(eventType, circleID, &FilterEventData{
+		FilterID: filterID,
+		Filter:   filter,
+		User:     user,
+	}))
+}
+
+// BroadcastCircleMemberEvent broadcasts a membership change of memberUserID
+func (b *EventBroadcaster) BroadcastCircleMemberEvent(eventType EventType, circleID, memberUserID int, role string, user *uModel.User) {
+	b.publish(circleID, NewEvent(eventType, circleID, &CircleMemberEventData{
+		MemberUserID: memberUserID,
+		Role:         role,
+		User:         user,
+	}))
+}
+
+// BroadcastPointsRedeemed broadcasts that an admin redeemed points on behalf of a member
+func (b *EventBroadcaster) BroadcastPointsRedeemed(circleID, memberUserID, points int, user *uModel.User) {
+	b.publish(circleID, NewEvent(EventTypeCirclePointsRedeem, circleID, &CircleMemberEventData{
+		MemberUserID: memberUserID,
+		Points:       points,
+		User:         user,
+	}))
+}
+
+// BroadcastRewardEvent broadcasts a reward catalog event. reward is nil for deletions.
+func (b *EventBroadcaster) BroadcastRewardEvent(eventType EventType, circleID, rewardID int, reward *pModel.Reward, user *uModel.User) {
+	b.publish(circleID, NewEvent(eventType, circleID, &RewardEventData{
+		RewardID: rewardID,
+		Reward:   reward,
+		User:     user,
+	}))
+}
+
+// BroadcastRedemptionEvent broadcasts a change of a reward redemption request
+func (b *EventBroadcaster) BroadcastRedemptionEvent(eventType EventType, redemption *pModel.RewardRedemption, user *uModel.User) {
+	b.publish(redemption.CircleID, NewEvent(eventType, redemption.CircleID, &RedemptionEventData{
+		Redemption: redemption,
+		User:       user,
+	}))
+}
+
+// publish broadcasts the event to the circle
+func (b *EventBroadcaster) publish(circleID int, event *Event) {
+	if !b.service.config.Enabled {
+		return
+	}
 
 	b.service.BroadcastToCircle(circleID, event)
 }
 
-// generateEventID generates a unique event ID
-func (b *EventBroadcaster) generateEventID() string {
-	bytes := make([]byte, 8)
-	rand.Read(bytes)
-	return hex.EncodeToString(bytes)
+// publishChore broadcasts an event about the chore. Events about private chores are restricted
+// to the members Chore.CanView allows, the rest of the circle gets a tombstone.
+func (b *EventBroadcaster) publishChore(chore *chModel.Chore, event *Event) {
+	event.Audience = choreAudience(chore)
+	b.publish(chore.CircleID, event)
+}
+
+// choreAudience returns the members who can view a private chore, or nil for chores the whole
+// circle can view
+func choreAudience(chore *chModel.Chore) *Audience {
+	if !chore.IsPrivate {
+		return nil
+	}
+
+	candidates := []int{chore.CreatedBy}
+	for _, assignee := range chore.Assignees {
+		candidates = append(candidates, assignee.UserID)
+	}
+	if chore.AssignedTo != nil {
+		candidates = append(candidates, *chore.AssignedTo)
+	}
+
+	audience := &Audience{ChoreID: chore.ID, UserIDs: []int{}}
+	for _, userID := range candidates {
+		if chore.CanView(userID, nil) && !audience.Includes(userID) {
+			audience.UserIDs = append(audience.UserIDs, userID)
+		}
+	}
+	return audience
 }
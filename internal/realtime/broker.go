@@ -0,0 +1,80 @@
+package realtime
+
+import (
+	"context"
+	"sync"
+
+	"donetick.com/core/config"
+	"donetick.com/core/internal/database"
+	"donetick.com/core/logging"
+	"gorm.io/gorm"
+)
+
+const (
+	BrokerTypeMemory   = "memory"
+	BrokerTypePostgres = "postgres"
+)
+
+// DeliverFunc hands an event to the connections held by this instance
+type DeliverFunc func(circleID int, event *Event)
+
+// Broker fans events out to every instance of the service. Each instance passes the events it
+// receives to its own connection pools, so clients get them whichever replica they are connected to.
+type Broker interface {
+	// Start begins passing events published by any instance to deliver
+	Start(ctx context.Context, deliver DeliverFunc) error
+	Publish(ctx context.Context, circleID int, event *Event) error
+	Stop() error
+}
+
+// NewBroker creates the broker selected by realtime.broker. The Postgres broker needs a Postgres
+// database, otherwise the in-process broker is used.
+func NewBroker(cfg *config.Config, db *gorm.DB) Broker {
+	switch cfg.RealTimeConfig.Broker {
+	case BrokerTypePostgres:
+		if cfg.Database.Type != "postgres" {
+			logging.DefaultLogger().Warnw("Postgres realtime broker requires a postgres database, falling back to the in-process broker",
+				"database_type", cfg.Database.Type)
+			return NewInProcessBroker()
+		}
+		return NewPostgresBroker(db, database.PostgresDSN(cfg))
+	default:
+		return NewInProcessBroker()
+	}
+}
+
+// InProcessBroker delivers events to the local instance only. It is enough when a single
+// instance serves all connections.
+type InProcessBroker struct {
+	mu      sync.RWMutex
+	deliver DeliverFunc
+}
+
+func NewInProcessBroker() *InProcessBroker {
+	return &InProcessBroker{}
+}
+
+func (b *InProcessBroker) Start(ctx context.Context, deliver DeliverFunc) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.deliver = deliver
+	return nil
+}
+
+func (b *InProcessBroker) Publish(ctx context.Context, circleID int, event *Event) error {
+	b.mu.RLock()
+	deliver := b.deliver
+	b.mu.RUnlock()
+
+	if deliver != nil {
+		deliver(circleID, event)
+	}
+	return nil
+}
+
+func (b *InProcessBroker) Stop() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.deliver = nil
+	return nil
+}
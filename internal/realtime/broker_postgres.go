@@ -0,0 +1,216 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	rtModel "donetick.com/core/internal/realtime/model"
+	"donetick.com/core/logging"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"gorm.io/gorm"
+)
+
+const (
+	brokerChannel = "donetick_realtime"
+	// Postgres rejects NOTIFY payloads of 8000 bytes or more
+	maxNotifyPayload        = 7900
+	brokerReconnectDelay    = time.Second
+	brokerMaxReconnectDelay = 30 * time.Second
+	brokerMessageRetention  = 10 * time.Minute
+	brokerCleanupInterval   = 5 * time.Minute
+)
+
+// brokerEnvelope is the NOTIFY payload. Events too large for a notification are stored as a
+// BrokerMessage and only MessageID is sent.
+type brokerEnvelope struct {
+	Origin    string          `json:"o"`
+	CircleID  int             `json:"c"`
+	Event     json.RawMessage `json:"e,omitempty"`
+	MessageID int             `json:"m,omitempty"`
+	Audience  *Audience       `json:"a,omitempty"`
+}
+
+// PostgresBroker fans events out to every instance through Postgres LISTEN/NOTIFY. Events are
+// delivered to the publishing instance directly, and the other instances pick them up from the
+// notification. Events published while an instance is reconnecting its listener are lost to it.
+type PostgresBroker struct {
+	db         *gorm.DB
+	dsn        string
+	instanceID string
+	deliver    DeliverFunc
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+}
+
+func NewPostgresBroker(db *gorm.DB, dsn string) *PostgresBroker {
+	return &PostgresBroker{
+		db:         db,
+		dsn:        dsn,
+		instanceID: uuid.NewString(),
+	}
+}
+
+func (b *PostgresBroker) Start(ctx context.Context, deliver DeliverFunc) error {
+	b.deliver = deliver
+	ctx, b.cancel = context.WithCancel(ctx)
+
+	b.wg.Add(2)
+	go b.listen(ctx)
+	go b.cleanup(ctx)
+
+	logging.FromContext(ctx).Infow("Postgres realtime broker started", "instance_id", b.instanceID)
+	return nil
+}
+
+func (b *PostgresBroker) Stop() error {
+	if b.cancel != nil {
+		b.cancel()
+	}
+	b.wg.Wait()
+	return nil
+}
+
+func (b *PostgresBroker) Publish(ctx context.Context, circleID int, event *Event) error {
+	if b.deliver != nil {
+		b.deliver(circleID, event)
+	}
+
+	payload, err := b.encode(ctx, circleID, event)
+	if err != nil {
+		return err
+	}
+	return b.db.WithContext(ctx).Exec("SELECT pg_notify(?, ?)", brokerChannel, payload).Error
+}
+
+// encode builds the notification payload, storing the event in the database when it doesn't fit.
+func (b *PostgresBroker) encode(ctx context.Context, circleID int, event *Event) (string, error) {
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	envelope := brokerEnvelope{Origin: b.instanceID, CircleID: circleID, Event: eventJSON, Audience: event.Audience}
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		return "", err
+	}
+	if len(payload) < maxNotifyPayload {
+		return string(payload), nil
+	}
+
+	message := &rtModel.BrokerMessage{Payload: string(eventJSON), CreatedAt: time.Now().UTC()}
+	if err := b.db.WithContext(ctx).Create(message).Error; err != nil {
+		return "", fmt.Errorf("failed to store large event: %w", err)
+	}
+	payload, err = json.Marshal(brokerEnvelope{Origin: b.instanceID, CircleID: circleID, MessageID: message.ID, Audience: event.Audience})
+	if err != nil {
+		return "", err
+	}
+	return string(payload), nil
+}
+
+// decode parses a notification payload. It returns a nil event for notifications sent by this instance.
+func (b *PostgresBroker) decode(ctx context.Context, payload string) (int, *Event, error) {
+	var envelope brokerEnvelope
+	if err := json.Unmarshal([]byte(payload), &envelope); err != nil {
+		return 0, nil, fmt.Errorf("failed to unmarshal notification: %w", err)
+	}
+	if envelope.Origin == b.instanceID {
+		return envelope.CircleID, nil, nil
+	}
+
+	eventJSON := []byte(envelope.Event)
+	if envelope.MessageID != 0 {
+		var message rtModel.BrokerMessage
+		if err := b.db.WithContext(ctx).First(&message, envelope.MessageID).Error; err != nil {
+			return 0, nil, fmt.Errorf("failed to load large event %d: %w", envelope.MessageID, err)
+		}
+		eventJSON = []byte(message.Payload)
+	}
+
+	// keep the data as raw JSON so it is sent to clients exactly as the publisher encoded it
+	data := json.RawMessage{}
+	event := &Event{Data: &data}
+	if err := json.Unmarshal(eventJSON, event); err != nil {
+		return 0, nil, fmt.Errorf("failed to unmarshal event: %w", err)
+	}
+	event.Audience = envelope.Audience
+	return envelope.CircleID, event, nil
+}
+
+// listen keeps a dedicated connection listening on the broker channel, reconnecting with backoff.
+func (b *PostgresBroker) listen(ctx context.Context) {
+	defer b.wg.Done()
+	logger := logging.FromContext(ctx)
+
+	delay := brokerReconnectDelay
+	for {
+		err := b.listenOnce(ctx, func() { delay = brokerReconnectDelay })
+		if ctx.Err() != nil {
+			return
+		}
+		logger.Warnw("Realtime broker listener disconnected, reconnecting", "error", err, "delay", delay)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if delay > brokerMaxReconnectDelay {
+			delay = brokerMaxReconnectDelay
+		}
+	}
+}
+
+func (b *PostgresBroker) listenOnce(ctx context.Context, connected func()) error {
+	conn, err := pgx.Connect(ctx, b.dsn)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+brokerChannel); err != nil {
+		return err
+	}
+	connected()
+
+	logger := logging.FromContext(ctx)
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		circleID, event, err := b.decode(ctx, notification.Payload)
+		if err != nil {
+			logger.Errorw("Failed to decode realtime notification", "error", err)
+			continue
+		}
+		if event != nil && b.deliver != nil {
+			b.deliver(circleID, event)
+		}
+	}
+}
+
+// cleanup removes stored large events once every instance has had the chance to load them.
+func (b *PostgresBroker) cleanup(ctx context.Context) {
+	defer b.wg.Done()
+	ticker := time.NewTicker(brokerCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			cutoff := time.Now().UTC().Add(-brokerMessageRetention)
+			if err := b.db.WithContext(ctx).Where("created_at < ?", cutoff).Delete(&rtModel.BrokerMessage{}).Error; err != nil {
+				logging.FromContext(ctx).Warnw("Failed to clean up realtime broker messages", "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+
+	"donetick.com/core/config"
+	chModel "donetick.com/core/internal/chore/model"
+	"donetick.com/core/internal/database"
+	uModel "donetick.com/core/internal/user/model"
+	"github.com/glebarez/sqlite"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func TestInProcessBrokerDeliversLocally(t *testing.T) {
+	broker := NewInProcessBroker()
+	var got []int
+	if err := broker.Start(context.Background(), func(circleID int, event *Event) {
+		got = append(got, circleID)
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := broker.Publish(context.Background(), 7, &Event{Type: EventTypeChoreUpdated}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 || got[0] != 7 {
+		t.Fatalf("expected one delivery for circle 7, got %v", got)
+	}
+
+	broker.Stop()
+	broker.Publish(context.Background(), 7, &Event{Type: EventTypeChoreUpdated})
+	if len(got) != 1 {
+		t.Errorf("expected no delivery after stop, got %v", got)
+	}
+}
+
+func TestPostgresBrokerEnvelope(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect database: %v", err)
+	}
+	if err := database.Migration(db); err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
+	}
+	publisher := NewPostgresBroker(db, "")
+	subscriber := NewPostgresBroker(db, "")
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		choreName  string
+		wantStored bool
+	}{
+		{name: "inline", choreName: "Dishes"},
+		{name: "stored when too large", choreName: strings.Repeat("x", maxNotifyPayload), wantStored: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := NewChoreCreatedEvent(&chModel.Chore{ID: 3, Name: tt.choreName, CircleID: 5}, nil)
+			event.ID = "evt-1"
+			event.Audience = &Audience{ChoreID: 3, UserIDs: []int{1}}
+
+			payload, err := publisher.encode(ctx, 5, event)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(payload) >= maxNotifyPayload {
+				t.Fatalf("payload of %d bytes exceeds the notify limit", len(payload))
+			}
+			if stored := strings.Contains(payload, `"m":`); stored != tt.wantStored {
+				t.Errorf("expected stored=%v, payload %s", tt.wantStored, payload)
+			}
+
+			// the publisher ignores its own notifications, it already delivered the event
+			if _, own, err := publisher.decode(ctx, payload); err != nil || own != nil {
+				t.Errorf("expected own notification to be skipped, got %v, %v", own, err)
+			}
+
+			circleID, received, err := subscriber.decode(ctx, payload)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if circleID != 5 || received.ID != "evt-1" || received.Type != EventTypeChoreCreated {
+				t.Errorf("unexpected event %+v for circle %d", received, circleID)
+			}
+			if received.Audience == nil || !received.Audience.Includes(1) || received.Audience.Includes(2) {
+				t.Errorf("expected the audience to be kept, got %+v", received.Audience)
+			}
+			want, _ := json.Marshal(event.Data)
+			got, _ := json.Marshal(received.Data)
+			if string(got) != string(want) {
+				t.Errorf("expected data %s, got %s", want, got)
+			}
+		})
+	}
+}
+
+type recordingBroker struct {
+	events []*Event
+}
+
+func (b *recordingBroker) Start(ctx context.Context, deliver DeliverFunc) error { return nil }
+func (b *recordingBroker) Stop() error                                          { return nil }
+func (b *recordingBroker) Publish(ctx context.Context, circleID int, event *Event) error {
+	b.events = append(b.events, event)
+	return nil
+}
+
+func TestBroadcasterPublishesTypedEvents(t *testing.T) {
+	cfg := config.NewConfig()
+	broker := &recordingBroker{}
+	service := NewRealTimeService(cfg, broker, NewReplayLog(cfg, nil))
+	if err := service.Start(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer service.Stop()
+
+	b := service.GetEventBroadcaster()
+	chore := &chModel.Chore{ID: 1, Name: "Dishes", CircleID: 2}
+	b.BroadcastChoreChanged(EventTypeChoreArchived, chore, nil, nil, nil, nil)
+	b.BroadcastLabelEvent(EventTypeLabelDeleted, 2, 9, nil, nil)
+	b.BroadcastCircleMemberEvent(EventTypeCircleMemberJoined, 2, 5, "member", nil)
+
+	want := []EventType{EventTypeChoreArchived, EventTypeLabelDeleted, EventTypeCircleMemberJoined}
+	if len(broker.events) != len(want) {
+		t.Fatalf("expected %d events, got %d", len(want), len(broker.events))
+	}
+	for i, event := range broker.events {
+		if event.Type != want[i] || event.CircleID != 2 || event.ID == "" {
+			t.Errorf("event %d: expected %s for circle 2 with an ID, got %+v", i, want[i], event)
+		}
+	}
+}
+
+func TestPrivateChoreEventsAreRedacted(t *testing.T) {
+	cfg := config.NewConfig()
+	service := NewRealTimeService(cfg, NewInProcessBroker(), NewReplayLog(cfg, nil))
+	if err := service.Start(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer service.Stop()
+
+	logger := zap.NewNop().Sugar()
+	creator := NewConnection("creator", 2, 1, &uModel.User{ID: 1}, nil, logger)
+	assignee := NewConnection("assignee", 2, 3, &uModel.User{ID: 3}, nil, logger)
+	other := NewConnection("other", 2, 4, &uModel.User{ID: 4}, nil, logger)
+	for _, conn := range []*Connection{creator, assignee, other} {
+		if err := service.AddConnection(conn); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	chore := &chModel.Chore{ID: 9, Name: "Secret", CircleID: 2, CreatedBy: 1, IsPrivate: true,
+		Assignees: []chModel.ChoreAssignees{{UserID: 3}}}
+	service.GetEventBroadcaster().BroadcastChoreUpdated(chore, nil, nil, nil)
+	chore.IsPrivate = false
+	service.GetEventBroadcaster().BroadcastChoreUpdated(chore, nil, nil, nil)
+
+	tests := []struct {
+		name      string
+		conn      *Connection
+		wantTypes []EventType
+	}{
+		{name: "creator", conn: creator, wantTypes: []EventType{EventTypeChoreUpdated, EventTypeChoreUpdated}},
+		{name: "assignee", conn: assignee, wantTypes: []EventType{EventTypeChoreUpdated, EventTypeChoreUpdated}},
+		{name: "other member", conn: other, wantTypes: []EventType{EventTypeChoreHidden, EventTypeChoreUpdated}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if len(tt.conn.Send) != len(tt.wantTypes) {
+				t.Fatalf("expected %d events, got %d", len(tt.wantTypes), len(tt.conn.Send))
+			}
+			for i, want := range tt.wantTypes {
+				event := <-tt.conn.Send
+				if event.Type != want {
+					t.Errorf("event %d: expected %s, got %s", i, want, event.Type)
+				}
+				if data, ok := event.Data.(*ChoreHiddenData); ok && data.ChoreID != 9 {
+					t.Errorf("expected tombstone for chore 9, got %d", data.ChoreID)
+				}
+			}
+		})
+	}
+
+	// replay applies the same visibility as live delivery
+	events, complete := service.EventsSince(context.Background(), 2, 4, strconv.FormatInt(service.replay.startSeq, 10))
+	if !complete || len(events) != 2 || events[0].Type != EventTypeChoreHidden {
+		t.Errorf("expected the replayed private event to be redacted, got complete=%v %+v", complete, events)
+	}
+}
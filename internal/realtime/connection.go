@@ -23,6 +23,9 @@ type Connection struct {
 	mu           sync.RWMutex
 	closed       bool
 	logger       *zap.SugaredLogger
+	// replayedThrough is the last event ID replayed on connect. Live events up to it were
+	// already sent and are skipped.
+	replayedThrough int64
 }
 
 // NewConnection creates a new WebSocket or SSE connection
@@ -171,6 +174,10 @@ func (c *Connection) StartWritePump() {
 				return
 			}
 
+			if c.alreadyReplayed(event) {
+				continue
+			}
+
 			// Send the event as JSON
 			eventJSON, err := event.ToJSON()
 			if err != nil {
@@ -193,6 +200,15 @@ func (c *Connection) StartWritePump() {
 	}
 }
 
+// alreadyReplayed reports whether the event was sent as part of the replay on connect
+func (c *Connection) alreadyReplayed(event *Event) bool {
+	if c.replayedThrough == 0 {
+		return false
+	}
+	seq, ok := ParseEventID(event.ID)
+	return ok && seq <= c.replayedThrough
+}
+
 // handleIncomingMessage processes incoming WebSocket messages
 func (c *Connection) handleIncomingMessage(message []byte) {
 	// For now, we mainly handle ping/pong for keepalive
@@ -5,6 +5,11 @@ import (
 	"time"
 
 	chModel "donetick.com/core/internal/chore/model"
+	fModel "donetick.com/core/internal/filter/model"
+	lModel "donetick.com/core/internal/label/model"
+	pModel "donetick.com/core/internal/points"
+	projModel "donetick.com/core/internal/project/model"
+	tModel "donetick.com/core/internal/thing/model"
 	uModel "donetick.com/core/internal/user/model"
 )
 
@@ -22,15 +27,68 @@ const (
 	EventTypeChoreStatus          EventType = "chore.status"
 	EventTypeChoreDueDateChanged  EventType = "chore.due_date_changed"
 	EventTypeChoreArchived        EventType = "chore.archived"
+	EventTypeChoreMissed          EventType = "chore.missed"
+	EventTypeChoreUnarchived      EventType = "chore.unarchived"
+	EventTypeChoreApproved        EventType = "chore.approved"
+	EventTypeChoreRejected        EventType = "chore.rejected"
+	EventTypeChoreUndone          EventType = "chore.undone"
+	EventTypeChoreNudged          EventType = "chore.nudged"
+	EventTypeChorePriority        EventType = "chore.priority_changed"
+	// EventTypeChoreHidden replaces events about a private chore for members who can't view it,
+	// so clients drop the chore if they still show it
+	EventTypeChoreHidden EventType = "chore.hidden"
+
+	// Chore history and time tracking events
+	EventTypeChoreHistoryUpdated EventType = "chore.history_updated"
+	EventTypeChoreHistoryDeleted EventType = "chore.history_deleted"
+	EventTypeTimeSessionUpdated  EventType = "chore.time_session_updated"
+	EventTypeTimeSessionDeleted  EventType = "chore.time_session_deleted"
 
 	// Subtask events
 	EventTypeSubtaskUpdated   EventType = "subtask.updated"
 	EventTypeSubtaskCompleted EventType = "subtask.completed"
 
+	// Thing events
+	EventTypeThingCreated      EventType = "thing.created"
+	EventTypeThingUpdated      EventType = "thing.updated"
+	EventTypeThingStateChanged EventType = "thing.state_changed"
+	EventTypeThingDeleted      EventType = "thing.deleted"
+
+	// Label, project and filter events
+	EventTypeLabelCreated   EventType = "label.created"
+	EventTypeLabelUpdated   EventType = "label.updated"
+	EventTypeLabelDeleted   EventType = "label.deleted"
+	EventTypeProjectCreated EventType = "project.created"
+	EventTypeProjectUpdated EventType = "project.updated"
+	EventTypeProjectDeleted EventType = "project.deleted"
+	EventTypeFilterCreated  EventType = "filter.created"
+	EventTypeFilterUpdated  EventType = "filter.updated"
+	EventTypeFilterDeleted  EventType = "filter.deleted"
+
+	// Circle membership events
+	EventTypeCircleJoinRequested EventType = "circle.join_requested"
+	EventTypeCircleMemberJoined  EventType = "circle.member_joined"
+	EventTypeCircleMemberLeft    EventType = "circle.member_left"
+	EventTypeCircleMemberRemoved EventType = "circle.member_removed"
+	EventTypeCircleMemberRole    EventType = "circle.member_role_changed"
+	EventTypeCirclePointsRedeem  EventType = "circle.points_redeemed"
+
+	// Reward events
+	EventTypeRewardCreated       EventType = "reward.created"
+	EventTypeRewardUpdated       EventType = "reward.updated"
+	EventTypeRewardDeleted       EventType = "reward.deleted"
+	EventTypeRedemptionRequested EventType = "reward.redemption_requested"
+	EventTypeRedemptionApproved  EventType = "reward.redemption_approved"
+	EventTypeRedemptionRejected  EventType = "reward.redemption_rejected"
+	EventTypeRedemptionCancelled EventType = "reward.redemption_cancelled"
+
 	// System events
 	EventTypeConnectionEstablished EventType = "connection.established"
 	EventTypeHeartbeat             EventType = "heartbeat"
 	EventTypeError                 EventType = "error"
+	// EventTypeResync tells a reconnecting client that events it missed are no longer available
+	// and it should refetch its data
+	EventTypeResync EventType = "resync"
 )
 
 // Event represents a real-time event to be sent to clients
@@ -40,6 +98,40 @@ type Event struct {
 	CircleID  int         `json:"circleId"`
 	Data      interface{} `json:"data"`
 	ID        string      `json:"id,omitempty"`
+	// Audience is set for events about private chores. It is never sent to clients.
+	Audience *Audience `json:"-"`
+}
+
+// Audience restricts an event about a private chore to the members who can view the chore.
+// Other members of the circle get a chore.hidden tombstone with the same event ID instead.
+type Audience struct {
+	ChoreID int   `json:"choreId"`
+	UserIDs []int `json:"userIds"`
+}
+
+// Includes reports whether the user may receive the full event
+func (a *Audience) Includes(userID int) bool {
+	for _, id := range a.UserIDs {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// ForUser returns the event as the user should receive it: the event itself, or a tombstone
+// when it is about a private chore the user can't view
+func (e *Event) ForUser(userID int) *Event {
+	if e.Audience == nil || e.Audience.Includes(userID) {
+		return e
+	}
+	return &Event{
+		Type:      EventTypeChoreHidden,
+		Timestamp: e.Timestamp,
+		CircleID:  e.CircleID,
+		ID:        e.ID,
+		Data:      &ChoreHiddenData{ChoreID: e.Audience.ChoreID},
+	}
 }
 
 // ChoreEventData contains data for chore-related events
@@ -73,6 +165,84 @@ type SubtaskEventData struct {
 	User        *uModel.User `json:"user"`
 }
 
+// ChoreHistoryEventData contains data for chore history edits and deletions
+type ChoreHistoryEventData struct {
+	ChoreID   int                   `json:"choreId"`
+	HistoryID int                   `json:"historyId"`
+	History   *chModel.ChoreHistory `json:"history,omitempty"`
+	User      *uModel.User          `json:"user"`
+}
+
+// TimeSessionEventData contains data for time session edits and deletions
+type TimeSessionEventData struct {
+	ChoreID   int                  `json:"choreId"`
+	SessionID int                  `json:"sessionId"`
+	Session   *chModel.TimeSession `json:"session,omitempty"`
+	User      *uModel.User         `json:"user"`
+}
+
+// ChoreNudgeData contains data for nudge events
+type ChoreNudgeData struct {
+	ChoreID   int          `json:"choreId"`
+	ChoreName string       `json:"choreName"`
+	UserIDs   []int        `json:"userIds"`
+	User      *uModel.User `json:"user"`
+}
+
+// ThingEventData contains data for thing events. Thing is omitted for deletions.
+type ThingEventData struct {
+	ThingID int           `json:"thingId"`
+	Thing   *tModel.Thing `json:"thing,omitempty"`
+	User    *uModel.User  `json:"user"`
+}
+
+// LabelEventData contains data for label events. Label is omitted for deletions.
+type LabelEventData struct {
+	LabelID int           `json:"labelId"`
+	Label   *lModel.Label `json:"label,omitempty"`
+	User    *uModel.User  `json:"user"`
+}
+
+// ProjectEventData contains data for project events. Project is omitted for deletions.
+type ProjectEventData struct {
+	ProjectID int                `json:"projectId"`
+	Project   *projModel.Project `json:"project,omitempty"`
+	User      *uModel.User       `json:"user"`
+}
+
+// FilterEventData contains data for filter events. Filter is omitted for deletions.
+type FilterEventData struct {
+	FilterID int            `json:"filterId"`
+	Filter   *fModel.Filter `json:"filter,omitempty"`
+	User     *uModel.User   `json:"user"`
+}
+
+// CircleMemberEventData contains data for circle membership events
+type CircleMemberEventData struct {
+	MemberUserID int          `json:"memberUserId"`
+	Role         string       `json:"role,omitempty"`
+	Points       int          `json:"points,omitempty"`
+	User         *uModel.User `json:"user"`
+}
+
+// RewardEventData contains data for reward catalog events. Reward is omitted for deletions.
+type RewardEventData struct {
+	RewardID int            `json:"rewardId"`
+	Reward   *pModel.Reward `json:"reward,omitempty"`
+	User     *uModel.User   `json:"user"`
+}
+
+// RedemptionEventData contains data for reward redemption events
+type RedemptionEventData struct {
+	Redemption *pModel.RewardRedemption `json:"redemption"`
+	User       *uModel.User             `json:"user"`
+}
+
+// ChoreHiddenData identifies a chore the recipient can't view
+type ChoreHiddenData struct {
+	ChoreID int `json:"choreId"`
+}
+
 // ConnectionEstablishedData contains data sent when connection is established
 type ConnectionEstablishedData struct {
 	ConnectionID string    `json:"connectionId"`
@@ -87,6 +257,11 @@ type HeartbeatData struct {
 	ServerID  string    `json:"serverId,omitempty"`
 }
 
+// ResyncData contains the last event ID the client reported
+type ResyncData struct {
+	LastEventID string `json:"lastEventId"`
+}
+
 // ErrorData contains error information
 type ErrorData struct {
 	Code    string `json:"code"`
@@ -165,6 +340,14 @@ func NewChoreSkippedEvent(chore *chModel.Chore, user *uModel.User, history *chMo
 	})
 }
 
+// NewChoreMissedEvent creates a chore missed event, raised when a chore passes its deadline
+func NewChoreMissedEvent(chore *chModel.Chore, history *chModel.ChoreHistory) *Event {
+	return NewEvent(EventTypeChoreMissed, chore.CircleID, &ChoreEventData{
+		Chore:   chore,
+		History: history,
+	})
+}
+
 // NewSubtaskUpdatedEvent creates a subtask update event
 func NewSubtaskUpdatedEvent(choreID, subtaskID int, completedAt *time.Time, user *uModel.User, circleID int) *Event {
 	return NewEvent(EventTypeSubtaskUpdated, circleID, &SubtaskEventData{
@@ -202,6 +385,13 @@ func NewHeartbeatEvent(circleID int) *Event {
 	})
 }
 
+// NewResyncEvent creates an event telling the client to refetch its data
+func NewResyncEvent(circleID int, lastEventID string) *Event {
+	return NewEvent(EventTypeResync, circleID, &ResyncData{
+		LastEventID: lastEventID,
+	})
+}
+
 // NewErrorEvent creates an error event
 func NewErrorEvent(circleID int, code, message string) *Event {
 	return NewEvent(EventTypeError, circleID, &ErrorData{
@@ -104,9 +104,23 @@ func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
 		"username", user.Username,
 		"circleId", circleID)
 
-	// Send connection established event
+	// Send connection established event and any events missed since the client's last event.
+	// The write pump isn't running yet, so these are written directly and go out before the
+	// live events already queued for the connection.
 	establishedEvent := NewConnectionEstablishedEvent(connectionID, circleID, user.ID)
-	wsConn.SendEvent(establishedEvent)
+	if err := conn.WriteJSON(establishedEvent); err != nil {
+		h.realTimeService.RemoveConnection(wsConn)
+		wsConn.Close()
+		return
+	}
+	if lastEventID := lastEventIDFromRequest(c); lastEventID != "" {
+		if err := h.replayMissedEvents(c, wsConn, lastEventID); err != nil {
+			h.logger.Warnw("Failed to replay missed WebSocket events", "error", err, "connectionId", connectionID)
+			h.realTimeService.RemoveConnection(wsConn)
+			wsConn.Close()
+			return
+		}
+	}
 
 	// Get connection pool for this circle
 	pool := h.realTimeService.GetConnectionPool(circleID)
@@ -116,6 +130,23 @@ func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
 	go wsConn.StartReadPump(pool) // This will block until connection closes
 }
 
+// replayMissedEvents writes the events published after lastEventID, or a resync event when they
+// are no longer available
+func (h *WebSocketHandler) replayMissedEvents(c *gin.Context, conn *Connection, lastEventID string) error {
+	events, complete := h.realTimeService.EventsSince(c.Request.Context(), conn.CircleID, conn.UserID, lastEventID)
+	if !complete {
+		return conn.Conn.WriteJSON(NewResyncEvent(conn.CircleID, lastEventID))
+	}
+
+	for _, event := range events {
+		if err := conn.Conn.WriteJSON(event); err != nil {
+			return err
+		}
+		conn.replayedThrough, _ = ParseEventID(event.ID)
+	}
+	return nil
+}
+
 // generateConnectionID generates a unique connection identifier
 func (h *WebSocketHandler) generateConnectionID() string {
 	bytes := make([]byte, 16)
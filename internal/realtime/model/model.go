@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// BrokerMessage holds an event that is too large for a Postgres NOTIFY payload. The
+// notification only carries its ID and each instance loads the event from here.
+type BrokerMessage struct {
+	ID        int       `json:"id" gorm:"primary_key"`
+	Payload   string    `json:"payload" gorm:"column:payload;type:text;not null"`
+	CreatedAt time.Time `json:"createdAt" gorm:"column:created_at;index"`
+}
+
+// ReplayEvent is a published event kept so clients reconnecting to any instance can replay what
+// they missed. Its ID is the event ID sent to clients.
+type ReplayEvent struct {
+	ID       int64  `json:"id" gorm:"primary_key"`
+	CircleID int    `json:"circleId" gorm:"column:circle_id;index"`
+	Payload  string `json:"payload" gorm:"column:payload;type:text;not null"`
+	// Audience is the JSON encoded audience of events about private chores
+	Audience  *string   `json:"audience,omitempty" gorm:"column:audience;type:text"`
+	CreatedAt time.Time `json:"createdAt" gorm:"column:created_at;index"`
+}
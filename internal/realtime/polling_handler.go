@@ -165,6 +165,17 @@ func (h *PollingHandler) HandleSSE(c *gin.Context) {
 
 	h.logger.Infow("SSE initial event sent successfully", "connectionId", connectionID)
 
+	// Replay what the client missed while it was disconnected before streaming live events.
+	// The connection is already in the pool, so events published meanwhile are queued and
+	// the ones included in the replay are skipped by the event loop.
+	if lastEventID := lastEventIDFromRequest(c); lastEventID != "" {
+		if !h.replayMissedEvents(c, sseConn, lastEventID) {
+			h.logger.Warnw("Failed to replay missed SSE events", "connectionId", connectionID)
+			h.realTimeService.RemoveConnection(sseConn)
+			return
+		}
+	}
+
 	// Create context for managing the connection lifecycle
 	// Important: Create a background context to avoid HTTP request timeout issues
 	ctx, cancel := context.WithCancel(context.Background())
@@ -219,6 +230,28 @@ func (h *PollingHandler) setSSEHeaders(c *gin.Context) {
 	}
 }
 
+// replayMissedEvents sends the events published after lastEventID, or a resync event when they
+// are no longer available
+func (h *PollingHandler) replayMissedEvents(c *gin.Context, conn *Connection, lastEventID string) bool {
+	events, complete := h.realTimeService.EventsSince(c.Request.Context(), conn.CircleID, conn.UserID, lastEventID)
+	if !complete {
+		h.logger.Infow("Missed SSE events are no longer available, asking client to resync",
+			"connectionId", conn.ID,
+			"lastEventId", lastEventID)
+		return h.sendSSEEvent(c, NewResyncEvent(conn.CircleID, lastEventID))
+	}
+
+	for _, event := range events {
+		if !h.sendSSEEvent(c, event) {
+			return false
+		}
+		conn.replayedThrough, _ = ParseEventID(event.ID)
+	}
+
+	h.logger.Debugw("Replayed missed SSE events", "connectionId", conn.ID, "count", len(events))
+	return true
+}
+
 // sendSSEEvent sends an event over SSE
 func (h *PollingHandler) sendSSEEvent(c *gin.Context, event *Event) bool {
 	eventJSON, err := json.Marshal(event)
@@ -227,7 +260,14 @@ func (h *PollingHandler) sendSSEEvent(c *gin.Context, event *Event) bool {
 		return false
 	}
 
-	// Write SSE format
+	// Write SSE format. The id lets the browser send Last-Event-ID when it reconnects.
+	if event.ID != "" {
+		_, err = fmt.Fprintf(c.Writer, "id: %s\n", event.ID)
+		if err != nil {
+			h.logger.Debugw("Failed to write SSE event (client likely disconnected)", "error", err)
+			return false
+		}
+	}
 	_, err = fmt.Fprintf(c.Writer, "data: %s\n\n", eventJSON)
 	if err != nil {
 		h.logger.Debugw("Failed to write SSE event (client likely disconnected)", "error", err)
@@ -283,6 +323,10 @@ func (h *PollingHandler) sseEventLoop(ctx context.Context, c *gin.Context, conn
 				return
 			}
 
+			if conn.alreadyReplayed(event) {
+				continue
+			}
+
 			h.logger.Debugw("Sending SSE event", "connectionId", conn.ID, "eventType", event.Type)
 
 			if !h.sendSSEEvent(c, event) {
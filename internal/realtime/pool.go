@@ -135,6 +135,20 @@ func (p *ConnectionPool) BroadcastToUser(userID int, event *Event) {
 	}
 }
 
+// BroadcastForUsers sends each connected user the event as they may see it, see Event.ForUser
+func (p *ConnectionPool) BroadcastForUsers(event *Event) {
+	p.mu.RLock()
+	userIDs := make([]int, 0, len(p.userConns))
+	for userID := range p.userConns {
+		userIDs = append(userIDs, userID)
+	}
+	p.mu.RUnlock()
+
+	for _, userID := range userIDs {
+		p.BroadcastToUser(userID, event.ForUser(userID))
+	}
+}
+
 // GetConnection returns a connection by ID
 func (p *ConnectionPool) GetConnection(connectionID string) (*Connection, bool) {
 	p.mu.RLock()
@@ -208,14 +222,14 @@ func (p *ConnectionPool) GetStats() ConnectionPoolStats {
 	p.mu.RUnlock()
 
 	p.stats.mu.RLock()
-	stats := ConnectionPoolStats{
+	defer p.stats.mu.RUnlock()
+
+	// return a literal so the mutex isn't copied
+	return ConnectionPoolStats{
 		ActiveConnections: p.stats.ActiveConnections,
 		TotalMessages:     p.stats.TotalMessages,
 		QueueSize:         queueSize,
 	}
-	p.stats.mu.RUnlock()
-
-	return stats
 }
 
 // GetConnectionCount returns the number of active connections
@@ -0,0 +1,235 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"donetick.com/core/config"
+	rtModel "donetick.com/core/internal/realtime/model"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ReplayLog assigns monotonic IDs to published events and keeps the most recent events of each
+// circle in a bounded ring, so a client reconnecting with Last-Event-ID receives what it missed.
+//
+// Without persistence IDs come from an in-memory counter seeded with the start time, so they keep
+// increasing across restarts and a client holding an ID from before a restart is asked to resync.
+// With persistence every event is stored and its row ID becomes the event ID, which keeps IDs
+// ordered across instances and lets any instance replay from the database when its ring doesn't
+// reach back far enough.
+type ReplayLog struct {
+	size      int
+	persist   bool
+	retention time.Duration
+	db        *gorm.DB
+	mu        sync.Mutex
+	seq       int64
+	startSeq  int64
+	circles   map[int]*eventRing
+}
+
+// eventRing holds the latest events of a circle. floor is the highest event ID known to be
+// missing from the ring, so the ring can answer any Since call with lastID >= floor.
+type eventRing struct {
+	events []*Event
+	next   int
+	full   bool
+	floor  int64
+}
+
+func NewReplayLog(cfg *config.Config, db *gorm.DB) *ReplayLog {
+	seq := time.Now().UTC().UnixMicro()
+	return &ReplayLog{
+		size:      cfg.RealTimeConfig.ReplayBufferSize,
+		persist:   cfg.RealTimeConfig.ReplayPersist,
+		retention: cfg.RealTimeConfig.ReplayRetention,
+		db:        db,
+		seq:       seq,
+		startSeq:  seq,
+		circles:   make(map[int]*eventRing),
+	}
+}
+
+// ParseEventID parses an event ID sent back by a client
+func ParseEventID(id string) (int64, bool) {
+	seq, err := strconv.ParseInt(id, 10, 64)
+	if err != nil || seq <= 0 {
+		return 0, false
+	}
+	return seq, true
+}
+
+// lastEventIDFromRequest returns the last event ID a reconnecting client has seen. Browsers send it
+// in the Last-Event-ID header; other clients can pass it as the since query parameter.
+func lastEventIDFromRequest(c *gin.Context) string {
+	if id := c.GetHeader("Last-Event-ID"); id != "" {
+		return id
+	}
+	return c.Query("since")
+}
+
+// Assign gives the event the next ID, storing it first when replay is persisted
+func (l *ReplayLog) Assign(ctx context.Context, circleID int, event *Event) error {
+	if !l.persist {
+		l.mu.Lock()
+		l.seq++
+		event.ID = strconv.FormatInt(l.seq, 10)
+		l.mu.Unlock()
+		return nil
+	}
+
+	event.ID = ""
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+	row := &rtModel.ReplayEvent{CircleID: circleID, Payload: string(payload), CreatedAt: event.Timestamp}
+	if event.Audience != nil {
+		audience, err := json.Marshal(event.Audience)
+		if err != nil {
+			return fmt.Errorf("failed to marshal event audience: %w", err)
+		}
+		value := string(audience)
+		row.Audience = &value
+	}
+	if err := l.db.WithContext(ctx).Create(row).Error; err != nil {
+		return fmt.Errorf("failed to store event: %w", err)
+	}
+	event.ID = strconv.FormatInt(row.ID, 10)
+	return nil
+}
+
+// Append records an event delivered to this instance
+func (l *ReplayLog) Append(circleID int, event *Event) {
+	seq, ok := ParseEventID(event.ID)
+	if !ok || l.size <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	ring, exists := l.circles[circleID]
+	if !exists {
+		ring = &eventRing{events: make([]*Event, l.size), floor: l.startSeq}
+		if l.persist {
+			// earlier events may be stored but were never seen by this instance
+			ring.floor = seq - 1
+		}
+		l.circles[circleID] = ring
+	}
+
+	if ring.full {
+		if evicted, ok := ParseEventID(ring.events[ring.next].ID); ok && evicted > ring.floor {
+			ring.floor = evicted
+		}
+	}
+	ring.events[ring.next] = event
+	ring.next = (ring.next + 1) % len(ring.events)
+	if ring.next == 0 {
+		ring.full = true
+	}
+}
+
+// Since returns the events of the circle published after lastID in ID order. complete is false
+// when some of those events are no longer available and the client should refetch instead.
+func (l *ReplayLog) Since(ctx context.Context, circleID int, lastID int64) (events []*Event, complete bool, err error) {
+	if l.size <= 0 {
+		return nil, false, nil
+	}
+
+	if events, ok := l.fromRing(circleID, lastID); ok {
+		return events, true, nil
+	}
+	if !l.persist {
+		return nil, false, nil
+	}
+	return l.fromDatabase(ctx, circleID, lastID)
+}
+
+func (l *ReplayLog) fromRing(circleID int, lastID int64) ([]*Event, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	ring, exists := l.circles[circleID]
+	if !exists {
+		// nothing was published to the circle since this instance started
+		return nil, !l.persist && lastID >= l.startSeq
+	}
+	if lastID < ring.floor {
+		return nil, false
+	}
+
+	var events []*Event
+	for _, event := range ring.events {
+		if event == nil {
+			continue
+		}
+		if seq, _ := ParseEventID(event.ID); seq > lastID {
+			events = append(events, event)
+		}
+	}
+	sort.Slice(events, func(i, j int) bool {
+		a, _ := ParseEventID(events[i].ID)
+		b, _ := ParseEventID(events[j].ID)
+		return a < b
+	})
+	return events, true
+}
+
+func (l *ReplayLog) fromDatabase(ctx context.Context, circleID int, lastID int64) ([]*Event, bool, error) {
+	// the event the client last saw must still be stored, otherwise events after it may have been pruned
+	var last rtModel.ReplayEvent
+	if err := l.db.WithContext(ctx).Where("id = ? AND circle_id = ?", lastID, circleID).First(&last).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	var rows []rtModel.ReplayEvent
+	if err := l.db.WithContext(ctx).
+		Where("circle_id = ? AND id > ?", circleID, lastID).
+		Order("id asc").
+		Limit(l.size + 1).
+		Find(&rows).Error; err != nil {
+		return nil, false, err
+	}
+	if len(rows) > l.size {
+		return nil, false, nil
+	}
+
+	events := make([]*Event, 0, len(rows))
+	for _, row := range rows {
+		data := json.RawMessage{}
+		event := &Event{Data: &data}
+		if err := json.Unmarshal([]byte(row.Payload), event); err != nil {
+			return nil, false, fmt.Errorf("failed to unmarshal event %d: %w", row.ID, err)
+		}
+		event.ID = strconv.FormatInt(row.ID, 10)
+		if row.Audience != nil {
+			event.Audience = &Audience{}
+			if err := json.Unmarshal([]byte(*row.Audience), event.Audience); err != nil {
+				return nil, false, fmt.Errorf("failed to unmarshal audience of event %d: %w", row.ID, err)
+			}
+		}
+		events = append(events, event)
+	}
+	return events, true, nil
+}
+
+// Prune deletes stored events older than the retention
+func (l *ReplayLog) Prune(ctx context.Context) error {
+	if !l.persist {
+		return nil
+	}
+	cutoff := time.Now().UTC().Add(-l.retention)
+	return l.db.WithContext(ctx).Where("created_at < ?", cutoff).Delete(&rtModel.ReplayEvent{}).Error
+}
@@ -0,0 +1,135 @@
+package realtime
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"donetick.com/core/config"
+	"donetick.com/core/internal/database"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+func publishReplayEvents(t *testing.T, log *ReplayLog, circleID, count int) []int64 {
+	t.Helper()
+	ids := make([]int64, 0, count)
+	for i := 0; i < count; i++ {
+		event := NewEvent(EventTypeChoreUpdated, circleID, map[string]int{"n": i})
+		if err := log.Assign(context.Background(), circleID, event); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		log.Append(circleID, event)
+		id, ok := ParseEventID(event.ID)
+		if !ok {
+			t.Fatalf("expected a numeric event ID, got %q", event.ID)
+		}
+		if len(ids) > 0 && id <= ids[len(ids)-1] {
+			t.Fatalf("expected increasing IDs, got %d after %d", id, ids[len(ids)-1])
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func TestReplayLogSince(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.RealTimeConfig.ReplayBufferSize = 3
+	log := NewReplayLog(cfg, nil)
+
+	ids := publishReplayEvents(t, log, 1, 5)
+	publishReplayEvents(t, log, 2, 1)
+
+	tests := []struct {
+		name         string
+		circleID     int
+		lastID       int64
+		wantIDs      []int64
+		wantComplete bool
+	}{
+		{name: "up to date", circleID: 1, lastID: ids[4], wantComplete: true},
+		{name: "missed events in the ring", circleID: 1, lastID: ids[2], wantIDs: ids[3:], wantComplete: true},
+		{name: "oldest kept event is the next one", circleID: 1, lastID: ids[1], wantIDs: ids[2:], wantComplete: true},
+		{name: "evicted events", circleID: 1, lastID: ids[0], wantComplete: false},
+		{name: "ID from before a restart", circleID: 1, lastID: 42, wantComplete: false},
+		{name: "circle without events since start", circleID: 3, lastID: ids[4], wantComplete: true},
+		{name: "circle without events, stale ID", circleID: 3, lastID: 42, wantComplete: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, complete, err := log.Since(context.Background(), tt.circleID, tt.lastID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if complete != tt.wantComplete {
+				t.Fatalf("expected complete=%v, got %v", tt.wantComplete, complete)
+			}
+			if len(events) != len(tt.wantIDs) {
+				t.Fatalf("expected %d events, got %d", len(tt.wantIDs), len(events))
+			}
+			for i, event := range events {
+				if event.ID != strconv.FormatInt(tt.wantIDs[i], 10) || event.CircleID != tt.circleID {
+					t.Errorf("event %d: expected ID %d for circle %d, got %s for circle %d",
+						i, tt.wantIDs[i], tt.circleID, event.ID, event.CircleID)
+				}
+			}
+		})
+	}
+}
+
+func TestPersistedReplayLog(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect database: %v", err)
+	}
+	if err := database.Migration(db); err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
+	}
+	cfg := config.NewConfig()
+	cfg.RealTimeConfig.ReplayBufferSize = 3
+	cfg.RealTimeConfig.ReplayPersist = true
+
+	ids := publishReplayEvents(t, NewReplayLog(cfg, db), 1, 4)
+
+	// a freshly started instance has an empty ring and replays from the database
+	restarted := NewReplayLog(cfg, db)
+	events, complete, err := restarted.Since(context.Background(), 1, ids[1])
+	if err != nil || !complete {
+		t.Fatalf("expected a complete replay, got complete=%v err=%v", complete, err)
+	}
+	if len(events) != 2 || events[0].ID != strconv.FormatInt(ids[2], 10) || events[1].ID != strconv.FormatInt(ids[3], 10) {
+		t.Fatalf("expected events %v, got %+v", ids[2:], events)
+	}
+
+	// more missed events than the buffer holds
+	if _, complete, _ := restarted.Since(context.Background(), 1, ids[0]-1); complete {
+		t.Error("expected an unknown last event ID to require a resync")
+	}
+	publishReplayEvents(t, restarted, 1, 3)
+	if _, complete, _ := restarted.Since(context.Background(), 1, ids[0]); complete {
+		t.Error("expected more missed events than the buffer size to require a resync")
+	}
+}
+
+func TestValidateConfigRequiresPersistedReplayWithPostgresBroker(t *testing.T) {
+	tests := []struct {
+		name    string
+		broker  string
+		persist bool
+		wantErr bool
+	}{
+		{name: "memory broker", broker: BrokerTypeMemory},
+		{name: "postgres broker with persisted replay", broker: BrokerTypePostgres, persist: true},
+		{name: "postgres broker without persisted replay", broker: BrokerTypePostgres, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.NewConfig().RealTimeConfig
+			cfg.Broker = tt.broker
+			cfg.ReplayPersist = tt.persist
+			if err := validateConfig(&cfg); (err != nil) != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
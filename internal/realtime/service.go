@@ -16,6 +16,8 @@ type RealTimeService struct {
 	config          *config.RealTimeConfig
 	connectionPools map[int]*ConnectionPool // circleID -> ConnectionPool
 	broadcaster     *EventBroadcaster
+	broker          Broker
+	replay          *ReplayLog
 	mu              sync.RWMutex
 	ctx             context.Context
 	cancel          context.CancelFunc
@@ -36,7 +38,7 @@ type ServiceStats struct {
 }
 
 // NewRealTimeService creates a new real-time service instance
-func NewRealTimeService(cfg *config.Config) *RealTimeService {
+func NewRealTimeService(cfg *config.Config, broker Broker, replay *ReplayLog) *RealTimeService {
 	// Validate configuration
 	if err := validateConfig(&cfg.RealTimeConfig); err != nil {
 		panic(fmt.Sprintf("Invalid real-time configuration: %v", err))
@@ -47,6 +49,8 @@ func NewRealTimeService(cfg *config.Config) *RealTimeService {
 	service := &RealTimeService{
 		config:          &cfg.RealTimeConfig,
 		connectionPools: make(map[int]*ConnectionPool),
+		broker:          broker,
+		replay:          replay,
 		mu:              sync.RWMutex{},
 		ctx:             ctx,
 		cancel:          cancel,
@@ -76,6 +80,10 @@ func (s *RealTimeService) Start(ctx context.Context) error {
 		return nil
 	}
 
+	if err := s.broker.Start(s.ctx, s.deliverLocal); err != nil {
+		return fmt.Errorf("failed to start realtime broker: %w", err)
+	}
+
 	// Start cleanup routine
 	go s.cleanupRoutine()
 
@@ -101,6 +109,10 @@ func (s *RealTimeService) Stop() error {
 	// Cancel context to signal shutdown
 	s.cancel()
 
+	if err := s.broker.Stop(); err != nil {
+		s.logger.Warnw("Failed to stop realtime broker", "error", err)
+	}
+
 	// Close all connection pools
 	for circleID, pool := range s.connectionPools {
 		pool.Close()
@@ -155,24 +167,62 @@ func (s *RealTimeService) RemoveConnection(conn *Connection) {
 	}
 }
 
-// BroadcastToCircle sends an event to all connections in a specific circle
+// BroadcastToCircle assigns the event its ID and publishes it through the broker so every
+// instance sends it to its connections in the circle
 func (s *RealTimeService) BroadcastToCircle(circleID int, event *Event) {
 	if !s.started || !s.config.Enabled {
 		return
 	}
 
+	if err := s.replay.Assign(s.ctx, circleID, event); err != nil {
+		s.logger.Errorw("Failed to assign realtime event ID", "error", err, "circle_id", circleID, "event_type", event.Type)
+		return
+	}
+
+	if err := s.broker.Publish(s.ctx, circleID, event); err != nil {
+		s.logger.Errorw("Failed to publish realtime event", "error", err, "circle_id", circleID, "event_type", event.Type)
+	}
+}
+
+// deliverLocal sends an event to the connections this instance holds for the circle
+func (s *RealTimeService) deliverLocal(circleID int, event *Event) {
+	s.replay.Append(circleID, event)
+
 	s.mu.RLock()
 	pool, exists := s.connectionPools[circleID]
 	s.mu.RUnlock()
 
 	if exists {
-		pool.Broadcast(event)
+		if event.Audience == nil {
+			pool.Broadcast(event)
+		} else {
+			pool.BroadcastForUsers(event)
+		}
 		s.stats.mu.Lock()
 		s.stats.EventsPublished++
 		s.stats.mu.Unlock()
 	}
 }
 
+// EventsSince returns the events of the circle published after lastEventID as the user may see
+// them. complete is false when the missed events are no longer available and the client has to refetch.
+func (s *RealTimeService) EventsSince(ctx context.Context, circleID, userID int, lastEventID string) (events []*Event, complete bool) {
+	lastID, ok := ParseEventID(lastEventID)
+	if !ok {
+		return nil, false
+	}
+
+	events, complete, err := s.replay.Since(ctx, circleID, lastID)
+	if err != nil {
+		s.logger.Errorw("Failed to load events for replay", "error", err, "circle_id", circleID, "last_event_id", lastEventID)
+		return nil, false
+	}
+	for i, event := range events {
+		events[i] = event.ForUser(userID)
+	}
+	return events, complete
+}
+
 // GetStats returns current service statistics
 func (s *RealTimeService) GetStats() ServiceStats {
 	s.stats.mu.RLock()
@@ -201,6 +251,9 @@ func (s *RealTimeService) cleanupRoutine() {
 			return
 		case <-ticker.C:
 			s.performCleanup()
+			if err := s.replay.Prune(s.ctx); err != nil {
+				s.logger.Warnw("Failed to prune stored realtime events", "error", err)
+			}
 		}
 	}
 }
@@ -273,6 +326,20 @@ func validateConfig(cfg *config.RealTimeConfig) error {
 		return fmt.Errorf("staleThreshold must be positive, got %v", cfg.StaleThreshold)
 	}
 
+	if cfg.ReplayBufferSize < 0 {
+		return fmt.Errorf("replayBufferSize cannot be negative, got %d", cfg.ReplayBufferSize)
+	}
+
+	// without persistence each instance numbers events from its own counter, so the IDs of events
+	// fanned out from peers aren't ordered against local ones and replay would drop or repeat them
+	if cfg.Broker == BrokerTypePostgres && !cfg.ReplayPersist {
+		return fmt.Errorf("replayPersist must be enabled with the %s broker", BrokerTypePostgres)
+	}
+
+	if cfg.ReplayPersist && cfg.ReplayRetention <= 0 {
+		return fmt.Errorf("replayRetention must be positive when replay is persisted, got %v", cfg.ReplayRetention)
+	}
+
 	if cfg.StaleThreshold <= cfg.CleanupInterval {
 		return fmt.Errorf("staleThreshold (%v) should be greater than cleanupInterval (%v)",
 			cfg.StaleThreshold, cfg.CleanupInterval)
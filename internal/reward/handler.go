@@ -0,0 +1,612 @@
+package reward
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"donetick.com/core/internal/auth"
+	cRepo "donetick.com/core/internal/circle/repo"
+	pModel "donetick.com/core/internal/points"
+	pRepo "donetick.com/core/internal/points/repo"
+	"donetick.com/core/internal/realtime"
+	uModel "donetick.com/core/internal/user/model"
+	"donetick.com/core/logging"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var redemptionStatuses = map[string]pModel.RedemptionStatus{
+	"pending":   pModel.RedemptionStatusPending,
+	"approved":  pModel.RedemptionStatusApproved,
+	"rejected":  pModel.RedemptionStatusRejected,
+	"cancelled": pModel.RedemptionStatusCancelled,
+}
+
+type Handler struct {
+	pointsRepo      *pRepo.PointsRepository
+	circleRepo      *cRepo.CircleRepository
+	realTimeService *realtime.RealTimeService
+}
+
+func NewHandler(pr *pRepo.PointsRepository, cr *cRepo.CircleRepository, rts *realtime.RealTimeService) *Handler {
+	return &Handler{
+		pointsRepo:      pr,
+		circleRepo:      cr,
+		realTimeService: rts,
+	}
+}
+
+type rewardReq struct {
+	Name        string  `json:"name" binding:"required"`
+	Description *string `json:"description"`
+	Cost        int     `json:"cost" binding:"required,gt=0"`
+	Stock       *int    `json:"stock" binding:"omitempty,gte=0"`
+	Image       *string `json:"image"`
+	IsActive    *bool   `json:"isActive"`
+}
+
+type noteReq struct {
+	Note *string `json:"note"`
+}
+
+func (h *Handler) isCircleAdmin(c *gin.Context, userID, circleID int) (bool, error) {
+	admins, err := h.circleRepo.GetCircleAdmins(c, circleID)
+	if err != nil {
+		return false, err
+	}
+	for _, admin := range admins {
+		if admin.ID == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// requireAdmin writes the error response and returns false when the current user is not an admin of their circle.
+func (h *Handler) requireAdmin(c *gin.Context, userID, circleID int) bool {
+	isAdmin, err := h.isCircleAdmin(c, userID, circleID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get circle details"})
+		return false
+	}
+	if !isAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not an admin"})
+		return false
+	}
+	return true
+}
+
+func parseID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return 0, false
+	}
+	return id, true
+}
+
+// getRewards godoc
+//
+//	@Summary		List rewards
+//	@Description	Retrieves the rewards catalog of the current user's circle. Admins can include inactive rewards
+//	@Tags			rewards
+//	@Produce		json
+//	@Security		JWTKeyAuth
+//	@Security		APIKeyAuth
+//	@Param			includeInactive	query		bool							false	"Include inactive rewards (admins only)"
+//	@Success		200				{object}	map[string][]pModel.Reward		"res: array of rewards"
+//	@Failure		500				{object}	map[string]string				"error: Failed to get rewards"
+//	@Router			/rewards [get]
+func (h *Handler) getRewards(c *gin.Context) {
+	log := logging.FromContext(c)
+	currentUser, ok := auth.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get current user"})
+		return
+	}
+
+	includeInactive := false
+	if c.Query("includeInactive") == "true" {
+		isAdmin, err := h.isCircleAdmin(c, currentUser.ID, currentUser.CircleID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get circle details"})
+			return
+		}
+		includeInactive = isAdmin
+	}
+
+	rewards, err := h.pointsRepo.GetCircleRewards(c, currentUser.CircleID, includeInactive)
+	if err != nil {
+		log.Errorw("Failed to get rewards", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get rewards"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"res": rewards})
+}
+
+// createReward godoc
+//
+//	@Summary		Create a reward
+//	@Description	Adds a reward to the circle's catalog. Only circle admins can manage rewards
+//	@Tags			rewards
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWTKeyAuth
+//	@Security		APIKeyAuth
+//	@Param			reward	body		rewardReq					true	"Reward"
+//	@Success		201		{object}	map[string]pModel.Reward	"res: the created reward"
+//	@Failure		400		{object}	map[string]string			"error: Invalid request"
+//	@Failure		403		{object}	map[string]string			"error: You are not an admin"
+//	@Failure		500		{object}	map[string]string			"error: Failed to create reward"
+//	@Router			/rewards [post]
+func (h *Handler) createReward(c *gin.Context) {
+	log := logging.FromContext(c)
+	currentUser, ok := auth.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get current user"})
+		return
+	}
+	if !h.requireAdmin(c, currentUser.ID, currentUser.CircleID) {
+		return
+	}
+
+	var req rewardReq
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	reward := &pModel.Reward{
+		CircleID:    currentUser.CircleID,
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		Cost:        req.Cost,
+		Stock:       req.Stock,
+		Image:       req.Image,
+		IsActive:    req.IsActive == nil || *req.IsActive,
+		CreatedBy:   currentUser.ID,
+		CreatedAt:   time.Now().UTC(),
+	}
+	if err := h.pointsRepo.CreateReward(c, reward); err != nil {
+		log.Errorw("Failed to create reward", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create reward"})
+		return
+	}
+	if h.realTimeService != nil {
+		h.realTimeService.GetEventBroadcaster().BroadcastRewardEvent(realtime.EventTypeRewardCreated, currentUser.CircleID, reward.ID, reward, &currentUser.User)
+	}
+	c.JSON(http.StatusCreated, gin.H{"res": reward})
+}
+
+// updateReward godoc
+//
+//	@Summary		Update a reward
+//	@Description	Updates a reward of the circle's catalog. Setting isActive to false hides it from members
+//	@Tags			rewards
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWTKeyAuth
+//	@Security		APIKeyAuth
+//	@Param			id		path		int							true	"Reward ID"
+//	@Param			reward	body		rewardReq					true	"Reward"
+//	@Success		200		{object}	map[string]pModel.Reward	"res: the updated reward"
+//	@Failure		400		{object}	map[string]string			"error: Invalid request"
+//	@Failure		403		{object}	map[string]string			"error: You are not an admin"
+//	@Failure		404		{object}	map[string]string			"error: Reward not found"
+//	@Failure		500		{object}	map[string]string			"error: Failed to update reward"
+//	@Router			/rewards/{id} [put]
+func (h *Handler) updateReward(c *gin.Context) {
+	log := logging.FromContext(c)
+	currentUser, ok := auth.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get current user"})
+		return
+	}
+	if !h.requireAdmin(c, currentUser.ID, currentUser.CircleID) {
+		return
+	}
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	var req rewardReq
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	reward, err := h.pointsRepo.GetRewardByID(c, currentUser.CircleID, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reward not found"})
+		return
+	}
+	reward.Name = strings.TrimSpace(req.Name)
+	reward.Description = req.Description
+	reward.Cost = req.Cost
+	reward.Stock = req.Stock
+	reward.Image = req.Image
+	if req.IsActive != nil {
+		reward.IsActive = *req.IsActive
+	}
+	if err := h.pointsRepo.UpdateReward(c, reward); err != nil {
+		log.Errorw("Failed to update reward", "error", err, "rewardID", id)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update reward"})
+		return
+	}
+	if h.realTimeService != nil {
+		h.realTimeService.GetEventBroadcaster().BroadcastRewardEvent(realtime.EventTypeRewardUpdated, currentUser.CircleID, reward.ID, reward, &currentUser.User)
+	}
+	c.JSON(http.StatusOK, gin.H{"res": reward})
+}
+
+// deleteReward godoc
+//
+//	@Summary		Delete a reward
+//	@Description	Removes a reward from the catalog. The reward is deactivated rather than deleted so past redemptions keep it
+//	@Tags			rewards
+//	@Produce		json
+//	@Security		JWTKeyAuth
+//	@Security		APIKeyAuth
+//	@Param			id	path		int					true	"Reward ID"
+//	@Success		200	{object}	map[string]string	"message: Reward deleted"
+//	@Failure		403	{object}	map[string]string	"error: You are not an admin"
+//	@Failure		404	{object}	map[string]string	"error: Reward not found"
+//	@Failure		500	{object}	map[string]string	"error: Failed to delete reward"
+//	@Router			/rewards/{id} [delete]
+func (h *Handler) deleteReward(c *gin.Context) {
+	log := logging.FromContext(c)
+	currentUser, ok := auth.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get current user"})
+		return
+	}
+	if !h.requireAdmin(c, currentUser.ID, currentUser.CircleID) {
+		return
+	}
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	reward, err := h.pointsRepo.GetRewardByID(c, currentUser.CircleID, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reward not found"})
+		return
+	}
+	reward.IsActive = false
+	if err := h.pointsRepo.UpdateReward(c, reward); err != nil {
+		log.Errorw("Failed to delete reward", "error", err, "rewardID", id)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete reward"})
+		return
+	}
+	if h.realTimeService != nil {
+		h.realTimeService.GetEventBroadcaster().BroadcastRewardEvent(realtime.EventTypeRewardDeleted, currentUser.CircleID, id, nil, &currentUser.User)
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Reward deleted"})
+}
+
+// redeemReward godoc
+//
+//	@Summary		Request a reward
+//	@Description	Creates a redemption request for the reward. Points are held until an admin approves or rejects it
+//	@Tags			rewards
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWTKeyAuth
+//	@Security		APIKeyAuth
+//	@Param			id		path		int									true	"Reward ID"
+//	@Param			body	body		noteReq								false	"Optional note for the admins"
+//	@Success		201		{object}	map[string]pModel.RewardRedemption	"res: the redemption request"
+//	@Failure		400		{object}	map[string]string					"error: Not enough points | Reward is out of stock | Reward is not available"
+//	@Failure		404		{object}	map[string]string					"error: Reward not found"
+//	@Failure		500		{object}	map[string]string					"error: Failed to redeem reward"
+//	@Router			/rewards/{id}/redeem [post]
+func (h *Handler) redeemReward(c *gin.Context) {
+	log := logging.FromContext(c)
+	currentUser, ok := auth.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get current user"})
+		return
+	}
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	var req noteReq
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+			return
+		}
+	}
+
+	redemption := &pModel.RewardRedemption{
+		RewardID: id,
+		CircleID: currentUser.CircleID,
+		UserID:   currentUser.ID,
+		Note:     req.Note,
+	}
+	if err := h.pointsRepo.CreateRedemption(c, redemption); err != nil {
+		h.redemptionError(c, err, "Failed to redeem reward")
+		return
+	}
+	log.Infow("Reward redemption requested", "redemptionID", redemption.ID, "rewardID", id, "userID", currentUser.ID)
+	h.broadcastRedemption(c, realtime.EventTypeRedemptionRequested, redemption, currentUser)
+	c.JSON(http.StatusCreated, gin.H{"res": redemption})
+}
+
+// getRedemptions godoc
+//
+//	@Summary		List redemption requests
+//	@Description	Admins see every request of the circle, members only their own
+//	@Tags			rewards
+//	@Produce		json
+//	@Security		JWTKeyAuth
+//	@Security		APIKeyAuth
+//	@Param			status	query		string									false	"pending, approved, rejected or cancelled"
+//	@Success		200		{object}	map[string][]pModel.RewardRedemption	"res: array of redemptions"
+//	@Failure		400		{object}	map[string]string						"error: Invalid status"
+//	@Failure		500		{object}	map[string]string						"error: Failed to get redemptions"
+//	@Router			/rewards/redemptions [get]
+func (h *Handler) getRedemptions(c *gin.Context) {
+	log := logging.FromContext(c)
+	currentUser, ok := auth.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get current user"})
+		return
+	}
+
+	var status *pModel.RedemptionStatus
+	if rawStatus := c.Query("status"); rawStatus != "" {
+		s, ok := redemptionStatuses[rawStatus]
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
+			return
+		}
+		status = &s
+	}
+
+	isAdmin, err := h.isCircleAdmin(c, currentUser.ID, currentUser.CircleID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get circle details"})
+		return
+	}
+	var userID *int
+	if !isAdmin {
+		userID = &currentUser.ID
+	}
+
+	redemptions, err := h.pointsRepo.GetRedemptions(c, currentUser.CircleID, userID, status)
+	if err != nil {
+		log.Errorw("Failed to get redemptions", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get redemptions"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"res": redemptions})
+}
+
+// approveRedemption godoc
+//
+//	@Summary		Approve a redemption request
+//	@Description	Deducts the points from the member, takes the reward out of stock and records it in the points history
+//	@Tags			rewards
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWTKeyAuth
+//	@Security		APIKeyAuth
+//	@Param			id		path		int					true	"Redemption ID"
+//	@Param			body	body		noteReq				false	"Optional review note"
+//	@Success		200		{object}	map[string]string	"message: Redemption approved"
+//	@Failure		400		{object}	map[string]string	"error: Not enough points | Reward is out of stock"
+//	@Failure		403		{object}	map[string]string	"error: You are not an admin"
+//	@Failure		404		{object}	map[string]string	"error: Redemption not found"
+//	@Failure		409		{object}	map[string]string	"error: Redemption is not pending"
+//	@Failure		500		{object}	map[string]string	"error: Failed to approve redemption"
+//	@Router			/rewards/redemptions/{id}/approve [post]
+func (h *Handler) approveRedemption(c *gin.Context) {
+	h.reviewRedemption(c, true)
+}
+
+// rejectRedemption godoc
+//
+//	@Summary		Reject a redemption request
+//	@Description	Rejects a pending request and releases the held points
+//	@Tags			rewards
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWTKeyAuth
+//	@Security		APIKeyAuth
+//	@Param			id		path		int					true	"Redemption ID"
+//	@Param			body	body		noteReq				false	"Optional review note"
+//	@Success		200		{object}	map[string]string	"message: Redemption rejected"
+//	@Failure		403		{object}	map[string]string	"error: You are not an admin"
+//	@Failure		404		{object}	map[string]string	"error: Redemption not found"
+//	@Failure		409		{object}	map[string]string	"error: Redemption is not pending"
+//	@Failure		500		{object}	map[string]string	"error: Failed to reject redemption"
+//	@Router			/rewards/redemptions/{id}/reject [post]
+func (h *Handler) rejectRedemption(c *gin.Context) {
+	h.reviewRedemption(c, false)
+}
+
+func (h *Handler) reviewRedemption(c *gin.Context, approve bool) {
+	log := logging.FromContext(c)
+	currentUser, ok := auth.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get current user"})
+		return
+	}
+	if !h.requireAdmin(c, currentUser.ID, currentUser.CircleID) {
+		return
+	}
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	var req noteReq
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+			return
+		}
+	}
+
+	redemption, err := h.pointsRepo.GetRedemptionByID(c, currentUser.CircleID, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Redemption not found"})
+		return
+	}
+
+	if approve {
+		if err := h.pointsRepo.ApproveRedemption(c, redemption, currentUser.ID, req.Note); err != nil {
+			h.redemptionError(c, err, "Failed to approve redemption")
+			return
+		}
+		log.Infow("Reward redemption approved", "redemptionID", id, "adminID", currentUser.ID)
+		h.broadcastRedemption(c, realtime.EventTypeRedemptionApproved, redemption, currentUser)
+		c.JSON(http.StatusOK, gin.H{"message": "Redemption approved"})
+		return
+	}
+
+	if err := h.pointsRepo.RejectRedemption(c, redemption, currentUser.ID, req.Note); err != nil {
+		h.redemptionError(c, err, "Failed to reject redemption")
+		return
+	}
+	log.Infow("Reward redemption rejected", "redemptionID", id, "adminID", currentUser.ID)
+	h.broadcastRedemption(c, realtime.EventTypeRedemptionRejected, redemption, currentUser)
+	c.JSON(http.StatusOK, gin.H{"message": "Redemption rejected"})
+}
+
+// cancelRedemption godoc
+//
+//	@Summary		Cancel a redemption request
+//	@Description	Lets a member withdraw their own pending request
+//	@Tags			rewards
+//	@Produce		json
+//	@Security		JWTKeyAuth
+//	@Security		APIKeyAuth
+//	@Param			id	path		int					true	"Redemption ID"
+//	@Success		200	{object}	map[string]string	"message: Redemption cancelled"
+//	@Failure		404	{object}	map[string]string	"error: Redemption not found"
+//	@Failure		409	{object}	map[string]string	"error: Redemption is not pending"
+//	@Failure		500	{object}	map[string]string	"error: Failed to cancel redemption"
+//	@Router			/rewards/redemptions/{id}/cancel [post]
+func (h *Handler) cancelRedemption(c *gin.Context) {
+	currentUser, ok := auth.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get current user"})
+		return
+	}
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	redemption, err := h.pointsRepo.GetRedemptionByID(c, currentUser.CircleID, id)
+	if err != nil || redemption.UserID != currentUser.ID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Redemption not found"})
+		return
+	}
+	if err := h.pointsRepo.CancelRedemption(c, redemption); err != nil {
+		h.redemptionError(c, err, "Failed to cancel redemption")
+		return
+	}
+	h.broadcastRedemption(c, realtime.EventTypeRedemptionCancelled, redemption, currentUser)
+	c.JSON(http.StatusOK, gin.H{"message": "Redemption cancelled"})
+}
+
+// getLedger godoc
+//
+//	@Summary		Get points ledger
+//	@Description	Retrieves the points history of a member, newest first, with the running balance after each entry. Only admins can see other members' ledgers
+//	@Tags			rewards
+//	@Produce		json
+//	@Security		JWTKeyAuth
+//	@Security		APIKeyAuth
+//	@Param			userId	query		int									false	"Member ID (defaults to the current user)"
+//	@Success		200		{object}	map[string][]pModel.LedgerEntry		"res: ledger entries"
+//	@Failure		400		{object}	map[string]string					"error: Invalid user ID"
+//	@Failure		403		{object}	map[string]string					"error: You are not an admin"
+//	@Failure		500		{object}	map[string]string					"error: Failed to get points ledger"
+//	@Router			/rewards/ledger [get]
+func (h *Handler) getLedger(c *gin.Context) {
+	log := logging.FromContext(c)
+	currentUser, ok := auth.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get current user"})
+		return
+	}
+
+	userID := currentUser.ID
+	if rawUserID := c.Query("userId"); rawUserID != "" {
+		var err error
+		userID, err = strconv.Atoi(rawUserID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+			return
+		}
+		if userID != currentUser.ID && !h.requireAdmin(c, currentUser.ID, currentUser.CircleID) {
+			return
+		}
+	}
+
+	ledger, err := h.pointsRepo.GetPointsLedger(c, currentUser.CircleID, userID)
+	if err != nil {
+		log.Errorw("Failed to get points ledger", "error", err, "userID", userID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get points ledger"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"res": ledger})
+}
+
+// broadcastRedemption reloads the redemption so the event carries its new status and review details
+func (h *Handler) broadcastRedemption(c *gin.Context, eventType realtime.EventType, redemption *pModel.RewardRedemption, currentUser *uModel.UserDetails) {
+	if h.realTimeService == nil {
+		return
+	}
+	if updated, err := h.pointsRepo.GetRedemptionByID(c, currentUser.CircleID, redemption.ID); err == nil {
+		redemption = updated
+	}
+	h.realTimeService.GetEventBroadcaster().BroadcastRedemptionEvent(eventType, redemption, &currentUser.User)
+}
+
+func (h *Handler) redemptionError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, pRepo.ErrNotEnoughPoints):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Not enough points"})
+	case errors.Is(err, pRepo.ErrRewardOutOfStock):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Reward is out of stock"})
+	case errors.Is(err, pRepo.ErrRewardUnavailable):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Reward is not available"})
+	case errors.Is(err, pRepo.ErrRedemptionNotPending):
+		c.JSON(http.StatusConflict, gin.H{"error": "Redemption is not pending"})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reward not found"})
+	default:
+		logging.FromContext(c).Errorw(fallback, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// Routes sets up the rewards catalog, redemption and ledger routes
+func Routes(r *gin.Engine, h *Handler, multiAuthMiddleware *auth.MultiAuthMiddleware) {
+	rewardRoutes := r.Group("api/v1/rewards")
+	rewardRoutes.Use(multiAuthMiddleware.MiddlewareFunc())
+	{
+		rewardRoutes.GET("", h.getRewards)
+		rewardRoutes.POST("", h.createReward)
+		rewardRoutes.GET("/ledger", h.getLedger)
+		rewardRoutes.GET("/redemptions", h.getRedemptions)
+		rewardRoutes.POST("/redemptions/:id/approve", h.approveRedemption)
+		rewardRoutes.POST("/redemptions/:id/reject", h.rejectRedemption)
+		rewardRoutes.POST("/redemptions/:id/cancel", h.cancelRedemption)
+		rewardRoutes.PUT("/:id", h.updateReward)
+		rewardRoutes.DELETE("/:id", h.deleteReward)
+		rewardRoutes.POST("/:id/redeem", h.redeemReward)
+	}
+}
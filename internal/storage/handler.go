@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"net/url"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -27,7 +29,7 @@ import (
 // Handler handles file storage-related routes
 type Handler struct {
 	storage     Storage
-	signer      *URLSignerS3
+	signer      URLSigner
 	storageRepo *storageRepo.StorageRepository
 	choreRepo   *chRepo.ChoreRepository
 	circleRepo  *cRepo.CircleRepository
@@ -35,8 +37,8 @@ type Handler struct {
 }
 
 // NewHandler creates a new Handler
-func NewHandler(storage *S3Storage, choreRepo *chRepo.ChoreRepository, circleRepo *cRepo.CircleRepository,
-	repo *storageRepo.StorageRepository, signer *URLSignerS3, cfg *config.Config) *Handler {
+func NewHandler(storage Storage, choreRepo *chRepo.ChoreRepository, circleRepo *cRepo.CircleRepository,
+	repo *storageRepo.StorageRepository, signer URLSigner, cfg *config.Config) *Handler {
 	return &Handler{storage: storage, circleRepo: circleRepo,
 		choreRepo:   choreRepo,
 		storageRepo: repo,
@@ -79,20 +81,11 @@ func (h *Handler) AssetHandler(c *gin.Context) {
 	}
 	defer file.Close()
 
-	// Detect content type
-	// buf := make([]byte, 512)
-	// n, _ := file.Read(buf)
-	// contentType := http.DetectContentType(buf[:n])
-
-	// Reset reader to stream full file
-
-	// if err != nil {
-	// 	c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read file"})
-	// 	return
-	// }
-
 	// Set headers
-	// c.Header("Content-Type", contentType)
+	if contentType := mime.TypeByExtension(filepath.Ext(filename)); contentType != "" {
+		c.Header("Content-Type", contentType)
+	}
+	c.Header("X-Content-Type-Options", "nosniff")
 	c.Header("Cache-Control", "public, max-age=604800, immutable")
 	c.Header("Expires", time.Now().UTC().Add(7*24*time.Hour).Format(http.TimeFormat))
 	c.Status(http.StatusOK)
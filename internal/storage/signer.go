@@ -1,6 +1,24 @@
 package storage
 
+import (
+	"fmt"
+
+	"donetick.com/core/config"
+)
+
 type URLSigner interface {
 	Sign(rawPath string) (string, error)
 	IsValid(rawPath string, providedSig string) bool
 }
+
+// NewURLSigner returns the signer that matches the configured storage backend.
+func NewURLSigner(storage Storage, config *config.Config) (URLSigner, error) {
+	switch s := storage.(type) {
+	case *LocalStorage:
+		return NewURLSignerLocal(config), nil
+	case *S3Storage:
+		return NewURLSignerS3(s, config), nil
+	default:
+		return nil, fmt.Errorf("no url signer for storage %T", storage)
+	}
+}
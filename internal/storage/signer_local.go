@@ -6,16 +6,27 @@ import (
 	"encoding/hex"
 	"fmt"
 	"net/url"
+	"strings"
 
 	"donetick.com/core/config"
 )
 
+// AssetRoutePrefix is where AssetHandler serves files from local storage
+const AssetRoutePrefix = "/api/v1/assets/"
+
 type URLSignerLocal struct {
 	Secret []byte
+	// BaseURL is prepended to signed paths so they resolve to AssetHandler. The signature
+	// itself only covers the storage path.
+	BaseURL string
 }
 
 func NewURLSignerLocal(config *config.Config) *URLSignerLocal {
-	return &URLSignerLocal{Secret: []byte(config.Jwt.Secret)}
+	signer := &URLSignerLocal{Secret: []byte(config.Jwt.Secret)}
+	if config.Storage.StorageType == StorageTypeLocal {
+		signer.BaseURL = strings.TrimSuffix(config.Storage.PublicHost, "/") + AssetRoutePrefix
+	}
+	return signer
 }
 
 // sign method without expiration:
@@ -23,7 +34,7 @@ func (s *URLSignerLocal) Sign(rawPath string) (string, error) {
 	sig := s.sign(rawPath)
 	values := url.Values{}
 	values.Set("sig", sig)
-	return fmt.Sprintf("%s?%s", rawPath, values.Encode()), nil
+	return fmt.Sprintf("%s%s?%s", s.BaseURL, rawPath, values.Encode()), nil
 }
 
 func (s *URLSignerLocal) sign(path string) string {
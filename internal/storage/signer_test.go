@@ -2,6 +2,7 @@ package storage
 
 import (
 	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -76,3 +77,31 @@ func TestSignReturnsValidURL(t *testing.T) {
 	}
 
 }
+
+func TestLocalStorageSignerPrefixesAssetRoute(t *testing.T) {
+	signer := NewURLSignerLocal(&config.Config{
+		Jwt: config.JwtConfig{
+			Secret: "secret",
+		},
+		Storage: config.StorageConfig{
+			StorageType: StorageTypeLocal,
+			PublicHost:  "https://donetick.example.com/",
+		},
+	})
+	signed, err := signer.Sign("users/1/photo.png")
+	if err != nil {
+		t.Fatalf("Sign returned error: %v", err)
+	}
+
+	parsed, err := url.Parse(signed)
+	if err != nil {
+		t.Fatalf("Failed to parse signed URL: %v", err)
+	}
+	if parsed.Host != "donetick.example.com" || parsed.Path != AssetRoutePrefix+"users/1/photo.png" {
+		t.Errorf("want asset url on public host, got %q", signed)
+	}
+	// AssetHandler validates the path relative to the asset route
+	if !signer.IsValid(strings.TrimPrefix(parsed.Path, AssetRoutePrefix), parsed.Query().Get("sig")) {
+		t.Errorf("Signature is not valid for %q", signed)
+	}
+}
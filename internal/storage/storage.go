@@ -2,7 +2,15 @@ package storage
 
 import (
 	"context"
+	"fmt"
 	"io"
+
+	"donetick.com/core/config"
+)
+
+const (
+	StorageTypeS3    = "s3"
+	StorageTypeLocal = "local"
 )
 
 type Storage interface {
@@ -11,3 +19,15 @@ type Storage interface {
 	GetURL(ctx context.Context, path string) (string, error)
 	Get(ctx context.Context, path string) (io.ReadCloser, error)
 }
+
+// NewStorage returns the storage backend selected by storage.storage_type, defaulting to S3.
+func NewStorage(config *config.Config) (Storage, error) {
+	switch config.Storage.StorageType {
+	case StorageTypeLocal:
+		return NewLocalStorage(config), nil
+	case StorageTypeS3, "":
+		return NewS3Storage(config)
+	default:
+		return nil, fmt.Errorf("unknown storage type: %s", config.Storage.StorageType)
+	}
+}
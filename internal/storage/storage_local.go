@@ -11,12 +11,19 @@ import (
 	"donetick.com/core/config"
 )
 
+// defaultLocalBasePath keeps assets next to the sqlite database so a single volume holds all data
+const defaultLocalBasePath = "data/assets"
+
 type LocalStorage struct {
 	BasePath string
 }
 
 func NewLocalStorage(config *config.Config) *LocalStorage {
-	return &LocalStorage{BasePath: config.Storage.BasePath}
+	basePath := config.Storage.BasePath
+	if basePath == "" {
+		basePath = defaultLocalBasePath
+	}
+	return &LocalStorage{BasePath: basePath}
 }
 
 func sanitizePath(p string) (string, error) {
@@ -10,6 +10,7 @@ import (
 	chRepo "donetick.com/core/internal/chore/repo"
 	cRepo "donetick.com/core/internal/circle/repo"
 	"donetick.com/core/internal/events"
+	"donetick.com/core/internal/realtime"
 	tModel "donetick.com/core/internal/thing/model"
 	tRepo "donetick.com/core/internal/thing/repo"
 	uRepo "donetick.com/core/internal/user/repo"
@@ -20,26 +21,58 @@ import (
 )
 
 type API struct {
-	choreRepo      *chRepo.ChoreRepository
-	circleRepo     *cRepo.CircleRepository
-	thingRepo      *tRepo.ThingRepository
-	userRepo       *uRepo.UserRepository
-	tRepo          *tRepo.ThingRepository
-	eventsProducer *events.EventsProducer
+	choreRepo       *chRepo.ChoreRepository
+	circleRepo      *cRepo.CircleRepository
+	thingRepo       *tRepo.ThingRepository
+	userRepo        *uRepo.UserRepository
+	tRepo           *tRepo.ThingRepository
+	eventsProducer  *events.EventsProducer
+	realTimeService *realtime.RealTimeService
 }
 
 func NewAPI(cr *chRepo.ChoreRepository, circleRepo *cRepo.CircleRepository,
-	thingRepo *tRepo.ThingRepository, userRepo *uRepo.UserRepository, tRepo *tRepo.ThingRepository, eventsProducer *events.EventsProducer) *API {
+	thingRepo *tRepo.ThingRepository, userRepo *uRepo.UserRepository, tRepo *tRepo.ThingRepository, eventsProducer *events.EventsProducer,
+	rts *realtime.RealTimeService) *API {
 	return &API{
-		choreRepo:      cr,
-		circleRepo:     circleRepo,
-		thingRepo:      thingRepo,
-		userRepo:       userRepo,
-		tRepo:          tRepo,
-		eventsProducer: eventsProducer,
+		choreRepo:       cr,
+		circleRepo:      circleRepo,
+		thingRepo:       thingRepo,
+		userRepo:        userRepo,
+		tRepo:           tRepo,
+		eventsProducer:  eventsProducer,
+		realTimeService: rts,
 	}
 }
 
+// broadcastStateChanged sends a thing state change to the circle of the current user
+func (h *API) broadcastStateChanged(c *gin.Context, thing *tModel.Thing) {
+	if h.realTimeService == nil {
+		return
+	}
+	currentUser := auth.MustCurrentUser(c)
+	h.realTimeService.GetEventBroadcaster().BroadcastThingEvent(realtime.EventTypeThingStateChanged, currentUser.CircleID, thing.ID, thing, &currentUser.User)
+}
+
+// broadcastTriggeredChore sends the new due date of a chore scheduled by a thing trigger
+func broadcastTriggeredChore(c *gin.Context, choreRepo *chRepo.ChoreRepository, rts *realtime.RealTimeService, choreID int) {
+	if rts == nil {
+		return
+	}
+	currentUser, ok := auth.CurrentUser(c)
+	if !ok {
+		return
+	}
+	chore, err := choreRepo.GetChore(c, choreID, currentUser.ID, currentUser.CircleID)
+	if err != nil {
+		return
+	}
+	changes := map[string]interface{}{
+		"nextDueDate": chore.NextDueDate,
+		"updatedAt":   time.Now().UTC(),
+	}
+	rts.GetEventBroadcaster().BroadcastChoreChanged(realtime.EventTypeChoreDueDateChanged, chore, &currentUser.User, changes, nil, nil)
+}
+
 func (h *API) UpdateThingState(c *gin.Context) {
 	thing, shouldReturn := validateUserAndThing(c, h)
 	if shouldReturn {
@@ -70,13 +103,14 @@ func (h *API) UpdateThingState(c *gin.Context) {
 	}
 
 	currentUser := auth.MustCurrentUser(c)
-	h.eventsProducer.ThingsUpdated(c.Request.Context(), currentUser.WebhookURL, map[string]interface{}{
+	h.eventsProducer.ThingsUpdated(c.Request.Context(), currentUser.CircleID, currentUser.WebhookURL, map[string]interface{}{
 		"id":         thing.ID,
 		"name":       thing.Name,
 		"type":       thing.Type,
 		"from_state": oldState,
 		"to_state":   state,
 	})
+	h.broadcastStateChanged(c, thing)
 
 	c.JSON(200, gin.H{})
 }
@@ -130,13 +164,14 @@ func (h *API) ChangeThingState(c *gin.Context) {
 	}
 
 	currentUser := auth.MustCurrentUser(c)
-	h.eventsProducer.ThingsUpdated(c.Request.Context(), currentUser.WebhookURL, map[string]interface{}{
+	h.eventsProducer.ThingsUpdated(c.Request.Context(), currentUser.CircleID, currentUser.WebhookURL, map[string]interface{}{
 		"id":         thing.ID,
 		"name":       thing.Name,
 		"type":       thing.Type,
 		"from_state": oldState,
 		"to_state":   thing.State,
 	})
+	h.broadcastStateChanged(c, thing)
 
 	c.JSON(200, gin.H{"state": thing.State})
 }
@@ -159,7 +194,9 @@ func WebhookEvaluateTriggerAndScheduleDueDate(h *API, c *gin.Context, thing *tMo
 			if errSave != nil {
 				log.Error("Error setting due date for chore ", errSave)
 				log.Error("Chore ID ", tc.ChoreID, " Thing ID ", thing.ID, " State ", thing.State)
+				continue
 			}
+			broadcastTriggeredChore(c, h.choreRepo, h.realTimeService, tc.ChoreID)
 		}
 
 	}
@@ -10,6 +10,7 @@ import (
 	"donetick.com/core/internal/events"
 	nRepo "donetick.com/core/internal/notifier/repo"
 	nps "donetick.com/core/internal/notifier/service"
+	"donetick.com/core/internal/realtime"
 	tModel "donetick.com/core/internal/thing/model"
 	tRepo "donetick.com/core/internal/thing/repo"
 	"donetick.com/core/logging"
@@ -17,12 +18,13 @@ import (
 )
 
 type Handler struct {
-	choreRepo      *chRepo.ChoreRepository
-	circleRepo     *cRepo.CircleRepository
-	nPlanner       *nps.NotificationPlanner
-	nRepo          *nRepo.NotificationRepository
-	tRepo          *tRepo.ThingRepository
-	eventsProducer *events.EventsProducer
+	choreRepo       *chRepo.ChoreRepository
+	circleRepo      *cRepo.CircleRepository
+	nPlanner        *nps.NotificationPlanner
+	nRepo           *nRepo.NotificationRepository
+	tRepo           *tRepo.ThingRepository
+	eventsProducer  *events.EventsProducer
+	realTimeService *realtime.RealTimeService
 }
 
 type ThingRequest struct {
@@ -33,17 +35,31 @@ type ThingRequest struct {
 }
 
 func NewHandler(cr *chRepo.ChoreRepository, circleRepo *cRepo.CircleRepository,
-	np *nps.NotificationPlanner, nRepo *nRepo.NotificationRepository, tRepo *tRepo.ThingRepository, eventsProducer *events.EventsProducer) *Handler {
+	np *nps.NotificationPlanner, nRepo *nRepo.NotificationRepository, tRepo *tRepo.ThingRepository, eventsProducer *events.EventsProducer,
+	rts *realtime.RealTimeService) *Handler {
 	return &Handler{
-		choreRepo:      cr,
-		circleRepo:     circleRepo,
-		nPlanner:       np,
-		nRepo:          nRepo,
-		tRepo:          tRepo,
-		eventsProducer: eventsProducer,
+		choreRepo:       cr,
+		circleRepo:      circleRepo,
+		nPlanner:        np,
+		nRepo:           nRepo,
+		tRepo:           tRepo,
+		eventsProducer:  eventsProducer,
+		realTimeService: rts,
 	}
 }
 
+// broadcastThing sends a thing event to the circle of the current user
+func (h *Handler) broadcastThing(c *gin.Context, eventType realtime.EventType, thingID int, thing *tModel.Thing) {
+	if h.realTimeService == nil {
+		return
+	}
+	currentUser, ok := auth.CurrentUser(c)
+	if !ok {
+		return
+	}
+	h.realTimeService.GetEventBroadcaster().BroadcastThingEvent(eventType, currentUser.CircleID, thingID, thing, &currentUser.User)
+}
+
 // CreateThing godoc
 //
 //	@Summary		Create a new thing
@@ -87,6 +103,7 @@ func (h *Handler) CreateThing(c *gin.Context) {
 		c.JSON(500, gin.H{"error": err.Error()})
 		return
 	}
+	h.broadcastThing(c, realtime.EventTypeThingCreated, thing.ID, thing)
 	c.JSON(201, gin.H{
 		"res": thing,
 	})
@@ -153,13 +170,14 @@ func (h *Handler) UpdateThingState(c *gin.Context) {
 	if shouldReturn {
 		return
 	}
-	h.eventsProducer.ThingsUpdated(c.Request.Context(), currentUser.WebhookURL, map[string]interface{}{
+	h.eventsProducer.ThingsUpdated(c.Request.Context(), currentUser.CircleID, currentUser.WebhookURL, map[string]interface{}{
 		"id":         thing.ID,
 		"name":       thing.Name,
 		"type":       thing.Type,
 		"from_state": old_state,
 		"to_state":   val,
 	})
+	h.broadcastThing(c, realtime.EventTypeThingStateChanged, thing.ID, thing)
 
 	c.JSON(200, gin.H{
 		"res": thing,
@@ -180,6 +198,7 @@ func EvaluateTriggerAndScheduleDueDate(h *Handler, c *gin.Context, thing *tModel
 				c.JSON(500, gin.H{"error": err.Error()})
 				return true
 			}
+			broadcastTriggeredChore(c, h.choreRepo, h.realTimeService, tc.ChoreID)
 		}
 	}
 	return false
@@ -238,6 +257,7 @@ func (h *Handler) UpdateThing(c *gin.Context) {
 		c.JSON(500, gin.H{"error": err.Error()})
 		return
 	}
+	h.broadcastThing(c, realtime.EventTypeThingUpdated, thing.ID, thing)
 	c.JSON(200, gin.H{
 		"res": thing,
 	})
@@ -383,6 +403,7 @@ func (h *Handler) DeleteThing(c *gin.Context) {
 		c.JSON(500, gin.H{"error": err.Error()})
 		return
 	}
+	h.broadcastThing(c, realtime.EventTypeThingDeleted, thingID, nil)
 	c.JSON(200, gin.H{})
 }
 
@@ -13,10 +13,10 @@ import (
 
 type DeletionService struct {
 	db      *gorm.DB
-	storage *storage.S3Storage
+	storage storage.Storage
 }
 
-func NewDeletionService(db *gorm.DB, storage *storage.S3Storage) *DeletionService {
+func NewDeletionService(db *gorm.DB, storage storage.Storage) *DeletionService {
 	return &DeletionService{
 		db:      db,
 		storage: storage,
@@ -105,7 +105,12 @@ func (s *DeletionService) deleteUserAccount(ctx context.Context, userID int, tra
 			{"mfa_sessions", s.countMFASessions},
 			{"api_tokens", s.countAPITokens},
 			{"password_reset_tokens", s.countPasswordResetTokens},
-			{"user_notification_targets", s.countNotificationTargets},
+			{"notification_rules", s.countNotificationRules},
+			{"notification_targets", s.countNotificationTargets},
+			{"telegram_links", s.countTelegramLinks},
+			{"notification_settings", s.countNotificationSettings},
+			{"delivery_failures", s.countDeliveryFailures},
+			{"web_push_subscriptions", s.countWebPushSubscriptions},
 			{"notifications", s.countNotifications},
 			{"time_sessions", s.countTimeSessions},
 			{"chore_history", s.countChoreHistory},
@@ -181,7 +186,12 @@ func (s *DeletionService) deleteUserAccount(ctx context.Context, userID int, tra
 		{"mfa_sessions", s.deleteMFASessions},
 		{"api_tokens", s.deleteAPITokens},
 		{"password_reset_tokens", s.deletePasswordResetTokens},
-		{"user_notification_targets", s.deleteNotificationTargets},
+		{"notification_rules", s.deleteNotificationRules},
+		{"notification_targets", s.deleteNotificationTargets},
+		{"telegram_links", s.deleteTelegramLinks},
+		{"notification_settings", s.deleteNotificationSettings},
+		{"delivery_failures", s.deleteDeliveryFailures},
+		{"web_push_subscriptions", s.deleteWebPushSubscriptions},
 		{"notifications", s.deleteNotifications},
 		{"time_sessions", s.deleteTimeSessions},
 		{"chore_history", s.deleteChoreHistory},
@@ -331,8 +341,28 @@ func (s *DeletionService) deletePasswordResetTokens(tx *gorm.DB, userID int) (in
 	return s.safeDelete(tx, "DELETE FROM user_password_resets WHERE user_id = ?", userID)
 }
 
+func (s *DeletionService) deleteNotificationRules(tx *gorm.DB, userID int) (int, error) {
+	return s.safeDelete(tx, "DELETE FROM notification_rules WHERE user_id = ?", userID)
+}
+
 func (s *DeletionService) deleteNotificationTargets(tx *gorm.DB, userID int) (int, error) {
-	return s.safeDelete(tx, "DELETE FROM user_notification_targets WHERE user_id = ?", userID)
+	return s.safeDelete(tx, "DELETE FROM notification_targets WHERE user_id = ?", userID)
+}
+
+func (s *DeletionService) deleteTelegramLinks(tx *gorm.DB, userID int) (int, error) {
+	return s.safeDelete(tx, "DELETE FROM telegram_links WHERE user_id = ?", userID)
+}
+
+func (s *DeletionService) deleteNotificationSettings(tx *gorm.DB, userID int) (int, error) {
+	return s.safeDelete(tx, "DELETE FROM notification_settings WHERE user_id = ?", userID)
+}
+
+func (s *DeletionService) deleteDeliveryFailures(tx *gorm.DB, userID int) (int, error) {
+	return s.safeDelete(tx, "DELETE FROM delivery_failures WHERE user_id = ?", userID)
+}
+
+func (s *DeletionService) deleteWebPushSubscriptions(tx *gorm.DB, userID int) (int, error) {
+	return s.safeDelete(tx, "DELETE FROM web_push_subscriptions WHERE user_id = ?", userID)
 }
 
 func (s *DeletionService) deleteNotifications(tx *gorm.DB, userID int) (int, error) {
@@ -436,8 +466,28 @@ func (s *DeletionService) countPasswordResetTokens(tx *gorm.DB, userID int) (int
 	return s.safeCount(tx, "SELECT COUNT(*) FROM user_password_resets WHERE user_id = ?", userID)
 }
 
+func (s *DeletionService) countNotificationRules(tx *gorm.DB, userID int) (int, error) {
+	return s.safeCount(tx, "SELECT COUNT(*) FROM notification_rules WHERE user_id = ?", userID)
+}
+
 func (s *DeletionService) countNotificationTargets(tx *gorm.DB, userID int) (int, error) {
-	return s.safeCount(tx, "SELECT COUNT(*) FROM user_notification_targets WHERE user_id = ?", userID)
+	return s.safeCount(tx, "SELECT COUNT(*) FROM notification_targets WHERE user_id = ?", userID)
+}
+
+func (s *DeletionService) countTelegramLinks(tx *gorm.DB, userID int) (int, error) {
+	return s.safeCount(tx, "SELECT COUNT(*) FROM telegram_links WHERE user_id = ?", userID)
+}
+
+func (s *DeletionService) countNotificationSettings(tx *gorm.DB, userID int) (int, error) {
+	return s.safeCount(tx, "SELECT COUNT(*) FROM notification_settings WHERE user_id = ?", userID)
+}
+
+func (s *DeletionService) countDeliveryFailures(tx *gorm.DB, userID int) (int, error) {
+	return s.safeCount(tx, "SELECT COUNT(*) FROM delivery_failures WHERE user_id = ?", userID)
+}
+
+func (s *DeletionService) countWebPushSubscriptions(tx *gorm.DB, userID int) (int, error) {
+	return s.safeCount(tx, "SELECT COUNT(*) FROM web_push_subscriptions WHERE user_id = ?", userID)
 }
 
 func (s *DeletionService) countNotifications(tx *gorm.DB, userID int) (int, error) {
@@ -3,10 +3,13 @@ package user
 import (
 	"crypto/rand"
 	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"html"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -16,8 +19,12 @@ import (
 	cModel "donetick.com/core/internal/circle/model"
 	cRepo "donetick.com/core/internal/circle/repo"
 	"donetick.com/core/internal/email"
+	"donetick.com/core/internal/events"
 	"donetick.com/core/internal/mfa"
 	nModel "donetick.com/core/internal/notifier/model"
+	emailNotifier "donetick.com/core/internal/notifier/service/email"
+	"donetick.com/core/internal/notifier/service/message"
+	"donetick.com/core/internal/notifier/service/webhook"
 	storage "donetick.com/core/internal/storage"
 	storageRepo "donetick.com/core/internal/storage/repo"
 	uModel "donetick.com/core/internal/user/model"
@@ -30,6 +37,7 @@ import (
 	"google.golang.org/api/googleapi"
 	"google.golang.org/api/oauth2/v1"
 	"google.golang.org/api/option"
+	"gorm.io/gorm"
 )
 
 type Handler struct {
@@ -42,21 +50,23 @@ type Handler struct {
 	isDonetickDotCom       bool
 	IsUserCreationDisabled bool
 	DonetickCloudConfig    config.DonetickCloudConfig
-	storage                *storage.S3Storage
+	storage                storage.Storage
 	storageRepo            *storageRepo.StorageRepository
-	signer                 *storage.URLSignerS3
+	signer                 storage.URLSigner
+	maxFileSize            int64
 	deletionService        *DeletionService
 	appleService           *apple.AppleService
 	mfaService             *mfa.MFAService
 	maxSubaccounts         int
 	plusMaxSubaccounts     int
+	telegramBotUsername    string
 }
 
 func NewHandler(ur *uRepo.UserRepository, cr *cRepo.CircleRepository,
 	jwtAuth *jwt.GinJWTMiddleware, tokenService *auth.TokenService,
 	email *email.EmailSender,
-	idp *auth.IdentityProvider, storage *storage.S3Storage,
-	signer *storage.URLSignerS3, storageRepo *storageRepo.StorageRepository,
+	idp *auth.IdentityProvider, storage storage.Storage,
+	signer storage.URLSigner, storageRepo *storageRepo.StorageRepository,
 	appleService *apple.AppleService,
 	deletionService *DeletionService, mfaService *mfa.MFAService, config *config.Config) *Handler {
 	return &Handler{
@@ -72,11 +82,13 @@ func NewHandler(ur *uRepo.UserRepository, cr *cRepo.CircleRepository,
 		storage:                storage,
 		storageRepo:            storageRepo,
 		signer:                 signer,
+		maxFileSize:            config.Storage.MaxFileSize,
 		deletionService:        deletionService,
 		appleService:           appleService,
 		mfaService:             mfaService,
 		maxSubaccounts:         config.FeatureLimits.MaxSubaccounts,
 		plusMaxSubaccounts:     config.FeatureLimits.PlusMaxSubaccounts,
+		telegramBotUsername:    config.Telegram.BotUsername,
 	}
 }
 
@@ -829,6 +841,7 @@ func (h *Handler) UpdateUserDetails(c *gin.Context) {
 		ChatID      *int64  `json:"chatID" binding:"omitempty"`
 		Image       *string `json:"image" binding:"omitempty"`
 		Timezone    *string `json:"timezone" binding:"omitempty"`
+		Locale      *string `json:"locale" binding:"omitempty"`
 	}
 	user, ok := auth.CurrentUser(c)
 	if !ok {
@@ -863,6 +876,17 @@ func (h *Handler) UpdateUserDetails(c *gin.Context) {
 		}
 		user.Timezone = *req.Timezone
 	}
+	if req.Locale != nil {
+		// an empty locale resets notifications to the default language
+		locale := message.NormalizeLocale(*req.Locale)
+		if locale == "" && *req.Locale != "" {
+			c.JSON(400, gin.H{
+				"error": "Unsupported locale, supported locales are " + strings.Join(message.SupportedLocales(), ", "),
+			})
+			return
+		}
+		user.Locale = locale
+	}
 
 	if err := h.userRepo.UpdateUser(c, &user.User); err != nil {
 		c.JSON(500, gin.H{
@@ -981,6 +1005,46 @@ func (h *Handler) DeleteUserToken(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{})
 }
 
+type notificationTargetRequest struct {
+	Name      string                      `json:"name"`
+	Type      nModel.NotificationPlatform `json:"type"`
+	Target    string                      `json:"target"`
+	IsPrimary bool                        `json:"isPrimary"`
+	// only used by webhook targets
+	Headers      nModel.WebhookHeaders `json:"headers"`
+	BodyTemplate string                `json:"body_template"`
+}
+
+// applyTo validates the request and copies it onto the target
+func (req *notificationTargetRequest) applyTo(target *uModel.NotificationTarget, currentUser *uModel.UserDetails) error {
+	target.Name = req.Name
+	target.Type = req.Type
+	target.TargetID = req.Target
+	target.Headers = nil
+	target.BodyTemplate = ""
+	switch req.Type {
+	case nModel.NotificationPlatformEmail:
+		if target.TargetID == "" {
+			// default to the address of the account
+			target.TargetID = currentUser.Email
+		}
+		return emailNotifier.ValidateTarget(target.TargetID)
+	case nModel.NotificationPlatformWebhook:
+		if err := webhook.ValidateTarget(req.Target, req.Headers, req.BodyTemplate); err != nil {
+			return err
+		}
+		target.Headers = req.Headers
+		target.BodyTemplate = req.BodyTemplate
+	case nModel.NotificationPlatformWebPush:
+		// pushed to every browser the user subscribed, there is no address
+		target.TargetID = ""
+	case nModel.NotificationPlatformNone:
+		return errors.New("a notification target needs a type")
+	}
+	return nil
+}
+
+// UpdateNotificationTarget sets the user's primary notification target, a None type removes it
 func (h *Handler) UpdateNotificationTarget(c *gin.Context) {
 	currentUser, ok := auth.CurrentUser(c)
 	if !ok {
@@ -988,38 +1052,268 @@ func (h *Handler) UpdateNotificationTarget(c *gin.Context) {
 		return
 	}
 
-	type Request struct {
-		Type   nModel.NotificationPlatform `json:"type"`
-		Target string                      `json:"target"`
-	}
-
-	var req Request
+	var req notificationTargetRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
 		return
 	}
+	primary, err := h.userRepo.GetPrimaryNotificationTarget(c, currentUser.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get notification target"})
+		return
+	}
 	if req.Type == nModel.NotificationPlatformNone {
-		err := h.userRepo.DeleteNotificationTarget(c, currentUser.ID)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete notification target"})
-			return
+		if primary != nil {
+			if err := h.userRepo.DeleteNotificationTarget(c, currentUser.ID, primary.ID); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete notification target"})
+				return
+			}
 		}
 		c.JSON(http.StatusOK, gin.H{})
 		return
 	}
 
-	err := h.userRepo.UpdateNotificationTarget(c, currentUser.ID, req.Target, req.Type)
-	if err != nil {
+	target := primary
+	if target == nil {
+		target = &uModel.NotificationTarget{UserID: currentUser.ID}
+	}
+	if req.Name == "" {
+		req.Name = target.Name
+	}
+	if err := req.applyTo(target, currentUser); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	target.IsPrimary = true
+	h.saveNotificationTarget(c, target)
+}
+
+func (h *Handler) saveNotificationTarget(c *gin.Context, target *uModel.NotificationTarget) {
+	if err := h.userRepo.SaveNotificationTarget(c, target); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification target"})
 		return
 	}
 
-	err = h.userRepo.UpdateNotificationTargetForAllNotifications(c, currentUser.ID, req.Target, req.Type)
-	if err != nil {
+	if err := h.userRepo.UpdateNotificationTargetForAllNotifications(c, target); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification target for all notifications"})
 		return
 	}
 
+	c.JSON(http.StatusOK, gin.H{"res": target})
+}
+
+// GetNotificationTargets godoc
+//
+//	@Summary		List notification targets
+//	@Description	Lists the notification targets of the current user, the primary target first
+//	@Tags			users
+//	@Produce		json
+//	@Success		200	{object}	map[string][]uModel.NotificationTarget
+//	@Router			/users/targets [get]
+func (h *Handler) GetNotificationTargets(c *gin.Context) {
+	currentUser, ok := auth.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get current user"})
+		return
+	}
+	targets, err := h.userRepo.GetNotificationTargets(c, currentUser.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get notification targets"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"res": targets})
+}
+
+// CreateNotificationTarget godoc
+//
+//	@Summary		Add a notification target
+//	@Description	Adds a notification target. The first target of a user becomes the primary one
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	map[string]uModel.NotificationTarget
+//	@Failure		400	{object}	map[string]string
+//	@Router			/users/targets [post]
+func (h *Handler) CreateNotificationTarget(c *gin.Context) {
+	currentUser, ok := auth.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get current user"})
+		return
+	}
+	var req notificationTargetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	target := &uModel.NotificationTarget{UserID: currentUser.ID, IsPrimary: req.IsPrimary}
+	if err := req.applyTo(target, currentUser); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.userRepo.SaveNotificationTarget(c, target); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create notification target"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"res": target})
+}
+
+// UpdateNotificationTargetByID godoc
+//
+//	@Summary		Update a notification target
+//	@Description	Updates a notification target and the pending notifications routed to it
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		int	true	"Target ID"
+//	@Success		200	{object}	map[string]uModel.NotificationTarget
+//	@Failure		400	{object}	map[string]string
+//	@Failure		404	{object}	map[string]string
+//	@Router			/users/targets/{id} [put]
+func (h *Handler) UpdateNotificationTargetByID(c *gin.Context) {
+	currentUser, ok := auth.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get current user"})
+		return
+	}
+	targetID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid target ID"})
+		return
+	}
+	var req notificationTargetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	target, err := h.userRepo.GetNotificationTarget(c, currentUser.ID, targetID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notification target not found"})
+		return
+	}
+	if err := req.applyTo(target, currentUser); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// the primary target can only be replaced by promoting another one
+	target.IsPrimary = target.IsPrimary || req.IsPrimary
+	h.saveNotificationTarget(c, target)
+}
+
+// DeleteNotificationTarget godoc
+//
+//	@Summary		Delete a notification target
+//	@Description	Deletes a notification target with its routing rules. Deleting the primary target promotes the oldest remaining one
+//	@Tags			users
+//	@Param			id	path	int	true	"Target ID"
+//	@Success		200
+//	@Failure		404	{object}	map[string]string
+//	@Router			/users/targets/{id} [delete]
+func (h *Handler) DeleteNotificationTarget(c *gin.Context) {
+	currentUser, ok := auth.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get current user"})
+		return
+	}
+	targetID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid target ID"})
+		return
+	}
+	if _, err := h.userRepo.GetNotificationTarget(c, currentUser.ID, targetID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notification target not found"})
+		return
+	}
+	if err := h.userRepo.DeleteNotificationTarget(c, currentUser.ID, targetID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete notification target"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+// telegramLinkCodeTTL is how long a code to link a Telegram account can be redeemed
+const telegramLinkCodeTTL = 15 * time.Minute
+
+// GetTelegramLink godoc
+//
+//	@Summary		Get the linked Telegram account
+//	@Description	Returns the Telegram account the bot acts as the current user for, null when none is linked
+//	@Tags			users
+//	@Produce		json
+//	@Success		200	{object}	map[string]uModel.TelegramLink
+//	@Router			/users/telegram [get]
+func (h *Handler) GetTelegramLink(c *gin.Context) {
+	currentUser, ok := auth.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get current user"})
+		return
+	}
+	link, err := h.userRepo.GetTelegramLink(c, currentUser.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get Telegram link"})
+		return
+	}
+	if link == nil || link.TelegramUserID == nil {
+		c.JSON(http.StatusOK, gin.H{"res": nil})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"res": link})
+}
+
+// CreateTelegramLinkCode godoc
+//
+//	@Summary		Create a Telegram link code
+//	@Description	Creates a one-time code that links the Telegram account sending "/start <code>" to the bot. The url opens the bot with the code when the bot username is configured
+//	@Tags			users
+//	@Produce		json
+//	@Success		200	{object}	map[string]interface{}
+//	@Router			/users/telegram/link [post]
+func (h *Handler) CreateTelegramLinkCode(c *gin.Context) {
+	currentUser, ok := auth.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get current user"})
+		return
+	}
+	randomBytes := make([]byte, 24)
+	if _, err := rand.Read(randomBytes); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate link code"})
+		return
+	}
+	// Telegram only passes letters, digits, _ and - as the start parameter
+	code := base64.RawURLEncoding.EncodeToString(randomBytes)
+	expiresAt := time.Now().UTC().Add(telegramLinkCodeTTL)
+	if err := h.userRepo.SetTelegramLinkCode(c, currentUser.ID, code, expiresAt); err != nil {
+		logging.FromContext(c).Errorw("Failed to store Telegram link code", "error", err, "userID", currentUser.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create link code"})
+		return
+	}
+	res := gin.H{
+		"code":      code,
+		"command":   "/start " + code,
+		"expiresAt": expiresAt,
+	}
+	if h.telegramBotUsername != "" {
+		res["url"] = "https://t.me/" + h.telegramBotUsername + "?start=" + code
+	}
+	c.JSON(http.StatusOK, gin.H{"res": res})
+}
+
+// DeleteTelegramLink godoc
+//
+//	@Summary		Unlink the Telegram account
+//	@Description	Stops the bot from acting as the current user and drops any pending link code
+//	@Tags			users
+//	@Success		200
+//	@Router			/users/telegram [delete]
+func (h *Handler) DeleteTelegramLink(c *gin.Context) {
+	currentUser, ok := auth.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get current user"})
+		return
+	}
+	if err := h.userRepo.DeleteTelegramLink(c, currentUser.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlink Telegram account"})
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{})
 }
 
@@ -1114,6 +1408,26 @@ func (h *Handler) setWebhook(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set webhook URL"})
 		return
 	}
+
+	// make sure deliveries can be signed as soon as a webhook is configured:
+	if req.URL != nil {
+		circle, err := h.circleRepo.GetCircleByID(c, currentUser.CircleID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get circle details"})
+			return
+		}
+		if circle.WebhookSecret == nil || *circle.WebhookSecret == "" {
+			secret, err := events.GenerateWebhookSecret()
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate webhook secret"})
+				return
+			}
+			if err := h.circleRepo.SetWebhookSecret(c, currentUser.CircleID, secret); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set webhook secret"})
+				return
+			}
+		}
+	}
 	c.JSON(http.StatusOK, gin.H{})
 }
 
@@ -1129,6 +1443,14 @@ func (h *Handler) updateProfilePhoto(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file"})
 		return
 	}
+	if file.Size > h.maxFileSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "File size is too large"})
+		return
+	}
+	if !strings.Contains(file.Filename, ".") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file extension"})
+		return
+	}
 	fileExtension := file.Filename[strings.LastIndex(file.Filename, "."):]
 	// validate file extension:
 	if fileExtension != ".jpg" && fileExtension != ".jpeg" && fileExtension != ".png" {
@@ -1622,6 +1944,13 @@ func Routes(router *gin.Engine, h *Handler, jwtAuth *jwt.GinJWTMiddleware, limit
 		userRoutes.DELETE("/tokens/:id", h.DeleteUserToken)
 		userRoutes.PUT("/webhook", h.setWebhook)
 		userRoutes.PUT("/targets", h.UpdateNotificationTarget)
+		userRoutes.GET("/targets", h.GetNotificationTargets)
+		userRoutes.POST("/targets", h.CreateNotificationTarget)
+		userRoutes.PUT("/targets/:id", h.UpdateNotificationTargetByID)
+		userRoutes.DELETE("/targets/:id", h.DeleteNotificationTarget)
+		userRoutes.GET("/telegram", h.GetTelegramLink)
+		userRoutes.POST("/telegram/link", h.CreateTelegramLinkCode)
+		userRoutes.DELETE("/telegram", h.DeleteTelegramLink)
 		userRoutes.PUT("change_password", h.updateUserPasswordLoggedInOnly)
 		userRoutes.POST("profile_photo", h.updateProfilePhoto)
 		userRoutes.GET("storage", h.getStorageUsage)
@@ -20,6 +20,7 @@ type User struct {
 	ChatID      int64            `json:"chatID" gorm:"column:chat_id"`                                               // Telegram chat ID
 	Image       string           `json:"image" gorm:"column:image"`                                                  // Image
 	Timezone    string           `json:"timezone" gorm:"column:timezone"`                                            // Timezone
+	Locale      string           `json:"locale" gorm:"column:locale"`                                                // Language of notifications, e.g. "de"
 	// Parent-Child relationship fields
 	ParentUserID *int     `json:"parentUserId,omitempty" gorm:"column:parent_user_id;index"`
 	UserType     UserType `json:"userType" gorm:"column:user_type;default:0"`
@@ -32,10 +33,10 @@ type User struct {
 	UpdatedAt       time.Time `json:"updated_at" gorm:"column:updated_at"`                            // Updated at
 	Disabled        bool      `json:"disabled" gorm:"column:disabled"`                                // Disabled
 	// Email    string `json:"email" gorm:"column:email"`       // Email
-	CustomerID              *string                `gorm:"column:customer_id;<-:false"`                      // read only column
-	Subscription            *string                `json:"subscription" gorm:"column:subscription;<-:false"` // read only column
-	Expiration              *time.Time             `json:"expiration" gorm:"column:expiration;<-:false"`     // read only column
-	UserNotificationTargets UserNotificationTarget `json:"notification_target" gorm:"foreignKey:UserID;references:ID"`
+	CustomerID              *string            `gorm:"column:customer_id;<-:false"`                                // read only column
+	Subscription            *string            `json:"subscription" gorm:"column:subscription;<-:false"`           // read only column
+	Expiration              *time.Time         `json:"expiration" gorm:"column:expiration;<-:false"`               // read only column
+	UserNotificationTargets NotificationTarget `json:"notification_target" gorm:"foreignKey:UserID;references:ID"` // the primary notification target
 }
 type UserDetails struct {
 	User
@@ -58,11 +59,31 @@ type APIToken struct {
 	CreatedAt time.Time `json:"createdAt" gorm:"column:created_at"`
 }
 
-type UserNotificationTarget struct {
-	UserID    int                         `json:"userId" gorm:"column:user_id;index;primaryKey"` // Index on userID
-	Type      nModel.NotificationPlatform `json:"type" gorm:"column:type"`                       // Type
-	TargetID  string                      `json:"target_id" gorm:"column:target_id"`             // Target ID
+// NotificationTarget is a destination for a user's notifications. A user can register several,
+// the primary one receives everything no routing rule claims.
+type NotificationTarget struct {
+	ID        int                         `json:"id" gorm:"primaryKey"`
+	UserID    int                         `json:"userId" gorm:"column:user_id;index"` // Index on userID
+	Name      string                      `json:"name" gorm:"column:name"`            // Name shown when choosing routing rules, e.g. "Phone"
+	Type      nModel.NotificationPlatform `json:"type" gorm:"column:type"`            // Type
+	TargetID  string                      `json:"target_id" gorm:"column:target_id"`  // Target ID, the URL for webhook targets
+	IsPrimary bool                        `json:"isPrimary" gorm:"column:is_primary"` // Receives notifications no rule routes elsewhere
 	CreatedAt time.Time                   `json:"-" gorm:"column:created_at"`
+	// Headers and BodyTemplate customize the request sent to webhook targets
+	Headers      nModel.WebhookHeaders `json:"headers,omitempty" gorm:"column:headers;type:text"`
+	BodyTemplate string                `json:"body_template,omitempty" gorm:"column:body_template;type:text"`
+}
+
+// TelegramLink is the Telegram account a user proved they own by sending the bot a one-time code
+// from Donetick. The bot acts on chores as the user when that account presses a button or sends a
+// command. A pending code is kept on the row until the bot redeems it.
+type TelegramLink struct {
+	ID             int        `json:"-" gorm:"primaryKey"`
+	UserID         int        `json:"-" gorm:"column:user_id;uniqueIndex"`
+	TelegramUserID *int64     `json:"telegramUserId" gorm:"column:telegram_user_id;index"` // Set once the code is redeemed
+	LinkedAt       *time.Time `json:"linkedAt" gorm:"column:linked_at"`
+	CodeHash       string     `json:"-" gorm:"column:code_hash;index"` // SHA-256 of the pending code, empty once redeemed
+	CodeExpiresAt  time.Time  `json:"-" gorm:"column:code_expires_at"`
 }
 
 // UserDeviceToken represents FCM/push notification tokens for user devices
@@ -78,6 +99,20 @@ type UserDeviceToken struct {
 	LastActiveAt time.Time `json:"lastActiveAt,omitempty" gorm:"column:last_active_at"`                            // Last active timestamp
 	CreatedAt    time.Time `json:"createdAt" gorm:"column:created_at"`                                             // Created timestamp
 }
+
+// WebPushSubscription is a browser's PushSubscription, notifications are encrypted with its keys
+// and posted to its endpoint
+type WebPushSubscription struct {
+	ID         int        `json:"id" gorm:"primaryKey"`
+	UserID     int        `json:"userId" gorm:"column:user_id;not null;index"`
+	Endpoint   string     `json:"endpoint" gorm:"column:endpoint;type:varchar(1024);not null;uniqueIndex"`
+	P256dh     string     `json:"-" gorm:"column:p256dh;not null"`
+	Auth       string     `json:"-" gorm:"column:auth;not null"`
+	UserAgent  string     `json:"userAgent,omitempty" gorm:"column:user_agent;type:varchar(255)"`
+	CreatedAt  time.Time  `json:"createdAt" gorm:"column:created_at"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty" gorm:"column:last_used_at"`
+}
+
 type AuthProviderType int
 
 const (
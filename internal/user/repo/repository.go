@@ -2,6 +2,8 @@ package user
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"time"
@@ -12,6 +14,7 @@ import (
 	uModel "donetick.com/core/internal/user/model"
 	"donetick.com/core/logging"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type IUserRepository interface {
@@ -91,12 +94,12 @@ func (r *UserRepository) GetUserByUsername(c context.Context, username string) (
 	var user *uModel.UserDetails
 	if r.isDonetickDotCom {
 		now := time.Now().UTC()
-		if err := r.db.WithContext(c).Preload("UserNotificationTargets").Table("users u").Select("u.*, s.status as subscription, s.expires_at as expiration, c.webhook_url as webhook_url").Joins("left join subscriptions s on s.circle_id = u.circle_id AND s.status = 'active' AND (s.expires_at IS NULL OR s.expires_at > ?)", now).Joins("left join circles c on c.id = u.circle_id").Where("username = ?", username).First(&user).Error; err != nil {
+		if err := r.db.WithContext(c).Preload("UserNotificationTargets", "is_primary = ?", true).Table("users u").Select("u.*, s.status as subscription, s.expires_at as expiration, c.webhook_url as webhook_url").Joins("left join subscriptions s on s.circle_id = u.circle_id AND s.status = 'active' AND (s.expires_at IS NULL OR s.expires_at > ?)", now).Joins("left join circles c on c.id = u.circle_id").Where("username = ?", username).First(&user).Error; err != nil {
 			return nil, err
 		}
 	} else {
 		// For self-hosted, first get the user without subscription/expiration fields
-		if err := r.db.WithContext(c).Preload("UserNotificationTargets").Table("users u").Select("u.*, c.webhook_url as webhook_url").Joins("left join circles c on c.id = u.circle_id").Where("username = ?", username).First(&user).Error; err != nil {
+		if err := r.db.WithContext(c).Preload("UserNotificationTargets", "is_primary = ?", true).Table("users u").Select("u.*, c.webhook_url as webhook_url").Joins("left join circles c on c.id = u.circle_id").Where("username = ?", username).First(&user).Error; err != nil {
 			return nil, err
 		}
 		// Then manually set the subscription status and expiration for self-hosted users
@@ -113,7 +116,7 @@ func (r *UserRepository) GetUserByID(c context.Context, userID int) (*uModel.Use
 	var user *uModel.User
 	now := time.Now().UTC()
 	if r.isDonetickDotCom {
-		if err := r.db.WithContext(c).Preload("UserNotificationTargets").
+		if err := r.db.WithContext(c).Preload("UserNotificationTargets", "is_primary = ?", true).
 			Table("users u").
 			Select("u.*, s.status as subscription, s.expires_at as expiration, c.webhook_url as webhook_url").
 			Joins("left join subscriptions s on s.circle_id = u.circle_id AND s.status = 'active' AND s.expires_at > ?", now).
@@ -123,7 +126,7 @@ func (r *UserRepository) GetUserByID(c context.Context, userID int) (*uModel.Use
 			return nil, err
 		}
 	} else {
-		if err := r.db.WithContext(c).Preload("UserNotificationTargets").
+		if err := r.db.WithContext(c).Preload("UserNotificationTargets", "is_primary = ?", true).
 			Table("users u").
 			Select("u.*, c.webhook_url as webhook_url").
 			Joins("left join circles c on c.id = u.circle_id").
@@ -265,22 +268,167 @@ func (r *UserRepository) DeleteAPIToken(c context.Context, userID int, tokenID s
 	return r.db.WithContext(c).Where("id = ? AND user_id = ?", tokenID, userID).Delete(&uModel.APIToken{}).Error
 }
 
-func (r *UserRepository) UpdateNotificationTarget(c context.Context, userID int, targetID string, targetType nModel.NotificationPlatform) error {
-	return r.db.WithContext(c).Save(&uModel.UserNotificationTarget{
-		UserID:    userID,
-		TargetID:  targetID,
-		Type:      targetType,
-		CreatedAt: time.Now().UTC(),
+func (r *UserRepository) GetNotificationTargets(c context.Context, userID int) ([]*uModel.NotificationTarget, error) {
+	var targets []*uModel.NotificationTarget
+	if err := r.db.WithContext(c).Where("user_id = ?", userID).Order("is_primary desc, id asc").Find(&targets).Error; err != nil {
+		return nil, err
+	}
+	return targets, nil
+}
+
+func (r *UserRepository) GetNotificationTarget(c context.Context, userID int, targetID int) (*uModel.NotificationTarget, error) {
+	var target uModel.NotificationTarget
+	if err := r.db.WithContext(c).Where("id = ? AND user_id = ?", targetID, userID).First(&target).Error; err != nil {
+		return nil, err
+	}
+	return &target, nil
+}
+
+// GetPrimaryNotificationTarget returns the user's primary target, or nil when they have none
+func (r *UserRepository) GetPrimaryNotificationTarget(c context.Context, userID int) (*uModel.NotificationTarget, error) {
+	var targets []*uModel.NotificationTarget
+	if err := r.db.WithContext(c).Where("user_id = ? AND is_primary = ?", userID, true).Limit(1).Find(&targets).Error; err != nil {
+		return nil, err
+	}
+	if len(targets) == 0 {
+		return nil, nil
+	}
+	return targets[0], nil
+}
+
+// SaveNotificationTarget creates or updates a target. The user's first target becomes primary, and
+// making a target primary demotes the previous one.
+func (r *UserRepository) SaveNotificationTarget(c context.Context, target *uModel.NotificationTarget) error {
+	return r.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if !target.IsPrimary {
+			var primaryCount int64
+			if err := tx.Model(&uModel.NotificationTarget{}).Where("user_id = ? AND is_primary = ? AND id <> ?", target.UserID, true, target.ID).Count(&primaryCount).Error; err != nil {
+				return err
+			}
+			target.IsPrimary = primaryCount == 0
+		} else if err := tx.Model(&uModel.NotificationTarget{}).Where("user_id = ? AND id <> ?", target.UserID, target.ID).Update("is_primary", false).Error; err != nil {
+			return err
+		}
+		if target.ID == 0 {
+			target.CreatedAt = time.Now().UTC()
+		}
+		return tx.Save(target).Error
+	})
+}
+
+// DeleteNotificationTarget deletes a target with the rules and pending notifications routed to it.
+// When the primary target is deleted the oldest remaining target takes its place.
+func (r *UserRepository) DeleteNotificationTarget(c context.Context, userID int, targetID int) error {
+	return r.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		var target uModel.NotificationTarget
+		if err := tx.Where("id = ? AND user_id = ?", targetID, userID).First(&target).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ? AND notification_target_id = ?", userID, targetID).Delete(&nModel.NotificationRule{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("notification_target_id = ? AND is_sent = ?", targetID, false).Delete(&nModel.Notification{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&target).Error; err != nil {
+			return err
+		}
+		if !target.IsPrimary {
+			return nil
+		}
+		var next uModel.NotificationTarget
+		if err := tx.Where("user_id = ?", userID).Order("id asc").Limit(1).Find(&next).Error; err != nil || next.ID == 0 {
+			return err
+		}
+		return tx.Model(&next).Update("is_primary", true).Error
+	})
+}
+
+// hashTelegramLinkCode is how link codes are stored, so a leaked database doesn't link accounts
+func hashTelegramLinkCode(code string) string {
+	hash := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(hash[:])
+}
+
+// SetTelegramLinkCode stores the code the user sends the bot to link their Telegram account. It
+// replaces any pending code and keeps the current link until the new code is redeemed.
+func (r *UserRepository) SetTelegramLinkCode(c context.Context, userID int, code string, expiresAt time.Time) error {
+	return r.db.WithContext(c).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"code_hash", "code_expires_at"}),
+	}).Create(&uModel.TelegramLink{
+		UserID:        userID,
+		CodeHash:      hashTelegramLinkCode(code),
+		CodeExpiresAt: expiresAt,
 	}).Error
 }
 
-func (r *UserRepository) DeleteNotificationTarget(c context.Context, userID int) error {
-	return r.db.WithContext(c).Where("user_id = ?", userID).Delete(&uModel.UserNotificationTarget{}).Error
+// RedeemTelegramLinkCode links the Telegram account to the user who created the code and returns
+// that user's ID. A code works once and only until it expires, gorm.ErrRecordNotFound otherwise.
+func (r *UserRepository) RedeemTelegramLinkCode(c context.Context, code string, telegramUserID int64, now time.Time) (int, error) {
+	codeHash := hashTelegramLinkCode(code)
+	var link uModel.TelegramLink
+	err := r.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("code_hash = ? AND code_expires_at > ?", codeHash, now).First(&link).Error; err != nil {
+			return err
+		}
+		// the code hash in the condition makes a concurrent redemption of the same code fail
+		result := tx.Model(&uModel.TelegramLink{}).Where("id = ? AND code_hash = ?", link.ID, codeHash).Updates(map[string]interface{}{
+			"telegram_user_id": telegramUserID,
+			"linked_at":        now,
+			"code_hash":        "",
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return link.UserID, nil
 }
 
-func (r *UserRepository) UpdateNotificationTargetForAllNotifications(c context.Context, userID int, targetID string, targetType nModel.NotificationPlatform) error {
-	return r.db.WithContext(c).Model(&nModel.Notification{}).Where("user_id = ?", userID).Update("target_id", targetID).Update("type", targetType).Error
+// GetTelegramLink returns the user's link, which has no Telegram user while the code is pending
+func (r *UserRepository) GetTelegramLink(c context.Context, userID int) (*uModel.TelegramLink, error) {
+	var link uModel.TelegramLink
+	if err := r.db.WithContext(c).Where("user_id = ?", userID).First(&link).Error; err != nil {
+		return nil, err
+	}
+	return &link, nil
 }
+
+// DeleteTelegramLink unlinks the user's Telegram account and drops any pending code
+func (r *UserRepository) DeleteTelegramLink(c context.Context, userID int) error {
+	return r.db.WithContext(c).Where("user_id = ?", userID).Delete(&uModel.TelegramLink{}).Error
+}
+
+// GetUserIDsByTelegramUser returns the users who linked the Telegram account. One Telegram account
+// can be linked to several users, e.g. a personal and a family account.
+func (r *UserRepository) GetUserIDsByTelegramUser(c context.Context, telegramUserID int64) ([]int, error) {
+	var userIDs []int
+	if err := r.db.WithContext(c).Model(&uModel.TelegramLink{}).Where("telegram_user_id = ?", telegramUserID).Order("user_id asc").Pluck("user_id", &userIDs).Error; err != nil {
+		return nil, err
+	}
+	return userIDs, nil
+}
+
+// UpdateNotificationTargetForAllNotifications points the pending notifications routed to the target
+// at its new platform and address
+func (r *UserRepository) UpdateNotificationTargetForAllNotifications(c context.Context, target *uModel.NotificationTarget) error {
+	query := r.db.WithContext(c).Model(&nModel.Notification{}).Where("is_sent = ?", false)
+	if target.IsPrimary {
+		// notifications created before targets were routed have no target ID and went to the primary target
+		query = query.Where("notification_target_id = ? OR (notification_target_id IS NULL AND user_id = ?)", target.ID, target.UserID)
+	} else {
+		query = query.Where("notification_target_id = ?", target.ID)
+	}
+	return query.Updates(map[string]interface{}{"target_id": target.TargetID, "type": target.Type}).Error
+}
+
 func (r *UserRepository) UpdatePasswordByUserId(c context.Context, userID int, password string) error {
 	return r.db.WithContext(c).Model(&uModel.User{}).Where("id = ?", userID).Update("password", password).Error
 }
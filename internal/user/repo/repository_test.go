@@ -0,0 +1,91 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"donetick.com/core/config"
+	"donetick.com/core/internal/database"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupUserRepo(t *testing.T) *UserRepository {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect database: %v", err)
+	}
+	if err := database.Migration(db); err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
+	}
+	return NewUserRepository(db, &config.Config{Database: config.DatabaseConfig{Type: "sqlite"}})
+}
+
+func TestRedeemTelegramLinkCode(t *testing.T) {
+	now := time.Now().UTC()
+	tests := []struct {
+		name       string
+		expiresAt  time.Time
+		redeemWith string
+		wantLinked bool
+	}{
+		{name: "valid code", expiresAt: now.Add(time.Minute), redeemWith: "code-1", wantLinked: true},
+		{name: "expired code", expiresAt: now.Add(-time.Minute), redeemWith: "code-1"},
+		{name: "wrong code", expiresAt: now.Add(time.Minute), redeemWith: "code-2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := setupUserRepo(t)
+			ctx := context.Background()
+			if err := r.SetTelegramLinkCode(ctx, 3, "code-1", tt.expiresAt); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			userID, err := r.RedeemTelegramLinkCode(ctx, tt.redeemWith, 42, now)
+			if !tt.wantLinked {
+				if !errors.Is(err, gorm.ErrRecordNotFound) {
+					t.Fatalf("expected the code to be refused, got user %d and error %v", userID, err)
+				}
+				if userIDs, _ := r.GetUserIDsByTelegramUser(ctx, 42); len(userIDs) != 0 {
+					t.Errorf("expected no linked users, got %v", userIDs)
+				}
+				return
+			}
+			if err != nil || userID != 3 {
+				t.Fatalf("expected user 3 to be linked, got %d and error %v", userID, err)
+			}
+			if userIDs, _ := r.GetUserIDsByTelegramUser(ctx, 42); len(userIDs) != 1 || userIDs[0] != 3 {
+				t.Errorf("expected user 3 to be linked, got %v", userIDs)
+			}
+			if _, err := r.RedeemTelegramLinkCode(ctx, tt.redeemWith, 43, now); !errors.Is(err, gorm.ErrRecordNotFound) {
+				t.Errorf("expected a redeemed code to be refused, got %v", err)
+			}
+		})
+	}
+}
+
+func TestTelegramAccountLinkedToSeveralUsers(t *testing.T) {
+	r := setupUserRepo(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	for userID, code := range map[int]string{3: "code-3", 4: "code-4"} {
+		if err := r.SetTelegramLinkCode(ctx, userID, code, now.Add(time.Minute)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := r.RedeemTelegramLinkCode(ctx, code, 42, now); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if userIDs, _ := r.GetUserIDsByTelegramUser(ctx, 42); len(userIDs) != 2 {
+		t.Fatalf("expected both users to be linked, got %v", userIDs)
+	}
+
+	if err := r.DeleteTelegramLink(ctx, 4); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if userIDs, _ := r.GetUserIDsByTelegramUser(ctx, 42); len(userIDs) != 1 || userIDs[0] != 3 {
+		t.Errorf("expected only user 3 to stay linked, got %v", userIDs)
+	}
+}
@@ -14,6 +14,8 @@ import (
 	"donetick.com/core/frontend"
 	auth "donetick.com/core/internal/auth"
 	"donetick.com/core/internal/auth/apple"
+	"donetick.com/core/internal/calendar"
+	calRepo "donetick.com/core/internal/calendar/repo"
 	"donetick.com/core/internal/chore"
 	chRepo "donetick.com/core/internal/chore/repo"
 	"donetick.com/core/internal/circle"
@@ -23,6 +25,7 @@ import (
 	dRepo "donetick.com/core/internal/device/repo"
 	"donetick.com/core/internal/email"
 	"donetick.com/core/internal/events"
+	eRepo "donetick.com/core/internal/events/repo"
 	label "donetick.com/core/internal/label"
 	lRepo "donetick.com/core/internal/label/repo"
 	"donetick.com/core/internal/mfa"
@@ -35,12 +38,16 @@ import (
 	nRepo "donetick.com/core/internal/notifier/repo"
 	nps "donetick.com/core/internal/notifier/service"
 	discord "donetick.com/core/internal/notifier/service/discord"
+	emailNotifier "donetick.com/core/internal/notifier/service/email"
 	"donetick.com/core/internal/notifier/service/fcm"
 	"donetick.com/core/internal/notifier/service/pushover"
 	telegram "donetick.com/core/internal/notifier/service/telegram"
+	"donetick.com/core/internal/notifier/service/webhook"
+	"donetick.com/core/internal/notifier/service/webpush"
 	pRepo "donetick.com/core/internal/points/repo"
 	"donetick.com/core/internal/realtime"
 	"donetick.com/core/internal/resource"
+	"donetick.com/core/internal/reward"
 	"donetick.com/core/internal/storage"
 	storageRepo "donetick.com/core/internal/storage/repo"
 	spRepo "donetick.com/core/internal/subtask/repo"
@@ -101,10 +108,19 @@ func main() {
 		// add notifier
 		fx.Provide(pushover.NewPushover),
 		fx.Provide(telegram.NewTelegramNotifier),
+		fx.Provide(telegram.NewBot),
+		fx.Provide(chore.NewTelegramActions),
 		fx.Provide(discord.NewDiscordNotifier),
+		fx.Provide(webhook.NewWebhookNotifier),
+		fx.Provide(emailNotifier.NewActionSigner),
+		fx.Provide(emailNotifier.NewEmailNotifier),
 		fx.Provide(notifier.NewNotifier),
+		fx.Provide(notifier.NewHandler),
 		fx.Provide(events.NewEventsProducer),
+		fx.Provide(eRepo.NewWebhookRepository),
+		fx.Provide(events.NewHandler),
 		fx.Provide(fcm.NewFCMNotifier),
+		fx.Provide(webpush.NewWebPushNotifier),
 
 		// Rate limiter
 		fx.Provide(utils.NewRateLimiter),
@@ -125,6 +141,7 @@ func main() {
 		// add handlers also
 		fx.Provide(newServer),
 		fx.Provide(notifier.NewScheduler),
+		fx.Provide(chore.NewDeadlineScheduler),
 
 		// things
 		fx.Provide(tRepo.NewThingRepository),
@@ -132,6 +149,7 @@ func main() {
 		// points
 		fx.Provide(pRepo.NewPointsRepository),
 		fx.Provide(spRepo.NewSubTasksRepository),
+		fx.Provide(reward.NewHandler),
 
 		// Labels:
 		fx.Provide(lRepo.NewLabelRepository),
@@ -141,6 +159,10 @@ func main() {
 		fx.Provide(pjRepo.NewProjectRepository),
 		fx.Provide(project.NewHandler),
 
+		// Calendar feeds:
+		fx.Provide(calRepo.NewCalendarRepository),
+		fx.Provide(calendar.NewHandler),
+
 		// Filters:
 		fx.Provide(fRepo.NewFilterRepository),
 		fx.Provide(filter.NewHandler),
@@ -164,12 +186,9 @@ func main() {
 		// Docs
 		fx.Provide(docs.NewHandler),
 
-		// storage :
-		// is storage local or remote?
-		// fx.Provide(storage.NewLocalStorage),
-		// fx.Provide(storage.NewURLSignerLocal),
-		fx.Provide(storage.NewS3Storage),
-		fx.Provide(storage.NewURLSignerS3),
+		// storage, local or S3 based on storage.storage_type:
+		fx.Provide(storage.NewStorage),
+		fx.Provide(storage.NewURLSigner),
 
 		fx.Provide(storage.NewHandler),
 		fx.Provide(storageRepo.NewStorageRepository),
@@ -179,6 +198,8 @@ func main() {
 		// fx.Provide(backup.NewHandler),
 
 		// Real-time service and components
+		fx.Provide(realtime.NewBroker),
+		fx.Provide(realtime.NewReplayLog),
 		fx.Provide(realtime.NewRealTimeService),
 		fx.Provide(realtime.NewAuthMiddleware),
 
@@ -196,6 +217,10 @@ func main() {
 			label.Routes,
 			project.Routes,
 			filter.Routes,
+			events.Routes,
+			calendar.Routes,
+			reward.Routes,
+			notifier.Routes,
 
 			storage.Routes,
 			frontend.Routes,
@@ -217,7 +242,7 @@ func main() {
 
 }
 
-func newServer(lc fx.Lifecycle, cfg *config.Config, db *gorm.DB, notifier *notifier.Scheduler, eventProducer *events.EventsProducer, mfaCleanup *mfa.CleanupService, authCleanup *auth.CleanupService, rts *realtime.RealTimeService) *gin.Engine {
+func newServer(lc fx.Lifecycle, cfg *config.Config, db *gorm.DB, notifier *notifier.Scheduler, deadlineScheduler *chore.DeadlineScheduler, eventProducer *events.EventsProducer, mfaCleanup *mfa.CleanupService, authCleanup *auth.CleanupService, rts *realtime.RealTimeService, telegramBot *telegram.Bot) *gin.Engine {
 	// Set Gin mode based on logging configuration
 	if cfg.Logging.Development || strings.ToLower(cfg.Logging.Level) == "debug" {
 		gin.SetMode(gin.DebugMode)
@@ -276,9 +301,11 @@ func newServer(lc fx.Lifecycle, cfg *config.Config, db *gorm.DB, notifier *notif
 				}
 			}
 			notifier.Start(context.Background())
+			deadlineScheduler.Start(context.Background())
 			eventProducer.Start(context.Background())
 			mfaCleanup.Start(context.Background())
 			authCleanup.Start(context.Background())
+			telegramBot.Start(context.Background())
 
 			// Start real-time service
 			if err := rts.Start(ctx); err != nil {
@@ -308,8 +335,11 @@ func newServer(lc fx.Lifecycle, cfg *config.Config, db *gorm.DB, notifier *notif
 				log.Printf("Real-time service shutdown timeout, forcing shutdown")
 			}
 
+			deadlineScheduler.Stop()
+			eventProducer.Stop()
 			mfaCleanup.Stop()
 			authCleanup.Stop()
+			telegramBot.Stop()
 
 			// Shutdown HTTP server with timeout
 			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
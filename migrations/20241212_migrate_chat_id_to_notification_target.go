@@ -3,6 +3,7 @@ package migrations
 import (
 	"context"
 	"fmt"
+	"time"
 
 	nModel "donetick.com/core/internal/notifier/model"
 	uModel "donetick.com/core/internal/user/model"
@@ -24,15 +25,24 @@ func (m MigrateChatIdToNotificationTarget20241212) Down(ctx context.Context, db
 	return nil
 }
 
+// UserNotificationTarget is the single notification target per user table this migration creates,
+// later moved to notification targets by MigrateNotificationTargets20261018
+type UserNotificationTarget struct {
+	UserID    int                         `gorm:"column:user_id;index;primaryKey"`
+	Type      nModel.NotificationPlatform `gorm:"column:type"`
+	TargetID  string                      `gorm:"column:target_id"`
+	CreatedAt time.Time                   `gorm:"column:created_at"`
+}
+
 func (m MigrateChatIdToNotificationTarget20241212) Up(ctx context.Context, db *gorm.DB) error {
 	log := logging.FromContext(ctx)
 	// if UserNotificationTarget table already exists drop it and recreate it:
-	if err := db.Migrator().DropTable(&uModel.UserNotificationTarget{}); err != nil {
+	if err := db.Migrator().DropTable(&UserNotificationTarget{}); err != nil {
 		log.Errorf("Failed to drop user_notification_targets table: %v", err)
 	}
 
 	// Create UserNotificationTarget table
-	if err := db.AutoMigrate(&uModel.UserNotificationTarget{}); err != nil {
+	if err := db.AutoMigrate(&UserNotificationTarget{}); err != nil {
 		log.Errorf("Failed to create user_notification_targets table: %v", err)
 	}
 
@@ -44,12 +54,12 @@ func (m MigrateChatIdToNotificationTarget20241212) Up(ctx context.Context, db *g
 			log.Errorf("Failed to fetch users: %v", err)
 		}
 
-		var notificationTargets []uModel.UserNotificationTarget
+		var notificationTargets []UserNotificationTarget
 		for _, user := range users {
 			if user.ChatID == 0 {
 				continue
 			}
-			notificationTargets = append(notificationTargets, uModel.UserNotificationTarget{
+			notificationTargets = append(notificationTargets, UserNotificationTarget{
 				UserID:   user.ID,
 				TargetID: fmt.Sprint(user.ChatID),
 				Type:     nModel.NotificationPlatformTelegram,
@@ -0,0 +1,51 @@
+package migrations
+
+import (
+	"context"
+
+	"donetick.com/core/logging"
+	"gorm.io/gorm"
+)
+
+type BackfillPointsHistory20261017 struct{}
+
+func (m BackfillPointsHistory20261017) ID() string {
+	return "20261017_backfill_points_history"
+}
+
+func (m BackfillPointsHistory20261017) Description() string {
+	return `Create points history entries for chores completed before completions were recorded, so the points ledger adds up to the members' balances.`
+}
+
+func (m BackfillPointsHistory20261017) Down(ctx context.Context, db *gorm.DB) error {
+	// No-op: the backfilled entries can't be told apart from real ones
+	return nil
+}
+
+func (m BackfillPointsHistory20261017) Up(ctx context.Context, db *gorm.DB) error {
+	log := logging.FromContext(ctx)
+
+	if !db.Migrator().HasTable("points_histories") || !db.Migrator().HasTable("chore_histories") {
+		log.Info("Points or chore history table does not exist, skipping migration")
+		return nil
+	}
+
+	// 0 = add action, 1 = completed status
+	res := db.Exec(`
+		INSERT INTO points_histories (action, points, created_at, created_by, user_id, circle_id)
+		SELECT 0, ch.points, COALESCE(ch.performed_at, ch.created_at), ch.completed_by, ch.completed_by, c.circle_id
+		FROM chore_histories ch
+		JOIN chores c ON c.id = ch.chore_id
+		WHERE ch.status = 1 AND ch.points IS NOT NULL AND ch.points > 0`)
+	if res.Error != nil {
+		log.Errorf("Failed to backfill points history: %v", res.Error)
+		return res.Error
+	}
+
+	log.Infof("Backfilled %d points history entries", res.RowsAffected)
+	return nil
+}
+
+func init() {
+	Register(BackfillPointsHistory20261017{})
+}
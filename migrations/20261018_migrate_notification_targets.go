@@ -0,0 +1,63 @@
+package migrations
+
+import (
+	"context"
+
+	"donetick.com/core/logging"
+	"gorm.io/gorm"
+)
+
+type MigrateNotificationTargets20261018 struct{}
+
+func (m MigrateNotificationTargets20261018) ID() string {
+	return "20261018_migrate_notification_targets"
+}
+
+func (m MigrateNotificationTargets20261018) Description() string {
+	return `Move user notification targets to the notification targets table, which allows several targets per user. The existing target becomes the user's primary target.`
+}
+
+func (m MigrateNotificationTargets20261018) Down(ctx context.Context, db *gorm.DB) error {
+	return nil
+}
+
+func (m MigrateNotificationTargets20261018) Up(ctx context.Context, db *gorm.DB) error {
+	log := logging.FromContext(ctx)
+
+	if !db.Migrator().HasTable("user_notification_targets") {
+		log.Info("User notification targets table does not exist, skipping migration")
+		return nil
+	}
+	if !db.Migrator().HasTable("notification_targets") {
+		log.Info("Notification targets table does not exist, skipping migration")
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		// headers and body_template were added to user_notification_targets by AutoMigrate
+		headers := "NULL, NULL"
+		if tx.Migrator().HasColumn("user_notification_targets", "headers") {
+			headers = "unt.headers, unt.body_template"
+		}
+		res := tx.Exec(`
+			INSERT INTO notification_targets (user_id, name, type, target_id, is_primary, created_at, headers, body_template)
+			SELECT unt.user_id, '', unt.type, unt.target_id, true, unt.created_at, ` + headers + `
+			FROM user_notification_targets unt
+			WHERE NOT EXISTS (SELECT 1 FROM notification_targets nt WHERE nt.user_id = unt.user_id)`)
+		if res.Error != nil {
+			log.Errorf("Failed to copy notification targets: %v", res.Error)
+			return res.Error
+		}
+		log.Infof("Moved %d notification targets", res.RowsAffected)
+
+		if err := tx.Migrator().DropTable("user_notification_targets"); err != nil {
+			log.Errorf("Failed to drop user_notification_targets table: %v", err)
+			return err
+		}
+		return nil
+	})
+}
+
+func init() {
+	Register(MigrateNotificationTargets20261018{})
+}